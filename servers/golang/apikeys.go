@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/pkg/pokerserver"
+)
+
+const apiKeysUsage = `Usage: server apikeys [-file path] <command>
+
+Commands:
+  create -name <name> -scopes <scope,...> [-rate-limit <per minute>]
+  list
+  revoke <id>
+
+Scopes: rooms:create, export, admin
+`
+
+// runAPIKeys manages the API keys in the keys file, which the running server
+// picks up without a restart. It returns the exit code.
+func runAPIKeys(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("apikeys", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, apiKeysUsage) }
+	path := flags.String("file", os.Getenv("API_KEYS_FILE"), "API keys file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *path == "" || flags.NArg() == 0 {
+		fmt.Fprint(stderr, apiKeysUsage)
+		return 2
+	}
+
+	store, err := pokerserver.OpenAPIKeyStore(*path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	switch command := flags.Arg(0); command {
+	case "create":
+		create := flag.NewFlagSet("create", flag.ContinueOnError)
+		create.SetOutput(stderr)
+		name := create.String("name", "", "what the key is for")
+		scopes := create.String("scopes", "", "comma-separated scopes")
+		rateLimit := create.Int("rate-limit", 0, "requests per minute, 0 for the server default")
+		if err := create.Parse(flags.Args()[1:]); err != nil {
+			return 2
+		}
+		if *name == "" || *scopes == "" {
+			fmt.Fprint(stderr, apiKeysUsage)
+			return 2
+		}
+		key, secret, err := store.Create(*name, strings.FieldsFunc(*scopes, func(r rune) bool { return r == ',' || r == ' ' }), *rateLimit)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		fmt.Fprintf(stderr, "Created key %s; it won't be shown again:\n", key.ID)
+		fmt.Fprintln(stdout, secret)
+	case "list":
+		keys, err := store.List()
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "ID\tNAME\tSCOPES\tRATE LIMIT\tCREATED")
+		for _, key := range keys {
+			rateLimit := "default"
+			if key.RateLimit > 0 {
+				rateLimit = fmt.Sprintf("%d/min", key.RateLimit)
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, strings.Join(key.Scopes, ","), rateLimit, key.CreatedAt.Format("2006-01-02 15:04"))
+		}
+		table.Flush()
+	case "revoke":
+		if flags.NArg() != 2 {
+			fmt.Fprint(stderr, apiKeysUsage)
+			return 2
+		}
+		revoked, err := store.Revoke(flags.Arg(1))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		if !revoked {
+			fmt.Fprintf(stderr, "No key %s\n", flags.Arg(1))
+			return 1
+		}
+		fmt.Fprintf(stderr, "Revoked key %s\n", flags.Arg(1))
+	default:
+		fmt.Fprintf(stderr, "Unknown command %q\n%s", command, apiKeysUsage)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,262 @@
+// Command loadtest drives a running server with simulated rooms: every client
+// joins, then each round they all vote, the first client reveals and then
+// starts the next round. It reports latency percentiles for each step, as
+// seen by the clients.
+//
+//	go run ./cmd/loadtest -url ws://localhost:3001/api/ws -rooms 50 -clients 8 -rounds 20
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type message struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type participant struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// client is a simulated participant. Messages are read in the background so
+// the server never blocks on it, and consumed in order by await.
+type client struct {
+	conn     *websocket.Conn
+	name     string
+	id       string
+	messages chan message
+	writeMu  sync.Mutex
+}
+
+func dial(url, origin, name string) (*client, error) {
+	header := http.Header{}
+	if origin != "" {
+		header.Set("Origin", origin)
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{conn: conn, name: name, messages: make(chan message, 1024)}
+	go func() {
+		defer close(c.messages)
+		for {
+			var msg message
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+	return c, nil
+}
+
+func (c *client) send(msgType string, data interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteJSON(map[string]interface{}{"type": msgType, "data": data})
+}
+
+// await skips messages until one of msgType satisfies match.
+func (c *client) await(msgType string, timeout time.Duration, match func(json.RawMessage) bool) error {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case msg, ok := <-c.messages:
+			if !ok {
+				return errors.New("connection closed")
+			}
+			if msg.Type == msgType && (match == nil || match(msg.Data)) {
+				return nil
+			}
+		case <-deadline:
+			return fmt.Errorf("timed out waiting for %s", msgType)
+		}
+	}
+}
+
+// recorder collects latencies per step.
+type recorder struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+}
+
+func (r *recorder) record(step string, start time.Time, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.errors[step]++
+		return
+	}
+	r.latencies[step] = append(r.latencies[step], time.Since(start))
+}
+
+func (r *recorder) report(steps []string, elapsed time.Duration) {
+	fmt.Printf("%-8s %8s %8s %10s %10s %10s %10s\n", "step", "ok", "errors", "p50", "p90", "p99", "max")
+	for _, step := range steps {
+		latencies := r.latencies[step]
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		fmt.Printf("%-8s %8d %8d %10s %10s %10s %10s\n", step, len(latencies), r.errors[step],
+			percentile(latencies, 0.50), percentile(latencies, 0.90), percentile(latencies, 0.99), percentile(latencies, 1))
+	}
+	fmt.Printf("completed in %s\n", elapsed.Round(time.Millisecond))
+}
+
+// percentile expects sorted latencies.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(latencies)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return latencies[i].Round(10 * time.Microsecond)
+}
+
+type options struct {
+	url     string
+	origin  string
+	clients int
+	rounds  int
+	timeout time.Duration
+}
+
+// runRoom plays the rounds in one room with its own set of clients.
+func runRoom(roomID string, opts options, rec *recorder) {
+	clients := make([]*client, 0, opts.clients)
+	defer func() {
+		for _, c := range clients {
+			c.conn.Close()
+		}
+	}()
+
+	// Clients join one by one so the first becomes the facilitator
+	for i := 0; i < opts.clients; i++ {
+		name := roomID + "-" + strconv.Itoa(i)
+		c, err := dial(opts.url, opts.origin, name)
+		if err != nil {
+			rec.record("join", time.Time{}, err)
+			log.Printf("Room %s: %v", roomID, err)
+			return
+		}
+		clients = append(clients, c)
+
+		start := time.Now()
+		err = c.send("join-room", map[string]interface{}{"roomId": roomID, "name": name})
+		if err == nil {
+			err = c.await("room-state", opts.timeout, func(data json.RawMessage) bool {
+				var state struct {
+					Participants []participant `json:"participants"`
+				}
+				json.Unmarshal(data, &state)
+				for _, p := range state.Participants {
+					if p.Name == name {
+						c.id = p.ID
+						return true
+					}
+				}
+				return false
+			})
+		}
+		rec.record("join", start, err)
+		if err != nil {
+			log.Printf("Room %s: %s failed to join: %v", roomID, name, err)
+			return
+		}
+	}
+
+	facilitator := clients[0]
+	for round := 0; round < opts.rounds; round++ {
+		vote := strconv.Itoa(round%8 + 1)
+		everyone(clients, func(c *client) {
+			start := time.Now()
+			err := c.send("vote", map[string]interface{}{"roomId": roomID, "vote": vote})
+			if err == nil {
+				err = c.await("participant-voted", opts.timeout, func(data json.RawMessage) bool {
+					var voted participant
+					json.Unmarshal(data, &voted)
+					return voted.ID == c.id
+				})
+			}
+			rec.record("vote", start, err)
+		})
+
+		for _, step := range []struct{ name, send, await string }{
+			{"reveal", "reveal", "revealed"},
+			{"reset", "reestimate", "room-state"},
+		} {
+			start := time.Now()
+			if err := facilitator.send(step.send, map[string]interface{}{"roomId": roomID}); err != nil {
+				rec.record(step.name, start, err)
+				return
+			}
+			everyone(clients, func(c *client) {
+				rec.record(step.name, start, c.await(step.await, opts.timeout, nil))
+			})
+		}
+	}
+}
+
+// everyone runs fn for each client concurrently and waits for all of them.
+func everyone(clients []*client, fn func(*client)) {
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(c)
+		}()
+	}
+	wg.Wait()
+}
+
+func main() {
+	var opts options
+	rooms := flag.Int("rooms", 10, "number of rooms")
+	flag.StringVar(&opts.url, "url", "ws://localhost:3001/api/ws", "WebSocket endpoint of the server")
+	flag.StringVar(&opts.origin, "origin", "", "Origin header to send, when the server checks it")
+	flag.IntVar(&opts.clients, "clients", 5, "clients per room")
+	flag.IntVar(&opts.rounds, "rounds", 10, "voting rounds per room")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "how long a client waits for each reply")
+	flag.Parse()
+
+	if *rooms < 1 || opts.clients < 1 || opts.rounds < 0 {
+		fmt.Fprintln(os.Stderr, "rooms and clients must be positive, rounds must not be negative")
+		os.Exit(2)
+	}
+
+	rec := &recorder{latencies: make(map[string][]time.Duration), errors: make(map[string]int)}
+	run := strconv.FormatInt(time.Now().Unix(), 36)
+	log.Printf("Simulating %d rooms of %d clients, %d rounds each, against %s", *rooms, opts.clients, opts.rounds, opts.url)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < *rooms; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runRoom(fmt.Sprintf("loadtest-%s-%d", run, i), opts, rec)
+		}()
+	}
+	wg.Wait()
+
+	rec.report([]string{"join", "vote", "reveal", "reset"}, time.Since(start))
+}
@@ -0,0 +1,92 @@
+// Command pokerbot joins a room as a bot: a reader that puts up the stories
+// of a CSV file (title,link rows) one round after another, a stats bot that
+// posts each round's statistics to the chat, or both.
+//
+//	go run ./cmd/pokerbot -url ws://localhost:3001/api/ws -room team-room -stories backlog.csv -stats
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/pkg/pokerbot"
+)
+
+func main() {
+	url := flag.String("url", "ws://localhost:3001/api/ws", "WebSocket URL of the server")
+	origin := flag.String("origin", "", "Origin header to send, for servers that check it")
+	room := flag.String("room", "", "room to join")
+	name := flag.String("name", "🤖 Bot", "name the bot joins with")
+	passcode := flag.String("passcode", "", "passcode of a private room")
+	storiesFile := flag.String("stories", "", "CSV file of stories for the reader to put up")
+	stats := flag.Bool("stats", false, "post each round's statistics to the chat")
+	flag.Parse()
+
+	if *room == "" || (*storiesFile == "" && !*stats) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	bot := pokerbot.New(*name, *room)
+	bot.Passcode = *passcode
+	if *storiesFile != "" {
+		stories, err := readStories(*storiesFile)
+		if err != nil {
+			log.Fatalf("Reading stories: %v", err)
+		}
+		bot.Use(pokerbot.NewReader(stories))
+	}
+	if *stats {
+		bot.Use(pokerbot.NewStats())
+	}
+
+	header := http.Header{}
+	if *origin != "" {
+		header.Set("Origin", *origin)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.Printf("🤖 %s joining room %s", *name, *room)
+	if err := bot.Run(ctx, *url, header); err != nil {
+		log.Fatalf("Bot stopped: %v", err)
+	}
+}
+
+// readStories reads title,link rows, skipping a header row.
+func readStories(path string) ([]pokerbot.Story, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var stories []pokerbot.Story
+	for i, record := range records {
+		title := strings.TrimSpace(record[0])
+		if title == "" || (i == 0 && strings.EqualFold(title, "title")) {
+			continue
+		}
+		story := pokerbot.Story{Title: title}
+		if len(record) > 1 {
+			story.Link = strings.TrimSpace(record[1])
+		}
+		stories = append(stories, story)
+	}
+	return stories, nil
+}
@@ -0,0 +1,130 @@
+// Package hub keeps track of rooms and clients: the Hub of the rooms an
+// instance holds and the clients connected to it, the broker relaying
+// broadcasts between instances, the leases deciding which one holds a room, and
+// the encryption of what they share through Redis.
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BrokerMessage is a broadcast relayed between server instances. An empty
+// RoomID addresses every connected client.
+type BrokerMessage struct {
+	Type      string      `json:"type"`
+	RoomID    string      `json:"roomId"`
+	Data      interface{} `json:"data"`
+	ExcludeID string      `json:"excludeId,omitempty"`
+	Origin    string      `json:"origin,omitempty"`
+	// Target addresses one instance, for traffic between a room's owner and
+	// the instances its clients are connected to
+	Target string        `json:"target,omitempty"`
+	Relay  *RelayMessage `json:"relay,omitempty"`
+	// TraceContext holds W3C trace headers linking delivery to the publisher
+	TraceContext map[string]string `json:"traceContext,omitempty"`
+}
+
+// Broker fans room broadcasts out to other server instances. The Redis
+// implementation is used when REDIS_URL is set and no broker is injected, a
+// MemoryBroker otherwise.
+type Broker interface {
+	Publish(ctx context.Context, msg BrokerMessage) error
+	// Subscribe delivers messages published by any instance to handle until
+	// ctx is cancelled.
+	Subscribe(ctx context.Context, handle func(BrokerMessage)) error
+	Close() error
+}
+
+const redisBroadcastChannel = "ws-broadcast"
+
+type redisBroker struct {
+	pub    *redis.Client
+	sub    *redis.Client
+	cipher *RedisCipher
+	logger *log.Logger
+}
+
+func NewRedisBroker(ctx context.Context, redisURL string, cipher *RedisCipher, logger *log.Logger) (Broker, error) {
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+
+	b := &redisBroker{
+		pub:    redis.NewClient(opt),
+		sub:    redis.NewClient(opt),
+		cipher: cipher,
+		logger: logger,
+	}
+
+	// Test pub connection
+	if err := b.pub.Ping(ctx).Err(); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("redis pub connection failed: %w", err)
+	}
+	logger.Println("✓ Redis pub connected")
+
+	// Test sub connection
+	if err := b.sub.Ping(ctx).Err(); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("redis sub connection failed: %w", err)
+	}
+	logger.Println("✓ Redis sub connected")
+
+	return b, nil
+}
+
+func (b *redisBroker) Publish(ctx context.Context, msg BrokerMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshaling broker message: %w", err)
+	}
+	return b.pub.Publish(ctx, redisBroadcastChannel, b.cipher.Seal(payload, redisBroadcastChannel)).Err()
+}
+
+func (b *redisBroker) Subscribe(ctx context.Context, handle func(BrokerMessage)) error {
+	pubsub := b.sub.Subscribe(ctx, redisBroadcastChannel)
+	ch := pubsub.Channel()
+
+	b.logger.Printf("✓ Subscribed to %s channel", redisBroadcastChannel)
+
+	go func() {
+		for {
+			select {
+			case msg := <-ch:
+				if msg == nil {
+					return
+				}
+				payload, err := b.cipher.Open([]byte(msg.Payload), redisBroadcastChannel)
+				if err != nil {
+					b.logger.Printf("Redis message dropped: %v", err)
+					continue
+				}
+				var brokerMsg BrokerMessage
+				if err := json.Unmarshal(payload, &brokerMsg); err != nil {
+					b.logger.Printf("Redis message parse error: %v", err)
+					continue
+				}
+				handle(brokerMsg)
+			case <-ctx.Done():
+				pubsub.Close()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (b *redisBroker) Close() error {
+	pubErr := b.pub.Close()
+	subErr := b.sub.Close()
+	if pubErr != nil {
+		return pubErr
+	}
+	return subErr
+}
@@ -0,0 +1,190 @@
+package hub
+
+import (
+	"sync"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// Hub is the registry of an instance: the rooms it holds and the clients
+// connected to it, by ID. A room's Mu is taken after the rooms lock, and
+// before the clients lock.
+type Hub struct {
+	roomsMu   sync.RWMutex
+	rooms     map[string]*room.State
+	clientsMu sync.RWMutex
+	clients   map[string]*transport.Conn
+}
+
+func New() *Hub {
+	return &Hub{
+		rooms:   make(map[string]*room.State),
+		clients: make(map[string]*transport.Conn),
+	}
+}
+
+// Room returns the room held under id.
+func (h *Hub) Room(id string) (*room.State, bool) {
+	h.roomsMu.RLock()
+	defer h.roomsMu.RUnlock()
+	r, ok := h.rooms[id]
+	return r, ok
+}
+
+// Holds reports whether r is still the room held under its ID, so timers
+// armed for a room that was closed or replaced since can tell.
+func (h *Hub) Holds(r *room.State) bool {
+	h.roomsMu.RLock()
+	defer h.roomsMu.RUnlock()
+	return h.rooms[r.ID] == r
+}
+
+// Rooms returns the rooms held at the time of the call, in no order.
+func (h *Hub) Rooms() []*room.State {
+	h.roomsMu.RLock()
+	defer h.roomsMu.RUnlock()
+
+	rooms := make([]*room.State, 0, len(h.rooms))
+	for _, r := range h.rooms {
+		rooms = append(rooms, r)
+	}
+	return rooms
+}
+
+// RoomOrCreate returns the room held under id, holding the one create
+// returns when there is none. create runs under the rooms lock.
+func (h *Hub) RoomOrCreate(id string, create func() *room.State) *room.State {
+	h.roomsMu.Lock()
+	defer h.roomsMu.Unlock()
+
+	if r, ok := h.rooms[id]; ok {
+		return r
+	}
+	r := create()
+	h.rooms[id] = r
+	return r
+}
+
+// AddRoom holds r, unless a room is already held under its ID. It reports
+// whether r was added.
+func (h *Hub) AddRoom(r *room.State) bool {
+	h.roomsMu.Lock()
+	defer h.roomsMu.Unlock()
+
+	if _, ok := h.rooms[r.ID]; ok {
+		return false
+	}
+	h.rooms[r.ID] = r
+	return true
+}
+
+// UpdateRoom calls update with the room held under id, while no room can be
+// added or removed, and drops the room when update returns true. It reports
+// whether there was a room.
+func (h *Hub) UpdateRoom(id string, update func(r *room.State) (remove bool)) bool {
+	h.roomsMu.Lock()
+	defer h.roomsMu.Unlock()
+
+	r, ok := h.rooms[id]
+	if !ok {
+		return false
+	}
+	if update(r) {
+		delete(h.rooms, id)
+	}
+	return true
+}
+
+// RemoveRoom drops the room held under id, returning it.
+func (h *Hub) RemoveRoom(id string) (*room.State, bool) {
+	h.roomsMu.Lock()
+	defer h.roomsMu.Unlock()
+
+	r, ok := h.rooms[id]
+	delete(h.rooms, id)
+	return r, ok
+}
+
+// ClearRooms drops every room.
+func (h *Hub) ClearRooms() {
+	h.roomsMu.Lock()
+	defer h.roomsMu.Unlock()
+	h.rooms = make(map[string]*room.State)
+}
+
+// Client returns the client connected under id, nil if there is none.
+func (h *Hub) Client(id string) *transport.Conn {
+	h.clientsMu.RLock()
+	defer h.clientsMu.RUnlock()
+	return h.clients[id]
+}
+
+// ClientsOf returns the clients connected under ids, skipping those that
+// aren't. Broadcasts look up a whole room with it, under one lock.
+func (h *Hub) ClientsOf(ids []string) []*transport.Conn {
+	h.clientsMu.RLock()
+	defer h.clientsMu.RUnlock()
+
+	clients := make([]*transport.Conn, 0, len(ids))
+	for _, id := range ids {
+		if client, ok := h.clients[id]; ok {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// Clients returns the clients connected at the time of the call, for sending
+// to without holding the lock.
+func (h *Hub) Clients() []*transport.Conn {
+	h.clientsMu.RLock()
+	defer h.clientsMu.RUnlock()
+
+	clients := make([]*transport.Conn, 0, len(h.clients))
+	for _, client := range h.clients {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// AddClient registers client under its ID, replacing any client there.
+func (h *Hub) AddClient(client *transport.Conn) {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+	h.clients[client.ID] = client
+}
+
+// ClientOrAdd returns the client connected under id, registering the one
+// create returns when there is none. create runs under the clients lock.
+func (h *Hub) ClientOrAdd(id string, create func() *transport.Conn) *transport.Conn {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+
+	if client, ok := h.clients[id]; ok {
+		return client
+	}
+	client := create()
+	h.clients[id] = client
+	return client
+}
+
+// RemoveClient unregisters the client connected under id.
+func (h *Hub) RemoveClient(id string) {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+	delete(h.clients, id)
+}
+
+// RemoveClients unregisters every client, returning them.
+func (h *Hub) RemoveClients() []*transport.Conn {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+
+	clients := make([]*transport.Conn, 0, len(h.clients))
+	for _, client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.clients = make(map[string]*transport.Conn)
+	return clients
+}
@@ -0,0 +1,119 @@
+package hub
+
+import (
+	"testing"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+func TestHubRoomOrCreate(t *testing.T) {
+	h := New()
+	created := 0
+	create := func() *room.State {
+		created++
+		return &room.State{ID: "room-1"}
+	}
+
+	first := h.RoomOrCreate("room-1", create)
+	if again := h.RoomOrCreate("room-1", create); again != first || created != 1 {
+		t.Fatalf("Expected the room to be created once, got %d", created)
+	}
+	if r, ok := h.Room("room-1"); !ok || r != first {
+		t.Error("Expected the created room to be held")
+	}
+}
+
+func TestHubAddRoom(t *testing.T) {
+	h := New()
+	if !h.AddRoom(&room.State{ID: "room-1"}) {
+		t.Fatal("Expected the room to be added")
+	}
+	if h.AddRoom(&room.State{ID: "room-1"}) {
+		t.Error("Expected a room under a held ID not to be added")
+	}
+}
+
+func TestHubUpdateRoom(t *testing.T) {
+	h := New()
+	h.RoomOrCreate("room-1", func() *room.State { return &room.State{ID: "room-1"} })
+
+	if !h.UpdateRoom("room-1", func(*room.State) bool { return false }) {
+		t.Fatal("Expected the room to be updated")
+	}
+	if _, ok := h.Room("room-1"); !ok {
+		t.Fatal("Expected the room to be kept")
+	}
+	if !h.UpdateRoom("room-1", func(*room.State) bool { return true }) {
+		t.Fatal("Expected the room to be updated")
+	}
+	if _, ok := h.Room("room-1"); ok {
+		t.Error("Expected the room to be dropped")
+	}
+	if h.UpdateRoom("room-2", func(*room.State) bool { return false }) {
+		t.Error("Expected no room to update")
+	}
+}
+
+func TestHubHolds(t *testing.T) {
+	h := New()
+	create := func() *room.State { return &room.State{ID: "room-1"} }
+	first := h.RoomOrCreate("room-1", create)
+	if !h.Holds(first) {
+		t.Fatal("Expected the room to be held")
+	}
+
+	// A timer armed for the room can tell once it is replaced
+	h.RemoveRoom("room-1")
+	h.RoomOrCreate("room-1", create)
+	if h.Holds(first) {
+		t.Error("Expected the removed room not to be held")
+	}
+}
+
+func TestHubRooms(t *testing.T) {
+	h := New()
+	for _, id := range []string{"room-1", "room-2"} {
+		h.RoomOrCreate(id, func() *room.State { return &room.State{ID: id} })
+	}
+	if rooms := h.Rooms(); len(rooms) != 2 {
+		t.Fatalf("Expected 2 rooms, got %d", len(rooms))
+	}
+	if _, ok := h.RemoveRoom("room-1"); !ok || len(h.Rooms()) != 1 {
+		t.Error("Expected the room to be removed")
+	}
+}
+
+func TestHubClientsOf(t *testing.T) {
+	h := New()
+	alice := &transport.Conn{ID: "alice"}
+	bob := &transport.Conn{ID: "bob"}
+	h.AddClient(alice)
+	h.AddClient(bob)
+
+	if clients := h.ClientsOf([]string{"alice", "carol", "bob"}); len(clients) != 2 || clients[0] != alice || clients[1] != bob {
+		t.Errorf("Expected the connected clients in order, got %d", len(clients))
+	}
+}
+
+func TestHubClientOrAdd(t *testing.T) {
+	h := New()
+	remote := h.ClientOrAdd("bob", func() *transport.Conn { return &transport.Conn{ID: "bob"} })
+	if h.ClientOrAdd("bob", func() *transport.Conn { return nil }) != remote {
+		t.Fatal("Expected the registered client back")
+	}
+}
+
+func TestHubRemoveClients(t *testing.T) {
+	h := New()
+	h.AddClient(&transport.Conn{ID: "alice"})
+	h.AddClient(&transport.Conn{ID: "bob"})
+
+	h.RemoveClient("alice")
+	if h.Client("alice") != nil {
+		t.Error("Expected alice to be gone")
+	}
+	if removed := h.RemoveClients(); len(removed) != 1 || len(h.Clients()) != 0 {
+		t.Errorf("Expected bob removed, got %d", len(removed))
+	}
+}
@@ -0,0 +1,99 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// How many messages a subscriber may fall behind before publishing waits
+const memoryBrokerBuffer = 1024
+
+// MemoryBroker is a Broker within one process. A server without Redis uses
+// its own, and tests can hand one to several servers to run them as a
+// cluster. Like Redis it round-trips messages through JSON and delivers them
+// to every subscriber, the publisher included, in the order published.
+type MemoryBroker struct {
+	mu          sync.Mutex
+	subscribers []*memorySubscriber
+}
+
+type memorySubscriber struct {
+	messages chan BrokerMessage
+	done     <-chan struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, msg BrokerMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, subscriber := range b.subscribers {
+		var decoded BrokerMessage
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			return err
+		}
+		select {
+		case subscriber.messages <- decoded:
+		case <-subscriber.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe hands messages to handle one at a time, until ctx is cancelled.
+func (b *MemoryBroker) Subscribe(ctx context.Context, handle func(BrokerMessage)) error {
+	subscriber := &memorySubscriber{
+		messages: make(chan BrokerMessage, memoryBrokerBuffer),
+		done:     ctx.Done(),
+	}
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, subscriber)
+	b.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case msg := <-subscriber.messages:
+				handle(msg)
+			case <-ctx.Done():
+				b.unsubscribe(subscriber)
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (b *MemoryBroker) unsubscribe(subscriber *memorySubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, s := range b.subscribers {
+		if s == subscriber {
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Subscribers is the number of live subscriptions.
+func (b *MemoryBroker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+// Close does nothing, as the broker may be shared; subscriptions end with
+// their context.
+func (b *MemoryBroker) Close() error {
+	return nil
+}
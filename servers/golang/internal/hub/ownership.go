@@ -0,0 +1,153 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+	"github.com/redis/go-redis/v9"
+)
+
+// RoomOwnership decides which instance holds a room's state when several
+// share a broker. The owner handles every message for the room; the other
+// instances relay their clients' messages to it and its replies back. Leases
+// expire unless renewed, so the rooms of an instance that dies are claimed by
+// the next one a client joins through.
+type RoomOwnership interface {
+	// Claim takes the lease on roomID for instanceID unless another instance
+	// holds it, and returns the holder either way.
+	Claim(ctx context.Context, roomID, instanceID string, ttl time.Duration) (string, error)
+	// Renew extends a lease held by instanceID, reporting false if it was lost.
+	Renew(ctx context.Context, roomID, instanceID string, ttl time.Duration) (bool, error)
+	// Release gives up a lease held by instanceID.
+	Release(ctx context.Context, roomID, instanceID string) error
+	// SaveState and LoadState hand a room over to its next owner. LoadState
+	// returns nil when there is nothing to take over, and only returns a
+	// state once.
+	SaveState(ctx context.Context, roomID string, state []byte) error
+	LoadState(ctx context.Context, roomID string) ([]byte, error)
+}
+
+// Broker message types for traffic between a room's owner and the instances
+// its clients are connected to
+const (
+	RelayClientMessage    = "relay-message"
+	RelayClientAttach     = "relay-attach"
+	RelayClientDisconnect = "relay-disconnect"
+	RelayClientDeliver    = "relay-deliver"
+	RelayClientClose      = "relay-close"
+	RoomHandoffMessage    = "room-handoff"
+)
+
+const DefaultRoomLeaseTTL = 30 * time.Second
+
+// RelayMessage carries a client's traffic between the instance it is
+// connected to and the instance that owns its room.
+type RelayMessage struct {
+	ClientID string                 `json:"clientId"`
+	RemoteIP string                 `json:"remoteIp,omitempty"`
+	TeamID   string                 `json:"teamId,omitempty"`
+	UserID   string                 `json:"userId,omitempty"`
+	UserName string                 `json:"userName,omitempty"`
+	Message  *transport.Message     `json:"message,omitempty"`
+	Close    *transport.CloseReason `json:"close,omitempty"`
+}
+
+// Handed-off state only needs to outlive a rolling restart
+const handoffStateTTL = time.Hour
+
+var (
+	renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// redisOwnership keeps leases and handed-off state in Redis keys.
+type redisOwnership struct {
+	client *redis.Client
+	cipher *RedisCipher
+}
+
+func NewRedisOwnership(ctx context.Context, redisURL string, cipher *RedisCipher) (RoomOwnership, error) {
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+	client := redis.NewClient(opt)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("redis ownership connection failed: %w", err)
+	}
+	return &redisOwnership{client: client, cipher: cipher}, nil
+}
+
+func roomOwnerKey(roomID string) string { return "poker:room-owner:" + roomID }
+
+func roomStateKey(roomID string) string { return "poker:room-state:" + roomID }
+
+func (o *redisOwnership) Claim(ctx context.Context, roomID, instanceID string, ttl time.Duration) (string, error) {
+	key := roomOwnerKey(roomID)
+	// Retry once should the current lease expire between the two calls
+	for attempt := 0; attempt < 2; attempt++ {
+		claimed, err := o.client.SetNX(ctx, key, instanceID, ttl).Result()
+		if err != nil {
+			return "", err
+		}
+		if claimed {
+			return instanceID, nil
+		}
+		owner, err := o.client.Get(ctx, key).Result()
+		if !errors.Is(err, redis.Nil) {
+			return owner, err
+		}
+	}
+	return "", fmt.Errorf("lease on room %s keeps changing hands", roomID)
+}
+
+func (o *redisOwnership) Renew(ctx context.Context, roomID, instanceID string, ttl time.Duration) (bool, error) {
+	renewed, err := renewLeaseScript.Run(ctx, o.client, []string{roomOwnerKey(roomID)}, instanceID, ttl.Milliseconds()).Int()
+	return renewed == 1, err
+}
+
+func (o *redisOwnership) Release(ctx context.Context, roomID, instanceID string) error {
+	return releaseLeaseScript.Run(ctx, o.client, []string{roomOwnerKey(roomID)}, instanceID).Err()
+}
+
+func (o *redisOwnership) Owner(ctx context.Context, roomID string) (string, error) {
+	owner, err := o.client.Get(ctx, roomOwnerKey(roomID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return owner, err
+}
+
+func (o *redisOwnership) SaveState(ctx context.Context, roomID string, state []byte) error {
+	key := roomStateKey(roomID)
+	return o.client.Set(ctx, key, o.cipher.Seal(state, key), handoffStateTTL).Err()
+}
+
+func (o *redisOwnership) LoadState(ctx context.Context, roomID string) ([]byte, error) {
+	key := roomStateKey(roomID)
+	state, err := o.client.GetDel(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return o.cipher.Open(state, key)
+}
+
+func (o *redisOwnership) Close() error {
+	return o.client.Close()
+}
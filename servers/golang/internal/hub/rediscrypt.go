@@ -0,0 +1,79 @@
+package hub
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// redisEncryptionKeySize is the size of the AES-256 key encrypting what is
+// kept in and relayed through Redis.
+const redisEncryptionKeySize = 32
+
+// redisCipherVersion prefixes every sealed value, leaving room for another
+// scheme later.
+const redisCipherVersion byte = 1
+
+var errRedisDecrypt = errors.New("redis value is not encrypted with the configured key")
+
+// RedisCipher encrypts room state and broadcasts with AES-GCM before they
+// reach Redis, so that whoever can read Redis in shared infrastructure can't
+// read story titles or votes. A nil cipher leaves values as they are.
+type RedisCipher struct {
+	aead cipher.AEAD
+}
+
+// NewRedisCipher takes a base64-encoded 32-byte key, returning nil when no
+// key is configured.
+func NewRedisCipher(key string) (*RedisCipher, error) {
+	if key == "" {
+		return nil, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != redisEncryptionKeySize {
+		return nil, fmt.Errorf("must be %d bytes, base64-encoded", redisEncryptionKeySize)
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisCipher{aead: aead}, nil
+}
+
+// Seal encrypts value, binding it to the Redis key or channel it is written
+// to so it can't be replayed under another.
+func (c *RedisCipher) Seal(value []byte, key string) []byte {
+	if c == nil {
+		return value
+	}
+	sealed := make([]byte, 1+c.aead.NonceSize(), 1+c.aead.NonceSize()+len(value)+c.aead.Overhead())
+	sealed[0] = redisCipherVersion
+	if _, err := rand.Read(sealed[1:]); err != nil {
+		panic(fmt.Sprintf("redis cipher: reading random nonce: %v", err))
+	}
+	return c.aead.Seal(sealed, sealed[1:], value, []byte(key))
+}
+
+// Open decrypts a value sealed for key. Values that weren't encrypted, or not
+// with this key, are refused rather than trusted.
+func (c *RedisCipher) Open(sealed []byte, key string) ([]byte, error) {
+	if c == nil {
+		return sealed, nil
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < 1+nonceSize || sealed[0] != redisCipherVersion {
+		return nil, errRedisDecrypt
+	}
+	value, err := c.aead.Open(nil, sealed[1:1+nonceSize], sealed[1+nonceSize:], []byte(key))
+	if err != nil {
+		return nil, errRedisDecrypt
+	}
+	return value, nil
+}
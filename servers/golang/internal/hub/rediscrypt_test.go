@@ -0,0 +1,51 @@
+package hub
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+)
+
+func newTestRedisCipher(t *testing.T) *RedisCipher {
+	key := make([]byte, redisEncryptionKeySize)
+	rand.Read(key)
+	cipher, err := NewRedisCipher(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatalf("Failed to create cipher: %v", err)
+	}
+	return cipher
+}
+
+func TestRedisCipher(t *testing.T) {
+	cipher := newTestRedisCipher(t)
+	state := []byte(`{"story":{"title":"Secret project"},"votes":{"alice":"8"}}`)
+
+	sealed := cipher.Seal(state, roomStateKey("room-1"))
+	if bytes.Contains(sealed, []byte("Secret project")) {
+		t.Fatal("Expected the state to be unreadable in Redis")
+	}
+	opened, err := cipher.Open(sealed, roomStateKey("room-1"))
+	if err != nil || !bytes.Equal(opened, state) {
+		t.Fatalf("Expected the state back, got %q %v", opened, err)
+	}
+	if bytes.Equal(sealed, cipher.Seal(state, roomStateKey("room-1"))) {
+		t.Error("Expected a fresh nonce for every value")
+	}
+
+	if _, err := cipher.Open(sealed, roomStateKey("room-2")); err == nil {
+		t.Error("Expected a value moved to another key to be refused")
+	}
+	if _, err := newTestRedisCipher(t).Open(sealed, roomStateKey("room-1")); err == nil {
+		t.Error("Expected a value sealed with another key to be refused")
+	}
+	if _, err := cipher.Open(state, roomStateKey("room-1")); err == nil {
+		t.Error("Expected an unencrypted value to be refused")
+	}
+
+	// Without a key values pass through as they are
+	var none *RedisCipher
+	if passed, err := none.Open(none.Seal(state, "key"), "key"); err != nil || !bytes.Equal(passed, state) {
+		t.Errorf("Expected no encryption without a key, got %q %v", passed, err)
+	}
+}
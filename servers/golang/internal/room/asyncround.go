@@ -0,0 +1,49 @@
+package room
+
+import "time"
+
+// An async round stays open for at most this long
+const MaxAsyncRoundDuration = 30 * 24 * time.Hour
+
+// clearDeadline cancels a pending async round. The caller must hold room.Mu.
+func clearDeadline(room *State) {
+	if room.DeadlineTimer != nil {
+		room.DeadlineTimer.Stop()
+		room.DeadlineTimer = nil
+	}
+	room.Deadline = time.Time{}
+}
+
+// AsyncRoundOpen reports whether participants should be kept through
+// disconnects until the deadline. The caller must hold room.Mu.
+func AsyncRoundOpen(room *State) bool {
+	return !room.Deadline.IsZero()
+}
+
+// DeadlineMillis is the deadline for room-state, or nil without an async
+// round. The caller must hold room.Mu.
+func DeadlineMillis(room *State) interface{} {
+	if !AsyncRoundOpen(room) {
+		return nil
+	}
+	return room.Deadline.UnixMilli()
+}
+
+// AsyncRoundEvent opens a round that is revealed at Deadline, starting the
+// votes over on Story, or on the current story without one.
+type AsyncRoundEvent struct {
+	Story    *Story    `json:"story,omitempty"`
+	Deadline time.Time `json:"deadline"`
+}
+
+func (AsyncRoundEvent) EventType() string { return "async-round" }
+
+func (e *AsyncRoundEvent) apply(room *State) {
+	resetVotes(room)
+	room.LastRound = nil
+	if e.Story != nil {
+		story := *e.Story
+		room.Story = &story
+	}
+	room.Deadline = e.Deadline
+}
@@ -0,0 +1,81 @@
+package room
+
+// Actions recorded in a room's audit log
+const (
+	AuditJoined        = "joined"
+	AuditLeft          = "left"
+	AuditVoted         = "voted"
+	AuditRevealed      = "revealed"
+	AuditReset         = "reset"
+	AuditStoryChanged  = "story-changed"
+	AuditFinalEstimate = "final-estimate"
+	AuditKicked        = "kicked"
+	AuditBanned        = "banned"
+	AuditMoved         = "moved"
+	AuditVoteChanged   = "vote-changed"
+)
+
+const maxAuditLog = 1000
+
+// AuditEntry is one action taken in a room. Votes are recorded without their
+// value; the revealed entry of the round lists them all.
+type AuditEntry struct {
+	At     int64  `json:"at"`
+	Action string `json:"action"`
+	// The actor is left out for actions taken by the server or an operator,
+	// such as revealing an async round at its deadline
+	ActorID  string `json:"actorId,omitempty"`
+	Actor    string `json:"actor,omitempty"`
+	TargetID string `json:"targetId,omitempty"`
+	Target   string `json:"target,omitempty"`
+	Story    string `json:"story,omitempty"`
+	Estimate string `json:"estimate,omitempty"`
+	RoundID  string `json:"roundId,omitempty"`
+	// Votes are the revealed values by participant name
+	Votes map[string]string `json:"votes,omitempty"`
+}
+
+// RecordAudit appends an entry to the room's audit log, naming its actor and target
+// from the participants and dropping the oldest entries past maxAuditLog.
+// The caller must hold room.Mu.
+func (room *State) RecordAudit(entry AuditEntry) {
+	entry.At = room.Now().UnixMilli()
+	if p, ok := room.Participants[entry.ActorID]; ok && entry.Actor == "" {
+		entry.Actor = p.Name
+	}
+	if p, ok := room.Participants[entry.TargetID]; ok && entry.Target == "" {
+		entry.Target = p.Name
+	}
+	room.Audit = append(room.Audit, entry)
+	if len(room.Audit) > maxAuditLog {
+		room.Audit = room.Audit[len(room.Audit)-maxAuditLog:]
+	}
+	room.publishAudit(entry)
+}
+
+// AuditReveal records a revealed round with its votes. The caller must hold
+// room.Mu.
+func (room *State) AuditReveal(actorID string, round RoundRecord) {
+	votes := make(map[string]string, len(round.Votes))
+	for _, vote := range round.Votes {
+		value := vote.Vote
+		if vote.SecondVote != "" {
+			value += " / " + vote.SecondVote
+		}
+		votes[vote.Name] = value
+	}
+	room.RecordAudit(AuditEntry{
+		Action:  AuditRevealed,
+		ActorID: actorID,
+		Story:   AuditStory(round.Story),
+		RoundID: round.ID,
+		Votes:   votes,
+	})
+}
+
+func AuditStory(story *Story) string {
+	if story == nil {
+		return ""
+	}
+	return story.Title
+}
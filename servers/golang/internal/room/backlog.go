@@ -0,0 +1,42 @@
+package room
+
+// BacklogEvent queues stories at the end of the backlog.
+type BacklogEvent struct {
+	Stories []Story `json:"stories"`
+}
+
+func (BacklogEvent) EventType() string { return "backlog" }
+
+func (e *BacklogEvent) apply(room *State) {
+	room.Backlog = append(room.Backlog, e.Stories...)
+}
+
+// TakeStoryEvent takes the next story off the backlog, to be started once it
+// is enriched.
+type TakeStoryEvent struct{}
+
+func (TakeStoryEvent) EventType() string { return "take-story" }
+
+func (e *TakeStoryEvent) apply(room *State) {
+	if len(room.Backlog) > 0 {
+		room.Backlog = room.Backlog[1:]
+	}
+}
+
+// StartStoryEvent clears the round and puts the story up for estimation.
+type StartStoryEvent struct {
+	Story *Story `json:"story"`
+}
+
+func (StartStoryEvent) EventType() string { return "start-story" }
+
+func (e *StartStoryEvent) apply(room *State) {
+	resetVotes(room)
+	room.LastRound = nil
+	room.Batch = nil
+	room.Story = nil
+	if e.Story != nil {
+		story := *e.Story
+		room.Story = &story
+	}
+}
@@ -0,0 +1,130 @@
+package room
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const MaxBatchStories = 20
+
+// BatchStory is one of the stories open for estimation at once. Votes on it
+// are kept in Participant.BatchVotes under its ID.
+type BatchStory struct {
+	ID       string `json:"id"`
+	Story    Story  `json:"story"`
+	Revealed bool   `json:"revealed"`
+}
+
+// batchStoryState adds how many participants have voted on the story, for
+// room-state.
+type batchStoryState struct {
+	BatchStory
+	Votes int `json:"votes"`
+}
+
+// BatchState describes the open batch, or is nil without one. The caller
+// must hold room.Mu.
+func BatchState(room *State) []batchStoryState {
+	if len(room.Batch) == 0 {
+		return nil
+	}
+	state := make([]batchStoryState, len(room.Batch))
+	for i, story := range room.Batch {
+		state[i] = batchStoryState{BatchStory: story}
+		for _, p := range room.Participants {
+			if p.BatchVotes[story.ID] != "" {
+				state[i].Votes++
+			}
+		}
+	}
+	return state
+}
+
+// BatchIndex returns the position of a story in the batch, or -1. The caller
+// must hold room.Mu.
+func BatchIndex(room *State, storyID string) int {
+	for i, story := range room.Batch {
+		if story.ID == storyID {
+			return i
+		}
+	}
+	return -1
+}
+
+// BatchEvent puts several stories up for estimation at once, starting the
+// round over.
+type BatchEvent struct {
+	Batch []BatchStory `json:"batch"`
+}
+
+func (BatchEvent) EventType() string { return "batch" }
+
+func (e *BatchEvent) apply(room *State) {
+	resetVotes(room)
+	room.Batch = slices.Clone(e.Batch)
+}
+
+// BatchVoteEvent sets a participant's vote on one story of the batch. An
+// empty vote takes it back.
+type BatchVoteEvent struct {
+	ClientID string `json:"clientId"`
+	StoryID  string `json:"storyId"`
+	Vote     string `json:"vote"`
+}
+
+func (BatchVoteEvent) EventType() string { return "batch-vote" }
+
+func (e *BatchVoteEvent) apply(room *State) {
+	participant, ok := room.Participants[e.ClientID]
+	if !ok {
+		return
+	}
+	// The map is replaced rather than changed, as copies of the participant
+	// are read outside the lock
+	votes := maps.Clone(participant.BatchVotes)
+	if votes == nil {
+		votes = make(map[string]string, 1)
+	}
+	if e.Vote == "" {
+		delete(votes, e.StoryID)
+	} else {
+		votes[e.StoryID] = e.Vote
+	}
+	participant.BatchVotes = votes
+}
+
+// BatchRevealEvent reveals stories of the batch, recording a round for each
+// in the order of the batch. Raised hands go down and reveal requests start
+// over.
+type BatchRevealEvent struct {
+	StoryIDs []string `json:"storyIds"`
+}
+
+func (BatchRevealEvent) EventType() string { return "batch-reveal" }
+
+func (e *BatchRevealEvent) apply(room *State) {
+	room.RevealRequests = nil
+	for _, p := range room.Participants {
+		p.HandRaised = false
+	}
+	revealedAt := room.Now()
+	for i := range room.Batch {
+		story := &room.Batch[i]
+		if story.Revealed || !slices.Contains(e.StoryIDs, story.ID) {
+			continue
+		}
+		story.Revealed = true
+
+		participants := make([]Participant, 0, len(room.Participants))
+		for _, p := range room.Participants {
+			vote := p.BatchVotes[story.ID]
+			participants = append(participants, Participant{ID: p.ID, Name: p.Name, Vote: &vote})
+		}
+		// In join order, so the round replays the same
+		slices.SortFunc(participants, func(a, b Participant) int { return strings.Compare(a.ID, b.ID) })
+		roundID := strconv.FormatInt(revealedAt.UnixMilli(), 10) + "-" + story.ID
+		appendRound(room, newRoundRecord(room, roundID, &story.Story, participants, revealedAt))
+	}
+}
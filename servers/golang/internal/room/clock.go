@@ -0,0 +1,158 @@
+package room
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and runs the timers behind heartbeats, reconnect grace
+// periods, async round deadlines and expiring invites. Servers use the real
+// clock unless given another with WithClock.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a pending AfterFunc call.
+type Timer interface {
+	// Stop cancels the call, reporting false if it already ran or was stopped.
+	Stop() bool
+}
+
+// Ticker delivers ticks on C at a fixed interval.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type RealClock struct{}
+
+func (RealClock) Now() time.Time { return time.Now() }
+
+func (RealClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func (RealClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// FakeClock is a Clock that only moves when told to, so tests can step through
+// timers without sleeping. Timer functions run synchronously in Advance, in
+// the order they are due. Room-state broadcasts coalesced with a recent one
+// are held until the clock moves on too.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	when   time.Time
+	f      func()
+	period time.Duration
+	ch     chan time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.add(&fakeTimer{clock: c, when: c.Now().Add(d), f: f})
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return fakeTicker{c.add(&fakeTimer{clock: c, when: c.Now().Add(d), period: d, ch: make(chan time.Time, 1)})}
+}
+
+func (c *FakeClock) add(t *fakeTimer) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, firing every timer and tick that
+// falls due on the way.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].when.Before(c.timers[j].when) })
+		if len(c.timers) == 0 || c.timers[0].when.After(end) {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+		t := c.timers[0]
+		if t.when.After(c.now) {
+			c.now = t.when
+		}
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+		} else {
+			c.timers = c.timers[1:]
+		}
+		now := c.now
+		c.mu.Unlock()
+
+		// Run outside the lock, as timer functions may use the clock
+		if t.ch != nil {
+			select {
+			case t.ch <- now:
+			default:
+			}
+		} else {
+			t.f()
+		}
+	}
+}
+
+// Pending is the number of timers and tickers still waiting to fire.
+func (c *FakeClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTicker struct {
+	*fakeTimer
+}
+
+func (t fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t fakeTicker) Stop() { t.fakeTimer.Stop() }
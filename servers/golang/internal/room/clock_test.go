@@ -0,0 +1,49 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	var fired []string
+	clock.AfterFunc(2*time.Minute, func() { fired = append(fired, "second") })
+	clock.AfterFunc(time.Minute, func() {
+		fired = append(fired, "first")
+		// Timers armed while advancing fire in the same Advance when due
+		clock.AfterFunc(30*time.Second, func() { fired = append(fired, "nested") })
+	})
+	stopped := clock.AfterFunc(time.Minute, func() { fired = append(fired, "stopped") })
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("Expected Stop to report true only the first time")
+	}
+	ticker := clock.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	clock.Advance(90 * time.Second)
+	if len(fired) != 2 || fired[0] != "first" || fired[1] != "nested" {
+		t.Errorf("Expected the first and nested timers, got %v", fired)
+	}
+	if !clock.Now().Equal(start.Add(90 * time.Second)) {
+		t.Errorf("Expected the clock at 9:01:30, got %v", clock.Now())
+	}
+	select {
+	case tick := <-ticker.C():
+		if !tick.Equal(start.Add(time.Minute)) {
+			t.Errorf("Expected a tick at 9:01, got %v", tick)
+		}
+	default:
+		t.Error("Expected a tick")
+	}
+
+	clock.Advance(time.Minute)
+	if len(fired) != 3 || fired[2] != "second" {
+		t.Errorf("Expected the second timer, got %v", fired)
+	}
+	if clock.Pending() != 1 {
+		t.Errorf("Expected only the ticker pending, got %d", clock.Pending())
+	}
+}
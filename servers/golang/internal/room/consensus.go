@@ -0,0 +1,110 @@
+package room
+
+import "slices"
+
+// deckValues are the numeric cards the web client deals, in order, unless
+// the room has a deck of its own.
+var deckValues = []string{"0", "0.5", "1", "2", "3", "5", "8", "13", "20", "40"}
+
+// Consensus is the verdict on a revealed round of story points. Value is the
+// vote everyone agreed on, when they all picked the same card.
+type Consensus struct {
+	RoundID   string    `json:"roundId"`
+	Consensus bool      `json:"consensus"`
+	Value     string    `json:"value,omitempty"`
+	Outliers  *Outliers `json:"outliers,omitempty"`
+}
+
+// Outliers are the participants who voted highest and lowest in a round
+// without consensus.
+type Outliers struct {
+	High []CastVote `json:"high"`
+	Low  []CastVote `json:"low"`
+}
+
+// CastVote identifies a participant and the card they played.
+type CastVote struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Vote string `json:"vote"`
+}
+
+// CheckConsensus decides whether the votes agree: all the same, or all
+// within one step on the room's deck. Without consensus it names the
+// outliers, by the points the votes count as. Abstentions are left out; ok
+// is false when nobody else voted.
+func CheckConsensus(roundID string, participants []Participant, settings Settings) (result Consensus, ok bool) {
+	result.RoundID = roundID
+
+	var voters []Participant
+	for _, p := range participants {
+		if p.Vote != nil && *p.Vote != "" && !IsAbstention(*p.Vote) {
+			voters = append(voters, p)
+		}
+	}
+	if len(voters) == 0 {
+		return result, false
+	}
+
+	deck := settings.Deck
+	if len(deck) == 0 {
+		deck = deckValues
+	}
+	same, lowStep, highStep := true, len(deck), -1
+	for _, p := range voters {
+		same = same && *p.Vote == *voters[0].Vote
+		step := slices.Index(deck, *p.Vote)
+		if step < 0 {
+			// Off-deck votes only agree when they are all the same
+			lowStep, highStep = 0, len(deck)
+			continue
+		}
+		lowStep, highStep = min(lowStep, step), max(highStep, step)
+	}
+
+	switch {
+	case same:
+		result.Consensus = true
+		result.Value = *voters[0].Vote
+	case highStep-lowStep <= 1:
+		result.Consensus = true
+	default:
+		result.Outliers = findOutliers(voters, settings.Points)
+	}
+	return result, true
+}
+
+// findOutliers returns the voters at both ends of the votes that count as
+// points, or nil if fewer than two distinct numbers were cast.
+func findOutliers(voters []Participant, points map[string]float64) *Outliers {
+	var low, high float64
+	var numeric []Participant
+	for _, p := range voters {
+		value, ok := PointsOf(*p.Vote, points)
+		if !ok {
+			continue
+		}
+		if len(numeric) == 0 || value < low {
+			low = value
+		}
+		if len(numeric) == 0 || value > high {
+			high = value
+		}
+		numeric = append(numeric, p)
+	}
+	if len(numeric) == 0 || low == high {
+		return nil
+	}
+
+	outliers := &Outliers{}
+	for _, p := range numeric {
+		value, _ := PointsOf(*p.Vote, points)
+		vote := CastVote{ID: p.ID, Name: p.Name, Vote: *p.Vote}
+		if value == high {
+			outliers.High = append(outliers.High, vote)
+		} else if value == low {
+			outliers.Low = append(outliers.Low, vote)
+		}
+	}
+	return outliers
+}
@@ -0,0 +1,71 @@
+package room
+
+import "testing"
+
+func votingParticipants(values ...string) []Participant {
+	participants := make([]Participant, len(values))
+	for i, value := range values {
+		participants[i] = Participant{ID: string(rune('a' + i)), Name: string(rune('A' + i)), Vote: strPtr(value)}
+	}
+	return participants
+}
+
+func TestCheckConsensus(t *testing.T) {
+	tests := []struct {
+		name      string
+		votes     []string
+		consensus bool
+		value     string
+	}{
+		{"same vote", []string{"5", "5"}, true, "5"},
+		{"adjacent cards", []string{"3", "5", "5"}, true, ""},
+		{"two steps apart", []string{"3", "8"}, false, ""},
+		{"off-deck votes", []string{"4", "5"}, false, ""},
+		{"same off-deck vote", []string{"4", "4"}, true, "4"},
+		{"abstentions left out", []string{"8", "☕", "?", "8"}, true, "8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := CheckConsensus("1", votingParticipants(tt.votes...), Settings{})
+			if !ok || result.Consensus != tt.consensus || result.Value != tt.value {
+				t.Errorf("Expected consensus %v with value %q, got %+v", tt.consensus, tt.value, result)
+			}
+		})
+	}
+
+	if _, ok := CheckConsensus("1", votingParticipants("", "☕"), Settings{}); ok {
+		t.Error("Expected no verdict without votes")
+	}
+}
+
+func TestCheckConsensusOutliers(t *testing.T) {
+	result, _ := CheckConsensus("1", votingParticipants("2", "13", "5", "2"), Settings{})
+	if result.Outliers == nil || len(result.Outliers.High) != 1 || len(result.Outliers.Low) != 2 {
+		t.Fatalf("Unexpected outliers: %+v", result.Outliers)
+	}
+	if high := result.Outliers.High[0]; high.Name != "B" || high.Vote != "13" {
+		t.Errorf("Expected B to be the high outlier, got %+v", high)
+	}
+}
+
+func TestCheckConsensusOnRoomDeck(t *testing.T) {
+	powers := Settings{Deck: []string{"1", "2", "4", "8", "16", "32"}}
+	if result, _ := CheckConsensus("1", votingParticipants("4", "8"), powers); !result.Consensus || result.Outliers != nil {
+		t.Errorf("Expected adjacent cards of the room's deck to agree, got %+v", result)
+	}
+	if result, _ := CheckConsensus("1", votingParticipants("2", "8"), powers); result.Consensus {
+		t.Errorf("Expected cards two steps apart to diverge, got %+v", result)
+	}
+
+	sizes := Settings{Deck: []string{"XS", "S", "M", "L", "XL"}, Points: map[string]float64{"XS": 1, "S": 2, "M": 3, "L": 5, "XL": 8}}
+	if result, _ := CheckConsensus("1", votingParticipants("S", "M"), sizes); !result.Consensus || result.Outliers != nil {
+		t.Errorf("Expected adjacent sizes to agree, got %+v", result)
+	}
+	result, _ := CheckConsensus("1", votingParticipants("S", "XL", "M"), sizes)
+	if result.Consensus || result.Outliers == nil || len(result.Outliers.High) != 1 || len(result.Outliers.Low) != 1 {
+		t.Fatalf("Expected sizes far apart to have outliers, got %+v", result)
+	}
+	if result.Outliers.High[0].Vote != "XL" || result.Outliers.Low[0].Vote != "S" {
+		t.Errorf("Expected XL and S as outliers by their points, got %+v", result.Outliers)
+	}
+}
@@ -0,0 +1,168 @@
+package room
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression: minute, hour, day of
+// the month, month and day of the week. Fields take *, numbers, names such as
+// MON or JAN, ranges, lists and steps. A CRON_TZ=<zone> prefix sets the time
+// zone, which is UTC otherwise.
+type cronSchedule struct {
+	minute, hour, day, month, weekday uint64
+	// Days match the day of the month or of the week when both are
+	// restricted, as in standard cron
+	anyDay, anyWeekday bool
+	location           *time.Location
+}
+
+// Shorthands for common schedules
+var cronDescriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of the month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	// 7 is Sunday too
+	{name: "day of the week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// Occurrences are looked for this far ahead, so a schedule that never
+// matches, such as February 30th, doesn't search forever
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+func ParseCron(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	schedule := &cronSchedule{location: time.UTC}
+	if zone, rest, ok := strings.Cut(spec, " "); ok && strings.HasPrefix(zone, "CRON_TZ=") {
+		location, err := time.LoadLocation(strings.TrimPrefix(zone, "CRON_TZ="))
+		if err != nil {
+			return nil, fmt.Errorf("unknown time zone %q", strings.TrimPrefix(zone, "CRON_TZ="))
+		}
+		schedule.location = location
+		spec = strings.TrimSpace(rest)
+	}
+	if expanded, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(cronFields), len(parts))
+	}
+	sets := make([]uint64, len(cronFields))
+	for i, field := range cronFields {
+		set, err := field.parse(parts[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+	schedule.minute, schedule.hour, schedule.day, schedule.month = sets[0], sets[1], sets[2], sets[3]
+	schedule.weekday = sets[4]
+	if schedule.weekday&(1<<7) != 0 {
+		schedule.weekday |= 1
+	}
+	schedule.anyDay, schedule.anyWeekday = parts[2] == "*", parts[4] == "*"
+	return schedule, nil
+}
+
+// parse turns a field into the set of values it matches, as bits.
+func (f cronField) parse(expr string) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepExpr, f.name)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rangeExpr != "*" {
+			lowExpr, highExpr, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if low, err = f.value(lowExpr); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = f.value(highExpr); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = f.max
+			}
+			if high < low {
+				return 0, fmt.Errorf("invalid range %q in %s", rangeExpr, f.name)
+			}
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (f cronField) value(expr string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(expr, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q", f.name, expr)
+	}
+	return v, nil
+}
+
+var errNoOccurrence = errors.New("schedule has no occurrence")
+
+// Next returns the first time after t that the schedule matches.
+func (c *cronSchedule) Next(t time.Time) (time.Time, error) {
+	horizon := t.Add(cronHorizon)
+	t = t.In(c.location).Truncate(time.Minute).Add(time.Minute)
+	for t.Before(horizon) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.location)
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.location)
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.location)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t, nil
+		}
+	}
+	return time.Time{}, errNoOccurrence
+}
+
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	day := c.day&(1<<t.Day()) != 0
+	weekday := c.weekday&(1<<int(t.Weekday())) != 0
+	if c.anyDay || c.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
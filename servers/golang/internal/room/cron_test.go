@@ -0,0 +1,50 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	// A Monday
+	from := time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"0 10 * * MON", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1", time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 1, 9, 45, 0, 0, time.UTC)},
+		{"30 14 * * tue-thu", time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)},
+		{"0 0 1 feb *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Either day matches when both are restricted
+		{"0 12 15 * 5", time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=Europe/Warsaw 0 11 * * 1", time.Date(2024, 1, 1, 11, 0, 0, 0, warsaw)},
+	} {
+		schedule, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Errorf("parseCron(%q): %v", tt.spec, err)
+			continue
+		}
+		if next, err := schedule.Next(from); err != nil || !next.Equal(tt.expected) {
+			t.Errorf("Next occurrence of %q = %v, %v; want %v", tt.spec, next, err, tt.expected)
+		}
+	}
+
+	for _, spec := range []string{"", "0 10 * *", "60 * * * *", "0 10 * * funday", "5-1 * * * *", "*/0 * * * *", "CRON_TZ=Mars/Olympus 0 10 * * 1"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("Expected %q to be refused", spec)
+		}
+	}
+	schedule, _ := ParseCron("0 0 30 2 *")
+	if _, err := schedule.Next(from); err != errNoOccurrence {
+		t.Errorf("Expected February 30th never to occur, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package room
+
+import "fmt"
+
+// Delphi runs take at least two iterations, or there is nothing to converge
+const (
+	minDelphiRounds     = 2
+	maxDelphiRounds     = 10
+	defaultDelphiRounds = 3
+)
+
+// Delphi estimates each story over several anonymous iterations of a points
+// round. Each reveal starts the next iteration at once, showing only how the
+// votes were spread, until they converge or Rounds iterations were voted.
+// The room's Delphi is replaced, never changed, as room states share it.
+type Delphi struct {
+	Rounds int `json:"rounds"`
+	// Iteration is the one being voted on, from 1
+	Iteration int `json:"iteration"`
+	// Previous is how the votes of the last iteration were spread, by card,
+	// without who cast them
+	Previous map[string]int `json:"previous,omitempty"`
+}
+
+// ParseDelphiRounds reads the iterations of a set-delphi message, 0 ending
+// Delphi runs.
+func ParseDelphiRounds(data map[string]interface{}) (int, error) {
+	rounds, ok := data["rounds"].(float64)
+	if !ok {
+		return defaultDelphiRounds, nil
+	}
+	if rounds == 0 {
+		return 0, nil
+	}
+	if rounds != float64(int(rounds)) || rounds < minDelphiRounds || rounds > maxDelphiRounds {
+		return 0, fmt.Errorf("rounds must be a whole number from %d to %d, or 0", minDelphiRounds, maxDelphiRounds)
+	}
+	return int(rounds), nil
+}
+
+// DelphiStep works out where the reveal of a Delphi iteration leaves the run.
+// Unless the votes converged or it was the last iteration, the next one
+// starts with the spread of these votes. next is nil when the run ended, and
+// iteration 0 when the room isn't in one. The caller must hold room.Mu.
+func (room *State) DelphiStep(roundID string, participants []Participant) (iteration int, converged bool, next *Delphi) {
+	if room.Delphi == nil || room.Settings.Mode != ModePoints || len(room.Batch) > 0 || len(room.History) == 0 {
+		return 0, false, nil
+	}
+	delphi := room.Delphi
+	consensus, ok := CheckConsensus(roundID, participants, room.Settings)
+	converged = ok && consensus.Consensus
+
+	if converged || delphi.Iteration >= delphi.Rounds {
+		return delphi.Iteration, converged, nil
+	}
+	round := room.History[len(room.History)-1]
+	return delphi.Iteration, false, &Delphi{Rounds: delphi.Rounds, Iteration: delphi.Iteration + 1, Previous: round.Stats.Distribution}
+}
+
+// DelphiEvent starts Delphi runs on the room's stories, or ends them when
+// Delphi is nil.
+type DelphiEvent struct {
+	Delphi *Delphi `json:"delphi"`
+}
+
+func (DelphiEvent) EventType() string { return "delphi" }
+
+func (e *DelphiEvent) apply(room *State) {
+	room.Delphi = e.Delphi
+}
+
+// DelphiStepEvent follows the reveal of a Delphi iteration, numbering it in
+// the history. With Next the run goes on: the votes are cleared and the last
+// round hidden, leaving only their spread. Without it the run starts over
+// for the next story.
+type DelphiStepEvent struct {
+	Iteration int     `json:"iteration"`
+	Next      *Delphi `json:"next,omitempty"`
+}
+
+func (DelphiStepEvent) EventType() string { return "delphi-step" }
+
+func (e *DelphiStepEvent) apply(room *State) {
+	if room.Delphi == nil || len(room.History) == 0 {
+		return
+	}
+	room.History[len(room.History)-1].DelphiIteration = e.Iteration
+	if e.Next == nil {
+		room.Delphi = &Delphi{Rounds: room.Delphi.Rounds, Iteration: 1}
+		return
+	}
+	next := *e.Next
+	room.Delphi = &next
+	(&ResetEvent{Reestimate: true}).apply(room)
+	room.LastRound = nil
+}
@@ -0,0 +1,129 @@
+package room
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+)
+
+// Limits for a dot-voting round, which prioritizes several options at once
+const (
+	defaultDotsPerParticipant = 3
+	maxDotsPerParticipant     = 20
+	maxDotOptions             = 20
+	maxDotOptionLength        = 200
+)
+
+// DotOption is one of the stories put up for dot-voting.
+type DotOption struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// DotResult is an option's tally once dot-voting is revealed.
+type DotResult struct {
+	DotOption
+	Dots   int `json:"dots"`
+	Voters int `json:"voters"`
+}
+
+// parseDotVotingSettings reads the options and dots per participant of a
+// set-mode message. Options are numbered in the order they were given.
+func parseDotVotingSettings(data map[string]interface{}) (Settings, error) {
+	settings := Settings{Mode: ModeDotVoting, Dots: defaultDotsPerParticipant}
+
+	raw, _ := data["options"].([]interface{})
+	if len(raw) < 2 || len(raw) > maxDotOptions {
+		return Settings{}, fmt.Errorf("dot-voting needs 2 to %d options", maxDotOptions)
+	}
+	for i, value := range raw {
+		title, _ := value.(string)
+		title = CleanText(title, false)
+		if title == "" || len(title) > maxDotOptionLength {
+			return Settings{}, fmt.Errorf("option titles must be 1 to %d characters", maxDotOptionLength)
+		}
+		settings.Options = append(settings.Options, DotOption{ID: strconv.Itoa(i + 1), Title: title})
+	}
+
+	if dots, ok := data["dots"].(float64); ok {
+		if dots != float64(int(dots)) || dots < 1 || dots > maxDotsPerParticipant {
+			return Settings{}, fmt.Errorf("dots must be a whole number from 1 to %d", maxDotsPerParticipant)
+		}
+		settings.Dots = int(dots)
+	}
+	return settings, nil
+}
+
+// ParseDots validates a participant's allocation of dots by option ID. The
+// counts must be whole and may add up to at most the room's dots; options
+// given no dots are left out.
+func ParseDots(settings Settings, raw map[string]interface{}) (map[string]int, error) {
+	dots := make(map[string]int, len(raw))
+	total := 0
+	for id, value := range raw {
+		count, ok := value.(float64)
+		if !ok || count != float64(int(count)) || count < 0 {
+			return nil, fmt.Errorf("invalid dot count for option %q", id)
+		}
+		if !settings.hasOption(id) {
+			return nil, fmt.Errorf("unknown option %q", id)
+		}
+		if count > 0 {
+			dots[id] = int(count)
+			total += int(count)
+		}
+	}
+	if total > settings.Dots {
+		return nil, errors.New("more dots than allowed")
+	}
+	return dots, nil
+}
+
+func (settings Settings) hasOption(id string) bool {
+	for _, option := range settings.Options {
+		if option.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// rankDots tallies the dots of every participant per option, most dots
+// first. Ties keep the order the options were posted in.
+func rankDots(options []DotOption, participants []Participant) []DotResult {
+	ranking := make([]DotResult, len(options))
+	for i, option := range options {
+		ranking[i] = DotResult{DotOption: option}
+		for _, p := range participants {
+			if count := p.Dots[option.ID]; count > 0 {
+				ranking[i].Dots += count
+				ranking[i].Voters++
+			}
+		}
+	}
+	sort.SliceStable(ranking, func(i, j int) bool { return ranking[i].Dots > ranking[j].Dots })
+	return ranking
+}
+
+// DotsEvent replaces a participant's dots, taking them all back when empty.
+type DotsEvent struct {
+	ClientID string         `json:"clientId"`
+	Dots     map[string]int `json:"dots,omitempty"`
+}
+
+func (DotsEvent) EventType() string { return "dots" }
+
+func (e *DotsEvent) apply(room *State) {
+	participant, ok := room.Participants[e.ClientID]
+	if !ok {
+		return
+	}
+	participant.Dots = nil
+	if len(e.Dots) > 0 {
+		// The event is kept in the log, the participant's dots must not share it
+		participant.Dots = maps.Clone(e.Dots)
+	}
+	participant.VotedAt = room.votedAt(len(e.Dots) > 0)
+}
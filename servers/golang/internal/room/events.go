@@ -0,0 +1,469 @@
+package room
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// The event log keeps this many events per room, dropping the oldest
+const MaxEventLog = 5000
+
+// Event is a change to a room's state. Handlers validate a message and
+// apply the event it results in; apply is the only place the change is made,
+// so replaying a room's events rebuilds its state. Side effects such as
+// broadcasts, webhooks and timers stay in the handlers, and so do secrets
+// like session tokens, passcodes and bans, which the log leaves out along
+// with the audit log and when clients were last active.
+type Event interface {
+	EventType() string
+	// apply changes the room. The caller must hold room.Mu.
+	apply(room *State)
+}
+
+// EventRecord is an applied event in a room's log. Seq counts from 1 for the
+// room's first event.
+type EventRecord struct {
+	Seq   int64     `json:"seq"`
+	At    time.Time `json:"at"`
+	Type  string    `json:"type"`
+	Event Event     `json:"event"`
+}
+
+// eventTypes make the empty event for each type, to decode a log.
+var eventTypes = map[string]func() Event{
+	"join":             func() Event { return &JoinEvent{} },
+	"leave":            func() Event { return &LeaveEvent{} },
+	"vote":             func() Event { return &VoteEvent{} },
+	"reveal":           func() Event { return &RevealEvent{} },
+	"reset":            func() Event { return &ResetEvent{} },
+	"story":            func() Event { return &StoryEvent{} },
+	"rename":           func() Event { return &RenameEvent{} },
+	"pause":            func() Event { return &PauseEvent{} },
+	"final-estimate":   func() Event { return &FinalEstimateEvent{} },
+	"discuss":          func() Event { return &DiscussEvent{} },
+	"room-info":        func() Event { return &InfoEvent{} },
+	"vote-change":      func() Event { return &VoteChangeEvent{} },
+	"clear-vote":       func() Event { return &ClearVoteEvent{} },
+	"dimension-vote":   func() Event { return &DimensionVoteEvent{} },
+	"dots":             func() Event { return &DotsEvent{} },
+	"three-point":      func() Event { return &ThreePointEvent{} },
+	"reveal-dimension": func() Event { return &RevealDimensionEvent{} },
+	"reopen":           func() Event { return &ReopenEvent{} },
+	"hand":             func() Event { return &HandEvent{} },
+	"status":           func() Event { return &StatusEvent{} },
+	"presence":         func() Event { return &PresenceEvent{} },
+	"chat":             func() Event { return &ChatEvent{} },
+	"team":             func() Event { return &TeamEvent{} },
+	"close":            func() Event { return &CloseEvent{} },
+	"settings":         func() Event { return &SettingsEvent{} },
+	"reveal-policy":    func() Event { return &RevealPolicyEvent{} },
+	"reveal-request":   func() Event { return &RevealRequestEvent{} },
+	"vote-changes":     func() Event { return &VoteChangesEvent{} },
+	"delphi":           func() Event { return &DelphiEvent{} },
+	"delphi-step":      func() Event { return &DelphiStepEvent{} },
+	"async-round":      func() Event { return &AsyncRoundEvent{} },
+	"batch":            func() Event { return &BatchEvent{} },
+	"batch-vote":       func() Event { return &BatchVoteEvent{} },
+	"batch-reveal":     func() Event { return &BatchRevealEvent{} },
+	"backlog":          func() Event { return &BacklogEvent{} },
+	"take-story":       func() Event { return &TakeStoryEvent{} },
+	"start-story":      func() Event { return &StartStoryEvent{} },
+	"recurrence":       func() Event { return &RecurrenceEvent{} },
+	"new-session":      func() Event { return &NewSessionEvent{} },
+}
+
+func (r *EventRecord) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Seq   int64           `json:"seq"`
+		At    time.Time       `json:"at"`
+		Type  string          `json:"type"`
+		Event json.RawMessage `json:"event"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	newEvent, ok := eventTypes[raw.Type]
+	if !ok {
+		return fmt.Errorf("unknown event type %q", raw.Type)
+	}
+	event := newEvent()
+	if err := json.Unmarshal(raw.Event, event); err != nil {
+		return fmt.Errorf("decoding %s event: %w", raw.Type, err)
+	}
+	*r = EventRecord{Seq: raw.Seq, At: raw.At, Type: raw.Type, Event: event}
+	return nil
+}
+
+// Apply applies an event to the room and appends it to the room's log.
+// The caller must hold room.Mu.
+func (room *State) Apply(event Event) {
+	event.apply(room)
+	room.EventSeq++
+	room.Events = append(room.Events, EventRecord{
+		Seq:   room.EventSeq,
+		At:    room.Now(),
+		Type:  event.EventType(),
+		Event: event,
+	})
+	if len(room.Events) > MaxEventLog {
+		room.Events = room.Events[len(room.Events)-MaxEventLog:]
+	}
+}
+
+var errTrimmedLog = errors.New("event log doesn't start at the room's first event")
+
+// Replay rebuilds a room from its event log, with each event applied at
+// the time it was recorded. Logs that lost their oldest events to
+// MaxEventLog can't be replayed.
+func Replay(roomID string, events []EventRecord) (*State, error) {
+	room := &State{
+		ID:           roomID,
+		Participants: make(map[string]*Participant),
+		Settings:     Settings{Mode: ModePoints},
+		RevealPolicy: RevealPolicy{Mode: RevealAnyone},
+	}
+	if len(events) == 0 {
+		return room, nil
+	}
+	if events[0].Seq != 1 {
+		return nil, errTrimmedLog
+	}
+	room.CreatedAt = events[0].At
+	room.RoundStartedAt = events[0].At
+	for _, record := range events {
+		room.Clock = NewFakeClock(record.At)
+		room.Apply(record.Event)
+		room.LastActivity = record.At
+	}
+	room.Clock = nil
+	return room, nil
+}
+
+// JoinEvent adds a participant, or moves a reconnecting one to their new
+// connection when Replaces is the client ID they had. A client rejoining
+// under its own ID just takes the name.
+type JoinEvent struct {
+	ClientID      string `json:"clientId"`
+	Name          string `json:"name"`
+	ParticipantID string `json:"participantId,omitempty"`
+	Replaces      string `json:"replaces,omitempty"`
+}
+
+func (JoinEvent) EventType() string { return "join" }
+
+func (e *JoinEvent) apply(room *State) {
+	// Whoever creates the room facilitates it
+	if len(room.Participants) == 0 {
+		room.FacilitatorID = e.ClientID
+	}
+	if existing, ok := room.Participants[e.Replaces]; ok && e.Replaces == e.ClientID {
+		existing.Name = e.Name
+		return
+	} else if ok {
+		// Keep the votes, paused state and participant ID of the old entry
+		delete(room.Participants, e.Replaces)
+		if room.FacilitatorID == e.Replaces {
+			room.FacilitatorID = e.ClientID
+		}
+		restored := *existing
+		restored.ID = e.ClientID
+		restored.Name = e.Name
+		restored.Online = true
+		if e.ParticipantID != "" {
+			restored.ParticipantId = e.ParticipantID
+		}
+		room.Participants[e.ClientID] = &restored
+		return
+	}
+	room.Participants[e.ClientID] = &Participant{
+		ID:            e.ClientID,
+		Name:          e.Name,
+		ParticipantId: e.ParticipantID,
+		Online:        true,
+	}
+}
+
+// LeaveEvent removes a participant, handing facilitation on if it was theirs.
+type LeaveEvent struct {
+	ClientID string `json:"clientId"`
+}
+
+func (LeaveEvent) EventType() string { return "leave" }
+
+func (e *LeaveEvent) apply(room *State) {
+	delete(room.Participants, e.ClientID)
+	if room.FacilitatorID == e.ClientID {
+		room.FacilitatorID = nextFacilitator(room)
+	}
+}
+
+// VoteEvent sets a participant's vote in a points round. An empty vote takes
+// it back.
+type VoteEvent struct {
+	ClientID   string `json:"clientId"`
+	Vote       string `json:"vote"`
+	Confidence string `json:"confidence,omitempty"`
+}
+
+func (VoteEvent) EventType() string { return "vote" }
+
+func (e *VoteEvent) apply(room *State) {
+	participant, ok := room.Participants[e.ClientID]
+	if !ok {
+		return
+	}
+	vote := e.Vote
+	participant.Vote = &vote
+	participant.Confidence = e.Confidence
+	participant.VotedAt = room.votedAt(vote != "")
+}
+
+// RevealEvent shows every card and records the round under RoundID, closing
+// an open async round. Raised hands go down and reveal requests start over.
+type RevealEvent struct {
+	RoundID string `json:"roundId"`
+}
+
+func (RevealEvent) EventType() string { return "reveal" }
+
+func (e *RevealEvent) apply(room *State) {
+	for _, p := range room.Participants {
+		p.HandRaised = false
+	}
+	room.Revealed = true
+	room.SecondRevealed = room.Settings.Mode == ModeTwoDimensional
+	room.ReopenedRoundID = ""
+	room.RevealRequests = nil
+	clearDeadline(room)
+
+	// In join order, as client IDs start with the connection time, so the
+	// round replays the same
+	participants := make([]Participant, 0, len(room.Participants))
+	for _, p := range room.Participants {
+		participants = append(participants, *p)
+	}
+	sort.Slice(participants, func(i, j int) bool { return participants[i].ID < participants[j].ID })
+	room.LastRound = &LastRound{
+		ID:           e.RoundID,
+		Participants: participants,
+	}
+	RecordRound(room, e.RoundID, participants, room.Now())
+}
+
+// ResetEvent clears the votes to start the round over. Unless Reestimate is
+// set it also clears the story, the last round and any batch.
+type ResetEvent struct {
+	Reestimate bool `json:"reestimate,omitempty"`
+}
+
+func (ResetEvent) EventType() string { return "reset" }
+
+func (e *ResetEvent) apply(room *State) {
+	resetVotes(room)
+	if e.Reestimate {
+		return
+	}
+	room.LastRound = nil
+	room.Story = nil
+	room.Batch = nil
+	// A new story starts its Delphi run over
+	if room.Delphi != nil {
+		room.Delphi = &Delphi{Rounds: room.Delphi.Rounds, Iteration: 1}
+	}
+}
+
+// StoryEvent sets the story being estimated, or clears it when Story is nil.
+type StoryEvent struct {
+	Story *Story `json:"story"`
+}
+
+func (StoryEvent) EventType() string { return "story" }
+
+func (e *StoryEvent) apply(room *State) {
+	room.Story = nil
+	if e.Story != nil {
+		// The room's story changes in place later, the event's must not
+		story := *e.Story
+		room.Story = &story
+	}
+}
+
+// RenameEvent changes a participant's name.
+type RenameEvent struct {
+	ClientID string `json:"clientId"`
+	Name     string `json:"name"`
+}
+
+func (RenameEvent) EventType() string { return "rename" }
+
+func (e *RenameEvent) apply(room *State) {
+	if participant, ok := room.Participants[e.ClientID]; ok {
+		participant.Name = e.Name
+	}
+}
+
+// PauseEvent suspends or resumes a participant's voting. Away is set when
+// the server paused them for going quiet; resuming brings them back.
+type PauseEvent struct {
+	ClientID string `json:"clientId"`
+	Paused   bool   `json:"paused"`
+	Away     bool   `json:"away,omitempty"`
+}
+
+func (PauseEvent) EventType() string { return "pause" }
+
+func (e *PauseEvent) apply(room *State) {
+	if participant, ok := room.Participants[e.ClientID]; ok {
+		participant.Paused = e.Paused
+		participant.Away = e.Paused && e.Away
+	}
+}
+
+// FinalEstimateEvent settles the story's estimate, and the revealed round's.
+type FinalEstimateEvent struct {
+	Estimate string `json:"estimate"`
+}
+
+func (FinalEstimateEvent) EventType() string { return "final-estimate" }
+
+func (e *FinalEstimateEvent) apply(room *State) {
+	if room.Story == nil {
+		room.Story = &Story{}
+	}
+	room.Story.FinalEstimate = e.Estimate
+	// The estimate belongs to the round that was just revealed for this story
+	if n := len(room.History); n > 0 && room.Revealed {
+		room.History[n-1].FinalEstimate = e.Estimate
+	}
+}
+
+// DiscussEvent starts the discussion of the revealed round.
+type DiscussEvent struct{}
+
+func (DiscussEvent) EventType() string { return "discuss" }
+
+func (e *DiscussEvent) apply(room *State) {
+	room.Discussing = true
+}
+
+// InfoEvent sets the room's name, description and scheduled start.
+type InfoEvent struct {
+	Info Info `json:"info"`
+}
+
+func (InfoEvent) EventType() string { return "room-info" }
+
+func (e *InfoEvent) apply(room *State) {
+	room.Info = e.Info
+}
+
+// ClearVoteEvent takes back every value a participant voted this round.
+type ClearVoteEvent struct {
+	ClientID string `json:"clientId"`
+}
+
+func (ClearVoteEvent) EventType() string { return "clear-vote" }
+
+func (e *ClearVoteEvent) apply(room *State) {
+	if participant, ok := room.Participants[e.ClientID]; ok {
+		participant.Vote = nil
+		participant.SecondVote = nil
+		participant.Dots = nil
+		participant.Confidence = ""
+		participant.VotedAt = time.Time{}
+	}
+}
+
+// ReopenEvent hides the cards of the last revealed round again, taking the
+// round out of the history to be revealed again under its ID.
+type ReopenEvent struct{}
+
+func (ReopenEvent) EventType() string { return "reopen" }
+
+func (e *ReopenEvent) apply(room *State) {
+	room.Revealed = false
+	room.SecondRevealed = false
+	room.Discussing = false
+	if n := len(room.History); n > 0 && room.LastRound != nil && room.History[n-1].ID == room.LastRound.ID {
+		room.History = room.History[:n-1]
+		room.ReopenedRoundID = room.LastRound.ID
+	}
+}
+
+// HandEvent raises or lowers a participant's hand.
+type HandEvent struct {
+	ClientID string `json:"clientId"`
+	Raised   bool   `json:"raised"`
+}
+
+func (HandEvent) EventType() string { return "hand" }
+
+func (e *HandEvent) apply(room *State) {
+	if participant, ok := room.Participants[e.ClientID]; ok {
+		participant.HandRaised = e.Raised
+	}
+}
+
+// PresenceEvent marks a participant connected or not. Disconnected
+// participants keep their seat until they come back or are purged.
+type PresenceEvent struct {
+	ClientID string `json:"clientId"`
+	Online   bool   `json:"online"`
+}
+
+func (PresenceEvent) EventType() string { return "presence" }
+
+func (e *PresenceEvent) apply(room *State) {
+	if participant, ok := room.Participants[e.ClientID]; ok {
+		participant.Online = e.Online
+	}
+}
+
+// ChatEvent adds a message to the chat, dropping the oldest past
+// MaxChatHistory.
+type ChatEvent struct {
+	Message ChatMessage `json:"message"`
+}
+
+func (ChatEvent) EventType() string { return "chat" }
+
+func (e *ChatEvent) apply(room *State) {
+	room.Chat = append(room.Chat, e.Message)
+	if len(room.Chat) > MaxChatHistory {
+		room.Chat = room.Chat[len(room.Chat)-MaxChatHistory:]
+	}
+}
+
+// Status messages are short notes such as "brb", not chat
+const MaxStatusLength = 60
+
+// StatusEvent sets a participant's note to the room, or clears it when empty.
+type StatusEvent struct {
+	ClientID string `json:"clientId"`
+	Status   string `json:"status"`
+}
+
+func (StatusEvent) EventType() string { return "status" }
+
+func (e *StatusEvent) apply(room *State) {
+	if participant, ok := room.Participants[e.ClientID]; ok {
+		participant.Status = e.Status
+	}
+}
+
+// TeamEvent files the room under a team. Scoped rooms were created with the
+// team's token and belong to it.
+type TeamEvent struct {
+	TeamID string `json:"teamId"`
+	Scoped bool   `json:"scoped,omitempty"`
+}
+
+func (TeamEvent) EventType() string { return "team" }
+
+func (e *TeamEvent) apply(room *State) {
+	room.TeamID = e.TeamID
+	room.TeamScoped = e.Scoped
+}
@@ -0,0 +1,13 @@
+package room
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReplayRoomNeedsWholeLog(t *testing.T) {
+	events := []EventRecord{{Seq: 2, Type: "leave", Event: &LeaveEvent{ClientID: "a"}}}
+	if _, err := Replay("trimmed", events); !errors.Is(err, errTrimmedLog) {
+		t.Errorf("Expected a trimmed log to be refused, got %v", err)
+	}
+}
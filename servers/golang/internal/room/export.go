@@ -0,0 +1,129 @@
+package room
+
+import "time"
+
+// RoundRecord is a completed (revealed) round kept for session exports.
+type RoundRecord struct {
+	ID            string      `json:"id"`
+	Story         *Story      `json:"story,omitempty"`
+	Votes         []RoundVote `json:"votes"`
+	Stats         VoteStats   `json:"stats"`
+	FinalEstimate string      `json:"finalEstimate,omitempty"`
+	RevealedAt    int64       `json:"revealedAt"`
+	// Mode is left out for story points. In a two-dimensional round Stats
+	// covers the first dimension and SecondStats the second
+	Mode        VotingMode `json:"mode,omitempty"`
+	Dimensions  []string   `json:"dimensions,omitempty"`
+	SecondStats *VoteStats `json:"secondStats,omitempty"`
+	// Ranking tallies a dot-voting round, most dots first
+	Ranking []DotResult `json:"ranking,omitempty"`
+	// PERT is the room's estimate in a PERT round
+	PERT *PERTResult `json:"pert,omitempty"`
+	// StartedAt is when voting opened and DurationMs how long it took until
+	// the reveal, both left out for rounds that weren't timed
+	StartedAt  int64 `json:"startedAt,omitempty"`
+	DurationMs int64 `json:"durationMs,omitempty"`
+	// Points are the numbers the room's cards counted as, when mapped
+	Points map[string]float64 `json:"points,omitempty"`
+	// Adjusted rounds had votes changed after the reveal, and Stats counts
+	// the changed votes
+	Adjusted bool `json:"adjusted,omitempty"`
+	// DelphiIteration numbers the round among the iterations of a Delphi run
+	DelphiIteration int `json:"delphiIteration,omitempty"`
+}
+
+type RoundVote struct {
+	Name string `json:"name"`
+	Vote string `json:"vote"`
+	// OriginalVote is the vote revealed, when it was changed after
+	OriginalVote string         `json:"originalVote,omitempty"`
+	SecondVote   string         `json:"secondVote,omitempty"`
+	Confidence   string         `json:"confidence,omitempty"`
+	Dots         map[string]int `json:"dots,omitempty"`
+	// ThreePoint is the vote of a PERT round
+	ThreePoint *ThreePointEstimate `json:"threePoint,omitempty"`
+	// VoteTimeMs is how long after the round opened the vote was cast
+	VoteTimeMs int64 `json:"voteTimeMs,omitempty"`
+}
+
+const maxRoundHistory = 500
+
+// RecordRound appends a revealed round of the room's story to its history.
+// The caller must hold room.Mu.
+func RecordRound(room *State, id string, participants []Participant, revealedAt time.Time) {
+	appendRound(room, newRoundRecord(room, id, room.Story, participants, revealedAt))
+}
+
+// newRoundRecord summarizes the votes on a story as the room's mode counts
+// them. The caller must hold room.Mu.
+func newRoundRecord(room *State, id string, story *Story, participants []Participant, revealedAt time.Time) RoundRecord {
+	record := RoundRecord{
+		ID:         id,
+		Votes:      make([]RoundVote, 0, len(participants)),
+		Stats:      ComputeVoteStats(participants, room.Settings.Points),
+		RevealedAt: revealedAt.UnixMilli(),
+	}
+	if !room.RoundStartedAt.IsZero() {
+		record.StartedAt = room.RoundStartedAt.UnixMilli()
+		record.DurationMs = elapsedMillis(room.RoundStartedAt, revealedAt)
+	}
+	if story != nil {
+		story := *story
+		record.Story = &story
+		record.FinalEstimate = story.FinalEstimate
+	}
+	if room.Settings.Mode != ModePoints {
+		record.Mode = room.Settings.Mode
+	}
+	record.Points = room.Settings.Points
+	record.Stats.Agreement = agreementPercent(room.Settings.Mode, record.Stats)
+	if room.Settings.Mode == ModeTwoDimensional {
+		secondStats := ComputeVoteStats(SecondDimensionVotes(participants), room.Settings.Points)
+		record.Dimensions = room.Settings.Dimensions
+		record.SecondStats = &secondStats
+	}
+	if room.Settings.Mode == ModeDotVoting {
+		record.Ranking = rankDots(room.Settings.Options, participants)
+	}
+	if room.Settings.Mode == ModePERT {
+		record.PERT = pertResult(participants)
+	}
+	for _, p := range participants {
+		vote := RoundVote{Name: p.Name, Confidence: p.Confidence, Dots: p.Dots, ThreePoint: p.ThreePoint}
+		if !room.RoundStartedAt.IsZero() && !p.VotedAt.IsZero() {
+			vote.VoteTimeMs = elapsedMillis(room.RoundStartedAt, p.VotedAt)
+		}
+		if p.Vote != nil {
+			vote.Vote = *p.Vote
+		}
+		if p.SecondVote != nil {
+			vote.SecondVote = *p.SecondVote
+		}
+		record.Votes = append(record.Votes, vote)
+	}
+	return record
+}
+
+// votedAt is the time to record for a vote, zero once it is taken back.
+// The caller must hold room.Mu.
+func (room *State) votedAt(hasVote bool) time.Time {
+	if !hasVote {
+		return time.Time{}
+	}
+	return room.Now()
+}
+
+// elapsedMillis is the time from start to end, at least a millisecond so a
+// timed round or vote never looks untimed.
+func elapsedMillis(start, end time.Time) int64 {
+	return max(end.Sub(start).Milliseconds(), 1)
+}
+
+// appendRound adds a round to the room history, dropping the oldest past
+// maxRoundHistory. The caller must hold room.Mu.
+func appendRound(room *State, record RoundRecord) {
+	room.History = append(room.History, record)
+	if len(room.History) > maxRoundHistory {
+		room.History = room.History[len(room.History)-maxRoundHistory:]
+	}
+}
@@ -0,0 +1,94 @@
+package room
+
+import (
+	"sync"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// How many events an operator's stream may fall behind before it is closed
+const firehoseBuffer = 256
+
+// Firehose streams what happens in every room of this instance to operators
+// connected to /admin/ws: the audit log's entries as they are recorded, which
+// carry no vote until the round is revealed, and the errors sent to clients.
+type Firehose struct {
+	mu          sync.Mutex
+	subscribers map[*firehoseSubscriber]struct{}
+}
+
+// firehoseSubscriber is an operator's stream, limited to the rooms of team
+// when it connected with a team token.
+type firehoseSubscriber struct {
+	team   string
+	Events chan transport.Message
+}
+
+func NewFirehose() *Firehose {
+	return &Firehose{subscribers: make(map[*firehoseSubscriber]struct{})}
+}
+
+func (f *Firehose) Subscribe(team string) *firehoseSubscriber {
+	sub := &firehoseSubscriber{team: team, Events: make(chan transport.Message, firehoseBuffer)}
+	f.mu.Lock()
+	f.subscribers[sub] = struct{}{}
+	f.mu.Unlock()
+	return sub
+}
+
+func (f *Firehose) Unsubscribe(sub *firehoseSubscriber) {
+	f.mu.Lock()
+	if _, ok := f.subscribers[sub]; ok {
+		delete(f.subscribers, sub)
+		close(sub.Events)
+	}
+	f.mu.Unlock()
+}
+
+// Close ends every stream, as when what they follow is gone.
+func (f *Firehose) Close() {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subscribers {
+		delete(f.subscribers, sub)
+		close(sub.Events)
+	}
+}
+
+// Publish hands an event to the streams that may see team's rooms. It never
+// blocks, as it is called with room locks held: a stream that is full is cut
+// off instead.
+func (f *Firehose) Publish(team string, message transport.Message) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subscribers {
+		if sub.team != "" && sub.team != team {
+			continue
+		}
+		select {
+		case sub.Events <- message:
+		default:
+			delete(f.subscribers, sub)
+			close(sub.Events)
+		}
+	}
+}
+
+// publishAudit streams an entry of the room's audit log. The caller must hold
+// room.Mu.
+func (room *State) publishAudit(entry AuditEntry) {
+	team := ""
+	if room.TeamScoped {
+		team = room.TeamID
+	}
+	room.Firehose.Publish(team, transport.Message{Type: "audit", Data: map[string]interface{}{
+		"roomId": room.ID,
+		"entry":  entry,
+	}})
+}
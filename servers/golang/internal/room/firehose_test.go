@@ -0,0 +1,30 @@
+package room
+
+import (
+	"testing"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+func TestFirehoseScopesAndCutsOff(t *testing.T) {
+	f := NewFirehose()
+	everything := f.Subscribe("")
+	blue := f.Subscribe("blue")
+
+	f.Publish("", transport.Message{Type: "audit"})
+	f.Publish("blue", transport.Message{Type: "audit"})
+	if len(everything.Events) != 2 || len(blue.Events) != 1 {
+		t.Errorf("Expected a team's stream to see only its rooms, got %d and %d", len(everything.Events), len(blue.Events))
+	}
+
+	for i := 0; i < firehoseBuffer; i++ {
+		f.Publish("", transport.Message{Type: "audit"})
+	}
+	for range everything.Events {
+	}
+	if len(f.subscribers) != 1 {
+		t.Errorf("Expected the stream that fell behind to be cut off, got %d streams", len(f.subscribers))
+	}
+	f.Unsubscribe(everything)
+	f.Unsubscribe(blue)
+}
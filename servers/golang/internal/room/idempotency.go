@@ -0,0 +1,44 @@
+package room
+
+import "time"
+
+const (
+	// MessageIDWindow is how long a message ID is remembered
+	MessageIDWindow = 5 * time.Minute
+	// maxMessageIDs is how many message IDs a room remembers at most
+	maxMessageIDs = 1000
+)
+
+// MessageKey is a message ID as sent by one participant.
+type MessageKey struct {
+	Sender string
+	ID     string
+}
+
+// SeeMessage remembers a message ID, reporting whether it was seen within
+// MessageIDWindow. When the room remembers too many, expired IDs are
+// forgotten, and then the oldest. The caller must hold room.Mu.
+func (room *State) SeeMessage(key MessageKey, now time.Time) bool {
+	if seenAt, ok := room.MessageIDs[key]; ok && now.Sub(seenAt) < MessageIDWindow {
+		return true
+	}
+	if room.MessageIDs == nil {
+		room.MessageIDs = make(map[MessageKey]time.Time)
+	}
+	if len(room.MessageIDs) >= maxMessageIDs {
+		var oldestKey MessageKey
+		var oldest time.Time
+		for k, seenAt := range room.MessageIDs {
+			if now.Sub(seenAt) >= MessageIDWindow {
+				delete(room.MessageIDs, k)
+			} else if oldestKey.ID == "" || seenAt.Before(oldest) {
+				oldestKey, oldest = k, seenAt
+			}
+		}
+		if len(room.MessageIDs) >= maxMessageIDs {
+			delete(room.MessageIDs, oldestKey)
+		}
+	}
+	room.MessageIDs[key] = now
+	return false
+}
@@ -0,0 +1,26 @@
+package room
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSeeMessageIsBounded(t *testing.T) {
+	room := &State{}
+	now := time.Now()
+	for i := range maxMessageIDs + 10 {
+		if room.SeeMessage(MessageKey{Sender: "alice", ID: strconv.Itoa(i)}, now.Add(time.Duration(i)*time.Millisecond)) {
+			t.Fatalf("Expected message %d to be new", i)
+		}
+	}
+	if len(room.MessageIDs) != maxMessageIDs {
+		t.Errorf("Expected %d message IDs, got %d", maxMessageIDs, len(room.MessageIDs))
+	}
+	if room.SeeMessage(MessageKey{Sender: "alice", ID: "0"}, now.Add(time.Second)) {
+		t.Error("Expected the oldest message ID to be forgotten")
+	}
+	if !room.SeeMessage(MessageKey{Sender: "alice", ID: strconv.Itoa(maxMessageIDs + 9)}, now.Add(time.Second)) {
+		t.Error("Expected the latest message ID to be remembered")
+	}
+}
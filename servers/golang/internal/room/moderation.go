@@ -0,0 +1,32 @@
+package room
+
+// RoomBans remembers banned participants for the room's lifetime. Addresses
+// are kept as keyed hashes rather than in the clear.
+type RoomBans struct {
+	SessionTokens  map[string]bool
+	ParticipantIDs map[string]bool
+	IPHashes       map[string]bool
+}
+
+func (b *RoomBans) Add(participant *Participant, ipHash string) {
+	if b.SessionTokens == nil {
+		b.SessionTokens = make(map[string]bool)
+		b.ParticipantIDs = make(map[string]bool)
+		b.IPHashes = make(map[string]bool)
+	}
+	if participant.SessionToken != "" {
+		b.SessionTokens[participant.SessionToken] = true
+	}
+	if participant.ParticipantId != "" {
+		b.ParticipantIDs[participant.ParticipantId] = true
+	}
+	if ipHash != "" {
+		b.IPHashes[ipHash] = true
+	}
+}
+
+func (b *RoomBans) Matches(sessionToken, participantID, ipHash string) bool {
+	return (sessionToken != "" && b.SessionTokens[sessionToken]) ||
+		(participantID != "" && b.ParticipantIDs[participantID]) ||
+		(ipHash != "" && b.IPHashes[ipHash])
+}
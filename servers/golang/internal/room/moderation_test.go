@@ -0,0 +1,26 @@
+package room
+
+import "testing"
+
+func TestRoomBansMatch(t *testing.T) {
+	var bans RoomBans
+	if bans.Matches("token", "id", "hash") {
+		t.Error("Expected empty ban list to match nothing")
+	}
+
+	bans.Add(&Participant{SessionToken: "token", ParticipantId: "id"}, "hash")
+	for _, tt := range []struct {
+		token, id, hash string
+		want            bool
+	}{
+		{"token", "", "", true},
+		{"", "id", "", true},
+		{"", "", "hash", true},
+		{"other", "other", "other", false},
+		{"", "", "", false},
+	} {
+		if got := bans.Matches(tt.token, tt.id, tt.hash); got != tt.want {
+			t.Errorf("matches(%q, %q, %q) = %v, want %v", tt.token, tt.id, tt.hash, got, tt.want)
+		}
+	}
+}
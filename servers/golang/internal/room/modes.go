@@ -0,0 +1,296 @@
+package room
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// VotingMode selects what participants estimate in a room.
+type VotingMode string
+
+const (
+	// ModePoints is a single estimate per participant.
+	ModePoints VotingMode = "points"
+	// ModeTwoDimensional asks every participant for two values, such as
+	// effort and uncertainty, each revealed and summarized on its own.
+	ModeTwoDimensional VotingMode = "two-dimensional"
+	// ModeFistOfFive gauges agreement from 1 (against) to 5 (fully behind).
+	ModeFistOfFive VotingMode = "fist-of-five"
+	// ModeThumbs gauges agreement with a thumb up, sideways or down.
+	ModeThumbs VotingMode = "thumbs"
+	// ModeDotVoting has participants spread a fixed number of dots over
+	// several options to rank them.
+	ModeDotVoting VotingMode = "dot-voting"
+	// ModePERT asks for an optimistic, most likely and pessimistic value,
+	// weighed into an expected value and standard deviation.
+	ModePERT VotingMode = "pert"
+)
+
+// modeVotes lists the values each agreement mode accepts, and whether they
+// count as agreeing. Other modes take the cards of the room's deck.
+var modeVotes = map[VotingMode]map[string]bool{
+	ModeFistOfFive: {"1": false, "2": false, "3": true, "4": true, "5": true},
+	ModeThumbs:     {"up": true, "neutral": false, "down": false},
+}
+
+const (
+	maxDimensionLength = 32
+	maxDeckSize        = 20
+	maxCardLength      = 8
+)
+
+var defaultDimensions = []string{"effort", "uncertainty"}
+
+// Settings are the facilitator's choices for how the room votes.
+// Dimensions name the two values of ModeTwoDimensional, in order: the first
+// is held in Participant.Vote and the second in Participant.SecondVote.
+// Options and Dots are what ModeDotVoting spreads Participant.Dots over.
+// Deck replaces the numeric cards of estimating modes, deckValues when empty,
+// and Points maps cards such as T-shirt sizes to the number they count as in
+// statistics and velocity.
+type Settings struct {
+	Mode       VotingMode         `json:"mode"`
+	Deck       []string           `json:"deck,omitempty"`
+	Points     map[string]float64 `json:"points,omitempty"`
+	Dimensions []string           `json:"dimensions,omitempty"`
+	Options    []DotOption        `json:"options,omitempty"`
+	Dots       int                `json:"dots,omitempty"`
+}
+
+// DimensionIndex returns the position of a dimension label, or -1.
+func (settings Settings) DimensionIndex(label string) int {
+	for i, dimension := range settings.Dimensions {
+		if dimension == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseRoomSettings validates the settings requested in a set-mode message.
+func ParseRoomSettings(data map[string]interface{}) (Settings, error) {
+	mode, _ := data["mode"].(string)
+	settings := Settings{Mode: VotingMode(mode)}
+
+	switch settings.Mode {
+	case ModeFistOfFive, ModeThumbs, ModePERT:
+		return settings, nil
+	case ModePoints:
+		deck, err := parseDeck(data)
+		if err != nil {
+			return Settings{}, err
+		}
+		settings.Deck = deck
+		settings.Points, err = parsePoints(data, deck)
+		return settings, err
+	case ModeTwoDimensional:
+		deck, err := parseDeck(data)
+		if err != nil {
+			return Settings{}, err
+		}
+		settings.Deck = deck
+		if settings.Points, err = parsePoints(data, deck); err != nil {
+			return Settings{}, err
+		}
+		raw, _ := data["dimensions"].([]interface{})
+		if len(raw) == 0 {
+			settings.Dimensions = append([]string(nil), defaultDimensions...)
+			return settings, nil
+		}
+		if len(raw) != 2 {
+			return Settings{}, errors.New("two-dimensional mode needs exactly two dimensions")
+		}
+		for _, value := range raw {
+			label, _ := value.(string)
+			label = strings.TrimSpace(label)
+			if label == "" || len(label) > maxDimensionLength {
+				return Settings{}, fmt.Errorf("dimension labels must be 1 to %d characters", maxDimensionLength)
+			}
+			settings.Dimensions = append(settings.Dimensions, label)
+		}
+		if settings.Dimensions[0] == settings.Dimensions[1] {
+			return Settings{}, errors.New("dimension labels must differ")
+		}
+		return settings, nil
+	case ModeDotVoting:
+		return parseDotVotingSettings(data)
+	default:
+		return Settings{}, fmt.Errorf("unsupported mode %q", mode)
+	}
+}
+
+// parseDeck reads the optional custom deck of a set-mode message. The
+// abstain cards are always dealt, so they needn't be listed.
+func parseDeck(data map[string]interface{}) ([]string, error) {
+	raw, _ := data["deck"].([]interface{})
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if len(raw) < 2 || len(raw) > maxDeckSize {
+		return nil, fmt.Errorf("a deck needs 2 to %d cards", maxDeckSize)
+	}
+	deck := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, value := range raw {
+		card, _ := value.(string)
+		card = CleanText(card, false)
+		if card == "" || utf8.RuneCountInString(card) > maxCardLength {
+			return nil, fmt.Errorf("cards must be 1 to %d characters", maxCardLength)
+		}
+		if seen[card] || IsAbstention(card) {
+			return nil, fmt.Errorf("card %q is dealt twice", card)
+		}
+		seen[card] = true
+		deck = append(deck, card)
+	}
+	return deck, nil
+}
+
+// parsePoints reads the optional points of a set-mode message, by card of
+// the deck, such as {"S": 2, "M": 3, "L": 5} for T-shirt sizes.
+func parsePoints(data map[string]interface{}, deck []string) (map[string]float64, error) {
+	raw, _ := data["points"].(map[string]interface{})
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if len(deck) == 0 {
+		deck = deckValues
+	}
+	points := make(map[string]float64, len(raw))
+	for card, value := range raw {
+		number, ok := value.(float64)
+		if !slices.Contains(deck, card) {
+			return nil, fmt.Errorf("points given for %q, which isn't a card of the deck", card)
+		}
+		if !ok || number < 0 || math.IsInf(number, 0) {
+			return nil, fmt.Errorf("points for %q must be a number of at least 0", card)
+		}
+		points[card] = number
+	}
+	return points, nil
+}
+
+// PointsOf is the number a vote or estimate counts as: its points when
+// mapped, or else its own value when numeric.
+func PointsOf(value string, points map[string]float64) (float64, bool) {
+	if number, ok := points[value]; ok {
+		return number, true
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	return number, err == nil
+}
+
+// AcceptsVote reports whether a vote is a card of the room's deck, or one
+// of the values of an agreement mode. Clearing a vote with an empty one
+// always is valid.
+func (settings Settings) AcceptsVote(vote string) bool {
+	if vote == "" {
+		return true
+	}
+	if values, restricted := modeVotes[settings.Mode]; restricted {
+		_, ok := values[vote]
+		return ok
+	}
+	if IsAbstention(vote) {
+		return true
+	}
+	deck := settings.Deck
+	if len(deck) == 0 {
+		deck = deckValues
+	}
+	return slices.Contains(deck, vote)
+}
+
+// agreementPercent is the share of votes in an agreement mode that agree:
+// three fingers or more, or a thumb up, to one decimal. It is nil for other
+// modes and for rounds without votes.
+func agreementPercent(mode VotingMode, stats VoteStats) *float64 {
+	values, ok := modeVotes[mode]
+	if !ok || stats.VoteCount == 0 {
+		return nil
+	}
+	agreeing := 0
+	for vote, count := range stats.Distribution {
+		if values[vote] {
+			agreeing += count
+		}
+	}
+	percent := math.Round(1000*float64(agreeing)/float64(stats.VoteCount)) / 10
+	return &percent
+}
+
+// SecondDimensionVotes returns the participants with their second value in
+// place of their vote, so ComputeVoteStats can summarize it.
+func SecondDimensionVotes(participants []Participant) []Participant {
+	second := make([]Participant, len(participants))
+	for i, p := range participants {
+		second[i] = Participant{ID: p.ID, Name: p.Name, Vote: p.SecondVote}
+	}
+	return second
+}
+
+// SettingsEvent switches the room's voting mode, starting the round over
+// without any batch.
+type SettingsEvent struct {
+	Settings Settings `json:"settings"`
+}
+
+func (SettingsEvent) EventType() string { return "settings" }
+
+func (e *SettingsEvent) apply(room *State) {
+	room.Settings = e.Settings
+	room.Batch = nil
+	resetVotes(room)
+}
+
+// DimensionVoteEvent sets either or both values of a participant's
+// two-dimensional vote, leaving out any that isn't changing.
+type DimensionVoteEvent struct {
+	ClientID   string  `json:"clientId"`
+	Vote       *string `json:"vote,omitempty"`
+	SecondVote *string `json:"secondVote,omitempty"`
+	Confidence string  `json:"confidence,omitempty"`
+}
+
+func (DimensionVoteEvent) EventType() string { return "dimension-vote" }
+
+func (e *DimensionVoteEvent) apply(room *State) {
+	participant, ok := room.Participants[e.ClientID]
+	if !ok {
+		return
+	}
+	if e.Vote != nil {
+		vote := *e.Vote
+		participant.Vote = &vote
+		participant.Confidence = e.Confidence
+	}
+	if e.SecondVote != nil {
+		secondVote := *e.SecondVote
+		participant.SecondVote = &secondVote
+	}
+	participant.VotedAt = room.votedAt(participant.Vote != nil && *participant.Vote != "" || participant.SecondVote != nil && *participant.SecondVote != "")
+}
+
+// RevealDimensionEvent shows one value of a two-dimensional round, by its
+// index. Revealing the other as well reveals the round.
+type RevealDimensionEvent struct {
+	Index int `json:"index"`
+}
+
+func (RevealDimensionEvent) EventType() string { return "reveal-dimension" }
+
+func (e *RevealDimensionEvent) apply(room *State) {
+	for _, p := range room.Participants {
+		p.HandRaised = false
+	}
+	if e.Index == 0 {
+		room.Revealed = true
+	} else {
+		room.SecondRevealed = true
+	}
+}
@@ -0,0 +1,112 @@
+package room
+
+import "testing"
+
+func TestParseRoomSettings(t *testing.T) {
+	settings, err := ParseRoomSettings(map[string]interface{}{"mode": "two-dimensional"})
+	if err != nil || len(settings.Dimensions) != 2 || settings.Dimensions[0] != "effort" {
+		t.Errorf("Expected the default dimensions, got %+v %v", settings, err)
+	}
+
+	for _, data := range []map[string]interface{}{
+		{"mode": "tarot"},
+		{"mode": "two-dimensional", "dimensions": []interface{}{"effort"}},
+		{"mode": "two-dimensional", "dimensions": []interface{}{"risk", "risk"}},
+		{"mode": "two-dimensional", "dimensions": []interface{}{"effort", " "}},
+	} {
+		if _, err := ParseRoomSettings(data); err == nil {
+			t.Errorf("Expected %v to be rejected", data)
+		}
+	}
+}
+
+func TestAcceptsVote(t *testing.T) {
+	points := Settings{Mode: ModePoints}
+	for _, vote := range []string{"", "0", "0.5", "13", "40", "?", "☕"} {
+		if !points.AcceptsVote(vote) {
+			t.Errorf("Expected %q on the default deck", vote)
+		}
+	}
+	for _, vote := range []string{"7", "100", " 5", "up", "coffee"} {
+		if points.AcceptsVote(vote) {
+			t.Errorf("Expected %q to be off the default deck", vote)
+		}
+	}
+
+	sizes := Settings{Mode: ModePoints, Deck: []string{"S", "M", "L"}}
+	if !sizes.AcceptsVote("M") || !sizes.AcceptsVote("?") || sizes.AcceptsVote("5") {
+		t.Error("Expected a custom deck, with the abstain cards, to replace the default")
+	}
+	thumbs := Settings{Mode: ModeThumbs}
+	if !thumbs.AcceptsVote("") || thumbs.AcceptsVote("?") || thumbs.AcceptsVote("5") {
+		t.Error("Expected thumbs mode to take thumbs only")
+	}
+}
+
+func TestParseDeck(t *testing.T) {
+	settings, err := ParseRoomSettings(map[string]interface{}{"mode": "points", "deck": []interface{}{"S", " M ", "L"}})
+	if err != nil || len(settings.Deck) != 3 || settings.Deck[1] != "M" {
+		t.Errorf("Expected the custom deck, got %+v %v", settings, err)
+	}
+	for _, deck := range [][]interface{}{
+		{"S"},
+		{"S", "S"},
+		{"S", "?"},
+		{"S", ""},
+		{"S", "enormous!"},
+	} {
+		if _, err := ParseRoomSettings(map[string]interface{}{"mode": "points", "deck": deck}); err == nil {
+			t.Errorf("Expected deck %v to be rejected", deck)
+		}
+	}
+}
+
+func TestParsePoints(t *testing.T) {
+	sizes := []interface{}{"S", "M", "L"}
+	settings, err := ParseRoomSettings(map[string]interface{}{"mode": "points", "deck": sizes, "points": map[string]interface{}{"S": 2.0, "M": 3.0}})
+	if err != nil || settings.Points["M"] != 3 || len(settings.Points) != 2 {
+		t.Errorf("Expected the sizes' points, got %+v %v", settings, err)
+	}
+	for _, points := range []map[string]interface{}{
+		{"XL": 8.0},
+		{"?": 0.0},
+		{"S": -1.0},
+		{"S": "2"},
+	} {
+		if _, err := ParseRoomSettings(map[string]interface{}{"mode": "points", "deck": sizes, "points": points}); err == nil {
+			t.Errorf("Expected points %v to be rejected", points)
+		}
+	}
+}
+
+func TestAgreementPercent(t *testing.T) {
+	stats := VoteStats{VoteCount: 3, Distribution: map[string]int{"2": 1, "3": 1, "5": 1}}
+	if agreement := agreementPercent(ModeFistOfFive, stats); agreement == nil || *agreement != 66.7 {
+		t.Errorf("Expected 66.7%% agreement, got %v", agreement)
+	}
+	if agreement := agreementPercent(ModePoints, stats); agreement != nil {
+		t.Errorf("Expected no agreement for story points, got %v", *agreement)
+	}
+}
+
+func TestParseDots(t *testing.T) {
+	settings, err := parseDotVotingSettings(map[string]interface{}{
+		"options": []interface{}{"Search", "Checkout"},
+		"dots":    float64(2),
+	})
+	if err != nil || settings.Dots != 2 || settings.Options[1].ID != "2" {
+		t.Fatalf("Unexpected settings: %+v %v", settings, err)
+	}
+
+	for _, raw := range []map[string]interface{}{
+		{"1": float64(3)},
+		{"1": float64(1), "2": float64(2)},
+		{"3": float64(1)},
+		{"1": float64(-1)},
+		{"1": 0.5},
+	} {
+		if _, err := ParseDots(settings, raw); err == nil {
+			t.Errorf("Expected %v to be rejected", raw)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package room
+
+import "math"
+
+// ThreePointEstimate is a participant's vote in ModePERT, with the PERT
+// expected value and standard deviation worked out from it.
+type ThreePointEstimate struct {
+	Optimistic  float64 `json:"optimistic"`
+	MostLikely  float64 `json:"mostLikely"`
+	Pessimistic float64 `json:"pessimistic"`
+	Expected    float64 `json:"expected"`
+	StdDev      float64 `json:"stdDev"`
+}
+
+// PERTResult sums up a PERT round: the room's three-point estimate is the
+// mean of each of the participants' values, and is weighed like theirs.
+type PERTResult struct {
+	Estimate  ThreePointEstimate `json:"estimate"`
+	VoteCount int                `json:"voteCount"`
+}
+
+// NewThreePointEstimate weighs the most likely value four times as much as
+// the extremes, as PERT does: (O + 4M + P) / 6, with a standard deviation of
+// (P - O) / 6. Values are rounded to two decimals.
+func NewThreePointEstimate(optimistic, mostLikely, pessimistic float64) ThreePointEstimate {
+	return ThreePointEstimate{
+		Optimistic:  optimistic,
+		MostLikely:  mostLikely,
+		Pessimistic: pessimistic,
+		Expected:    math.Round((optimistic+4*mostLikely+pessimistic)/6*100) / 100,
+		StdDev:      math.Round((pessimistic-optimistic)/6*100) / 100,
+	}
+}
+
+// pertResult works out the room's estimate from the participants who voted,
+// or nil when nobody did.
+func pertResult(participants []Participant) *PERTResult {
+	var optimistic, mostLikely, pessimistic float64
+	count := 0
+	for _, p := range participants {
+		if p.ThreePoint == nil {
+			continue
+		}
+		optimistic += p.ThreePoint.Optimistic
+		mostLikely += p.ThreePoint.MostLikely
+		pessimistic += p.ThreePoint.Pessimistic
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	n := float64(count)
+	return &PERTResult{
+		Estimate:  NewThreePointEstimate(optimistic/n, mostLikely/n, pessimistic/n),
+		VoteCount: count,
+	}
+}
+
+// ThreePointEvent replaces a participant's three-point estimate, taking it
+// back when Estimate is nil.
+type ThreePointEvent struct {
+	ClientID string              `json:"clientId"`
+	Estimate *ThreePointEstimate `json:"estimate"`
+}
+
+func (ThreePointEvent) EventType() string { return "three-point" }
+
+func (e *ThreePointEvent) apply(room *State) {
+	participant, ok := room.Participants[e.ClientID]
+	if !ok {
+		return
+	}
+	participant.ThreePoint = nil
+	if e.Estimate != nil {
+		estimate := *e.Estimate
+		participant.ThreePoint = &estimate
+	}
+	participant.VotedAt = room.votedAt(e.Estimate != nil)
+}
@@ -0,0 +1,63 @@
+package room
+
+// Phase is where a room is in its estimation cycle. Rooms start in the
+// lobby, vote, reveal, may discuss the result and go back to voting with a
+// reestimate, reset or the next story; ending the session closes them.
+type Phase string
+
+const (
+	// PhaseLobby is a room where nothing has been estimated or voted on yet
+	PhaseLobby      Phase = "lobby"
+	PhaseVoting     Phase = "voting"
+	PhaseRevealed   Phase = "revealed"
+	PhaseDiscussing Phase = "discussing"
+	PhaseClosed     Phase = "closed"
+)
+
+// PhaseRules are the phases that accept each message type. Other messages are
+// accepted in every phase but closed.
+var PhaseRules = map[string][]Phase{
+	"vote":                {PhaseLobby, PhaseVoting},
+	"clear-vote":          {PhaseLobby, PhaseVoting},
+	"reveal":              {PhaseLobby, PhaseVoting},
+	"request-reveal":      {PhaseLobby, PhaseVoting},
+	"request-explanation": {PhaseRevealed, PhaseDiscussing},
+	"reopen-round":        {PhaseRevealed, PhaseDiscussing},
+}
+
+// Phase works out the room's phase from its state. A two-dimensional round is
+// revealed once both dimensions are. The caller must hold room.Mu.
+func (room *State) Phase() Phase {
+	switch {
+	case room.Closed:
+		return PhaseClosed
+	case room.WaitingToStart():
+		return PhaseLobby
+	case room.Revealed && (room.Settings.Mode != ModeTwoDimensional || room.SecondRevealed):
+		if room.Discussing {
+			return PhaseDiscussing
+		}
+		return PhaseRevealed
+	case room.Story == nil && len(room.History) == 0 && len(room.Batch) == 0 && !HasVotes(room):
+		return PhaseLobby
+	}
+	return PhaseVoting
+}
+
+// HasVotes reports whether anyone has voted in the current round, on any
+// story of a batch. The caller must hold room.Mu.
+func HasVotes(room *State) bool {
+	for _, p := range room.Participants {
+		if p.HasVoted() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasVoted reports whether the participant voted in the current round, on
+// any story of a batch.
+func (p *Participant) HasVoted() bool {
+	return p.Vote != nil && *p.Vote != "" || p.SecondVote != nil && *p.SecondVote != "" ||
+		len(p.Dots) > 0 || p.ThreePoint != nil || len(p.BatchVotes) > 0
+}
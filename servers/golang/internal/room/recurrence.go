@@ -0,0 +1,70 @@
+package room
+
+import (
+	"fmt"
+	"time"
+)
+
+// Occurrences of a recurring room must be at least this far apart, so a
+// schedule can't wipe the room while it is in use
+const minRecurrenceInterval = time.Hour
+
+// Recurrence makes a room a standing session, such as a weekly refinement: at
+// each occurrence of Schedule the session so far is archived and the room
+// starts over under the same code. Recurring rooms are kept when everyone
+// leaves, until the session is ended.
+type Recurrence struct {
+	// Schedule is a cron expression, optionally with a CRON_TZ= prefix
+	Schedule string `json:"schedule"`
+	// NextAt is the next occurrence, in Unix milliseconds
+	NextAt int64 `json:"nextAt"`
+}
+
+// CheckRecurrence checks a schedule, including that its occurrences aren't
+// too close together.
+func CheckRecurrence(spec string, now time.Time) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	first, err := schedule.Next(now)
+	if err != nil {
+		return err
+	}
+	second, err := schedule.Next(first)
+	if err == nil && second.Sub(first) < minRecurrenceInterval {
+		return fmt.Errorf("occurrences must be at least %s apart", minRecurrenceInterval)
+	}
+	return nil
+}
+
+// RecurrenceEvent makes the room recur, or stop recurring when Recurrence is
+// nil. Room states share the Recurrence, so it is replaced rather than
+// changed.
+type RecurrenceEvent struct {
+	Recurrence *Recurrence `json:"recurrence"`
+}
+
+func (RecurrenceEvent) EventType() string { return "recurrence" }
+
+func (e *RecurrenceEvent) apply(room *State) {
+	room.Recurrence = nil
+	if e.Recurrence != nil {
+		recurrence := *e.Recurrence
+		room.Recurrence = &recurrence
+	}
+}
+
+// NewSessionEvent starts a recurring room's next session, clearing the round,
+// its history and chat but keeping the participants, settings and backlog.
+type NewSessionEvent struct{}
+
+func (NewSessionEvent) EventType() string { return "new-session" }
+
+func (e *NewSessionEvent) apply(room *State) {
+	(&ResetEvent{}).apply(room)
+	clearDeadline(room)
+	room.History = nil
+	room.Chat = nil
+	room.CreatedAt = room.Now()
+}
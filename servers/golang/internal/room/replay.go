@@ -0,0 +1,57 @@
+package room
+
+import "github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+
+// replayBufferSize is how many of its broadcasts a room keeps for replaying
+const replayBufferSize = 64
+
+// replayMessages are the broadcasts kept for replaying, and how many of the
+// latest of each type are replayed at most
+var replayMessages = map[string]int{
+	"revealed":       1,
+	"batch-revealed": 1,
+	"vote-changed":   10,
+	"delphi-round":   1,
+	"countdown":      1,
+	"announcement":   1,
+}
+
+// replayBuffer is a ring of a room's latest broadcasts.
+type replayBuffer struct {
+	messages []transport.Message
+	next     int
+}
+
+// Record keeps a broadcast if it is one replayed, dropping the oldest kept
+// when the buffer is full. The caller must hold room.SendMu.
+func (b *replayBuffer) Record(message transport.Message) {
+	if replayMessages[message.Type] == 0 {
+		return
+	}
+	message.Seq = 0
+	if len(b.messages) < replayBufferSize {
+		b.messages = append(b.messages, message)
+		return
+	}
+	b.messages[b.next] = message
+	b.next = (b.next + 1) % replayBufferSize
+}
+
+// Replay returns the kept broadcasts worth replaying, oldest first, leaving
+// out the ones the skip function rejects. The caller must hold room.SendMu.
+func (b *replayBuffer) Replay(skip func(msgType string) bool) []transport.Message {
+	counts := make(map[string]int)
+	var replayed []transport.Message
+	for i := len(b.messages) - 1; i >= 0; i-- {
+		message := b.messages[(b.next+i)%len(b.messages)]
+		if counts[message.Type] >= replayMessages[message.Type] || skip(message.Type) {
+			continue
+		}
+		counts[message.Type]++
+		replayed = append(replayed, message)
+	}
+	for i, j := 0, len(replayed)-1; i < j; i, j = i+1, j-1 {
+		replayed[i], replayed[j] = replayed[j], replayed[i]
+	}
+	return replayed
+}
@@ -0,0 +1,28 @@
+package room
+
+import (
+	"testing"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+func TestReplayBufferIsBounded(t *testing.T) {
+	var buffer replayBuffer
+	for i := range replayBufferSize + 10 {
+		buffer.Record(transport.Message{Type: "vote-changed", Data: i, Seq: int64(i)})
+		buffer.Record(transport.Message{Type: "participant-voted", Data: i})
+	}
+	if len(buffer.messages) != replayBufferSize {
+		t.Errorf("Expected %d kept broadcasts, got %d", replayBufferSize, len(buffer.messages))
+	}
+
+	replayed := buffer.Replay(func(string) bool { return false })
+	if len(replayed) != replayMessages["vote-changed"] {
+		t.Fatalf("Expected the latest %d vote changes, got %d", replayMessages["vote-changed"], len(replayed))
+	}
+	for i, message := range replayed {
+		if want := replayBufferSize + 10 - replayMessages["vote-changed"] + i; message.Data != want || message.Seq != 0 {
+			t.Errorf("Expected vote change %d in place %d without its number, got %+v", want, i, message)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package room
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// RevealMode is who may reveal a room's votes.
+type RevealMode string
+
+const (
+	RevealAnyone          RevealMode = "anyone"
+	RevealFacilitatorOnly RevealMode = "facilitator-only"
+	// RevealVoteThreshold lets anyone reveal once enough of the room voted
+	RevealVoteThreshold RevealMode = "vote-threshold"
+	// RevealMajorityRequest reveals once more than a share of the room sent
+	// request-reveal
+	RevealMajorityRequest RevealMode = "majority-request"
+)
+
+// Share of active participants who must have voted under
+// RevealVoteThreshold, or requested a reveal under RevealMajorityRequest,
+// unless the facilitator picks another
+const defaultRevealThreshold = 0.5
+
+// RevealPolicy is the facilitator's choice of who may reveal. The facilitator
+// may always reveal, whatever the policy.
+type RevealPolicy struct {
+	Mode RevealMode `json:"mode"`
+	// Threshold is the share of active participants, from 0 to 1, who must
+	// have voted under RevealVoteThreshold, or who must be exceeded by
+	// requests under RevealMajorityRequest
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+// ParseRevealPolicy validates the policy requested in a set-reveal-policy
+// message.
+func ParseRevealPolicy(data map[string]interface{}) (RevealPolicy, error) {
+	mode, _ := data["policy"].(string)
+	policy := RevealPolicy{Mode: RevealMode(mode)}
+	switch policy.Mode {
+	case RevealAnyone, RevealFacilitatorOnly:
+		return policy, nil
+	case RevealVoteThreshold, RevealMajorityRequest:
+		policy.Threshold = defaultRevealThreshold
+		if threshold, ok := data["threshold"].(float64); ok {
+			if threshold <= 0 || threshold > 1 {
+				return RevealPolicy{}, fmt.Errorf("threshold must be above 0 and at most 1, got %g", threshold)
+			}
+			policy.Threshold = threshold
+		}
+		return policy, nil
+	}
+	return RevealPolicy{}, fmt.Errorf("unknown reveal policy %q", mode)
+}
+
+// activeVoters counts the participants taking part in the round, online and
+// not sitting it out, and how many of them have voted. The caller must hold
+// room.Mu.
+func activeVoters(room *State) (active, voted int) {
+	for _, p := range room.Participants {
+		if !p.Online || p.Paused {
+			continue
+		}
+		active++
+		if p.HasVoted() {
+			voted++
+		}
+	}
+	return active, voted
+}
+
+// RevealRefusal returns why the room's policy keeps clientID from revealing,
+// or "" if it may. The caller must hold room.Mu.
+func (room *State) RevealRefusal(clientID string) string {
+	if clientID == room.FacilitatorID {
+		return ""
+	}
+	switch room.RevealPolicy.Mode {
+	case RevealFacilitatorOnly:
+		return "Only the facilitator can reveal"
+	case RevealVoteThreshold:
+		active, voted := activeVoters(room)
+		needed := int(math.Ceil(room.RevealPolicy.Threshold * float64(active)))
+		if voted < needed {
+			return fmt.Sprintf("%d of %d participants have voted, %d are needed to reveal", voted, active, needed)
+		}
+	case RevealMajorityRequest:
+		return "Reveals need more than " + formatShare(room.RevealPolicy.Threshold) + " of the room to send request-reveal"
+	}
+	return ""
+}
+
+// formatShare writes a share such as 0.5 as a percentage.
+func formatShare(share float64) string {
+	return strconv.FormatFloat(math.Round(share*1000)/10, 'f', -1, 64) + "%"
+}
+
+// revealRequester is a participant who asked for the reveal.
+type revealRequester struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// RevealRequests lists the active participants who asked for the reveal, in
+// the order they did, and how many requests reveal the round: more than the
+// threshold's share of active participants, or all of them when that's every
+// one. The caller must hold room.Mu.
+func RevealRequests(room *State) (requestedBy []revealRequester, needed int) {
+	requestedBy = []revealRequester{}
+	for _, id := range room.RevealRequests {
+		if p, ok := room.Participants[id]; ok && p.Online && !p.Paused {
+			requestedBy = append(requestedBy, revealRequester{ID: p.ID, Name: p.Name})
+		}
+	}
+	active, _ := activeVoters(room)
+	needed = min(int(math.Floor(room.RevealPolicy.Threshold*float64(active)))+1, active)
+	return requestedBy, needed
+}
+
+// RevealPolicyEvent sets who may reveal the votes.
+type RevealPolicyEvent struct {
+	Policy RevealPolicy `json:"policy"`
+}
+
+func (RevealPolicyEvent) EventType() string { return "reveal-policy" }
+
+func (e *RevealPolicyEvent) apply(room *State) {
+	room.RevealPolicy = e.Policy
+}
+
+// RevealRequestEvent counts a participant's request to reveal this round.
+type RevealRequestEvent struct {
+	ClientID string `json:"clientId"`
+}
+
+func (RevealRequestEvent) EventType() string { return "reveal-request" }
+
+func (e *RevealRequestEvent) apply(room *State) {
+	if !slices.Contains(room.RevealRequests, e.ClientID) {
+		room.RevealRequests = append(room.RevealRequests, e.ClientID)
+	}
+}
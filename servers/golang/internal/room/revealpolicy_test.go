@@ -0,0 +1,25 @@
+package room
+
+import "testing"
+
+func TestParseRevealPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		data     map[string]interface{}
+		expected RevealPolicy
+		valid    bool
+	}{
+		{map[string]interface{}{"policy": "anyone"}, RevealPolicy{Mode: RevealAnyone}, true},
+		{map[string]interface{}{"policy": "facilitator-only", "threshold": 0.9}, RevealPolicy{Mode: RevealFacilitatorOnly}, true},
+		{map[string]interface{}{"policy": "vote-threshold"}, RevealPolicy{Mode: RevealVoteThreshold, Threshold: defaultRevealThreshold}, true},
+		{map[string]interface{}{"policy": "vote-threshold", "threshold": 0.75}, RevealPolicy{Mode: RevealVoteThreshold, Threshold: 0.75}, true},
+		{map[string]interface{}{"policy": "majority-request", "threshold": 0.66}, RevealPolicy{Mode: RevealMajorityRequest, Threshold: 0.66}, true},
+		{map[string]interface{}{"policy": "vote-threshold", "threshold": 1.5}, RevealPolicy{}, false},
+		{map[string]interface{}{"policy": "vote-threshold", "threshold": 0.0}, RevealPolicy{}, false},
+		{map[string]interface{}{"policy": "whoever"}, RevealPolicy{}, false},
+	} {
+		policy, err := ParseRevealPolicy(tt.data)
+		if (err == nil) != tt.valid || policy != tt.expected {
+			t.Errorf("parseRevealPolicy(%v) = %+v, %v", tt.data, policy, err)
+		}
+	}
+}
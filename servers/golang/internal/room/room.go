@@ -0,0 +1,261 @@
+// Package room holds a planning-poker room: its participants, rounds and
+// settings, and the events that change them. The server serializes access
+// through each room's Mu and broadcasts what changed.
+package room
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Participant struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Vote       *string        `json:"vote"`
+	SecondVote *string        `json:"secondVote,omitempty"`
+	Dots       map[string]int `json:"dots,omitempty"`
+	// ThreePoint is the vote of a PERT round
+	ThreePoint *ThreePointEstimate `json:"threePoint,omitempty"`
+	Confidence string              `json:"confidence,omitempty"`
+	Paused     bool                `json:"paused,omitempty"`
+	// Away is set when the server paused the participant for inactivity,
+	// until they vote or resume
+	Away       bool `json:"away,omitempty"`
+	HandRaised bool `json:"handRaised,omitempty"`
+	// OriginalVote is the vote revealed, once it was changed after the reveal
+	OriginalVote string `json:"originalVote,omitempty"`
+	// Status is the participant's note to the room, until they next vote
+	Status        string `json:"status,omitempty"`
+	ParticipantId string `json:"participantId,omitempty"`
+	Online        bool   `json:"online"`
+	SessionToken  string `json:"-"`
+	// BatchVotes holds the votes on an open batch, by story ID
+	BatchVotes map[string]string `json:"batchVotes,omitempty"`
+	// VotedAt is when the current vote was cast, to time the round
+	VotedAt time.Time `json:"-"`
+	// LatencyMs is the round trip of the participant's connection, for
+	// admins and, when the server shows it, the room
+	LatencyMs int64 `json:"latencyMs,omitempty"`
+	// LastActiveAt is when the participant last sent a message, and
+	// AwayTimer checks whether they went away since
+	LastActiveAt time.Time `json:"-"`
+	AwayTimer    Timer     `json:"-"`
+}
+
+type Story struct {
+	Title         string `json:"title"`
+	Link          string `json:"link"`
+	Key           string `json:"key,omitempty"`
+	Summary       string `json:"summary,omitempty"`
+	Description   string `json:"description,omitempty"`
+	FinalEstimate string `json:"finalEstimate,omitempty"`
+	// Tracker is the issue tracker Key belongs to, once the story is enriched
+	// from its link
+	Tracker string `json:"tracker,omitempty"`
+	// Preview describes the linked page, when no tracker knows it
+	Preview *LinkPreview `json:"preview,omitempty"`
+}
+
+// Issue trackers a story can be enriched from
+const (
+	TrackerJira   = "jira"
+	TrackerLinear = "linear"
+	TrackerGitLab = "gitlab"
+)
+
+type LastRound struct {
+	ID           string        `json:"id"`
+	Participants []Participant `json:"participants"`
+}
+
+type ChatMessage struct {
+	ID         string `json:"id"`
+	SenderID   string `json:"senderId"`
+	SenderName string `json:"senderName"`
+	Text       string `json:"text"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+const (
+	MaxChatHistory     = 100
+	chatHistoryInState = 20
+	MaxChatLength      = 500
+)
+
+type State struct {
+	ID           string
+	Info         Info
+	Participants map[string]*Participant
+	Revealed     bool
+	LastRound    *LastRound
+	Story        *Story
+	Chat         []ChatMessage
+	History      []RoundRecord
+	CreatedAt    time.Time
+	LastActivity time.Time
+	// FacilitatorID is the client that created the room, or whoever took over
+	// after they left
+	FacilitatorID string
+	Passcode      []byte
+	Bans          RoomBans
+	Mu            sync.RWMutex
+
+	// How the room votes; SecondRevealed is Revealed for the second value of
+	// a two-dimensional round
+	Settings       Settings
+	SecondRevealed bool
+	// RevealPolicy is who may reveal the votes. RevealRequests are the
+	// clients who asked for the reveal this round, in order
+	RevealPolicy   RevealPolicy
+	RevealRequests []string
+	// AllowVoteChanges lets participants change their vote once revealed
+	AllowVoteChanges bool
+	// Delphi runs each story over several anonymous iterations, when set
+	Delphi *Delphi
+	// ReopenedRoundID is the round reopen-round took back, revealed again
+	// under the same ID
+	ReopenedRoundID string
+	// RoundStartedAt is when voting on the current round opened
+	RoundStartedAt time.Time
+	// TeamID files the room's sessions under a team, for its velocity.
+	// TeamScoped rooms were created with the team's token and belong to it
+	TeamID     string
+	TeamScoped bool
+	// Backlog are the stories queued for estimation, next first
+	Backlog []Story
+	// Audit is the log of actions taken in the room, oldest first
+	Audit []AuditEntry
+	// Events are the changes applied to the room, oldest first, and EventSeq
+	// the sequence number of the last
+	Events   []EventRecord
+	EventSeq int64
+
+	// Deadline is when an open async round is revealed, by DeadlineTimer
+	Deadline      time.Time
+	DeadlineTimer Timer
+	// StartTimer counts down to the scheduled start in Info
+	StartTimer Timer
+	// Recurrence starts the room over on a schedule, by RecurrenceTimer
+	Recurrence      *Recurrence
+	RecurrenceTimer Timer
+
+	// Batch are the stories open for estimation at once, in order
+	Batch []BatchStory
+
+	// MessageIDs are when the client message IDs seen recently were first
+	// seen, by sender, to handle each message once
+	MessageIDs map[MessageKey]time.Time
+
+	// Discussing is set when the revealed round is being discussed, and
+	// Closed once the session has ended; see phase
+	Discussing bool
+	Closed     bool
+
+	// Coalescing of room-state broadcasts, guarded by StateMu rather than Mu
+	// so building the state can take Mu
+	StateMu      sync.Mutex
+	StateSentAt  time.Time
+	StatePending Timer
+
+	// BroadcastSeq numbers the room's broadcasts. SendMu keeps them queued
+	// for each client in that order
+	SendMu       sync.Mutex
+	BroadcastSeq atomic.Int64
+	// Replay keeps recent broadcasts for clients joining later, under SendMu
+	Replay replayBuffer
+
+	// Clock is the server's, for timing rounds and votes
+	Clock Clock
+	// Firehose streams the room's audit entries to operators, and
+	// Spectators its broadcasts to those watching with a spectator link
+	Firehose   *Firehose
+	Spectators *Firehose
+	// MessageRate counts the messages of the room's clients, for metrics
+	MessageRate messageRate
+}
+
+// Now is the room's current time. The caller must hold room.Mu.
+func (room *State) Now() time.Time {
+	if room.Clock == nil {
+		return time.Now()
+	}
+	return room.Clock.Now()
+}
+
+// RecentChat returns the tail of the room's chat history for room-state.
+// The caller must hold room.Mu.
+func RecentChat(room *State) []ChatMessage {
+	start := len(room.Chat) - chatHistoryInState
+	if start < 0 {
+		start = 0
+	}
+	chat := make([]ChatMessage, len(room.Chat)-start)
+	copy(chat, room.Chat[start:])
+	return chat
+}
+
+// resetVotes hides the cards and clears every vote, to start the round over.
+// The caller must hold room.Mu.
+func resetVotes(room *State) {
+	room.Revealed = false
+	room.SecondRevealed = false
+	room.ReopenedRoundID = ""
+	room.Discussing = false
+	room.RoundStartedAt = room.Now()
+	room.RevealRequests = nil
+	for i := range room.Batch {
+		room.Batch[i].Revealed = false
+	}
+	for _, p := range room.Participants {
+		p.Vote = nil
+		p.SecondVote = nil
+		p.Dots = nil
+		p.ThreePoint = nil
+		p.VotedAt = time.Time{}
+		p.BatchVotes = nil
+		p.Confidence = ""
+		p.OriginalVote = ""
+	}
+}
+
+// nextFacilitator picks the longest-connected participant, as client IDs
+// start with their connection time. The caller must hold room.Mu.
+func nextFacilitator(room *State) string {
+	next := ""
+	for id := range room.Participants {
+		if next == "" || id < next {
+			next = id
+		}
+	}
+	return next
+}
+
+// LinkPreview is what a story's page says about itself, from its OpenGraph
+// tags or title, for clients to show as a card.
+type LinkPreview struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+	SiteName    string `json:"siteName,omitempty"`
+}
+
+// PasscodeHash returns the room's passcode hash, nil for public rooms.
+func (room *State) PasscodeHash() []byte {
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	return room.Passcode
+}
+
+// Admits reports whether a client of team may join the room. Rooms a team
+// created are closed to everyone else. The caller must hold room.Mu.
+func (room *State) Admits(team string) bool {
+	return !room.TeamScoped || room.TeamID == team
+}
+
+// VisibleTo reports whether an admin request scoped to team reaches the room.
+// The admin token, scoped to no team, reaches every room. The caller must hold
+// room.Mu.
+func (room *State) VisibleTo(team string) bool {
+	return team == "" || room.TeamScoped && room.TeamID == team
+}
@@ -0,0 +1,37 @@
+package room
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONMarshaling(t *testing.T) {
+	// Test Participant marshaling
+	vote := "5"
+	participant := Participant{
+		ID:     "123",
+		Name:   "Alice",
+		Vote:   &vote,
+		Paused: false,
+	}
+
+	data, err := json.Marshal(participant)
+	if err != nil {
+		t.Fatalf("Failed to marshal participant: %v", err)
+	}
+
+	var unmarshaled Participant
+	if err := json.Unmarshal(data, &unmarshaled); err != nil {
+		t.Fatalf("Failed to unmarshal participant: %v", err)
+	}
+
+	if unmarshaled.ID != participant.ID {
+		t.Errorf("Expected ID %s, got %s", participant.ID, unmarshaled.ID)
+	}
+	if unmarshaled.Name != participant.Name {
+		t.Errorf("Expected Name %s, got %s", participant.Name, unmarshaled.Name)
+	}
+	if *unmarshaled.Vote != *participant.Vote {
+		t.Errorf("Expected Vote %s, got %s", *participant.Vote, *unmarshaled.Vote)
+	}
+}
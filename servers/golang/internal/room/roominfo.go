@@ -0,0 +1,56 @@
+package room
+
+import "time"
+
+// Longer names and descriptions are cut off
+const (
+	maxRoomNameLength        = 100
+	maxRoomDescriptionLength = 2000
+)
+
+// Info describes a room for lobbies and calendar links. ScheduledAt is
+// when the session is planned to start, in Unix milliseconds, or 0.
+type Info struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	ScheduledAt int64  `json:"scheduledAt,omitempty"`
+}
+
+// WithFields returns info with the fields present in data changed, or false
+// if one of them is invalid. A scheduledAt of 0 unschedules the room.
+func (info Info) WithFields(data map[string]interface{}) (Info, bool) {
+	if name, ok := data["name"].(string); ok {
+		info.Name = TruncateRunes(CleanText(name, false), maxRoomNameLength)
+	}
+	if description, ok := data["description"].(string); ok {
+		info.Description = TruncateRunes(CleanText(description, true), maxRoomDescriptionLength)
+	}
+	if scheduledAt, ok := data["scheduledAt"].(float64); ok {
+		if scheduledAt < 0 {
+			return info, false
+		}
+		info.ScheduledAt = int64(scheduledAt)
+	}
+	return info, true
+}
+
+// ScheduledAtOrNil is ScheduledAt for room-state, null when unscheduled.
+func (info Info) ScheduledAtOrNil() interface{} {
+	if info.ScheduledAt == 0 {
+		return nil
+	}
+	return info.ScheduledAt
+}
+
+func TruncateRunes(s string, limit int) string {
+	if runes := []rune(s); len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return s
+}
+
+// WaitingToStart reports whether the room is scheduled to start later. The
+// caller must hold room.Mu.
+func (room *State) WaitingToStart() bool {
+	return room.Info.ScheduledAt != 0 && room.Now().Before(time.UnixMilli(room.Info.ScheduledAt))
+}
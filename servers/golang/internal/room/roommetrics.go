@@ -0,0 +1,33 @@
+package room
+
+import "time"
+
+// messageRate counts a room's messages by the minute. The caller must hold
+// room.Mu.
+type messageRate struct {
+	minute        int64
+	current, last int64
+}
+
+func (r *messageRate) Add(now time.Time) {
+	minute := now.Unix() / 60
+	if minute != r.minute {
+		r.last = 0
+		if minute == r.minute+1 {
+			r.last = r.current
+		}
+		r.minute, r.current = minute, 0
+	}
+	r.current++
+}
+
+// PerMinute returns the count of the last full minute.
+func (r *messageRate) PerMinute(now time.Time) int64 {
+	switch now.Unix() / 60 {
+	case r.minute:
+		return r.last
+	case r.minute + 1:
+		return r.current
+	}
+	return 0
+}
@@ -0,0 +1,27 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMessageRate(t *testing.T) {
+	var rate messageRate
+	start := time.Unix(6000, 0)
+	for i := 0; i < 5; i++ {
+		rate.Add(start.Add(time.Duration(i) * time.Second))
+	}
+	if perMinute := rate.PerMinute(start); perMinute != 0 {
+		t.Errorf("Expected no full minute yet, got %d", perMinute)
+	}
+	if perMinute := rate.PerMinute(start.Add(time.Minute)); perMinute != 5 {
+		t.Errorf("Expected 5 messages in the last minute, got %d", perMinute)
+	}
+	rate.Add(start.Add(time.Minute))
+	if perMinute := rate.PerMinute(start.Add(time.Minute)); perMinute != 5 {
+		t.Errorf("Expected the full minute to be reported, got %d", perMinute)
+	}
+	if perMinute := rate.PerMinute(start.Add(3 * time.Minute)); perMinute != 0 {
+		t.Errorf("Expected a quiet room to report none, got %d", perMinute)
+	}
+}
@@ -0,0 +1,32 @@
+package room
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// isBidiControl reports whether r is one of the invisible characters that
+// change the direction of the text around it.
+func isBidiControl(r rune) bool {
+	return r == '\u061c' || r == '\u200e' || r == '\u200f' ||
+		r >= '\u202a' && r <= '\u202e' || r >= '\u2066' && r <= '\u2069'
+}
+
+// CleanText normalizes text and drops control characters, keeping line
+// breaks when multiline and turning them into spaces otherwise.
+func CleanText(text string, multiline bool) string {
+	text = strings.ReplaceAll(norm.NFC.String(text), "\r\n", "\n")
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' && multiline:
+			return r
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case unicode.IsControl(r) || isBidiControl(r) || r == '\ufeff':
+			return -1
+		}
+		return r
+	}, text))
+}
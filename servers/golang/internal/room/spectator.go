@@ -0,0 +1,42 @@
+package room
+
+import "github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+
+// The broadcasts spectators see: enough to follow the rounds, but not the
+// room's chat, reactions or who is picking a card
+var spectatorMessages = map[string]bool{
+	"room-state":        true,
+	"participant-voted": true,
+	"revealed":          true,
+	"batch-revealed":    true,
+	"room-reset":        true,
+	"story-updated":     true,
+	"session-summary":   true,
+	"announcement":      true,
+	"countdown":         true,
+	"session-started":   true,
+	"room-closed":       true,
+}
+
+// PublishToSpectators streams a broadcast to the room's spectators when it is
+// one they see.
+func (room *State) PublishToSpectators(message transport.Message) {
+	if !spectatorMessages[message.Type] {
+		return
+	}
+	if state, ok := message.Data.(map[string]interface{}); ok && message.Type == "room-state" {
+		message.Data = SpectatorState(state)
+	}
+	room.Spectators.Publish("", message)
+}
+
+// SpectatorState is a room-state message without the room's chat.
+func SpectatorState(state map[string]interface{}) map[string]interface{} {
+	view := make(map[string]interface{}, len(state))
+	for key, value := range state {
+		if key != "chat" {
+			view[key] = value
+		}
+	}
+	return view
+}
@@ -0,0 +1,89 @@
+package room
+
+import "sort"
+
+// VoteStats summarizes the votes of a revealed round. Abstentions are only
+// counted, in Abstentions, and left out of everything else. Numeric
+// aggregates are only present when at least one vote parses as a number. Confidence counts
+// the votes per confidence level, for the votes that carried one. Agreement
+// is the percentage of agreeing votes in the fist-of-five and thumbs modes.
+type VoteStats struct {
+	VoteCount    int            `json:"voteCount"`
+	Abstentions  int            `json:"abstentions,omitempty"`
+	Average      *float64       `json:"average,omitempty"`
+	Median       *float64       `json:"median,omitempty"`
+	Min          *float64       `json:"min,omitempty"`
+	Max          *float64       `json:"max,omitempty"`
+	Distribution map[string]int `json:"distribution"`
+	Confidence   map[string]int `json:"confidence,omitempty"`
+	Agreement    *float64       `json:"agreement,omitempty"`
+}
+
+// abstainVotes are the cards for sitting a round out: a break, or not being
+// able to estimate the story.
+var abstainVotes = map[string]bool{"☕": true, "?": true}
+
+func IsAbstention(vote string) bool {
+	return abstainVotes[vote]
+}
+
+// Abstainers lists the participants who played an abstain card.
+func Abstainers(participants []Participant) []CastVote {
+	abstained := []CastVote{}
+	for _, p := range participants {
+		if p.Vote != nil && IsAbstention(*p.Vote) {
+			abstained = append(abstained, CastVote{ID: p.ID, Name: p.Name, Vote: *p.Vote})
+		}
+	}
+	return abstained
+}
+
+// ComputeVoteStats summarizes the votes, counting cards by their points where
+// the room maps them.
+func ComputeVoteStats(participants []Participant, points map[string]float64) VoteStats {
+	stats := VoteStats{Distribution: make(map[string]int)}
+	numeric := make([]float64, 0, len(participants))
+
+	for _, p := range participants {
+		if p.Vote == nil || *p.Vote == "" {
+			continue
+		}
+		if IsAbstention(*p.Vote) {
+			stats.Abstentions++
+			continue
+		}
+		stats.VoteCount++
+		stats.Distribution[*p.Vote]++
+		if p.Confidence != "" {
+			if stats.Confidence == nil {
+				stats.Confidence = make(map[string]int)
+			}
+			stats.Confidence[p.Confidence]++
+		}
+		if value, ok := PointsOf(*p.Vote, points); ok {
+			numeric = append(numeric, value)
+		}
+	}
+
+	if len(numeric) == 0 {
+		return stats
+	}
+
+	sort.Float64s(numeric)
+	sum := 0.0
+	for _, v := range numeric {
+		sum += v
+	}
+	average := sum / float64(len(numeric))
+	median := numeric[len(numeric)/2]
+	if len(numeric)%2 == 0 {
+		median = (numeric[len(numeric)/2-1] + numeric[len(numeric)/2]) / 2
+	}
+	minValue, maxValue := numeric[0], numeric[len(numeric)-1]
+
+	stats.Average = &average
+	stats.Median = &median
+	stats.Min = &minValue
+	stats.Max = &maxValue
+	return stats
+}
@@ -0,0 +1,82 @@
+package room
+
+import "testing"
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestComputeVoteStats(t *testing.T) {
+	participants := []Participant{
+		{ID: "1", Name: "Alice", Vote: strPtr("3")},
+		{ID: "2", Name: "Bob", Vote: strPtr("8")},
+		{ID: "3", Name: "Carol", Vote: strPtr("5")},
+		{ID: "4", Name: "Dave", Vote: strPtr("?")},
+		{ID: "5", Name: "Eve", Vote: nil},
+		{ID: "6", Name: "Frank", Vote: strPtr("5")},
+	}
+
+	stats := ComputeVoteStats(participants, nil)
+
+	// Dave abstained, which doesn't count as a vote
+	if stats.VoteCount != 4 || stats.Abstentions != 1 {
+		t.Errorf("Expected 4 votes and 1 abstention, got %d and %d", stats.VoteCount, stats.Abstentions)
+	}
+	if stats.Distribution["5"] != 2 || stats.Distribution["?"] != 0 {
+		t.Errorf("Unexpected distribution: %v", stats.Distribution)
+	}
+	if stats.Average == nil || *stats.Average != 5.25 {
+		t.Errorf("Expected average 5.25, got %v", stats.Average)
+	}
+	if stats.Median == nil || *stats.Median != 5 {
+		t.Errorf("Expected median 5, got %v", stats.Median)
+	}
+	if *stats.Min != 3 || *stats.Max != 8 {
+		t.Errorf("Expected min 3 and max 8, got %v and %v", *stats.Min, *stats.Max)
+	}
+}
+
+func TestComputeVoteStatsWithoutNumericVotes(t *testing.T) {
+	stats := ComputeVoteStats([]Participant{
+		{ID: "1", Name: "Alice", Vote: strPtr("XL")},
+		{ID: "2", Name: "Bob", Vote: strPtr("")},
+	}, nil)
+
+	if stats.VoteCount != 1 {
+		t.Errorf("Expected 1 vote, got %d", stats.VoteCount)
+	}
+	if stats.Average != nil || stats.Median != nil {
+		t.Error("Expected no numeric aggregates without numeric votes")
+	}
+}
+
+func TestComputeVoteStatsWithPoints(t *testing.T) {
+	stats := ComputeVoteStats([]Participant{
+		{ID: "1", Name: "Alice", Vote: strPtr("S")},
+		{ID: "2", Name: "Bob", Vote: strPtr("L")},
+		{ID: "3", Name: "Carol", Vote: strPtr("?")},
+	}, map[string]float64{"S": 2, "M": 3, "L": 5})
+
+	if stats.Average == nil || *stats.Average != 3.5 || *stats.Max != 5 {
+		t.Errorf("Expected sizes to count as their points, got %+v", stats)
+	}
+	if stats.Distribution["S"] != 1 || stats.Abstentions != 1 {
+		t.Errorf("Expected votes counted by card, got %+v", stats)
+	}
+}
+
+func TestComputeVoteStatsCountsConfidence(t *testing.T) {
+	stats := ComputeVoteStats([]Participant{
+		{ID: "1", Name: "Alice", Vote: strPtr("5"), Confidence: "high"},
+		{ID: "2", Name: "Bob", Vote: strPtr("5"), Confidence: "low"},
+		{ID: "3", Name: "Carol", Vote: strPtr("5"), Confidence: "low"},
+		{ID: "4", Name: "Dave", Vote: strPtr("5")},
+	}, nil)
+
+	if stats.Confidence["low"] != 2 || stats.Confidence["high"] != 1 || len(stats.Confidence) != 2 {
+		t.Errorf("Unexpected confidence counts: %v", stats.Confidence)
+	}
+	if ComputeVoteStats([]Participant{{ID: "1", Vote: strPtr("3")}}, nil).Confidence != nil {
+		t.Error("Expected no confidence counts without confidence")
+	}
+}
@@ -0,0 +1,126 @@
+package room
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// SessionSummary sums up a session when the facilitator ends it.
+// TotalPoints adds up the final estimates, numeric or mapped to points such
+// as those of T-shirt sizes, AverageRoundMs is the mean
+// time from opening a round to its reveal, and ParticipationRate is the
+// percentage of seats that cast a vote across all rounds.
+type SessionSummary struct {
+	RoomID            string   `json:"roomId"`
+	TeamID            string   `json:"teamId,omitempty"`
+	StartedAt         int64    `json:"startedAt"`
+	EndedAt           int64    `json:"endedAt"`
+	Rounds            int      `json:"rounds"`
+	StoriesEstimated  int      `json:"storiesEstimated"`
+	TotalPoints       float64  `json:"totalPoints"`
+	AverageRoundMs    int64    `json:"averageRoundMs,omitempty"`
+	Participants      int      `json:"participants"`
+	ParticipationRate *float64 `json:"participationRate,omitempty"`
+}
+
+// SummarizeSession computes the summary of a session's rounds. A story voted
+// on several times counts once, with its last final estimate.
+func SummarizeSession(session Session, history []RoundRecord, endedAt time.Time) SessionSummary {
+	summary := SessionSummary{
+		RoomID:    session.RoomID,
+		TeamID:    session.TeamID,
+		StartedAt: session.StartedAt.UnixMilli(),
+		EndedAt:   endedAt.UnixMilli(),
+		Rounds:    len(history),
+	}
+
+	estimates := make(map[string]string)
+	// The points the estimates count as, for rooms that mapped their cards
+	points := make(map[string]map[string]float64)
+	var stories []string
+	var timed, totalMs int64
+	var seats, votes int
+	names := make(map[string]bool)
+	for i, round := range history {
+		key := StoryKey(round.Story)
+		if key == "" {
+			key = "round " + strconv.Itoa(i)
+		}
+		if _, seen := estimates[key]; !seen {
+			stories = append(stories, key)
+			estimates[key] = ""
+		}
+		if round.FinalEstimate != "" {
+			estimates[key] = round.FinalEstimate
+			points[key] = round.Points
+		}
+
+		if round.DurationMs > 0 {
+			timed++
+			totalMs += round.DurationMs
+		}
+		for _, vote := range round.Votes {
+			names[vote.Name] = true
+			seats++
+			if vote.Vote != "" || vote.SecondVote != "" || len(vote.Dots) > 0 || vote.ThreePoint != nil {
+				votes++
+			}
+		}
+	}
+
+	summary.StoriesEstimated = len(stories)
+	for _, key := range stories {
+		if estimate, ok := PointsOf(estimates[key], points[key]); ok {
+			summary.TotalPoints += estimate
+		}
+	}
+	if timed > 0 {
+		summary.AverageRoundMs = totalMs / timed
+	}
+	summary.Participants = len(names)
+	if seats > 0 {
+		rate := math.Round(float64(votes)*1000/float64(seats)) / 10
+		summary.ParticipationRate = &rate
+	}
+	return summary
+}
+
+// StoryKey identifies a story across rounds by its link, or its title
+// without one. Empty for rounds without a story.
+func StoryKey(story *Story) string {
+	if story == nil {
+		return ""
+	}
+	if story.Link != "" {
+		return story.Link
+	}
+	return story.Title
+}
+
+// CloseEvent ends the session, cancelling any async round.
+type CloseEvent struct{}
+
+func (CloseEvent) EventType() string { return "close" }
+
+func (e *CloseEvent) apply(room *State) {
+	clearDeadline(room)
+	room.Closed = true
+}
+
+// Session is one lifetime of a room, from its creation until everyone left.
+type Session struct {
+	RoomID    string
+	StartedAt time.Time
+	// PasscodeHash guards a private room's history; nil for public rooms
+	PasscodeHash []byte
+	// TeamID groups the sessions of one team across rooms; empty without one
+	TeamID string
+	// Summary is set once the session was ended, when loaded from storage
+	Summary *SessionSummary
+}
+
+// Session describes the room's current session. The caller must hold room.Mu.
+func (room *State) Session() Session {
+	return Session{RoomID: room.ID, StartedAt: room.CreatedAt, PasscodeHash: room.Passcode, TeamID: room.TeamID}
+}
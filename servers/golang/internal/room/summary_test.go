@@ -0,0 +1,69 @@
+package room
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeSession(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	history := []RoundRecord{
+		{
+			ID:         "1",
+			Story:      &Story{Title: "Checkout", Link: "https://example.com/1"},
+			Votes:      []RoundVote{{Name: "Alice", Vote: "5"}, {Name: "Bob", Vote: "13"}},
+			DurationMs: 60000,
+		},
+		// Reestimated: the story counts once, with this estimate
+		{
+			ID:            "2",
+			Story:         &Story{Title: "Checkout", Link: "https://example.com/1"},
+			Votes:         []RoundVote{{Name: "Alice", Vote: "8"}, {Name: "Bob", Vote: "8"}},
+			FinalEstimate: "8",
+			DurationMs:    30000,
+		},
+		{
+			ID:            "3",
+			Story:         &Story{Title: "Search"},
+			Votes:         []RoundVote{{Name: "Alice", Vote: "3"}, {Name: "Bob"}, {Name: "Carol", Vote: "?"}},
+			FinalEstimate: "3",
+		},
+	}
+
+	summary := SummarizeSession(Session{RoomID: "room", StartedAt: start}, history, start.Add(time.Hour))
+
+	if summary.Rounds != 3 || summary.StoriesEstimated != 2 {
+		t.Errorf("Expected 3 rounds over 2 stories, got %d over %d", summary.Rounds, summary.StoriesEstimated)
+	}
+	if summary.TotalPoints != 11 {
+		t.Errorf("Expected 11 points, got %v", summary.TotalPoints)
+	}
+	if summary.AverageRoundMs != 45000 {
+		t.Errorf("Expected the untimed round to be left out of 45000ms, got %d", summary.AverageRoundMs)
+	}
+	if summary.Participants != 3 {
+		t.Errorf("Expected 3 participants, got %d", summary.Participants)
+	}
+	if summary.ParticipationRate == nil || *summary.ParticipationRate != 85.7 {
+		t.Errorf("Expected 6 of 7 seats voting, got %v", summary.ParticipationRate)
+	}
+	if summary.EndedAt-summary.StartedAt != time.Hour.Milliseconds() {
+		t.Errorf("Expected an hour long session, got %dms", summary.EndedAt-summary.StartedAt)
+	}
+}
+
+func TestSummarizeSessionWithPoints(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	sizes := map[string]float64{"S": 2, "M": 3, "L": 5}
+	history := []RoundRecord{
+		{ID: "1", Story: &Story{Title: "Checkout"}, FinalEstimate: "M", Points: sizes},
+		{ID: "2", Story: &Story{Title: "Search"}, FinalEstimate: "L", Points: sizes},
+		// Rounds before the sizes were mapped count for nothing
+		{ID: "3", Story: &Story{Title: "Filters"}, FinalEstimate: "S"},
+	}
+
+	summary := SummarizeSession(Session{RoomID: "room", StartedAt: start}, history, start.Add(time.Hour))
+	if summary.TotalPoints != 8 {
+		t.Errorf("Expected the sizes to count as 8 points, got %v", summary.TotalPoints)
+	}
+}
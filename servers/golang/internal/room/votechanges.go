@@ -0,0 +1,80 @@
+package room
+
+import "slices"
+
+// TakesVoteChanges reports whether a vote changes the revealed round rather
+// than being refused. Only rounds of a single value can be adjusted, not two
+// dimensions, dots, three-point estimates or a batch. The caller must hold room.Mu.
+func (room *State) TakesVoteChanges() bool {
+	return room.AllowVoteChanges && room.Revealed && room.LastRound != nil && len(room.Batch) == 0 &&
+		room.Settings.Mode != ModeTwoDimensional && room.Settings.Mode != ModeDotVoting && room.Settings.Mode != ModePERT
+}
+
+// VoteChangeEvent changes a vote of the revealed round, recording the vote
+// first revealed and the round as adjusted. Going back to that vote clears
+// it. Revealed rounds are shared with broadcasts, so they are replaced rather
+// than changed.
+type VoteChangeEvent struct {
+	ClientID string `json:"clientId"`
+	Vote     string `json:"vote"`
+}
+
+func (VoteChangeEvent) EventType() string { return "vote-change" }
+
+func (e *VoteChangeEvent) apply(room *State) {
+	if room.LastRound == nil {
+		return
+	}
+	i := slices.IndexFunc(room.LastRound.Participants, func(p Participant) bool { return p.ID == e.ClientID })
+	if i < 0 || room.LastRound.Participants[i].Vote == nil {
+		return
+	}
+	participants := slices.Clone(room.LastRound.Participants)
+	changed := &participants[i]
+	if changed.OriginalVote == "" {
+		changed.OriginalVote = *changed.Vote
+	}
+	if changed.OriginalVote == e.Vote {
+		changed.OriginalVote = ""
+	}
+	vote := e.Vote
+	changed.Vote = &vote
+	room.LastRound = &LastRound{ID: room.LastRound.ID, Participants: participants}
+	if p, ok := room.Participants[e.ClientID]; ok {
+		p.Vote = &vote
+		p.OriginalVote = changed.OriginalVote
+	}
+
+	// The round's votes are in the order of its participants
+	round, ok := room.LastRoundRecord()
+	if !ok || len(round.Votes) != len(participants) {
+		return
+	}
+	round.Votes = slices.Clone(round.Votes)
+	round.Votes[i].Vote = vote
+	round.Votes[i].OriginalVote = changed.OriginalVote
+	round.Stats = ComputeVoteStats(participants, room.Settings.Points)
+	round.Stats.Agreement = agreementPercent(room.Settings.Mode, round.Stats)
+	round.Adjusted = slices.ContainsFunc(round.Votes, func(v RoundVote) bool { return v.OriginalVote != "" })
+	room.History[len(room.History)-1] = round
+}
+
+// LastRoundRecord returns the history's record of the last revealed round,
+// the latest one. The caller must hold room.Mu.
+func (room *State) LastRoundRecord() (RoundRecord, bool) {
+	if room.LastRound == nil || len(room.History) == 0 || room.History[len(room.History)-1].ID != room.LastRound.ID {
+		return RoundRecord{}, false
+	}
+	return room.History[len(room.History)-1], true
+}
+
+// VoteChangesEvent lets votes change after the reveal, or stops them.
+type VoteChangesEvent struct {
+	Allowed bool `json:"allowed"`
+}
+
+func (VoteChangesEvent) EventType() string { return "vote-changes" }
+
+func (e *VoteChangesEvent) apply(room *State) {
+	room.AllowVoteChanges = e.Allowed
+}
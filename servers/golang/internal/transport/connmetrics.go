@@ -0,0 +1,41 @@
+package transport
+
+import (
+	"errors"
+
+	"github.com/gorilla/websocket"
+)
+
+// Reasons a connection ended, besides the close codes the server sends
+const (
+	DisconnectClientClose = "client-close"
+	disconnectError       = "error"
+	disconnectServerClose = "server-close"
+)
+
+// closeCodeReasons name the close codes the server disconnects clients with.
+var closeCodeReasons = map[int]string{
+	CloseShutdown:   "shutdown",
+	CloseHeartbeat:  "heartbeat",
+	CloseSlowClient: "slow-client",
+	CloseKicked:     "kicked",
+	CloseBanned:     "banned",
+	CloseRoomClosed: "room-closed",
+	CloseResumed:    "resumed",
+}
+
+// DisconnectReason tells why a client's connection ended: the close code the
+// server sent, the client closing it, or the connection failing.
+func (ws *Conn) DisconnectReason() string {
+	if code := int(ws.closedWith.Load()); code != 0 {
+		if reason, ok := closeCodeReasons[code]; ok {
+			return reason
+		}
+		return disconnectServerClose
+	}
+	var closeErr *websocket.CloseError
+	if ws.ReadErr == nil || errors.As(ws.ReadErr, &closeErr) {
+		return DisconnectClientClose
+	}
+	return disconnectError
+}
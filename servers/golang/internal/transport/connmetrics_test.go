@@ -0,0 +1,29 @@
+package transport
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestDisconnectReason(t *testing.T) {
+	for _, tt := range []struct {
+		closedWith int
+		readErr    error
+		expected   string
+	}{
+		{0, &websocket.CloseError{Code: websocket.CloseGoingAway}, DisconnectClientClose},
+		{0, io.ErrUnexpectedEOF, disconnectError},
+		{CloseHeartbeat, io.ErrUnexpectedEOF, "heartbeat"},
+		{CloseKicked, errors.New("use of closed network connection"), "kicked"},
+		{4999, nil, disconnectServerClose},
+	} {
+		ws := &Conn{ReadErr: tt.readErr}
+		ws.closedWith.Store(int32(tt.closedWith))
+		if reason := ws.DisconnectReason(); reason != tt.expected {
+			t.Errorf("Expected %s for code %d and %v, got %s", tt.expected, tt.closedWith, tt.readErr, reason)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Limits on client messages, well above anything the app sends
+const (
+	maxMessageDepth = 8
+	maxMessageType  = 64
+)
+
+// ErrMalformedMessage marks a message that was read but couldn't be decoded;
+// it is dropped and the connection kept.
+var ErrMalformedMessage = errors.New("malformed message")
+
+// DecodeMessage parses a JSON protocol message strictly: an object with type
+// and data fields only, and nothing after it.
+func DecodeMessage(payload []byte) (Message, error) {
+	var message Message
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&message); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return Message{}, fmt.Errorf("%w: data after the message", ErrMalformedMessage)
+	}
+	if err := CheckMessage(message); err != nil {
+		return Message{}, err
+	}
+	return message, nil
+}
+
+// CheckMessage enforces the limits on a decoded message, whichever wire
+// format it came in.
+func CheckMessage(message Message) error {
+	if message.Type == "" || len(message.Type) > maxMessageType {
+		return fmt.Errorf("%w: type must be 1 to %d characters", ErrMalformedMessage, maxMessageType)
+	}
+	if _, ok := message.Data.(map[string]interface{}); !ok {
+		return fmt.Errorf("%w: data must be an object", ErrMalformedMessage)
+	}
+	if depth(message.Data) > maxMessageDepth {
+		return fmt.Errorf("%w: data nested deeper than %d levels", ErrMalformedMessage, maxMessageDepth)
+	}
+	return nil
+}
+
+// depth is how deeply objects and arrays nest in a decoded JSON value.
+func depth(value interface{}) int {
+	deepest := 0
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			deepest = max(deepest, depth(item))
+		}
+	case []interface{}:
+		for _, item := range v {
+			deepest = max(deepest, depth(item))
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindNumber
+	kindObject
+	kindArray
+	kindBool
+	// kindObjectOrNull is an object that null clears
+	kindObjectOrNull
+)
+
+// MessageFields are the types of the optional fields of the built-in
+// messages. A field of the wrong type drops the message, where handlers would
+// otherwise read it as empty and clear whatever it sets.
+var MessageFields = map[string]map[string]fieldKind{
+	"hello": {"version": kindNumber, "features": kindArray},
+	"join-room": {
+		"name": kindString, "participantId": kindString, "sessionToken": kindString,
+		"passcode": kindString, "inviteToken": kindString, "teamId": kindString,
+		"resumable": kindBool,
+	},
+	"resume": {"lastSeq": kindNumber},
+	"vote": {
+		"vote": kindString, "secondVote": kindString, "confidence": kindString,
+		"storyId": kindString, "dots": kindObject,
+		"optimistic": kindNumber, "mostLikely": kindNumber, "pessimistic": kindNumber,
+	},
+	"reveal":             {"dimension": kindString, "storyId": kindString},
+	"update-story":       {"story": kindObjectOrNull},
+	"set-final-estimate": {"estimate": kindString},
+	"set-mode":           {"deck": kindArray, "points": kindObject, "dimensions": kindArray, "options": kindArray, "dots": kindNumber},
+	"set-team":           {"teamId": kindString},
+	"set-reveal-policy":  {"threshold": kindNumber},
+	"set-vote-changes":   {"allowed": kindBool},
+	"set-delphi":         {"rounds": kindNumber},
+	"update-room":        {"name": kindString, "description": kindString, "scheduledAt": kindNumber},
+	"import-stories":     {"stories": kindArray, "csv": kindString},
+	"open-async-round":   {"deadline": kindNumber, "story": kindObject},
+	"open-batch":         {"stories": kindArray},
+	"move-participant":   {"passcode": kindString},
+}
+
+// CheckFields reports the first field of data that isn't of its kind.
+func CheckFields(data map[string]interface{}, kinds map[string]fieldKind) (string, bool) {
+	for field, kind := range kinds {
+		value, present := data[field]
+		if !present {
+			continue
+		}
+		var ok bool
+		switch kind {
+		case kindString:
+			_, ok = value.(string)
+		case kindNumber:
+			_, ok = value.(float64)
+		case kindObject:
+			_, ok = value.(map[string]interface{})
+		case kindArray:
+			_, ok = value.([]interface{})
+		case kindBool:
+			_, ok = value.(bool)
+		case kindObjectOrNull:
+			_, ok = value.(map[string]interface{})
+			ok = ok || value == nil
+		}
+		if !ok {
+			return field, false
+		}
+	}
+	return "", true
+}
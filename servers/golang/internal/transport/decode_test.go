@@ -0,0 +1,68 @@
+package transport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		ok      bool
+	}{
+		{"valid", `{"type":"vote","data":{"roomId":"r","vote":"5"}}`, true},
+		{"unknown field", `{"type":"vote","data":{},"extra":1}`, false},
+		{"trailing data", `{"type":"vote","data":{}} {}`, false},
+		{"missing type", `{"data":{}}`, false},
+		{"long type", `{"type":"` + strings.Repeat("x", maxMessageType+1) + `","data":{}}`, false},
+		{"data not an object", `{"type":"vote","data":[1]}`, false},
+		{"too deep", `{"type":"vote","data":` + strings.Repeat(`{"a":`, maxMessageDepth+1) + `1` + strings.Repeat(`}`, maxMessageDepth+1) + `}`, false},
+		{"not JSON", `vote`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeMessage([]byte(tt.payload))
+			if tt.ok && err != nil {
+				t.Errorf("Expected the message to decode, got %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrMalformedMessage) {
+				t.Errorf("Expected a malformed message error, got %v", err)
+			}
+		})
+	}
+}
+
+func FuzzDecodeMessage(f *testing.F) {
+	f.Add([]byte(`{"type":"join-room","data":{"roomId":"r","name":"Alice"}}`))
+	f.Add([]byte(`{"type":"vote","data":{"roomId":"r","vote":"5","dots":{"a":1}}}`))
+	f.Add([]byte(`{"type":"import-stories","data":{"roomId":"r","stories":[{"title":"x"}]}}`))
+	f.Add([]byte(`{"type":"vote","data":null}`))
+	f.Add([]byte(`[]`))
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		message, err := DecodeMessage(payload)
+		if err != nil {
+			if !errors.Is(err, ErrMalformedMessage) {
+				t.Fatalf("Expected errors to be marked malformed, got %v", err)
+			}
+			return
+		}
+		if err := CheckMessage(message); err != nil {
+			t.Fatalf("Decoded message fails its own checks: %v", err)
+		}
+	})
+}
+
+func FuzzMsgpackDecode(f *testing.F) {
+	_, payload, _ := MsgpackCodec{}.Encode(Message{Type: "vote", Data: map[string]interface{}{"roomId": "r", "vote": "5"}})
+	f.Add(payload)
+	f.Add([]byte{0x80})
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		if _, err := (MsgpackCodec{}).Decode(payload); err != nil && !errors.Is(err, ErrMalformedMessage) {
+			t.Fatalf("Expected errors to be marked malformed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// Clients opt into MessagePack with this WebSocket subprotocol, or with
+// ?format=msgpack when they can't set subprotocols.
+const MsgpackSubprotocol = "msgpack"
+
+// wireDecoder is implemented by codecs that also change how clients encode
+// their messages.
+type wireDecoder interface {
+	Decode(payload []byte) (Message, error)
+}
+
+// MsgpackCodec carries messages as MessagePack maps in binary frames, using
+// the same field names as the JSON protocol.
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) Encode(message Message) (int, []byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	enc.UseCompactFloats(true)
+	if err := enc.Encode(message); err != nil {
+		return 0, nil, err
+	}
+	return websocket.BinaryMessage, buf.Bytes(), nil
+}
+
+func (MsgpackCodec) Ping(sentAt []byte) (int, []byte) {
+	return websocket.PingMessage, sentAt
+}
+
+// Decode converts the message to the shapes encoding/json produces (float64
+// numbers, map[string]interface{} objects), so handlers see the same data
+// whichever format the client uses.
+func (MsgpackCodec) Decode(payload []byte) (Message, error) {
+	// The library allocates whatever length a header claims, so walk the
+	// payload first to check its lengths are backed by data
+	if err := checkMsgpack(msgpack.NewDecoder(bytes.NewReader(payload)), 0); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	var raw interface{}
+	if err := msgpack.Unmarshal(payload, &raw); err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return Message{}, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	return DecodeMessage(normalized)
+}
+
+// checkMsgpack skips over one value, failing on truncated data or on
+// containers nested deeper than a message may be.
+func checkMsgpack(dec *msgpack.Decoder, level int) error {
+	if level > maxMessageDepth+1 {
+		return fmt.Errorf("nested deeper than %d levels", maxMessageDepth)
+	}
+	code, err := dec.PeekCode()
+	if err != nil {
+		return err
+	}
+	var items int
+	switch {
+	case msgpcode.IsFixedArray(code) || code == msgpcode.Array16 || code == msgpcode.Array32:
+		items, err = dec.DecodeArrayLen()
+	case msgpcode.IsFixedMap(code) || code == msgpcode.Map16 || code == msgpcode.Map32:
+		items, err = dec.DecodeMapLen()
+		items *= 2
+	default:
+		return dec.Skip()
+	}
+	if err != nil {
+		return err
+	}
+	for i := 0; i < items; i++ {
+		if err := checkMsgpack(dec, level+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NegotiateCodec picks the wire format for a newly upgraded connection.
+func NegotiateCodec(conn *websocket.Conn, r *http.Request) WireCodec {
+	if conn.Subprotocol() == MsgpackSubprotocol || r.URL.Query().Get("format") == "msgpack" {
+		return MsgpackCodec{}
+	}
+	return nil
+}
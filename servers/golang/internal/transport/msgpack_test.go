@@ -0,0 +1,32 @@
+package transport
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestMsgpackDecodeMatchesJSON(t *testing.T) {
+	payload, _ := msgpack.Marshal(map[string]interface{}{
+		"type": "vote",
+		"data": map[string]interface{}{"roomId": "r", "count": 3, "tags": []string{"a"}},
+	})
+
+	fromMsgpack, err := MsgpackCodec{}.Decode(payload)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	var fromJSON Message
+	json.Unmarshal([]byte(`{"type":"vote","data":{"roomId":"r","count":3,"tags":["a"]}}`), &fromJSON)
+
+	got, _ := json.Marshal(fromMsgpack)
+	want, _ := json.Marshal(fromJSON)
+	if string(got) != string(want) {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+	if _, ok := fromMsgpack.Data.(map[string]interface{})["count"].(float64); !ok {
+		t.Error("Expected numbers to decode as float64 like JSON")
+	}
+}
@@ -0,0 +1,21 @@
+package transport
+
+import "sync"
+
+// outbox holds a client's messages until a broadcast worker writes them. Only
+// one worker drains an outbox at a time, so messages arrive in order.
+type outbox struct {
+	Mu        sync.Mutex
+	Queue     []Outgoing
+	Scheduled bool
+	Closed    bool
+}
+
+// Outgoing is a protocol message, or a frame already encoded for the
+// connection when frame is set. When close is set the connection is closed
+// instead, once the messages before it are written.
+type Outgoing struct {
+	Message Message
+	Frame   []byte
+	Close   *CloseReason
+}
@@ -0,0 +1,51 @@
+package transport
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter is a sliding-window limiter allowing at most limit events per window.
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	events []time.Time
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:  limit,
+		window: window,
+		events: make([]time.Time, 0, limit),
+	}
+}
+
+func (r *RateLimiter) Allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-r.window)
+	kept := r.events[:0]
+	for _, t := range r.events {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	r.events = kept
+
+	if len(r.events) >= r.limit {
+		return false
+	}
+	r.events = append(r.events, now)
+	return true
+}
+
+// SetLimit changes the limit, keeping the events already recorded.
+func (r *RateLimiter) SetLimit(limit int, window time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limit = limit
+	r.window = window
+}
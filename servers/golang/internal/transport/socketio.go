@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// Engine.IO packet types
+const (
+	EngineOpen    = '0'
+	EngineClose   = '1'
+	EnginePing    = '2'
+	EnginePong    = '3'
+	EngineMessage = '4'
+)
+
+// Socket.IO packet types
+const (
+	SocketConnect      = '0'
+	SocketDisconnect   = '1'
+	SocketEvent        = '2'
+	SocketAck          = '3'
+	SocketConnectError = '4'
+)
+
+const (
+	// Clients drop the connection when no ping arrives within
+	// pingInterval + pingTimeout, so advertise the heartbeat we really run.
+	EngineIOPingTimeout = 20000
+	EngineIOMaxPayload  = 1000000
+)
+
+type EngineIOHandshake struct {
+	SID          string   `json:"sid"`
+	Upgrades     []string `json:"upgrades"`
+	PingInterval int64    `json:"pingInterval"`
+	PingTimeout  int64    `json:"pingTimeout"`
+	MaxPayload   int      `json:"maxPayload"`
+}
+
+// SocketIOCodec frames server messages as Socket.IO events.
+type SocketIOCodec struct{}
+
+func (SocketIOCodec) Encode(message Message) (int, []byte, error) {
+	payload, err := json.Marshal([]interface{}{message.Type, message.Data})
+	if err != nil {
+		return 0, nil, err
+	}
+	return websocket.TextMessage, append([]byte{EngineMessage, SocketEvent}, payload...), nil
+}
+
+// Ping can't carry the time it was sent, as Engine.IO pongs don't echo it.
+func (SocketIOCodec) Ping([]byte) (int, []byte) {
+	return websocket.TextMessage, []byte{EnginePing}
+}
+
+// ParseSocketIOPacket splits a Socket.IO packet into its type, namespace
+// ("/" when omitted), optional ack ID and JSON payload.
+func ParseSocketIOPacket(packet string) (packetType byte, namespace, ackID, payload string) {
+	packetType, rest := packet[0], packet[1:]
+
+	namespace = "/"
+	if strings.HasPrefix(rest, "/") {
+		if i := strings.IndexByte(rest, ','); i >= 0 {
+			namespace, rest = rest[:i], rest[i+1:]
+		} else {
+			namespace, rest = rest, ""
+		}
+	}
+
+	i := 0
+	for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+		i++
+	}
+	return packetType, namespace, rest[:i], rest[i:]
+}
+
+// SendSocketIOPacket writes a control packet. Write errors surface in the
+// read loop, which then disconnects the client.
+func SendSocketIOPacket(ws *Conn, packetType byte, body string) {
+	ws.WriteMessage(websocket.TextMessage, SocketIOPacket(packetType, body))
+}
+
+func SocketIOPacket(packetType byte, body string) []byte {
+	return append([]byte{EngineMessage, packetType}, body...)
+}
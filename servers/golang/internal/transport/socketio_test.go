@@ -0,0 +1,23 @@
+package transport
+
+import "testing"
+
+func TestParseSocketIOPacket(t *testing.T) {
+	tests := []struct {
+		packet                    string
+		packetType                byte
+		namespace, ackID, payload string
+	}{
+		{"0", '0', "/", "", ""},
+		{`2["vote",{}]`, '2', "/", "", `["vote",{}]`},
+		{`212["vote",{}]`, '2', "/", "12", `["vote",{}]`},
+		{`2/admin,5["x"]`, '2', "/admin", "5", `["x"]`},
+		{"1/admin", '1', "/admin", "", ""},
+	}
+	for _, tt := range tests {
+		packetType, namespace, ackID, payload := ParseSocketIOPacket(tt.packet)
+		if packetType != tt.packetType || namespace != tt.namespace || ackID != tt.ackID || payload != tt.payload {
+			t.Errorf("parseSocketIOPacket(%q) = %q, %q, %q, %q", tt.packet, packetType, namespace, ackID, payload)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package transport is the client side of the server: a connection and the
+// outbox it writes through, and the wire formats it speaks, JSON or MessagePack
+// over WebSocket and Socket.IO framing, decoded strictly.
+package transport
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Message is a protocol message. Broadcasts to a room carry its
+// next Seq, so clients can tell when they missed one and drop repeats.
+type Message struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+	Seq  int64       `json:"seq,omitempty"`
+}
+
+type Conn struct {
+	*websocket.Conn
+	ID      string
+	IsAlive atomic.Bool
+	// RemoteIP is the client's address, taken from forwarding headers
+	// when the connection came through a trusted proxy
+	RemoteIP string
+	// TeamID is the team whose token the client connected with, if any
+	TeamID string
+	// UserID and UserName identify a client that signed in with single
+	// sign-on; UserName is then the only name it joins rooms with
+	UserID   string
+	UserName string
+
+	Codec            WireCodec
+	ReactionLimiter  *RateLimiter
+	SelectingLimiter *RateLimiter
+	MessageLimiter   *RateLimiter
+	WriteMu          sync.Mutex
+
+	// roomID is the room the client is in. Its read loop joins and leaves
+	// rooms, but moves and admin requests come from other goroutines
+	roomMu sync.Mutex
+	roomID string
+
+	// ctx belongs to the message being handled; only the read loop sets it
+	ctx context.Context
+	// Refused is set when the message being handled is answered with an error
+	Refused bool
+	// Features are what the client's hello negotiated, unset until it says
+	// hello; only the read loop uses them
+	Features map[string]bool
+
+	// Relay delivers messages to a client connected to another instance,
+	// for which this one owns the room. Such clients have no Conn, and
+	// CloseRelay disconnects them
+	Relay      func(Message) error
+	CloseRelay func(CloseReason)
+
+	// Outbox holds messages waiting for a broadcast worker
+	Outbox outbox
+
+	// rtt is the smoothed round trip of heartbeats in nanoseconds, and
+	// pingSentAt when the last one went out, for pongs that don't echo it
+	rtt        atomic.Int64
+	pingSentAt atomic.Int64
+
+	// ConnectedAt is when the connection was accepted. closedWith is the
+	// close code the server ended it with, and ReadErr the error that ended
+	// the read loop, telling why it closed
+	ConnectedAt time.Time
+	closedWith  atomic.Int32
+	ReadErr     error
+}
+
+// How long a single write may take before the connection is considered dead,
+// so a stalled client can't keep a broadcast worker busy
+const writeWait = 10 * time.Second
+
+// How long the close frame may take, as the client may be gone already
+const closeWait = time.Second
+
+// Close codes the server disconnects clients with. Clients should reconnect
+// after a shutdown, a missed heartbeat or falling behind, and explain the
+// others instead of retrying. CloseResumed closes a connection whose
+// participant resumed on another one.
+const (
+	CloseShutdown   = websocket.CloseGoingAway
+	CloseHeartbeat  = 4000
+	CloseSlowClient = 4001
+	CloseKicked     = 4002
+	CloseBanned     = 4003
+	CloseRoomClosed = 4004
+	CloseResumed    = 4005
+)
+
+// CloseReason is the code and reason of a close frame.
+type CloseReason struct {
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// CloseWith sends the client a close frame saying why it is disconnected and
+// closes the connection. The read loop then fails and cleans up as usual.
+func (ws *Conn) CloseWith(code int, reason string) {
+	ws.closedWith.CompareAndSwap(0, int32(code))
+	if ws.Conn == nil {
+		if ws.CloseRelay != nil {
+			ws.CloseRelay(CloseReason{Code: code, Reason: reason})
+		}
+		return
+	}
+	ws.Conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(closeWait))
+	ws.Conn.Close()
+}
+
+// RoomID returns the room the client is in, or "" when it isn't in one.
+func (ws *Conn) RoomID() string {
+	ws.roomMu.Lock()
+	defer ws.roomMu.Unlock()
+	return ws.roomID
+}
+
+func (ws *Conn) SetRoomID(roomID string) {
+	ws.roomMu.Lock()
+	ws.roomID = roomID
+	ws.roomMu.Unlock()
+}
+
+// SwapRoomID puts the client in room to if it is still in room from, and
+// reports whether it was.
+func (ws *Conn) SwapRoomID(from, to string) bool {
+	ws.roomMu.Lock()
+	defer ws.roomMu.Unlock()
+	if ws.roomID != from {
+		return false
+	}
+	ws.roomID = to
+	return true
+}
+
+// Context returns the context of the message currently being handled,
+// carrying its trace span.
+func (ws *Conn) Context() context.Context {
+	if ws.ctx == nil {
+		return context.Background()
+	}
+	return ws.ctx
+}
+
+// SetContext sets the context of the message being handled, nil once it is.
+// Only the read loop calls it.
+func (ws *Conn) SetContext(ctx context.Context) {
+	ws.ctx = ctx
+}
+
+// WriteJSON serializes writes because broadcasts can come from timers and
+// background integrations, and gorilla/websocket allows only one concurrent writer.
+func (ws *Conn) WriteJSON(v interface{}) error {
+	ws.WriteMu.Lock()
+	defer ws.WriteMu.Unlock()
+	ws.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return ws.Conn.WriteJSON(v)
+}
+
+func (ws *Conn) WriteMessage(messageType int, data []byte) error {
+	ws.WriteMu.Lock()
+	defer ws.WriteMu.Unlock()
+	ws.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return ws.Conn.WriteMessage(messageType, data)
+}
+
+// Send writes a protocol message in the connection's wire format.
+func (ws *Conn) Send(message Message) error {
+	if ws.Relay != nil {
+		return ws.Relay(message)
+	}
+	if ws.Codec == nil {
+		return ws.WriteJSON(message)
+	}
+	messageType, payload, err := ws.Codec.Encode(message)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(messageType, payload)
+}
+
+// Ping sends a heartbeat the client is expected to answer. Its payload is
+// the time it was sent, which the pong echoes to measure the round trip.
+func (ws *Conn) Ping(at time.Time) error {
+	ws.pingSentAt.Store(at.UnixNano())
+	sentAt := binary.BigEndian.AppendUint64(nil, uint64(at.UnixNano()))
+	if ws.Codec == nil {
+		return ws.WriteMessage(websocket.PingMessage, sentAt)
+	}
+	messageType, payload := ws.Codec.Ping(sentAt)
+	return ws.WriteMessage(messageType, payload)
+}
+
+// RecordPong updates the round trip with a pong received at now, taking the
+// time the ping was sent from its payload or else from the last ping.
+func (ws *Conn) RecordPong(payload []byte, now time.Time) {
+	sentAt := ws.pingSentAt.Load()
+	if len(payload) == 8 {
+		sentAt = int64(binary.BigEndian.Uint64(payload))
+	}
+	sample := now.UnixNano() - sentAt
+	if sentAt == 0 || sample < 0 {
+		return
+	}
+	// Smoothed like TCP's, so one slow pong doesn't flag a connection
+	if rtt := ws.rtt.Load(); rtt > 0 {
+		sample = rtt - rtt/8 + sample/8
+	}
+	ws.rtt.Store(sample)
+}
+
+// RTT is the smoothed round trip of the client's heartbeats, zero until it
+// answers one.
+func (ws *Conn) RTT() time.Duration {
+	return time.Duration(ws.rtt.Load())
+}
+
+// ReadProtocolMessage reads the next client message in the connection's wire
+// format. A message that can't be decoded fails with ErrMalformedMessage.
+func (ws *Conn) ReadProtocolMessage() (Message, error) {
+	_, payload, err := ws.Conn.ReadMessage()
+	if err != nil {
+		return Message{}, err
+	}
+	if decoder, ok := ws.Codec.(wireDecoder); ok {
+		return decoder.Decode(payload)
+	}
+	return DecodeMessage(payload)
+}
+
+// WireCodec frames outbound messages for clients that don't speak the plain
+// JSON protocol.
+type WireCodec interface {
+	Encode(message Message) (messageType int, payload []byte, err error)
+	Ping(sentAt []byte) (messageType int, payload []byte)
+}
+
+// Wants reports whether the client gets an optional feature: it asked for
+// it in its hello, or never said hello. Clients relayed to the instance that
+// owns their room said hello to another one, so they get every feature.
+func (ws *Conn) Wants(feature string) bool {
+	return ws.Features == nil || ws.Features[feature]
+}
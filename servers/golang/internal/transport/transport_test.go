@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(2, 50*time.Millisecond)
+
+	if !limiter.Allow() || !limiter.Allow() {
+		t.Fatal("Expected first two events to be allowed")
+	}
+	if limiter.Allow() {
+		t.Error("Expected third event within the window to be rejected")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if !limiter.Allow() {
+		t.Error("Expected event to be allowed after the window passed")
+	}
+}
+
+func TestHeartbeatRoundTrip(t *testing.T) {
+	ws := &Conn{}
+	sentAt := time.Unix(1000, 0)
+	payload := binary.BigEndian.AppendUint64(nil, uint64(sentAt.UnixNano()))
+
+	ws.RecordPong(payload, sentAt.Add(80*time.Millisecond))
+	if rtt := ws.RTT(); rtt != 80*time.Millisecond {
+		t.Errorf("Expected the first pong to set the round trip, got %v", rtt)
+	}
+	ws.RecordPong(payload, sentAt.Add(880*time.Millisecond))
+	if rtt := ws.RTT(); rtt != 180*time.Millisecond {
+		t.Errorf("Expected one slow pong to be smoothed, got %v", rtt)
+	}
+
+	// A pong that doesn't echo the ping is timed from the last one sent
+	ws.pingSentAt.Store(sentAt.UnixNano())
+	ws.RecordPong(nil, sentAt.Add(180*time.Millisecond))
+	if rtt := ws.RTT(); rtt != 180*time.Millisecond {
+		t.Errorf("Expected the round trip to hold at 180ms, got %v", rtt)
+	}
+}
@@ -0,0 +1,157 @@
+package pokerbot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// Story is a story for the Reader to put up.
+type Story struct {
+	Title string `json:"title"`
+	Link  string `json:"link,omitempty"`
+}
+
+// Reader puts up the stories of its backlog one after another: the first when
+// it joins a room without a story, the next whenever the round is reset, and
+// posts each one's details to the chat.
+type Reader struct {
+	stories []Story
+	next    int
+	joined  bool
+}
+
+func NewReader(stories []Story) *Reader {
+	return &Reader{stories: stories}
+}
+
+func (r *Reader) Register(b *Bot) {
+	b.Handle("room-state", r.onRoomState)
+	b.Handle("room-reset", r.onRoomReset)
+}
+
+func (r *Reader) onRoomState(ctx context.Context, b *Bot, msg Message) error {
+	if r.joined {
+		return nil
+	}
+	r.joined = true
+
+	var state struct {
+		Story *Story `json:"story"`
+	}
+	if err := json.Unmarshal(msg.Data, &state); err != nil {
+		return err
+	}
+	if state.Story != nil {
+		return nil
+	}
+	return r.putUpNext(b)
+}
+
+func (r *Reader) onRoomReset(ctx context.Context, b *Bot, msg Message) error {
+	var reset struct {
+		Story *Story `json:"story"`
+	}
+	if err := json.Unmarshal(msg.Data, &reset); err != nil {
+		return err
+	}
+	// Someone else already picked the next story
+	if reset.Story != nil {
+		return nil
+	}
+	return r.putUpNext(b)
+}
+
+func (r *Reader) putUpNext(b *Bot) error {
+	if r.next >= len(r.stories) {
+		if r.next == len(r.stories) && len(r.stories) > 0 {
+			r.next++
+			return b.Say("📖 That was the last story")
+		}
+		return nil
+	}
+	story := r.stories[r.next]
+	r.next++
+
+	if err := b.Send("update-story", map[string]interface{}{"story": story}); err != nil {
+		return err
+	}
+	text := fmt.Sprintf("📖 Up next (%d/%d): %s", r.next, len(r.stories), story.Title)
+	if story.Link != "" {
+		text += " — " + story.Link
+	}
+	return b.Say(text)
+}
+
+// RoundStats are the statistics of a revealed round.
+type RoundStats struct {
+	VoteCount int      `json:"voteCount"`
+	Average   *float64 `json:"average"`
+	Median    *float64 `json:"median"`
+}
+
+// Stats posts each revealed round's statistics to the chat, along with the
+// average across the rounds it has seen.
+type Stats struct {
+	mu     sync.Mutex
+	rounds []RoundStats
+}
+
+func NewStats() *Stats {
+	return &Stats{}
+}
+
+func (s *Stats) Register(b *Bot) {
+	b.Handle("revealed", s.onRevealed)
+}
+
+// Rounds returns the rounds seen so far.
+func (s *Stats) Rounds() []RoundStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RoundStats(nil), s.rounds...)
+}
+
+func (s *Stats) onRevealed(ctx context.Context, b *Bot, msg Message) error {
+	var revealed struct {
+		Stats     *RoundStats `json:"stats"`
+		LastRound *struct{}   `json:"lastRound"`
+	}
+	if err := json.Unmarshal(msg.Data, &revealed); err != nil {
+		return err
+	}
+	// Revealing one dimension of a two-dimensional round doesn't end it
+	if revealed.Stats == nil || revealed.LastRound == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.rounds = append(s.rounds, *revealed.Stats)
+	n := len(s.rounds)
+	var total float64
+	var averaged int
+	for _, round := range s.rounds {
+		if round.Average != nil {
+			total += *round.Average
+			averaged++
+		}
+	}
+	s.mu.Unlock()
+
+	text := fmt.Sprintf("📊 Round %d: %d vote(s)", n, revealed.Stats.VoteCount)
+	if revealed.Stats.Average != nil && revealed.Stats.Median != nil {
+		text += fmt.Sprintf(", average %s, median %s", formatFloat(*revealed.Stats.Average), formatFloat(*revealed.Stats.Median))
+	}
+	if averaged > 1 {
+		text += fmt.Sprintf(" · session average %s", formatFloat(total/float64(averaged)))
+	}
+	return b.Say(text)
+}
+
+// formatFloat writes a statistic with at most two decimals.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
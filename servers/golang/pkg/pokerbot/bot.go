@@ -0,0 +1,163 @@
+// Package pokerbot runs headless participants that join a room over the
+// WebSocket API and react to what happens in it. What a bot does is made of
+// handlers registered per message type, usually bundled as a Behavior such as
+// Reader or Stats.
+//
+//	bot := pokerbot.New("Reader", "team-room")
+//	bot.Use(pokerbot.NewReader(stories), pokerbot.NewStats())
+//	err := bot.Run(ctx, "ws://localhost:3001/api/ws", nil)
+package pokerbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Message is a message from the server.
+type Message struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// HandlerFunc reacts to a message. An error is logged and the bot carries on.
+type HandlerFunc func(ctx context.Context, b *Bot, msg Message) error
+
+// Behavior bundles the handlers for one job, registering them with Use.
+type Behavior interface {
+	Register(b *Bot)
+}
+
+// Bot is a participant driven by its handlers. Handlers run one at a time, in
+// the order messages arrive.
+type Bot struct {
+	Name     string
+	RoomID   string
+	Passcode string
+	Logger   *log.Logger
+
+	handlers map[string][]HandlerFunc
+	// mu guards conn, its writes, and id
+	mu   sync.Mutex
+	conn *websocket.Conn
+	id   string
+}
+
+func New(name, roomID string) *Bot {
+	return &Bot{
+		Name:     name,
+		RoomID:   roomID,
+		Logger:   log.Default(),
+		handlers: make(map[string][]HandlerFunc),
+	}
+}
+
+// Handle adds fn to the handlers of msgType. Call it before Run.
+func (b *Bot) Handle(msgType string, fn HandlerFunc) {
+	b.handlers[msgType] = append(b.handlers[msgType], fn)
+}
+
+// Use registers the behaviors' handlers.
+func (b *Bot) Use(behaviors ...Behavior) {
+	for _, behavior := range behaviors {
+		behavior.Register(b)
+	}
+}
+
+// ID is the bot's participant ID, known once the first room-state arrives.
+func (b *Bot) ID() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.id
+}
+
+// Run connects to url, joins the room and dispatches messages until ctx is
+// done or the server closes the connection.
+func (b *Bot) Run(ctx context.Context, url string, header http.Header) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, header)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	b.mu.Lock()
+	b.conn = conn
+	b.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	join := map[string]interface{}{"name": b.Name}
+	if b.Passcode != "" {
+		join["passcode"] = b.Passcode
+	}
+	if err := b.Send("join-room", join); err != nil {
+		return err
+	}
+
+	for {
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) && closeErr.Code == websocket.CloseNormalClosure {
+				return nil
+			}
+			return err
+		}
+		if msg.Type == "room-state" && b.ID() == "" {
+			id := b.findSelf(msg.Data)
+			b.mu.Lock()
+			b.id = id
+			b.mu.Unlock()
+		}
+		for _, fn := range b.handlers[msg.Type] {
+			if err := fn(ctx, b, msg); err != nil {
+				b.Logger.Printf("🤖 %s failed handling %s: %v", b.Name, msg.Type, err)
+			}
+		}
+	}
+}
+
+// Send sends a message to the bot's room, adding the roomId to data.
+func (b *Bot) Send(msgType string, data map[string]interface{}) error {
+	payload := map[string]interface{}{"roomId": b.RoomID}
+	for key, value := range data {
+		payload[key] = value
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn == nil {
+		return errors.New("bot is not connected")
+	}
+	return b.conn.WriteJSON(map[string]interface{}{"type": msgType, "data": payload})
+}
+
+// Say posts a chat message to the room.
+func (b *Bot) Say(text string) error {
+	return b.Send("chat-message", map[string]interface{}{"text": text})
+}
+
+// findSelf picks the bot out of a room-state by its name.
+func (b *Bot) findSelf(data json.RawMessage) string {
+	var state struct {
+		Participants []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"participants"`
+	}
+	json.Unmarshal(data, &state)
+	for _, p := range state.Participants {
+		if p.Name == b.Name {
+			return p.ID
+		}
+	}
+	return ""
+}
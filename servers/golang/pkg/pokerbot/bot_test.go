@@ -0,0 +1,112 @@
+package pokerbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/pkg/pokerserver"
+)
+
+type message struct {
+	Type string                 `json:"type"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// readUntil skips messages until one of msgType satisfies match.
+func readUntil(t *testing.T, conn *websocket.Conn, msgType string, match func(map[string]interface{}) bool) map[string]interface{} {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg message
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Waiting for %s: %v", msgType, err)
+		}
+		if msg.Type == msgType && (match == nil || match(msg.Data)) {
+			return msg.Data
+		}
+	}
+}
+
+func send(t *testing.T, conn *websocket.Conn, msgType string, data map[string]interface{}) {
+	t.Helper()
+	if err := conn.WriteJSON(map[string]interface{}{"type": msgType, "data": data}); err != nil {
+		t.Fatalf("Sending %s: %v", msgType, err)
+	}
+}
+
+func chatFrom(name string, prefix string) func(map[string]interface{}) bool {
+	return func(data map[string]interface{}) bool {
+		text, _ := data["text"].(string)
+		return data["senderName"] == name && strings.HasPrefix(text, prefix)
+	}
+}
+
+func TestReaderAndStatsBot(t *testing.T) {
+	httpServer := httptest.NewServer(pokerserver.New().Handler())
+	defer httpServer.Close()
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/api/ws"
+	roomID := "bot-room"
+
+	alice, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer alice.Close()
+	send(t, alice, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readUntil(t, alice, "room-state", nil)
+
+	stats := NewStats()
+	bot := New("Bot", roomID)
+	bot.Use(NewReader([]Story{{Title: "Login", Link: "https://example.com/1"}, {Title: "Logout"}}), stats)
+	var handled []string
+	bot.Handle("chat-message", func(ctx context.Context, b *Bot, msg Message) error {
+		var chat struct{ Text string }
+		json.Unmarshal(msg.Data, &chat)
+		handled = append(handled, chat.Text)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() { done <- bot.Run(ctx, url, nil) }()
+
+	// The reader puts up the first story as the room has none
+	story := readUntil(t, alice, "story-updated", nil)["story"].(map[string]interface{})
+	if story["title"] != "Login" {
+		t.Errorf("Expected the first story, got %v", story)
+	}
+	readUntil(t, alice, "chat-message", chatFrom("Bot", "📖 Up next (1/2): Login — https://example.com/1"))
+
+	send(t, alice, "vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	send(t, alice, "reveal", map[string]interface{}{"roomId": roomID})
+	readUntil(t, alice, "chat-message", chatFrom("Bot", "📊 Round 1: 1 vote(s), average 5, median 5"))
+
+	// A reset without a story brings the next one up
+	send(t, alice, "reset", map[string]interface{}{"roomId": roomID})
+	readUntil(t, alice, "story-updated", func(data map[string]interface{}) bool {
+		story, _ := data["story"].(map[string]interface{})
+		return story != nil && story["title"] == "Logout"
+	})
+
+	if len(stats.Rounds()) != 1 || bot.ID() == "" {
+		t.Errorf("Expected one round and the bot's ID, got %v and %q", stats.Rounds(), bot.ID())
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the bot to stop cleanly, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the bot to stop with its context")
+	}
+	if len(handled) == 0 {
+		t.Error("Expected the custom handler to see the chat")
+	}
+}
@@ -0,0 +1,274 @@
+package pokerserver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// AdminRoomSummary is the operator's view of a room.
+type AdminRoomSummary struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name,omitempty"`
+	Participants int       `json:"participants"`
+	Connections  int       `json:"connections"`
+	Revealed     bool      `json:"revealed"`
+	Rounds       int       `json:"rounds"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastActivity time.Time `json:"lastActivity"`
+}
+
+type AdminRoomDetail struct {
+	AdminRoomSummary
+	Story            *roompkg.Story        `json:"story"`
+	ParticipantsList []roompkg.Participant `json:"participantsList"`
+}
+
+// requireAdmin rejects requests without the admin bearer token, an API key
+// with the admin scope or a team token, which limits the request to the
+// team's rooms. With none of them configured the admin API is not exposed at
+// all.
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" && len(s.teamTokens) == 0 && s.apiKeys == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		team, ok := s.adminScope(r)
+		if _, keyed := bearerAPIKey(r); !ok && keyed && s.apiKeys != nil {
+			if s.authorizeAPIKey(w, r, ScopeAdmin) {
+				next(w, withAdminTeam(r, ""))
+			}
+			return
+		}
+		if !ok {
+			s.logger.Printf("Rejected admin request %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, withAdminTeam(r, team))
+	}
+}
+
+// isAdminRequest reports whether the request carries the admin bearer token.
+func (s *Server) isAdminRequest(r *http.Request) bool {
+	if s.adminToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1
+}
+
+func (s *Server) handleAdminListRooms(w http.ResponseWriter, r *http.Request) {
+	rooms := s.hub.Rooms()
+
+	team := adminTeam(r)
+	summaries := make([]AdminRoomSummary, 0, len(rooms))
+	for _, room := range rooms {
+		room.Mu.RLock()
+		if room.VisibleTo(team) {
+			summaries = append(summaries, s.summarizeRoom(room))
+		}
+		room.Mu.RUnlock()
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].LastActivity.After(summaries[j].LastActivity)
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"rooms": summaries})
+}
+
+func (s *Server) handleAdminGetRoom(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.adminRoom(w, r)
+	if !ok {
+		return
+	}
+
+	room.Mu.RLock()
+	detail := AdminRoomDetail{
+		AdminRoomSummary: s.summarizeRoom(room),
+		Story:            room.Story,
+		ParticipantsList: s.withLatency(s.getParticipantsArray(room)),
+	}
+	room.Mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func (s *Server) handleAdminDeleteRoom(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.adminRoom(w, r)
+	if !ok {
+		return
+	}
+	roomID := room.ID
+
+	// Tell clients first, while they are still reachable through the room
+	s.broadcastToRoom(r.Context(), roomID, "room-closed", map[string]interface{}{"roomId": roomID})
+	s.closeRoom(roomID)
+	s.logger.Printf("🗑️ Room %s deleted by admin", roomID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAdminResetRoom(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.adminRoom(w, r)
+	if !ok {
+		return
+	}
+	roomID := room.ID
+	if !s.resetRoom(r.Context(), roomID, "") {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	s.logger.Printf("🔄 Room %s reset by admin", roomID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminBroadcast is the body of POST /admin/broadcast. Without a RoomID the
+// announcement goes to every connected client.
+type AdminBroadcast struct {
+	Message string `json:"message"`
+	RoomID  string `json:"roomId,omitempty"`
+	Level   string `json:"level,omitempty"`
+}
+
+func (s *Server) handleAdminBroadcast(w http.ResponseWriter, r *http.Request) {
+	var req AdminBroadcast
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" {
+		http.Error(w, "message is required", http.StatusBadRequest)
+		return
+	}
+	switch req.Level {
+	case "":
+		req.Level = "info"
+	case "info", "warning":
+	default:
+		http.Error(w, "level must be info or warning", http.StatusBadRequest)
+		return
+	}
+
+	announcement := map[string]interface{}{
+		"message":   req.Message,
+		"level":     req.Level,
+		"timestamp": s.clock.Now().UnixMilli(),
+	}
+
+	if team := adminTeam(r); team != "" {
+		if s.announceToTeam(r, team, req, announcement) == 0 && req.RoomID != "" {
+			http.Error(w, "room not found", http.StatusNotFound)
+			return
+		}
+	} else if req.RoomID == "" {
+		s.broadcastToAll(r.Context(), "announcement", announcement)
+		s.publishToBroker(r.Context(), "", "announcement", announcement, "")
+		s.logger.Printf("📢 Announcement sent to all clients: %s", req.Message)
+	} else {
+		// The room may live on another instance when there are others
+		if _, ok := s.hub.Room(req.RoomID); !ok && s.singleInstance() {
+			http.Error(w, "room not found", http.StatusNotFound)
+			return
+		}
+		announcement["roomId"] = req.RoomID
+		s.emitToRoom(r.Context(), req.RoomID, "announcement", announcement, "")
+		s.logger.Printf("📢 Announcement sent to room %s: %s", req.RoomID, req.Message)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// announceToTeam sends a team-scoped announcement to one of the team's rooms
+// or, without a RoomID, to every room of the team on this instance. It returns
+// how many rooms it reached.
+func (s *Server) announceToTeam(r *http.Request, team string, req AdminBroadcast, announcement map[string]interface{}) int {
+	rooms := s.hub.Rooms()
+
+	var roomIDs []string
+	for _, room := range rooms {
+		room.Mu.RLock()
+		if room.VisibleTo(team) && (req.RoomID == "" || req.RoomID == room.ID) {
+			roomIDs = append(roomIDs, room.ID)
+		}
+		room.Mu.RUnlock()
+	}
+
+	for _, roomID := range roomIDs {
+		// Each room gets its own copy, as roomId differs
+		roomAnnouncement := make(map[string]interface{}, len(announcement)+1)
+		for key, value := range announcement {
+			roomAnnouncement[key] = value
+		}
+		roomAnnouncement["roomId"] = roomID
+		s.emitToRoom(r.Context(), roomID, "announcement", roomAnnouncement, "")
+	}
+	s.logger.Printf("📢 Announcement sent to %d rooms of team %s: %s", len(roomIDs), team, req.Message)
+	return len(roomIDs)
+}
+
+// closeRoom removes a room and disconnects its participants, once they have
+// been sent what is already on its way to them.
+func (s *Server) closeRoom(roomID string) {
+	room, exists := s.hub.RemoveRoom(roomID)
+
+	if !exists {
+		return
+	}
+	// Handlers that found the room before it went stop at its phase
+	room.Mu.Lock()
+	if !room.Closed {
+		room.Apply(&roompkg.CloseEvent{})
+	}
+	ids := make([]string, 0, len(room.Participants))
+	for id := range room.Participants {
+		ids = append(ids, id)
+	}
+	room.Mu.Unlock()
+	room.Spectators.Close()
+
+	for _, client := range s.hub.ClientsOf(ids) {
+		if client.RoomID() == roomID {
+			s.disconnect(client, transport.CloseRoomClosed, "room closed")
+		}
+	}
+}
+
+// summarizeRoom builds the admin summary. The caller must hold room.Mu.
+func (s *Server) summarizeRoom(room *roompkg.State) AdminRoomSummary {
+	connections := 0
+	for id := range room.Participants {
+		if s.hub.Client(id) != nil {
+			connections++
+		}
+	}
+
+	return AdminRoomSummary{
+		ID:           room.ID,
+		Name:         room.Info.Name,
+		Participants: len(room.Participants),
+		Connections:  connections,
+		Revealed:     room.Revealed,
+		Rounds:       len(room.History),
+		CreatedAt:    room.CreatedAt,
+		LastActivity: room.LastActivity,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,211 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func adminRequest(handler http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	disabled := New(WithAdminToken("")).Handler()
+	if rec := adminRequest(disabled, http.MethodGet, "/admin/rooms", "anything"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected admin API to be hidden without a token, got %d", rec.Code)
+	}
+
+	handler := New(WithAdminToken("secret")).Handler()
+	if rec := adminRequest(handler, http.MethodGet, "/admin/rooms", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodGet, "/admin/rooms", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with wrong token, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodGet, "/admin/rooms", "secret"); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 with token, got %d", rec.Code)
+	}
+}
+
+func TestAdminListAndGetRooms(t *testing.T) {
+	server := New(WithAdminToken("secret"))
+	handler := server.Handler()
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	rec := adminRequest(handler, http.MethodGet, "/admin/rooms", "secret")
+	var list struct {
+		Rooms []AdminRoomSummary `json:"rooms"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode room list: %v", err)
+	}
+	if len(list.Rooms) != 1 {
+		t.Fatalf("Expected 1 room, got %d", len(list.Rooms))
+	}
+	if list.Rooms[0].Participants != 1 || list.Rooms[0].Connections != 1 {
+		t.Errorf("Expected 1 participant and connection, got %+v", list.Rooms[0])
+	}
+	if list.Rooms[0].LastActivity.IsZero() {
+		t.Error("Expected last activity to be set")
+	}
+
+	rec = adminRequest(handler, http.MethodGet, "/admin/rooms/test-room", "secret")
+	var detail AdminRoomDetail
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("Failed to decode room detail: %v", err)
+	}
+	if len(detail.ParticipantsList) != 1 || detail.ParticipantsList[0].Name != "Alice" {
+		t.Errorf("Unexpected participants: %+v", detail.ParticipantsList)
+	}
+
+	if rec := adminRequest(handler, http.MethodGet, "/admin/rooms/missing", "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown room, got %d", rec.Code)
+	}
+}
+
+func TestAdminResetAndDeleteRoom(t *testing.T) {
+	server := New(WithAdminToken("secret"))
+	handler := server.Handler()
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "vote", map[string]interface{}{
+		"roomId": "test-room",
+		"vote":   "5",
+	})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+
+	if rec := adminRequest(handler, http.MethodPost, "/admin/rooms/test-room/reset", "secret"); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 for reset, got %d", rec.Code)
+	}
+	if msg := readMessage(t, ws, 2*time.Second); msg.Type != "room-reset" {
+		t.Errorf("Expected room-reset message, got %s", msg.Type)
+	}
+
+	if rec := adminRequest(handler, http.MethodDelete, "/admin/rooms/test-room", "secret"); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 for delete, got %d", rec.Code)
+	}
+	if msg := readMessage(t, ws, 2*time.Second); msg.Type != "room-closed" {
+		t.Errorf("Expected room-closed message, got %s", msg.Type)
+	}
+	if _, exists := server.hub.Room("test-room"); exists {
+		t.Error("Expected room to be deleted")
+	}
+}
+
+func postBroadcast(handler http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/admin/broadcast", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminBroadcastToAllClients(t *testing.T) {
+	broker := &fakeBroker{}
+	server := New(WithAdminToken("secret"), WithBroker(broker))
+	handler := server.Handler()
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// A client that has not joined a room yet is still told
+	_, lobby := createTestWSConnection(t, server)
+	defer lobby.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	rec := postBroadcast(handler, `{"message":"Server restarting in 5 minutes","level":"warning"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, conn := range []*websocket.Conn{ws, lobby} {
+		msg := readMessage(t, conn, 2*time.Second)
+		if msg.Type != "announcement" {
+			t.Fatalf("Expected announcement, got %s", msg.Type)
+		}
+		data := msg.Data.(map[string]interface{})
+		if data["message"] != "Server restarting in 5 minutes" || data["level"] != "warning" {
+			t.Errorf("Unexpected announcement: %v", data)
+		}
+	}
+
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if len(broker.published) != 1 || broker.published[0].RoomID != "" {
+		t.Errorf("Expected one server-wide broker message, got %+v", broker.published)
+	}
+}
+
+func TestAdminBroadcastToRoom(t *testing.T) {
+	server := New(WithAdminToken("secret"))
+	handler := server.Handler()
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	if rec := postBroadcast(handler, `{"message":"hi","roomId":"missing"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown room, got %d", rec.Code)
+	}
+	if rec := postBroadcast(handler, `{"message":"  "}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for empty message, got %d", rec.Code)
+	}
+	if rec := postBroadcast(handler, `{"message":"hi","level":"panic"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown level, got %d", rec.Code)
+	}
+
+	if rec := postBroadcast(handler, `{"message":"Wrap up in 10","roomId":"test-room"}`); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", rec.Code)
+	}
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "announcement" {
+		t.Fatalf("Expected announcement, got %s", msg.Type)
+	}
+	data := msg.Data.(map[string]interface{})
+	if data["roomId"] != "test-room" || data["level"] != "info" {
+		t.Errorf("Unexpected announcement: %v", data)
+	}
+}
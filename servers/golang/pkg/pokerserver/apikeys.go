@@ -0,0 +1,401 @@
+package pokerserver
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// API keys guard the REST routes that are otherwise open: creating rooms,
+// exports and reports, and, for keys with the admin scope, the admin API.
+// They are kept in a JSON file holding only their hashes, managed with the
+// apikeys command or the admin API.
+
+// API key scopes.
+const (
+	ScopeRoomsCreate = "rooms:create"
+	ScopeExport      = "export"
+	ScopeAdmin       = "admin"
+)
+
+var apiKeyScopes = []string{ScopeRoomsCreate, ScopeExport, ScopeAdmin}
+
+// Keys look like pp_<id>_<secret>, so they are told apart from other tokens
+// and found by ID without comparing every hash
+const apiKeyPrefix = "pp_"
+
+// API key rate limits are per minute
+const apiKeyRateWindow = time.Minute
+
+const defaultAPIKeyRateLimit = 120
+
+var errAPIKeyInvalid = errors.New("invalid API key")
+
+// APIKey is a stored API key. Only the hash of the key itself is kept, and
+// listings leave that out too.
+type APIKey struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Hash   string   `json:"hash,omitempty"`
+	Scopes []string `json:"scopes"`
+	// RateLimit is the requests allowed per minute; 0 uses the server default
+	RateLimit int       `json:"rateLimit,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// HasScope reports whether the key grants scope.
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// APIKeyStore keeps API keys in a file. Changes made to the file by another
+// process, such as the apikeys command, are picked up on the next lookup.
+type APIKeyStore struct {
+	path string
+
+	mu   sync.Mutex
+	keys []APIKey
+	// The file's modification time and size when last read, to notice changes
+	modTime time.Time
+	size    int64
+}
+
+// OpenAPIKeyStore reads the keys in the file at path, which is created on
+// the first change if it doesn't exist.
+func OpenAPIKeyStore(path string) (*APIKeyStore, error) {
+	store := &APIKeyStore{path: path}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if err := store.refresh(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// refresh rereads the file when it changed. The caller must hold store.mu.
+func (store *APIKeyStore) refresh() error {
+	info, err := os.Stat(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		store.keys, store.modTime, store.size = nil, time.Time{}, 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading API keys: %w", err)
+	}
+	if info.ModTime().Equal(store.modTime) && info.Size() == store.size {
+		return nil
+	}
+
+	content, err := os.ReadFile(store.path)
+	if err != nil {
+		return fmt.Errorf("reading API keys: %w", err)
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return fmt.Errorf("parsing %s: %w", store.path, err)
+	}
+	store.keys, store.modTime, store.size = keys, info.ModTime(), info.Size()
+	return nil
+}
+
+// save writes the keys to the file, replacing it in one go so readers never
+// see half of it. The caller must hold store.mu.
+func (store *APIKeyStore) save() error {
+	content, err := json.MarshalIndent(store.keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(store.path), ".apikeys-*")
+	if err != nil {
+		return fmt.Errorf("saving API keys: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(content, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("saving API keys: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("saving API keys: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), store.path); err != nil {
+		return fmt.Errorf("saving API keys: %w", err)
+	}
+	if info, err := os.Stat(store.path); err == nil {
+		store.modTime, store.size = info.ModTime(), info.Size()
+	}
+	return nil
+}
+
+// Create adds a key and returns it along with the key itself, which is not
+// stored and can't be shown again.
+func (store *APIKeyStore) Create(name string, scopes []string, rateLimit int) (APIKey, string, error) {
+	for _, scope := range scopes {
+		if !slices.Contains(apiKeyScopes, scope) {
+			return APIKey{}, "", fmt.Errorf("%w: unknown scope %q", errAPIKeyInvalid, scope)
+		}
+	}
+	if rateLimit < 0 {
+		return APIKey{}, "", fmt.Errorf("%w: rate limit must not be negative", errAPIKeyInvalid)
+	}
+
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return APIKey{}, "", err
+	}
+	key := APIKey{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+		RateLimit: rateLimit,
+		CreatedAt: time.Now().UTC(),
+	}
+	secret := apiKeyPrefix + key.ID + "_" + generateToken()
+	key.Hash = hashAPIKey(secret)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if err := store.refresh(); err != nil {
+		return APIKey{}, "", err
+	}
+	store.keys = append(store.keys, key)
+	if err := store.save(); err != nil {
+		store.keys = store.keys[:len(store.keys)-1]
+		return APIKey{}, "", err
+	}
+	key.Hash = ""
+	return key, secret, nil
+}
+
+// List returns the keys, oldest first.
+func (store *APIKeyStore) List() ([]APIKey, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if err := store.refresh(); err != nil {
+		return nil, err
+	}
+	keys := make([]APIKey, len(store.keys))
+	for i, key := range store.keys {
+		key.Hash = ""
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// Revoke deletes the key with id, reporting whether there was one.
+func (store *APIKeyStore) Revoke(id string) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if err := store.refresh(); err != nil {
+		return false, err
+	}
+	i := slices.IndexFunc(store.keys, func(key APIKey) bool { return key.ID == id })
+	if i < 0 {
+		return false, nil
+	}
+	revoked := store.keys[i]
+	store.keys = slices.Delete(store.keys, i, i+1)
+	if err := store.save(); err != nil {
+		store.keys = slices.Insert(store.keys, i, revoked)
+		return false, err
+	}
+	return true, nil
+}
+
+// authenticate returns the key secret belongs to.
+func (store *APIKeyStore) authenticate(secret string) (APIKey, bool) {
+	id, _, ok := strings.Cut(strings.TrimPrefix(secret, apiKeyPrefix), "_")
+	if !ok || !strings.HasPrefix(secret, apiKeyPrefix) {
+		return APIKey{}, false
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	// Without the file the keys are unknown, so nothing gets in
+	if err := store.refresh(); err != nil {
+		return APIKey{}, false
+	}
+	hash := hashAPIKey(secret)
+	for _, key := range store.keys {
+		if key.ID == id && subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash)) == 1 {
+			return key, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// Keys are random, so a plain hash keeps them safe at rest
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAPIKeyStoreFromConfig returns nil when API keys aren't configured. A
+// file that can't be read leaves an empty store, so the routes it guards stay
+// closed rather than open.
+func newAPIKeyStoreFromConfig(path string) *APIKeyStore {
+	if path == "" {
+		return nil
+	}
+	store, err := OpenAPIKeyStore(path)
+	if err != nil {
+		log.Printf("❌ API keys unavailable, refusing keyed requests: %v", err)
+		return &APIKeyStore{path: path}
+	}
+	return store
+}
+
+// bearerAPIKey returns the API key a request carries, if any.
+func bearerAPIKey(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token, ok && strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// authorizeAPIKey checks that the request carries a key with scope and within
+// its rate limit, writing a 401, 403 or 429 otherwise.
+func (s *Server) authorizeAPIKey(w http.ResponseWriter, r *http.Request, scope string) bool {
+	secret, _ := bearerAPIKey(r)
+	key, ok := s.apiKeys.authenticate(secret)
+	if !ok {
+		s.logger.Printf("⛔ Rejected %s %s without a valid API key from %s", r.Method, r.URL.Path, s.clientIP(r))
+		http.Error(w, "API key required", http.StatusUnauthorized)
+		return false
+	}
+	if !key.HasScope(scope) {
+		http.Error(w, "API key lacks the "+scope+" scope", http.StatusForbidden)
+		return false
+	}
+	if !s.apiKeyLimiter(key).Allow() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(apiKeyRateWindow.Seconds())))
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+// apiKeyLimiter returns the rate limiter of a key.
+func (s *Server) apiKeyLimiter(key APIKey) *transport.RateLimiter {
+	limit := key.RateLimit
+	if limit == 0 {
+		limit = s.apiKeyRateLimit
+	}
+
+	s.apiKeyLimitersMu.Lock()
+	defer s.apiKeyLimitersMu.Unlock()
+	limiter, ok := s.apiKeyLimiters[key.ID]
+	if !ok {
+		limiter = transport.NewRateLimiter(limit, apiKeyRateWindow)
+		s.apiKeyLimiters[key.ID] = limiter
+	} else {
+		limiter.SetLimit(limit, apiKeyRateWindow)
+	}
+	return limiter
+}
+
+// requireAPIKey guards a route with a key granting scope, when API keys are
+// configured.
+func (s *Server) requireAPIKey(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKeys != nil && !s.authorizeAPIKey(w, r, scope) {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireFullAdmin limits an admin route to the admin token and admin keys,
+// keeping team tokens out.
+func requireFullAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if adminTeam(r) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleAdminListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if s.apiKeys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	keys, err := s.apiKeys.List()
+	if err != nil {
+		s.logger.Printf("❌ Failed to list API keys: %v", err)
+		http.Error(w, "API keys unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+}
+
+// handleAdminCreateAPIKey creates a key, answering with the key itself
+// alongside its details. It can't be retrieved later.
+func (s *Server) handleAdminCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if s.apiKeys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var body struct {
+		Name      string   `json:"name"`
+		Scopes    []string `json:"scopes"`
+		RateLimit int      `json:"rateLimit"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16<<10)).Decode(&body); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.Name) == "" || len(body.Scopes) == 0 {
+		http.Error(w, "name and scopes are required", http.StatusBadRequest)
+		return
+	}
+
+	key, secret, err := s.apiKeys.Create(strings.TrimSpace(body.Name), body.Scopes, body.RateLimit)
+	if errors.Is(err, errAPIKeyInvalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		s.logger.Printf("❌ Failed to create API key: %v", err)
+		http.Error(w, "API keys unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	s.logger.Printf("🔑 Created API key %s (%s) with scopes %s", key.ID, key.Name, strings.Join(key.Scopes, ","))
+	writeJSON(w, http.StatusCreated, struct {
+		APIKey
+		Key string `json:"key"`
+	}{key, secret})
+}
+
+func (s *Server) handleAdminRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if s.apiKeys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	revoked, err := s.apiKeys.Revoke(r.PathValue("id"))
+	if err != nil {
+		s.logger.Printf("❌ Failed to revoke API key: %v", err)
+		http.Error(w, "API keys unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !revoked {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+	s.logger.Printf("🔑 Revoked API key %s", r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
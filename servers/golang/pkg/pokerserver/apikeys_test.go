@@ -0,0 +1,123 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newAPIKeyServer(t *testing.T) (*Server, string) {
+	path := filepath.Join(t.TempDir(), "apikeys.json")
+	cfg := DefaultConfig()
+	cfg.AdminToken = "admin-secret"
+	cfg.TeamTokens = map[string]string{"payments": "payments-secret"}
+	cfg.APIKeysFile = path
+	return New(WithConfig(cfg)), path
+}
+
+func TestAPIKeysGuardRoutes(t *testing.T) {
+	server, path := newAPIKeyServer(t)
+	handler := server.Handler()
+
+	_, creator, err := server.apiKeys.Create("ci", []string{ScopeRoomsCreate}, 2)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	_, exporter, _ := server.apiKeys.Create("reports", []string{ScopeExport, ScopeAdmin}, 0)
+
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), creator) || strings.Contains(string(content), exporter) {
+		t.Error("Expected only key hashes in the keys file")
+	}
+
+	if rec := adminRequest(handler, http.MethodPost, "/api/rooms", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected creating a room without a key to be refused, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodPost, "/api/rooms", exporter); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected a key without the scope to be refused, got %d", rec.Code)
+	}
+	for i := 0; i < 2; i++ {
+		if rec := adminRequest(handler, http.MethodPost, "/api/rooms", creator); rec.Code != http.StatusCreated {
+			t.Errorf("Expected the key to create a room, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if rec := adminRequest(handler, http.MethodPost, "/api/rooms", creator); rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected the key's rate limit to apply, got %d", rec.Code)
+	}
+
+	if rec := adminRequest(handler, http.MethodGet, "/api/rooms/missing/export", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected exports to need a key, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodGet, "/api/rooms/missing/export", exporter); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected the key to reach the export, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodGet, "/admin/rooms", exporter); rec.Code != http.StatusOK {
+		t.Errorf("Expected an admin key to reach the admin API, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodGet, "/admin/rooms", creator); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected a key without the admin scope to be refused, got %d", rec.Code)
+	}
+	// Rooms can still be joined without keys
+	client := server.ConnectMemory()
+	defer client.Close()
+	client.Send("join-room", map[string]interface{}{"roomId": "open-room", "name": "Alice"})
+	awaitMessage(t, client, "room-state")
+}
+
+func TestAdminManagesAPIKeys(t *testing.T) {
+	server, path := newAPIKeyServer(t)
+	handler := server.Handler()
+
+	create := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/api-keys", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := create("payments-secret", `{"name": "ci", "scopes": ["export"]}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected team tokens to be kept from managing keys, got %d", rec.Code)
+	}
+	if rec := create("admin-secret", `{"name": "ci", "scopes": ["everything"]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown scope to be refused, got %d", rec.Code)
+	}
+	rec := create("admin-secret", `{"name": "ci", "scopes": ["export"], "rateLimit": 30}`)
+	var created struct {
+		APIKey
+		Key string `json:"key"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec.Code != http.StatusCreated || !strings.HasPrefix(created.Key, apiKeyPrefix+created.ID+"_") || created.Hash != "" {
+		t.Fatalf("Expected the new key, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = adminRequest(handler, http.MethodGet, "/admin/api-keys", "admin-secret")
+	var list struct {
+		Keys []APIKey `json:"keys"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if len(list.Keys) != 1 || list.Keys[0].Name != "ci" || list.Keys[0].RateLimit != 30 || list.Keys[0].Hash != "" {
+		t.Errorf("Expected the key listed without its hash, got %+v", list.Keys)
+	}
+
+	if rec := adminRequest(handler, http.MethodDelete, "/admin/api-keys/"+created.ID, "admin-secret"); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the key revoked, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodGet, "/api/rooms/missing/report", created.Key); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a revoked key to be refused, got %d", rec.Code)
+	}
+
+	// Keys added to the file by another process work right away
+	other, err := OpenAPIKeyStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open the keys file: %v", err)
+	}
+	_, secret, _ := other.Create("cli", []string{ScopeExport}, 0)
+	if rec := adminRequest(handler, http.MethodGet, "/api/rooms/missing/report", secret); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected a key from the file to be accepted, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,134 @@
+package pokerserver
+
+import (
+	"context"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// handleOpenAsyncRound starts a round that closes by itself: everyone can
+// join, vote and leave whenever suits their timezone, and the cards are
+// revealed when the deadline passes. Only the facilitator opens one, giving
+// the deadline in milliseconds since the epoch and optionally the story.
+func (s *Server) handleOpenAsyncRound(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	deadlineMs, _ := data["deadline"].(float64)
+	storyData, _ := data["story"].(map[string]interface{})
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	deadline := time.UnixMilli(int64(deadlineMs))
+	if until := deadline.Sub(s.clock.Now()); until <= 0 || until > roompkg.MaxAsyncRoundDuration {
+		s.logf(ws.Context(), "⚠️ Ignoring open-async-round from %s in room %s: deadline %v out of range", ws.ID, roomID, deadline)
+		s.sendError(ws, "open-async-round", errCodeInvalidInput, "deadline must be in the future, within the longest an async round may run", nil)
+		return
+	}
+
+	var story *roompkg.Story
+	if storyData != nil {
+		title, _ := storyData["title"].(string)
+		link, _ := storyData["link"].(string)
+		checked, invalid := checkStory(title, link)
+		if invalid != nil {
+			s.sendInputError(ws, "open-async-round", invalid)
+			return
+		}
+		story = &checked
+		s.enrichStory(ws.Context(), story)
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring open-async-round from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "open-async-round", errCodeNotAllowed, "Only the facilitator can open an async round", nil)
+		return
+	}
+	room.Apply(&roompkg.AsyncRoundEvent{Story: story, Deadline: deadline})
+	s.scheduleDeadline(room, deadline)
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "⏳ Async round opened in room %s until %s", roomID, deadline.UTC().Format(time.RFC3339))
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+// scheduleDeadline arms the timer that reveals the async round at its
+// deadline, replacing any armed before. A deadline that has already passed
+// fires right away. The caller must hold room.Mu.
+func (s *Server) scheduleDeadline(room *roompkg.State, deadline time.Time) {
+	if room.DeadlineTimer != nil {
+		room.DeadlineTimer.Stop()
+	}
+	room.DeadlineTimer = s.clock.AfterFunc(deadline.Sub(s.clock.Now()), func() {
+		s.closeAsyncRound(room, deadline)
+	})
+}
+
+// closeAsyncRound reveals the round once its deadline passes, notifies the
+// webhooks, and lets go of the participants who are no longer connected.
+func (s *Server) closeAsyncRound(room *roompkg.State, deadline time.Time) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	live := s.hub.Holds(room)
+	if !live {
+		return
+	}
+
+	// The round may have been revealed by hand or replaced in the meantime.
+	// Revealing it clears the deadline
+	room.Mu.Lock()
+	current := room.Deadline.Equal(deadline)
+	if current {
+		room.DeadlineTimer = nil
+	}
+	room.Mu.Unlock()
+	if !current {
+		return
+	}
+
+	ctx := context.Background()
+	round := s.revealRound(ctx, room, "", "")
+	s.logger.Printf("⏰ Async round in room %s closed at its deadline with %d vote(s)", room.ID, round.Stats.VoteCount)
+	s.notify(room.ID, EventAsyncRoundClosed, round)
+
+	room.Mu.RLock()
+	var offline []string
+	for id, p := range room.Participants {
+		if !p.Online {
+			offline = append(offline, id)
+		}
+	}
+	room.Mu.RUnlock()
+
+	for _, id := range offline {
+		s.removeParticipant(room.ID, id)
+	}
+	if len(offline) > 0 {
+		s.broadcastRoomState(ctx, room.ID)
+	}
+}
+
+// keepForAsyncRound marks a leaving participant offline instead of removing
+// them while an async round is open, so their vote still counts. Returns
+// false when there is no open round to keep them for.
+func (s *Server) keepForAsyncRound(roomID, clientID string) bool {
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return false
+	}
+
+	room.Mu.Lock()
+	defer room.Mu.Unlock()
+	if _, ok := room.Participants[clientID]; !ok || !roompkg.AsyncRoundOpen(room) {
+		return false
+	}
+	room.Apply(&roompkg.PresenceEvent{ClientID: clientID, Online: false})
+	return true
+}
@@ -0,0 +1,96 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAsyncRoundClosesAtDeadline(t *testing.T) {
+	events := make(chan Event, 2)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+	}))
+	defer webhook.Close()
+	slack := make(chan string, 1)
+	slackWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct{ Text string }
+		json.NewDecoder(r.Body).Decode(&message)
+		slack <- message.Text
+	}))
+	defer slackWebhook.Close()
+
+	cfg := DefaultConfig()
+	cfg.Webhooks = WebhooksConfig{URLs: []string{webhook.URL}, SlackURL: slackWebhook.URL}
+	cfg.Outbound.AllowedNetworks = []string{"127.0.0.0/8"}
+	server := New(WithConfig(cfg))
+	httpServer, alice := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer alice.Close()
+	_, bob := createTestWSConnection(t, server)
+	defer bob.Close()
+
+	roomID := "async-room"
+
+	sendMessage(t, alice, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, alice, 2*time.Second) // room-state
+
+	// Deadlines in the past are refused
+	sendMessage(t, alice, "open-async-round", map[string]interface{}{"roomId": roomID, "deadline": time.Now().Add(-time.Minute).UnixMilli()})
+	if msg := readMessage(t, alice, 2*time.Second); msg.Type != "error" || msg.Data.(map[string]interface{})["code"] != errCodeInvalidInput {
+		t.Fatalf("Expected a deadline in the past to be refused, got %s %v", msg.Type, msg.Data)
+	}
+	deadline := time.Now().Add(500 * time.Millisecond)
+	sendMessage(t, alice, "open-async-round", map[string]interface{}{
+		"roomId":   roomID,
+		"deadline": deadline.UnixMilli(),
+		"story":    map[string]interface{}{"title": "Checkout"},
+	})
+	msg := readMessage(t, alice, 2*time.Second)
+	if msg.Data.(map[string]interface{})["deadline"] != float64(deadline.UnixMilli()) {
+		t.Fatalf("Expected the deadline in room-state, got %v", msg.Data)
+	}
+
+	// Bob votes and leaves before the deadline, his vote still counts
+	sendMessage(t, bob, "join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	readUntilType(t, bob, "room-state")
+	sendMessage(t, bob, "vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	readUntilType(t, bob, "participant-voted")
+	sendMessage(t, bob, "leave-room", map[string]interface{}{"roomId": roomID})
+	sendMessage(t, alice, "vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+
+	revealed := readUntilType(t, alice, "revealed").Data.(map[string]interface{})
+	if stats := revealed["stats"].(map[string]interface{}); stats["voteCount"] != float64(2) {
+		t.Errorf("Expected both votes to be revealed, got %v", stats)
+	}
+
+	// The reveal is posted as well, in no particular order
+	timeout := time.After(2 * time.Second)
+	for closed := false; !closed; {
+		select {
+		case event := <-events:
+			if event.RoomID != roomID || (event.Type != EventAsyncRoundClosed && event.Type != EventRoundRevealed) {
+				t.Errorf("Unexpected webhook event: %+v", event)
+			}
+			closed = event.Type == EventAsyncRoundClosed
+		case <-timeout:
+			t.Fatal("Expected a webhook event when the round closed")
+		}
+	}
+	select {
+	case text := <-slack:
+		if !strings.Contains(text, "Checkout") || !strings.Contains(text, "2 vote(s)") {
+			t.Errorf("Unexpected Slack message: %s", text)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Expected a Slack message when the round closed")
+	}
+
+	// Bob is let go once the round is over
+	readRoomStateUntil(t, alice, func(participants []interface{}) bool { return len(participants) == 1 })
+}
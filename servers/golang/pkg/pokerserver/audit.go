@@ -0,0 +1,37 @@
+package pokerserver
+
+import (
+	"net/http"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// roomAudit returns a copy of a live room's audit log.
+func (s *Server) roomAudit(roomID string) ([]roompkg.AuditEntry, bool) {
+	room, ok := s.hub.Room(roomID)
+	if !ok {
+		return nil, false
+	}
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	entries := make([]roompkg.AuditEntry, len(room.Audit))
+	copy(entries, room.Audit)
+	return entries, true
+}
+
+func (s *Server) handleAdminRoomAudit(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.adminRoom(w, r)
+	if !ok {
+		return
+	}
+	roomID := room.ID
+	entries, ok := s.roomAudit(roomID)
+	if !ok {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"roomId":  roomID,
+		"entries": entries,
+	})
+}
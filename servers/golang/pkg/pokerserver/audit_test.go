@@ -0,0 +1,90 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestAuditLog(t *testing.T) {
+	server := New(WithAdminToken("secret"))
+	roomID := "audit-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+
+	alice.Send("update-story", map[string]interface{}{"roomId": roomID, "story": map[string]interface{}{"title": "Login"}})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	alice.Send("set-final-estimate", map[string]interface{}{"roomId": roomID, "estimate": "5"})
+	alice.Send("kick-participant", map[string]interface{}{"roomId": roomID, "id": bob.ID})
+	alice.Send("reset", map[string]interface{}{"roomId": roomID})
+
+	rec := adminRequest(server.Handler(), http.MethodGet, "/admin/rooms/"+roomID+"/audit", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Entries []roompkg.AuditEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode the audit log: %v", err)
+	}
+
+	expected := []struct{ action, actor, target string }{
+		{roompkg.AuditJoined, "Alice", ""},
+		{roompkg.AuditJoined, "Bob", ""},
+		{roompkg.AuditStoryChanged, "Alice", ""},
+		{roompkg.AuditVoted, "Bob", ""},
+		{roompkg.AuditRevealed, "Alice", ""},
+		{roompkg.AuditFinalEstimate, "Alice", ""},
+		{roompkg.AuditKicked, "Alice", "Bob"},
+		{roompkg.AuditLeft, "Bob", ""},
+		{roompkg.AuditReset, "Alice", ""},
+	}
+	if len(body.Entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %+v", len(expected), body.Entries)
+	}
+	for i, want := range expected {
+		entry := body.Entries[i]
+		if entry.Action != want.action || entry.Actor != want.actor || entry.Target != want.target {
+			t.Errorf("Entry %d: expected %s by %q on %q, got %+v", i, want.action, want.actor, want.target, entry)
+		}
+	}
+	if voted := body.Entries[3]; voted.Votes != nil || voted.Estimate != "" {
+		t.Errorf("Expected the vote logged without its value, got %+v", voted)
+	}
+	if revealed := body.Entries[4]; revealed.Votes["Bob"] != "5" || revealed.Story != "Login" || revealed.RoundID == "" {
+		t.Errorf("Expected the reveal to list the votes, got %+v", revealed)
+	}
+
+	if rec := adminRequest(server.Handler(), http.MethodGet, "/admin/rooms/missing/audit", "secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown room, got %d", rec.Code)
+	}
+}
+
+func TestExportIncludesAudit(t *testing.T) {
+	server := New()
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": "audit-export", "name": "Alice"})
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/rooms/audit-export/export", nil))
+	var export struct {
+		Audit []roompkg.AuditEntry `json:"audit"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &export); err != nil {
+		t.Fatalf("Failed to decode the export: %v", err)
+	}
+	if len(export.Audit) != 1 || export.Audit[0].Action != roompkg.AuditJoined {
+		t.Errorf("Expected the join in the exported audit log, got %+v", export.Audit)
+	}
+}
@@ -0,0 +1,55 @@
+package pokerserver
+
+import (
+	"errors"
+	"net/http"
+)
+
+// clientIdentity is who a connecting client authenticated as. Anonymous
+// clients have none of it.
+type clientIdentity struct {
+	// TeamID scopes the client's rooms, from a team token or the SSO team claim
+	TeamID string
+	// UserID and UserName come from a single sign-on session
+	UserID   string
+	UserName string
+}
+
+// authenticateClient works out who a connecting client is from the session
+// token or team token it presents, writing a 401 when the token is neither.
+// A session may also come from the login cookie. Without a token, or with an
+// expired session, the client is anonymous, unless single sign-on is
+// required. Tokens are ignored when neither is configured.
+func (s *Server) authenticateClient(w http.ResponseWriter, r *http.Request) (clientIdentity, bool) {
+	token := requestToken(r)
+	if s.oidc != nil {
+		session := token
+		if session == "" {
+			if cookie, err := r.Cookie(sessionCookieName); err == nil {
+				session = cookie.Value
+			}
+		}
+		claims, err := s.oidc.verifySession(session, s.clock.Now())
+		if err == nil {
+			return claims.identity(), true
+		}
+		if s.oidc.cfg.Required {
+			s.logger.Printf("⛔ Rejected connection without a valid session from %s", s.clientIP(r))
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return clientIdentity{}, false
+		}
+		if errors.Is(err, errSessionExpired) {
+			return clientIdentity{}, true
+		}
+	}
+
+	if token == "" || (s.oidc == nil && len(s.teamTokens) == 0) {
+		return clientIdentity{}, true
+	}
+	team, ok := s.teamForToken(token)
+	if !ok {
+		s.logger.Printf("⛔ Rejected connection with an unknown token from %s", s.clientIP(r))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}
+	return clientIdentity{TeamID: team}, ok
+}
@@ -0,0 +1,82 @@
+package pokerserver
+
+import (
+	"context"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// watchAway arms the check for the participant going away, unless one is
+// armed already. Heartbeats don't count as activity, only messages do. The
+// caller must hold room.Mu.
+func (s *Server) watchAway(room *roompkg.State, participant *roompkg.Participant) {
+	s.settingsMu.RLock()
+	awayAfter := s.awayAfter
+	s.settingsMu.RUnlock()
+	if awayAfter <= 0 || participant.AwayTimer != nil {
+		return
+	}
+	idle := s.clock.Now().Sub(participant.LastActiveAt)
+	participant.AwayTimer = s.clock.AfterFunc(max(awayAfter-idle, 0), func() {
+		s.checkAway(room, participant)
+	})
+}
+
+// checkAway pauses a participant who sent nothing for the away timeout, so
+// they don't count towards reveals, and checks again later otherwise.
+func (s *Server) checkAway(room *roompkg.State, participant *roompkg.Participant) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	live := s.hub.Holds(room)
+	if !live {
+		return
+	}
+
+	s.settingsMu.RLock()
+	awayAfter := s.awayAfter
+	s.settingsMu.RUnlock()
+
+	room.Mu.Lock()
+	participant.AwayTimer = nil
+	// Participants who left, disconnected or paused themselves are checked
+	// again on their next message
+	if room.Participants[participant.ID] != participant || !participant.Online || participant.Paused || awayAfter <= 0 {
+		room.Mu.Unlock()
+		return
+	}
+	if s.clock.Now().Sub(participant.LastActiveAt) < awayAfter {
+		s.watchAway(room, participant)
+		room.Mu.Unlock()
+		return
+	}
+	room.Apply(&roompkg.PauseEvent{ClientID: participant.ID, Paused: true, Away: true})
+	room.Mu.Unlock()
+
+	s.logger.Printf("💤 Paused %s in room %s after %s away", participant.ID, room.ID, awayAfter)
+	s.broadcastRoomState(context.Background(), room.ID)
+}
+
+// returnToVoting resumes the sender's voting if the server paused them for
+// being away, and clears their status, reporting whether either changed.
+func (s *Server) returnToVoting(ws *ExtendedWebSocket, room *roompkg.State) bool {
+	room.Mu.Lock()
+	participant, ok := room.Participants[ws.ID]
+	if !ok {
+		room.Mu.Unlock()
+		return false
+	}
+	away, hadStatus := participant.Away, participant.Status != ""
+	if away {
+		room.Apply(&roompkg.PauseEvent{ClientID: ws.ID, Paused: false})
+	}
+	if hadStatus {
+		room.Apply(&roompkg.StatusEvent{ClientID: ws.ID})
+	}
+	room.Mu.Unlock()
+
+	if away {
+		s.logf(ws.Context(), "👋 %s is back in room %s", ws.ID, room.ID)
+	}
+	return away || hadStatus
+}
@@ -0,0 +1,62 @@
+package pokerserver
+
+import (
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestAwayParticipantsArePaused(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	cfg := DefaultConfig()
+	cfg.AwayAfter = 5 * time.Minute
+	server := New(WithConfig(cfg), WithClock(clock))
+	roomID := "away-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	room, _ := server.hub.Room(roomID)
+
+	clock.Advance(3 * time.Minute)
+	bob.Send("chat-message", map[string]interface{}{"roomId": roomID, "text": "Still here"})
+	clock.Advance(3 * time.Minute)
+
+	// Earlier states are still queued
+	var participants []interface{}
+	for away := false; !away; {
+		participants = awaitMessage(t, alice, "room-state").Data.(map[string]interface{})["participants"].([]interface{})
+		for _, p := range participants {
+			away = away || p.(map[string]interface{})["away"] == true
+		}
+	}
+	for _, p := range participants {
+		p := p.(map[string]interface{})
+		if away := p["name"] == "Alice"; (p["away"] == true) != away || (p["paused"] == true) != away {
+			t.Errorf("Expected only Alice to be paused as away, got %v", p)
+		}
+	}
+
+	// Away participants don't hold up a reveal by vote threshold
+	room.Mu.Lock()
+	room.RevealPolicy = roompkg.RevealPolicy{Mode: roompkg.RevealVoteThreshold, Threshold: 1}
+	room.Mu.Unlock()
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "3"})
+	room.Mu.RLock()
+	refusal := room.RevealRefusal(bob.ID)
+	room.Mu.RUnlock()
+	if refusal != "" {
+		t.Errorf("Expected Bob to be able to reveal without Alice, got %q", refusal)
+	}
+
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if p := room.Participants[alice.ID]; p.Paused || p.Away || *p.Vote != "5" {
+		t.Errorf("Expected voting to bring Alice back, got %+v", p)
+	}
+}
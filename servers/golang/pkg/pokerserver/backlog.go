@@ -0,0 +1,244 @@
+package pokerserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+const (
+	maxBacklogStories = 200
+	maxStoryTitle     = 500
+	maxImportBytes    = 256 << 10
+)
+
+// ImportResult tells how an import went. Rows count from 1, not counting a
+// CSV header. Error is set instead when the import couldn't be read at all.
+type ImportResult struct {
+	Accepted int             `json:"accepted"`
+	Rejected []RejectedStory `json:"rejected"`
+	Backlog  int             `json:"backlog"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// RejectedStory is a row of an import that wasn't added, and why.
+type RejectedStory struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// importedStory is a row of an import, before validation.
+type importedStory struct {
+	Title string `json:"title"`
+	Link  string `json:"link"`
+}
+
+// parseStoriesCSV reads title,link rows. A first row naming the columns is
+// skipped, and the link column may be left out.
+func parseStoriesCSV(data []byte) ([]importedStory, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "title") {
+		records = records[1:]
+	}
+
+	stories := make([]importedStory, 0, len(records))
+	for _, record := range records {
+		story := importedStory{Title: record[0]}
+		if len(record) > 1 {
+			story.Link = record[1]
+		}
+		stories = append(stories, story)
+	}
+	return stories, nil
+}
+
+// parseStoriesJSON reads an array of {"title", "link"} objects.
+func parseStoriesJSON(data []byte) ([]importedStory, error) {
+	var stories []importedStory
+	if err := json.Unmarshal(data, &stories); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return stories, nil
+}
+
+// importStories validates the rows and queues the good ones at the end of the
+// room's backlog. Stories already queued are rejected as duplicates.
+func importStories(room *roompkg.State, rows []importedStory) ImportResult {
+	room.Mu.Lock()
+	defer room.Mu.Unlock()
+
+	result := ImportResult{Rejected: []RejectedStory{}}
+	var accepted []roompkg.Story
+	queued := make(map[string]bool, len(room.Backlog)+len(rows))
+	for _, story := range room.Backlog {
+		queued[roompkg.StoryKey(&story)] = true
+	}
+	for i, row := range rows {
+		story, invalid := checkStory(row.Title, row.Link)
+		reason := ""
+		switch {
+		case invalid != nil:
+			reason = invalid.Error()
+		case story.Title == "":
+			reason = "title is required"
+		case queued[roompkg.StoryKey(&story)]:
+			reason = "already in the backlog"
+		case len(room.Backlog)+len(accepted) >= maxBacklogStories:
+			reason = fmt.Sprintf("the backlog is full at %d stories", maxBacklogStories)
+		}
+		if reason != "" {
+			result.Rejected = append(result.Rejected, RejectedStory{Row: i + 1, Reason: reason})
+			continue
+		}
+		queued[roompkg.StoryKey(&story)] = true
+		accepted = append(accepted, story)
+	}
+	if len(accepted) > 0 {
+		room.Apply(&roompkg.BacklogEvent{Stories: accepted})
+	}
+	result.Accepted = len(accepted)
+	result.Backlog = len(room.Backlog)
+	return result
+}
+
+// handleImportStories lets the facilitator queue stories ahead of the
+// session, given as "stories", an array of {title, link}, or as "csv" text.
+// The result is sent back as stories-imported.
+func (s *Server) handleImportStories(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.RLock()
+	isFacilitator := room.FacilitatorID == ws.ID
+	room.Mu.RUnlock()
+	if !isFacilitator {
+		s.logf(ws.Context(), "⚠️ Ignoring import-stories from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "import-stories", errCodeNotAllowed, "Only the facilitator can import stories", nil)
+		return
+	}
+
+	var rows []importedStory
+	var err error
+	if text, ok := data["csv"].(string); ok {
+		rows, err = parseStoriesCSV([]byte(text))
+	} else {
+		// Round-trip through JSON to reuse the HTTP endpoint's decoding
+		raw, _ := json.Marshal(data["stories"])
+		rows, err = parseStoriesJSON(raw)
+	}
+	if err != nil {
+		s.logf(ws.Context(), "⚠️ Ignoring import-stories from %s in room %s: %v", ws.ID, roomID, err)
+		s.sendToClient(ws, "stories-imported", ImportResult{Rejected: []RejectedStory{}, Error: err.Error()})
+		s.sendError(ws, "import-stories", errCodeInvalidInput, err.Error(), nil)
+		return
+	}
+
+	result := importStories(room, rows)
+	s.logf(ws.Context(), "📋 %s imported %d stories into room %s, %d rejected", ws.ID, result.Accepted, roomID, len(result.Rejected))
+	s.sendToClient(ws, "stories-imported", result)
+	if result.Accepted > 0 {
+		s.broadcastRoomState(ws.Context(), roomID)
+	}
+}
+
+// handleImportStoriesHTTP queues stories from a CSV body, when sent as
+// text/csv, or a JSON array. Like invites it needs the passcode of a private
+// room or the admin token.
+func (s *Server) handleImportStoriesHTTP(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("id")
+	room, ok := s.hub.Room(roomID)
+	if !ok {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	if !s.isAdminRequest(r) && !s.authorizeRoomRead(w, r, room.PasscodeHash()) {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "import is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	var rows []importedStory
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv") {
+		rows, err = parseStoriesCSV(body)
+	} else {
+		rows, err = parseStoriesJSON(body)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := importStories(room, rows)
+	s.logger.Printf("📋 %d stories imported into room %s over HTTP, %d rejected", result.Accepted, roomID, len(result.Rejected))
+	if result.Accepted > 0 {
+		s.broadcastRoomState(r.Context(), roomID)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleNextStory starts a new round on the first story of the backlog.
+func (s *Server) handleNextStory(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID || len(room.Backlog) == 0 {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring next-story from %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "next-story", errCodeNotAllowed, "Only the facilitator can start the next story of the backlog", nil)
+		return
+	}
+	story := room.Backlog[0]
+	room.Apply(&roompkg.TakeStoryEvent{})
+	room.Mu.Unlock()
+
+	s.enrichStory(ws.Context(), &story)
+	s.startStory(ws.Context(), room, &story)
+}
+
+// startStory clears the round and puts the story up for estimation.
+func (s *Server) startStory(ctx context.Context, room *roompkg.State, story *roompkg.Story) {
+	room.Mu.Lock()
+	room.Apply(&roompkg.StartStoryEvent{Story: story})
+	participants := s.getParticipantsArray(room)
+	room.Mu.Unlock()
+
+	s.broadcastToRoom(ctx, room.ID, "room-reset", map[string]interface{}{
+		"participants": participants,
+		"story":        story,
+	})
+	s.broadcastRoomState(ctx, room.ID)
+}
@@ -0,0 +1,123 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestImportStories(t *testing.T) {
+	room := &roompkg.State{ID: "room", Backlog: []roompkg.Story{{Title: "Existing"}}}
+	rows, err := parseStoriesCSV([]byte("title,link\nCheckout,https://example.com/1\n,https://example.com/2\nSearch,ftp://example.com\nExisting\nProfile\n"))
+	if err != nil {
+		t.Fatalf("parseStoriesCSV failed: %v", err)
+	}
+
+	result := importStories(room, rows)
+	if result.Accepted != 2 || result.Backlog != 3 {
+		t.Errorf("Expected 2 stories accepted into a backlog of 3, got %+v", result)
+	}
+	want := []RejectedStory{
+		{Row: 2, Reason: "title is required"},
+		{Row: 3, Reason: "link must be an http(s) URL"},
+		{Row: 4, Reason: "already in the backlog"},
+	}
+	if len(result.Rejected) != len(want) {
+		t.Fatalf("Expected %d rejected rows, got %+v", len(want), result.Rejected)
+	}
+	for i := range want {
+		if result.Rejected[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], result.Rejected[i])
+		}
+	}
+	if room.Backlog[1].Link != "https://example.com/1" || room.Backlog[2].Title != "Profile" {
+		t.Errorf("Unexpected backlog: %+v", room.Backlog)
+	}
+
+	if _, err := parseStoriesJSON([]byte(`{"title": "not an array"}`)); err == nil {
+		t.Error("Expected a JSON object to be rejected")
+	}
+}
+
+func TestImportStoriesHTTP(t *testing.T) {
+	server := New()
+	server.getOrCreateRoom("import-room")
+
+	post := func(roomID, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/rooms/"+roomID+"/stories/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("import-room", "application/json", `[{"title": "Checkout", "link": "https://example.com/1"}, {"title": ""}]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var result ImportResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || result.Accepted != 1 || len(result.Rejected) != 1 {
+		t.Errorf("Unexpected result: %+v, %v", result, err)
+	}
+
+	rec = post("import-room", "text/csv", "Search\nProfile,https://example.com/3\n")
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || result.Accepted != 2 || result.Backlog != 3 {
+		t.Errorf("Unexpected CSV result: %+v, %v", result, err)
+	}
+
+	if rec := post("import-room", "application/json", "not json"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unreadable import, got %d", rec.Code)
+	}
+	if rec := post("missing", "application/json", "[]"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing room, got %d", rec.Code)
+	}
+}
+
+func TestImportStoriesAndNextStory(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "backlog-room"
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "import-stories", map[string]interface{}{
+		"roomId":  roomID,
+		"stories": []map[string]interface{}{{"title": "Checkout"}, {"title": "Search"}},
+	})
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "stories-imported" {
+		t.Fatalf("Expected stories-imported, got %s", msg.Type)
+	}
+	if data, _ := msg.Data.(map[string]interface{}); data["accepted"] != float64(2) {
+		t.Errorf("Expected 2 stories accepted, got %v", data)
+	}
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "next-story", map[string]interface{}{"roomId": roomID})
+	msg = readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-reset" {
+		t.Fatalf("Expected room-reset, got %s", msg.Type)
+	}
+	data, _ := msg.Data.(map[string]interface{})
+	if story, _ := data["story"].(map[string]interface{}); story["title"] != "Checkout" {
+		t.Errorf("Expected the first story to be up next, got %v", data["story"])
+	}
+	msg = readMessage(t, ws, 2*time.Second)
+	if data, _ := msg.Data.(map[string]interface{}); len(data["backlog"].([]interface{})) != 1 {
+		t.Errorf("Expected one story left in the backlog, got %v", data["backlog"])
+	}
+
+	sendMessage(t, ws, "import-stories", map[string]interface{}{"roomId": roomID, "csv": "\"unterminated"})
+	msg = readMessage(t, ws, 2*time.Second)
+	if data, _ := msg.Data.(map[string]interface{}); msg.Type != "stories-imported" || data["error"] == nil {
+		t.Errorf("Expected the import to fail with an error, got %s %v", msg.Type, msg.Data)
+	}
+}
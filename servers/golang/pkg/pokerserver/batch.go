@@ -0,0 +1,120 @@
+package pokerserver
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// handleOpenBatch lets the facilitator put several stories up for estimation
+// at once. It starts the round over, and only works with story points.
+func (s *Server) handleOpenBatch(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	rawStories, _ := data["stories"].([]interface{})
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	if len(rawStories) == 0 || len(rawStories) > roompkg.MaxBatchStories {
+		s.logf(ws.Context(), "⚠️ Ignoring open-batch from %s in room %s: expected 1 to %d stories", ws.ID, roomID, roompkg.MaxBatchStories)
+		s.sendError(ws, "open-batch", errCodeInvalidInput, "stories must hold 1 to "+strconv.Itoa(roompkg.MaxBatchStories)+" stories", nil)
+		return
+	}
+	batch := make([]roompkg.BatchStory, 0, len(rawStories))
+	for i, raw := range rawStories {
+		storyData, _ := raw.(map[string]interface{})
+		title, _ := storyData["title"].(string)
+		link, _ := storyData["link"].(string)
+		story, invalid := checkStory(title, link)
+		if invalid != nil {
+			invalid.Field = "stories[" + strconv.Itoa(i) + "]." + invalid.Field
+			s.sendInputError(ws, "open-batch", invalid)
+			return
+		}
+		s.enrichStory(ws.Context(), &story)
+		batch = append(batch, roompkg.BatchStory{ID: strconv.Itoa(i + 1), Story: story})
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID || room.Settings.Mode != roompkg.ModePoints {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring open-batch from %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "open-batch", errCodeNotAllowed, "Only the facilitator can open a batch, in points mode", nil)
+		return
+	}
+	room.Apply(&roompkg.BatchEvent{Batch: batch})
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "📚 %s opened %d stories for estimation in room %s", ws.ID, len(batch), roomID)
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+// voteBatch records a vote on one story of the batch. An empty vote takes it
+// back. Stories already revealed keep their votes.
+func (s *Server) voteBatch(ws *ExtendedWebSocket, room *roompkg.State, storyID, vote string) {
+	room.Mu.Lock()
+	_, ok := room.Participants[ws.ID]
+	index := roompkg.BatchIndex(room, storyID)
+	if !ok || index < 0 || room.Batch[index].Revealed {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring vote on story %q from %s in room %s", storyID, ws.ID, room.ID)
+		s.sendError(ws, "vote", errCodeInvalidInput, "storyId must name a story of the batch yet to be revealed", nil)
+		return
+	}
+	if !room.Settings.AcceptsVote(vote) {
+		room.Mu.Unlock()
+		s.sendInputError(ws, "vote", &inputError{Field: "vote", Reason: reasonNotInDeck})
+		return
+	}
+	room.Apply(&roompkg.BatchVoteEvent{ClientID: ws.ID, StoryID: storyID, Vote: vote})
+	if vote != "" {
+		room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditVoted, ActorID: ws.ID, Story: room.Batch[index].Story.Title})
+	}
+	completion := roompkg.BatchState(room)[index]
+	room.Mu.Unlock()
+
+	s.broadcastToRoom(ws.Context(), room.ID, "participant-voted", map[string]interface{}{
+		"id":      ws.ID,
+		"storyId": storyID,
+		"hasVote": vote != "",
+		"votes":   completion.Votes,
+	})
+}
+
+// revealBatch reveals one story of the batch, or all that are still hidden
+// when storyID is empty, recording a round for each.
+func (s *Server) revealBatch(ctx context.Context, room *roompkg.State, storyID, actorID string) {
+	room.Mu.Lock()
+	var storyIDs []string
+	for _, story := range room.Batch {
+		if !story.Revealed && (storyID == "" || story.ID == storyID) {
+			storyIDs = append(storyIDs, story.ID)
+		}
+	}
+	if len(storyIDs) == 0 {
+		room.Mu.Unlock()
+		return
+	}
+	room.Apply(&roompkg.BatchRevealEvent{StoryIDs: storyIDs})
+	// The rounds are the last ones recorded, one per story
+	rounds := slices.Clone(room.History[len(room.History)-len(storyIDs):])
+	for _, record := range rounds {
+		room.AuditReveal(actorID, record)
+	}
+	session := room.Session()
+	batch := roompkg.BatchState(room)
+	room.Mu.Unlock()
+
+	for _, round := range rounds {
+		s.saveRound(session, round)
+	}
+	s.broadcastToRoom(ctx, room.ID, "batch-revealed", map[string]interface{}{
+		"rounds": rounds,
+		"batch":  batch,
+	})
+}
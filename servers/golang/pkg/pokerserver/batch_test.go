@@ -0,0 +1,63 @@
+package pokerserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatchEstimation(t *testing.T) {
+	server := New()
+	httpServer, alice := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer alice.Close()
+	_, bob := createTestWSConnection(t, server)
+	defer bob.Close()
+
+	roomID := "batch-room"
+
+	sendMessage(t, alice, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, alice, 2*time.Second) // room-state
+	sendMessage(t, bob, "join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	readRoomStateUntil(t, alice, func(participants []interface{}) bool { return len(participants) == 2 })
+
+	sendMessage(t, alice, "open-batch", map[string]interface{}{
+		"roomId":  roomID,
+		"stories": []interface{}{map[string]interface{}{"title": "Search"}, map[string]interface{}{"title": "Checkout"}},
+	})
+	state := readUntilType(t, alice, "room-state").Data.(map[string]interface{})
+	if batch, _ := state["batch"].([]interface{}); len(batch) != 2 {
+		t.Fatalf("Expected two stories in the batch, got %v", state["batch"])
+	}
+
+	sendMessage(t, alice, "vote", map[string]interface{}{"roomId": roomID, "storyId": "1", "vote": "3"})
+	readUntilType(t, alice, "participant-voted")
+	sendMessage(t, alice, "vote", map[string]interface{}{"roomId": roomID, "storyId": "2", "vote": "8"})
+	readUntilType(t, alice, "participant-voted")
+	sendMessage(t, bob, "vote", map[string]interface{}{"roomId": roomID, "storyId": "1", "vote": "5"})
+	voted := readUntilType(t, alice, "participant-voted").Data.(map[string]interface{})
+	if voted["storyId"] != "1" || voted["votes"] != float64(2) {
+		t.Errorf("Expected two votes on the first story, got %v", voted)
+	}
+
+	// One story at a time, then the rest together
+	sendMessage(t, alice, "reveal", map[string]interface{}{"roomId": roomID, "storyId": "1"})
+	revealed := readUntilType(t, alice, "batch-revealed").Data.(map[string]interface{})
+	rounds := revealed["rounds"].([]interface{})
+	first := rounds[0].(map[string]interface{})
+	if len(rounds) != 1 || first["story"].(map[string]interface{})["title"] != "Search" ||
+		first["stats"].(map[string]interface{})["average"] != float64(4) {
+		t.Errorf("Expected the first story to be revealed alone, got %v", rounds)
+	}
+
+	sendMessage(t, alice, "reveal", map[string]interface{}{"roomId": roomID})
+	revealed = readUntilType(t, alice, "batch-revealed").Data.(map[string]interface{})
+	rounds = revealed["rounds"].([]interface{})
+	if len(rounds) != 1 || rounds[0].(map[string]interface{})["story"].(map[string]interface{})["title"] != "Checkout" {
+		t.Errorf("Expected the remaining story to be revealed, got %v", rounds)
+	}
+
+	history, _, _ := server.roomHistory(t.Context(), roomID)
+	if len(history) != 2 {
+		t.Errorf("Expected a round per story in the history, got %d", len(history))
+	}
+}
@@ -0,0 +1,113 @@
+package pokerserver
+
+import (
+	"context"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/hub"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BrokerMessage is a broadcast relayed between server instances. An empty
+// RoomID addresses every connected client.
+type BrokerMessage = hub.BrokerMessage
+
+// Broker fans room broadcasts out to other server instances. The Redis
+// implementation is used when REDIS_URL is set and no broker is injected, a
+// MemoryBroker otherwise.
+type Broker = hub.Broker
+
+// MemoryBroker is a Broker within one process. A server without Redis uses
+// its own, and tests can hand one to several servers to run them as a
+// cluster.
+type MemoryBroker = hub.MemoryBroker
+
+func NewMemoryBroker() *MemoryBroker {
+	return hub.NewMemoryBroker()
+}
+
+func (s *Server) subscribeToBroker() {
+	if s.broker == nil {
+		return
+	}
+
+	err := s.broker.Subscribe(s.ctx, func(msg BrokerMessage) {
+		// Our own publishes were already delivered locally
+		if msg.Origin == s.instanceID {
+			return
+		}
+
+		ctx := brokerPropagator.Extract(s.ctx, propagation.MapCarrier(msg.TraceContext))
+		ctx, span := s.tracer.Start(ctx, "broker.receive",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(attrMessageType.String(msg.Type), attrRoomID.String(msg.RoomID)),
+		)
+		defer span.End()
+
+		switch {
+		case msg.Type == hub.RoomHandoffMessage:
+			s.handleRoomHandoff(ctx, msg.RoomID)
+		case msg.Target == s.instanceID:
+			s.handleRelay(ctx, msg)
+		case msg.Target != "":
+			// Relayed between two other instances
+		case msg.RoomID == "":
+			s.broadcastToAll(ctx, msg.Type, msg.Data)
+		default:
+			s.broadcastToRoom(ctx, msg.RoomID, msg.Type, msg.Data, msg.ExcludeID)
+		}
+	})
+	if err != nil {
+		s.logger.Printf("Broker subscription failed: %v", err)
+		s.reportError(s.ctx, ErrorEvent{Err: err, Source: ErrorSourceRedis})
+	}
+}
+
+// singleInstance reports whether no other instance can hold rooms: there's no
+// broker, or this server is the only one subscribed to its in-memory broker.
+func (s *Server) singleInstance() bool {
+	if memory, ok := s.broker.(*MemoryBroker); ok {
+		return memory.Subscribers() <= 1
+	}
+	return s.broker == nil
+}
+
+func (s *Server) publishToBroker(ctx context.Context, roomID string, msgType string, data interface{}, excludeID string) {
+	if s.broker == nil {
+		return
+	}
+
+	err := s.publish(ctx, BrokerMessage{
+		Type:      msgType,
+		RoomID:    roomID,
+		Data:      data,
+		ExcludeID: excludeID,
+	})
+	if err != nil {
+		s.logf(ctx, "Error publishing to broker: %v", err)
+		s.reportError(ctx, ErrorEvent{Err: err, Source: ErrorSourceRedis, RoomID: roomID})
+	}
+}
+
+// publish sends msg from this instance, carrying the current trace.
+func (s *Server) publish(ctx context.Context, msg BrokerMessage) error {
+	ctx, span := s.tracer.Start(ctx, "broker.publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attrMessageType.String(msg.Type), attrRoomID.String(msg.RoomID)),
+	)
+	defer span.End()
+
+	msg.Origin = s.instanceID
+	msg.TraceContext = make(map[string]string)
+	brokerPropagator.Inject(ctx, propagation.MapCarrier(msg.TraceContext))
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+	if err := s.broker.Publish(ctx, msg); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "publish failed")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package pokerserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ipRanges is a list of CIDR ranges; single addresses match only themselves.
+type ipRanges []netip.Prefix
+
+// parseIPRanges parses CIDRs or addresses. Invalid entries are skipped and
+// reported in the error.
+func parseIPRanges(entries []string) (ipRanges, error) {
+	var ranges ipRanges
+	var invalid []string
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			ranges = append(ranges, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			ranges = append(ranges, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		invalid = append(invalid, strconv.Quote(entry))
+	}
+	if len(invalid) > 0 {
+		return ranges, fmt.Errorf("invalid IP ranges %s", strings.Join(invalid, ", "))
+	}
+	return ranges, nil
+}
+
+func (ranges ipRanges) contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range ranges {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipAllowed applies the IP_DENYLIST and IP_ALLOWLIST ranges. Denials win, and
+// an empty allow list admits everyone else.
+func (s *Server) ipAllowed(ip string) bool {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+
+	if s.ipDeny.contains(ip) {
+		return false
+	}
+	return len(s.ipAllow) == 0 || s.ipAllow.contains(ip)
+}
+
+func (s *Server) isTrustedProxy(ip string) bool {
+	return s.trustProxy || s.trustedProxies.contains(ip)
+}
+
+// clientIP returns the address of the client behind the request. Forwarding
+// headers are only believed when they come from a trusted proxy: walking
+// X-Forwarded-For from the nearest hop, the first untrusted address is the
+// client. X-Real-IP is used when a trusted proxy sends no X-Forwarded-For.
+func (s *Server) clientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !s.isTrustedProxy(peer) {
+		return peer
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := splitAndTrim(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			if i == 0 || !s.isTrustedProxy(hops[i]) {
+				return hops[i]
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return peer
+}
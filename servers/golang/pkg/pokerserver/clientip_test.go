@@ -0,0 +1,123 @@
+package pokerserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{
+			name:       "untrusted peer",
+			opts:       []Option{WithTrustProxy(false)},
+			remoteAddr: "192.0.2.10:5123",
+			forwarded:  "203.0.113.7",
+			want:       "192.0.2.10",
+		},
+		{
+			name:       "trust all",
+			opts:       []Option{WithTrustProxy(true)},
+			remoteAddr: "192.0.2.10:5123",
+			forwarded:  "203.0.113.7, 198.51.100.2",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy chain",
+			opts:       []Option{WithTrustedProxies("10.0.0.0/8")},
+			remoteAddr: "10.0.0.5:5123",
+			forwarded:  "198.51.100.99, 203.0.113.7, 10.1.2.3",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "peer is not a trusted proxy",
+			opts:       []Option{WithTrustedProxies("10.0.0.0/8")},
+			remoteAddr: "192.0.2.10:5123",
+			forwarded:  "203.0.113.7",
+			want:       "192.0.2.10",
+		},
+		{
+			name:       "X-Real-IP",
+			opts:       []Option{WithTrustedProxies("10.0.0.5")},
+			remoteAddr: "10.0.0.5:5123",
+			realIP:     "203.0.113.7",
+			want:       "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/ws", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if ip := New(tt.opts...).clientIP(req); ip != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, ip)
+			}
+		})
+	}
+}
+
+func TestIPFilter(t *testing.T) {
+	server := New(WithIPFilter([]string{"10.0.0.0/8", "192.0.2.1"}, []string{"10.9.0.0/16"}))
+
+	for ip, want := range map[string]bool{
+		"10.1.2.3":        true,
+		"192.0.2.1":       true,
+		"::ffff:10.1.2.3": true,
+		"10.9.1.1":        false,
+		"203.0.113.7":     false,
+		"not-an-ip":       false,
+	} {
+		if got := server.ipAllowed(ip); got != want {
+			t.Errorf("ipAllowed(%s) = %v, want %v", ip, got, want)
+		}
+	}
+
+	if !New(WithIPFilter(nil, nil)).ipAllowed("203.0.113.7") {
+		t.Error("Expected everyone to be allowed without lists")
+	}
+}
+
+func TestDeniedIPCannotConnect(t *testing.T) {
+	server := New(WithIPFilter(nil, []string{"127.0.0.1", "::1"}))
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/api/ws"
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatal("Expected connection from a denied address to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403, got %v", resp)
+	}
+}
+
+func TestParseIPRanges(t *testing.T) {
+	ranges, err := parseIPRanges([]string{"10.0.0.0/8", " 2001:db8::/32", "192.0.2.1", "bogus"})
+	if err == nil {
+		t.Error("Expected an error for the invalid entry")
+	}
+	if len(ranges) != 3 {
+		t.Fatalf("Expected invalid entries to be skipped, got %v", ranges)
+	}
+	if !ranges.contains("2001:db8::1") || ranges.contains("192.0.2.2") {
+		t.Errorf("Unexpected matches for %v", ranges)
+	}
+}
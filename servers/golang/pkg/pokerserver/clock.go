@@ -0,0 +1,27 @@
+package pokerserver
+
+import (
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// Clock tells the time and runs the timers behind heartbeats, reconnect grace
+// periods, async round deadlines and expiring invites. Servers use the real
+// clock unless given another with WithClock.
+type Clock = roompkg.Clock
+
+// Timer is a pending AfterFunc call.
+type Timer = roompkg.Timer
+
+// Ticker delivers ticks on C at a fixed interval.
+type Ticker = roompkg.Ticker
+
+// FakeClock is a Clock that only moves when told to, so tests can step through
+// timers without sleeping. Timer functions run synchronously in Advance, in
+// the order they are due.
+type FakeClock = roompkg.FakeClock
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return roompkg.NewFakeClock(now)
+}
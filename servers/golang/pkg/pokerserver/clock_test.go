@@ -0,0 +1,90 @@
+package pokerserver
+
+import (
+	"testing"
+	"time"
+)
+
+// awaitMessage skips a memory client's messages until one of msgType arrives.
+func awaitMessage(t *testing.T, c *MemoryClient, msgType string) WebSocketMessage {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case msg := <-c.Messages():
+			if msg.Type == msgType {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %s", msgType)
+		}
+	}
+}
+
+func TestGracePeriodWithFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cfg := DefaultConfig()
+	cfg.GracePeriod = time.Minute
+	server := New(WithConfig(cfg), WithClock(clock))
+	roomID := "clock-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	bob.Close()
+	room, _ := server.hub.Room(roomID)
+	participants := func() int {
+		room.Mu.RLock()
+		defer room.Mu.RUnlock()
+		return len(room.Participants)
+	}
+
+	clock.Advance(59 * time.Second)
+	if participants() != 2 {
+		t.Fatalf("Expected Bob kept within the grace period, got %d participants", participants())
+	}
+	clock.Advance(time.Second)
+	if participants() != 1 {
+		t.Fatalf("Expected Bob purged after the grace period, got %d participants", participants())
+	}
+}
+
+func TestAsyncRoundDeadlineWithFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	server := New(WithClock(clock))
+	roomID := "clock-async-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+
+	alice.Send("open-async-round", map[string]interface{}{
+		"roomId":   roomID,
+		"deadline": clock.Now().Add(24 * time.Hour).UnixMilli(),
+	})
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "3"})
+
+	clock.Advance(23 * time.Hour)
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	revealed := room.Revealed
+	room.Mu.RUnlock()
+	if revealed {
+		t.Fatal("Expected the round open before its deadline")
+	}
+
+	clock.Advance(time.Hour)
+	data := awaitMessage(t, alice, "revealed").Data.(map[string]interface{})
+	if stats := data["stats"].(map[string]interface{}); stats["voteCount"] != float64(1) {
+		t.Errorf("Expected the vote revealed at the deadline, got %v", stats)
+	}
+	room.Mu.RLock()
+	duration := room.History[0].DurationMs
+	room.Mu.RUnlock()
+	if duration != (24 * time.Hour).Milliseconds() {
+		t.Errorf("Expected the round to last a day on the fake clock, got %dms", duration)
+	}
+}
@@ -0,0 +1,933 @@
+package pokerserver
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/hub"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the server settings. LoadConfig reads it from an optional YAML
+// or TOML file, with environment variables taking precedence over the file.
+type Config struct {
+	Port           string   `yaml:"port" toml:"port"`
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins"`
+	RedisURL       string   `yaml:"redis_url" toml:"redis_url"`
+	// RedisEncryptionKey, a base64-encoded 32-byte key, encrypts the room
+	// state and broadcasts kept in and relayed through Redis
+	RedisEncryptionKey string        `yaml:"redis_encryption_key" toml:"redis_encryption_key"`
+	DatabaseURL        string        `yaml:"database_url" toml:"database_url"`
+	GracePeriod        time.Duration `yaml:"participant_grace_period" toml:"participant_grace_period"`
+	// AwayAfter pauses participants who sent nothing for this long, so they
+	// don't hold up reveals; 0 disables it
+	AwayAfter           time.Duration       `yaml:"away_after" toml:"away_after"`
+	DuplicateNamePolicy DuplicateNamePolicy `yaml:"duplicate_name_policy" toml:"duplicate_name_policy"`
+	AdminToken          string              `yaml:"admin_token" toml:"admin_token"`
+	// TeamTokens are the tokens clients and admin requests of each team
+	// present to be scoped to the team, by team ID
+	TeamTokens map[string]string `yaml:"team_tokens" toml:"team_tokens"`
+	// APIKeysFile holds the API keys required to create rooms, export them
+	// and use the admin API; keys are only required when it is set
+	APIKeysFile     string `yaml:"api_keys_file" toml:"api_keys_file"`
+	APIKeyRateLimit int    `yaml:"api_key_rate_limit" toml:"api_key_rate_limit"`
+	SocketIO        bool   `yaml:"socketio_enabled" toml:"socketio_enabled"`
+	// ShowLatency adds each participant's connection round trip to the
+	// room state, not just the admin API
+	ShowLatency bool `yaml:"show_latency" toml:"show_latency"`
+	// ParticipantStats serves how each participant's votes compare with
+	// their team's final estimates. It names people, so teams opt in by
+	// turning it on
+	ParticipantStats bool `yaml:"participant_stats" toml:"participant_stats"`
+	// SentryDSN reports handler panics, Redis failures and webhook delivery
+	// errors to Sentry
+	SentryDSN           string        `yaml:"sentry_dsn" toml:"sentry_dsn"`
+	InviteSecret        string        `yaml:"invite_secret" toml:"invite_secret"`
+	MaxConnections      int           `yaml:"max_connections" toml:"max_connections"`
+	MaxConnectionsPerIP int           `yaml:"max_connections_per_ip" toml:"max_connections_per_ip"`
+	MessageRateLimit    int           `yaml:"message_rate_limit" toml:"message_rate_limit"`
+	MessageRateWindow   time.Duration `yaml:"message_rate_window" toml:"message_rate_window"`
+	// HTTPLogSampleRate is the share of successful HTTP requests logged, from
+	// 0 to 1; failed requests are always logged
+	HTTPLogSampleRate float64 `yaml:"http_log_sample_rate" toml:"http_log_sample_rate"`
+	// TrustProxy trusts forwarding headers from every peer; TrustedProxies
+	// trusts only the listed addresses and CIDRs
+	TrustProxy     bool     `yaml:"trust_proxy" toml:"trust_proxy"`
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+	IPAllowlist    []string `yaml:"ip_allowlist" toml:"ip_allowlist"`
+	IPDenylist     []string `yaml:"ip_denylist" toml:"ip_denylist"`
+	// Each client's messages wait in a buffer of SendBufferSize for one of
+	// BroadcastWorkers to write them; SlowClientPolicy decides what happens
+	// to a client whose buffer is full
+	BroadcastWorkers int              `yaml:"broadcast_workers" toml:"broadcast_workers"`
+	SendBufferSize   int              `yaml:"send_buffer_size" toml:"send_buffer_size"`
+	SlowClientPolicy SlowClientPolicy `yaml:"slow_client_policy" toml:"slow_client_policy"`
+	// RoomMetrics decides whether the room gauges are totals or labelled by
+	// room, in which case RoomMetricsLimit rooms are labelled at most
+	RoomMetrics      RoomMetricsMode `yaml:"room_metrics" toml:"room_metrics"`
+	RoomMetricsLimit int             `yaml:"room_metrics_limit" toml:"room_metrics_limit"`
+	// Storage picks where sessions are recorded: "postgres" (DatabaseURL) or
+	// "sqlite" (SQLitePath). Left empty, Postgres is used when DatabaseURL is set
+	Storage    string `yaml:"storage" toml:"storage"`
+	SQLitePath string `yaml:"sqlite_path" toml:"sqlite_path"`
+	// RoomLeaseTTL is how long an instance owns a room without renewing,
+	// when instances share Redis
+	RoomLeaseTTL time.Duration `yaml:"room_lease_ttl" toml:"room_lease_ttl"`
+	// Rooms are snapshotted to SnapshotFile or, using RedisURL, to a hash at
+	// SnapshotRedisKey with a field per room, and restored on startup
+	SnapshotFile     string            `yaml:"snapshot_file" toml:"snapshot_file"`
+	SnapshotRedisKey string            `yaml:"snapshot_redis_key" toml:"snapshot_redis_key"`
+	SnapshotInterval time.Duration     `yaml:"snapshot_interval" toml:"snapshot_interval"`
+	Jira             JiraConfig        `yaml:"jira" toml:"jira"`
+	Linear           LinearConfig      `yaml:"linear" toml:"linear"`
+	GitLab           GitLabConfig      `yaml:"gitlab" toml:"gitlab"`
+	LinkPreviews     LinkPreviewConfig `yaml:"link_previews" toml:"link_previews"`
+	// Outbound limits the requests made to trackers, webhooks and story links
+	Outbound OutboundConfig `yaml:"outbound" toml:"outbound"`
+	TLS      TLSConfig      `yaml:"tls" toml:"tls"`
+	// Webhooks are told about room events, such as async rounds closing
+	Webhooks  WebhooksConfig  `yaml:"webhooks" toml:"webhooks"`
+	RoomCodes RoomCodesConfig `yaml:"room_codes" toml:"room_codes"`
+	OIDC      OIDCConfig      `yaml:"oidc" toml:"oidc"`
+}
+
+// JiraConfig enables story enrichment and estimate sync when BaseURL and
+// APIToken are set.
+type JiraConfig struct {
+	BaseURL          string `yaml:"base_url" toml:"base_url"`
+	Email            string `yaml:"email" toml:"email"`
+	APIToken         string `yaml:"api_token" toml:"api_token"`
+	StoryPointsField string `yaml:"story_points_field" toml:"story_points_field"`
+}
+
+// LinearConfig enables story enrichment and estimate sync for Linear issue
+// links when APIKey is set.
+type LinearConfig struct {
+	APIKey string `yaml:"api_key" toml:"api_key"`
+}
+
+// GitLabConfig enables story enrichment for GitLab issue links when Token is
+// set. BaseURL defaults to gitlab.com.
+type GitLabConfig struct {
+	BaseURL string `yaml:"base_url" toml:"base_url"`
+	Token   string `yaml:"token" toml:"token"`
+}
+
+// LinkPreviewConfig enables previews of story links to pages on Domains and
+// their subdomains.
+type LinkPreviewConfig struct {
+	Domains []string `yaml:"domains" toml:"domains"`
+}
+
+// OutboundConfig bounds the server's own HTTP requests. They only reach public
+// addresses, or those of AllowedNetworks, such as a self-hosted Jira's, and
+// give up after Timeout or MaxRedirects redirects.
+type OutboundConfig struct {
+	AllowedNetworks []string      `yaml:"allowed_networks" toml:"allowed_networks"`
+	Timeout         time.Duration `yaml:"timeout" toml:"timeout"`
+	MaxRedirects    int           `yaml:"max_redirects" toml:"max_redirects"`
+}
+
+// WebhooksConfig lists where room events are posted: URLs receive each
+// event as JSON and SlackURL, a Slack incoming webhook, a message about it.
+// TeamsURL, a Microsoft Teams incoming webhook, gets adaptive cards about
+// revealed rounds and final estimates. Templates post to any other service in
+// the format it expects.
+type WebhooksConfig struct {
+	URLs      []string          `yaml:"urls" toml:"urls"`
+	SlackURL  string            `yaml:"slack_url" toml:"slack_url"`
+	TeamsURL  string            `yaml:"teams_url" toml:"teams_url"`
+	Templates []WebhookTemplate `yaml:"templates" toml:"templates"`
+}
+
+// WebhookTemplate posts events to URL with a body rendered from Body, a Go
+// text/template executed on the Event. ContentType defaults to JSON, and
+// Events, when set, limits the destination to those event types.
+type WebhookTemplate struct {
+	URL         string   `yaml:"url" toml:"url"`
+	Body        string   `yaml:"body" toml:"body"`
+	ContentType string   `yaml:"content_type" toml:"content_type"`
+	Events      []string `yaml:"events" toml:"events"`
+}
+
+// RoomCodesConfig shapes the codes POST /api/rooms hands out, such as
+// "brave-otter-42": an adjective, a noun and SuffixLength characters from
+// Alphabet. The built-in word lists are used unless either list is set, and
+// codes containing a Reserved word, or one of the built-in ones, are skipped.
+type RoomCodesConfig struct {
+	Adjectives   []string `yaml:"adjectives" toml:"adjectives"`
+	Nouns        []string `yaml:"nouns" toml:"nouns"`
+	Alphabet     string   `yaml:"alphabet" toml:"alphabet"`
+	SuffixLength int      `yaml:"suffix_length" toml:"suffix_length"`
+	Reserved     []string `yaml:"reserved" toml:"reserved"`
+}
+
+// OIDCConfig enables single sign-on with an OpenID Connect provider when
+// Issuer is set. Logging in at /auth/login issues a session token clients
+// connect with, naming them after NameClaim and scoping them to the team in
+// TeamClaim. Required refuses connections without a session.
+type OIDCConfig struct {
+	Issuer       string `yaml:"issuer" toml:"issuer"`
+	ClientID     string `yaml:"client_id" toml:"client_id"`
+	ClientSecret string `yaml:"client_secret" toml:"client_secret"`
+	// RedirectURL is this server's /auth/callback as registered with the
+	// provider
+	RedirectURL string   `yaml:"redirect_url" toml:"redirect_url"`
+	Scopes      []string `yaml:"scopes" toml:"scopes"`
+	NameClaim   string   `yaml:"name_claim" toml:"name_claim"`
+	TeamClaim   string   `yaml:"team_claim" toml:"team_claim"`
+	Required    bool     `yaml:"required" toml:"required"`
+	// SessionSecret signs session tokens; instances sharing clients need the
+	// same one
+	SessionSecret string        `yaml:"session_secret" toml:"session_secret"`
+	SessionTTL    time.Duration `yaml:"session_ttl" toml:"session_ttl"`
+}
+
+// Enabled reports whether single sign-on is configured.
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != ""
+}
+
+// TLSConfig makes the standalone binary serve HTTPS and WSS itself, from
+// certificate files or with certificates obtained from Let's Encrypt for
+// AutocertHosts.
+type TLSConfig struct {
+	CertFile         string   `yaml:"cert_file" toml:"cert_file"`
+	KeyFile          string   `yaml:"key_file" toml:"key_file"`
+	AutocertHosts    []string `yaml:"autocert_hosts" toml:"autocert_hosts"`
+	AutocertEmail    string   `yaml:"autocert_email" toml:"autocert_email"`
+	AutocertCacheDir string   `yaml:"autocert_cache_dir" toml:"autocert_cache_dir"`
+	// HTTPPort answers ACME HTTP-01 challenges and redirects to HTTPS
+	HTTPPort string `yaml:"http_port" toml:"http_port"`
+}
+
+// Enabled reports whether TLS is configured.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || len(c.AutocertHosts) > 0
+}
+
+// Storage backends.
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+)
+
+// storageBackend returns the configured backend, or "" when sessions aren't
+// recorded.
+func (c Config) storageBackend() string {
+	if c.Storage == "" && c.DatabaseURL != "" {
+		return StoragePostgres
+	}
+	return c.Storage
+}
+
+// DefaultConfig returns the settings used when nothing is configured.
+func DefaultConfig() Config {
+	return Config{
+		Port:                "3001",
+		AllowedOrigins:      []string{"http://localhost:3000", "https://localhost:3000"},
+		GracePeriod:         5 * time.Minute,
+		DuplicateNamePolicy: DuplicateNameSuffix,
+		APIKeyRateLimit:     defaultAPIKeyRateLimit,
+		MaxConnections:      10000,
+		MaxConnectionsPerIP: 100,
+		MessageRateLimit:    defaultMessageLimit,
+		MessageRateWindow:   defaultMessageWindow,
+		HTTPLogSampleRate:   1,
+		BroadcastWorkers:    defaultBroadcastWorkers,
+		SendBufferSize:      defaultSendBufferSize,
+		SlowClientPolicy:    SlowClientEvict,
+		RoomMetrics:         RoomMetricsAggregate,
+		RoomMetricsLimit:    defaultRoomMetricsLimit,
+		SQLitePath:          "planning-poker.db",
+		SnapshotInterval:    30 * time.Second,
+		RoomLeaseTTL:        hub.DefaultRoomLeaseTTL,
+		Jira:                JiraConfig{StoryPointsField: defaultStoryPointsField},
+		Outbound:            OutboundConfig{Timeout: defaultOutboundTimeout, MaxRedirects: defaultOutboundMaxRedirects},
+		TLS:                 TLSConfig{AutocertCacheDir: "autocert-cache", HTTPPort: "80"},
+		RoomCodes:           RoomCodesConfig{Alphabet: defaultRoomCodeChars, SuffixLength: 2},
+		OIDC:                OIDCConfig{Scopes: []string{"openid", "profile", "email"}, NameClaim: "name", SessionTTL: 12 * time.Hour},
+	}
+}
+
+// LoadConfig builds the configuration from the defaults, the file at path
+// (skipped when empty) and the environment, then validates it.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := errors.Join(cfg.applyEnv(), cfg.Validate()); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// loadFile decodes a YAML or TOML file, chosen by extension. Unknown keys are
+// rejected so typos don't go unnoticed.
+func (c *Config) loadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(content), c)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("parsing %s: unknown key %q", path, undecoded[0].String())
+		}
+	default:
+		return fmt.Errorf("unsupported config file extension %q, use .yaml, .yml or .toml", ext)
+	}
+	return nil
+}
+
+// applyEnv overrides settings with the environment variables that are set.
+// Values that don't parse are reported together and leave the setting as is.
+func (c *Config) applyEnv() error {
+	var errs []error
+	setString := func(key string, dst *string) {
+		if value := os.Getenv(key); value != "" {
+			*dst = value
+		}
+	}
+	setList := func(key string, dst *[]string) {
+		if value := os.Getenv(key); value != "" {
+			*dst = splitAndTrim(value, ",")
+		}
+	}
+	setInt := func(key string, dst *int) {
+		if value := os.Getenv(key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid number %q", key, value))
+				return
+			}
+			*dst = n
+		}
+	}
+	setDuration := func(key string, dst *time.Duration) {
+		if value := os.Getenv(key); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid duration %q", key, value))
+				return
+			}
+			*dst = d
+		}
+	}
+	setFloat := func(key string, dst *float64) {
+		if value := os.Getenv(key); value != "" {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid number %q", key, value))
+				return
+			}
+			*dst = f
+		}
+	}
+	setBool := func(key string, dst *bool) {
+		if value := os.Getenv(key); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid boolean %q", key, value))
+				return
+			}
+			*dst = b
+		}
+	}
+
+	setString("PORT", &c.Port)
+	setList("ALLOWED_ORIGINS", &c.AllowedOrigins)
+	setString("REDIS_URL", &c.RedisURL)
+	setString("REDIS_ENCRYPTION_KEY", &c.RedisEncryptionKey)
+	setString("DATABASE_URL", &c.DatabaseURL)
+	setString("STORAGE", &c.Storage)
+	setString("SQLITE_PATH", &c.SQLitePath)
+	setDuration("ROOM_LEASE_TTL", &c.RoomLeaseTTL)
+	setDuration("PARTICIPANT_GRACE_PERIOD", &c.GracePeriod)
+	setDuration("AWAY_AFTER", &c.AwayAfter)
+	if value := os.Getenv("DUPLICATE_NAME_POLICY"); value != "" {
+		c.DuplicateNamePolicy = DuplicateNamePolicy(value)
+	}
+	setString("ADMIN_TOKEN", &c.AdminToken)
+	// TEAM_TOKENS lists team=token pairs
+	if value := os.Getenv("TEAM_TOKENS"); value != "" {
+		c.TeamTokens = make(map[string]string)
+		for _, pair := range splitAndTrim(value, ",") {
+			team, token, ok := strings.Cut(pair, "=")
+			if !ok {
+				errs = append(errs, fmt.Errorf("TEAM_TOKENS: expected team=token, got %q", redact(pair)))
+				continue
+			}
+			c.TeamTokens[strings.TrimSpace(team)] = strings.TrimSpace(token)
+		}
+	}
+	setString("API_KEYS_FILE", &c.APIKeysFile)
+	setInt("API_KEY_RATE_LIMIT", &c.APIKeyRateLimit)
+	setBool("SOCKETIO_ENABLED", &c.SocketIO)
+	setBool("SHOW_LATENCY", &c.ShowLatency)
+	setBool("PARTICIPANT_STATS", &c.ParticipantStats)
+	setString("SENTRY_DSN", &c.SentryDSN)
+	setString("INVITE_SECRET", &c.InviteSecret)
+	setInt("MAX_CONNECTIONS", &c.MaxConnections)
+	setInt("MAX_CONNECTIONS_PER_IP", &c.MaxConnectionsPerIP)
+	setInt("MESSAGE_RATE_LIMIT", &c.MessageRateLimit)
+	setDuration("MESSAGE_RATE_WINDOW", &c.MessageRateWindow)
+	setFloat("HTTP_LOG_SAMPLE_RATE", &c.HTTPLogSampleRate)
+	setInt("BROADCAST_WORKERS", &c.BroadcastWorkers)
+	setInt("SEND_BUFFER_SIZE", &c.SendBufferSize)
+	if value := os.Getenv("SLOW_CLIENT_POLICY"); value != "" {
+		c.SlowClientPolicy = SlowClientPolicy(value)
+	}
+	if value := os.Getenv("ROOM_METRICS"); value != "" {
+		c.RoomMetrics = RoomMetricsMode(value)
+	}
+	setInt("ROOM_METRICS_LIMIT", &c.RoomMetricsLimit)
+	// TRUST_PROXY is either a boolean or the list of trusted proxies
+	if value := os.Getenv("TRUST_PROXY"); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			c.TrustProxy, c.TrustedProxies = b, nil
+		} else {
+			c.TrustProxy, c.TrustedProxies = false, splitAndTrim(value, ",")
+		}
+	}
+	setList("IP_ALLOWLIST", &c.IPAllowlist)
+	setList("IP_DENYLIST", &c.IPDenylist)
+	setString("SNAPSHOT_FILE", &c.SnapshotFile)
+	setString("SNAPSHOT_REDIS_KEY", &c.SnapshotRedisKey)
+	setDuration("SNAPSHOT_INTERVAL", &c.SnapshotInterval)
+	setString("JIRA_BASE_URL", &c.Jira.BaseURL)
+	setString("JIRA_EMAIL", &c.Jira.Email)
+	setString("JIRA_API_TOKEN", &c.Jira.APIToken)
+	setString("JIRA_STORY_POINTS_FIELD", &c.Jira.StoryPointsField)
+	setString("LINEAR_API_KEY", &c.Linear.APIKey)
+	setString("GITLAB_BASE_URL", &c.GitLab.BaseURL)
+	setString("GITLAB_TOKEN", &c.GitLab.Token)
+	setList("LINK_PREVIEW_DOMAINS", &c.LinkPreviews.Domains)
+	setList("OUTBOUND_ALLOWED_NETWORKS", &c.Outbound.AllowedNetworks)
+	setDuration("OUTBOUND_TIMEOUT", &c.Outbound.Timeout)
+	setInt("OUTBOUND_MAX_REDIRECTS", &c.Outbound.MaxRedirects)
+	setList("WEBHOOK_URLS", &c.Webhooks.URLs)
+	setString("SLACK_WEBHOOK_URL", &c.Webhooks.SlackURL)
+	setString("TEAMS_WEBHOOK_URL", &c.Webhooks.TeamsURL)
+	setString("TLS_CERT_FILE", &c.TLS.CertFile)
+	setString("TLS_KEY_FILE", &c.TLS.KeyFile)
+	setList("TLS_AUTOCERT_HOSTS", &c.TLS.AutocertHosts)
+	setString("TLS_AUTOCERT_EMAIL", &c.TLS.AutocertEmail)
+	setString("TLS_AUTOCERT_CACHE_DIR", &c.TLS.AutocertCacheDir)
+	setString("TLS_HTTP_PORT", &c.TLS.HTTPPort)
+	setList("ROOM_CODE_ADJECTIVES", &c.RoomCodes.Adjectives)
+	setList("ROOM_CODE_NOUNS", &c.RoomCodes.Nouns)
+	setString("ROOM_CODE_ALPHABET", &c.RoomCodes.Alphabet)
+	setInt("ROOM_CODE_SUFFIX_LENGTH", &c.RoomCodes.SuffixLength)
+	setList("ROOM_CODE_RESERVED", &c.RoomCodes.Reserved)
+	setString("OIDC_ISSUER", &c.OIDC.Issuer)
+	setString("OIDC_CLIENT_ID", &c.OIDC.ClientID)
+	setString("OIDC_CLIENT_SECRET", &c.OIDC.ClientSecret)
+	setString("OIDC_REDIRECT_URL", &c.OIDC.RedirectURL)
+	setList("OIDC_SCOPES", &c.OIDC.Scopes)
+	setString("OIDC_NAME_CLAIM", &c.OIDC.NameClaim)
+	setString("OIDC_TEAM_CLAIM", &c.OIDC.TeamClaim)
+	setBool("OIDC_REQUIRED", &c.OIDC.Required)
+	setString("OIDC_SESSION_SECRET", &c.OIDC.SessionSecret)
+	setDuration("OIDC_SESSION_TTL", &c.OIDC.SessionTTL)
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every setting that would stop the server from working as
+// configured.
+func (c Config) Validate() error {
+	var errs []error
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("port: invalid port %q", c.Port))
+	}
+	if len(c.AllowedOrigins) == 0 {
+		errs = append(errs, errors.New("allowed_origins: at least one origin is required"))
+	}
+	if c.RedisURL != "" {
+		if _, err := redis.ParseURL(c.RedisURL); err != nil {
+			errs = append(errs, fmt.Errorf("redis_url: %w", err))
+		}
+	}
+	if c.RedisEncryptionKey != "" {
+		if c.RedisURL == "" {
+			errs = append(errs, errors.New("redis_encryption_key: requires redis_url"))
+		}
+		if _, err := hub.NewRedisCipher(c.RedisEncryptionKey); err != nil {
+			errs = append(errs, fmt.Errorf("redis_encryption_key: %w", err))
+		}
+	}
+	switch c.Storage {
+	case "", StorageSQLite:
+	case StoragePostgres:
+		if c.DatabaseURL == "" {
+			errs = append(errs, errors.New("storage: postgres requires database_url"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("storage: unknown backend %q", c.Storage))
+	}
+	if c.Storage == StorageSQLite && c.SQLitePath == "" {
+		errs = append(errs, errors.New("sqlite_path: required when storage is sqlite"))
+	}
+	if c.RoomLeaseTTL <= 0 {
+		errs = append(errs, fmt.Errorf("room_lease_ttl: must be positive, got %s", c.RoomLeaseTTL))
+	}
+	if c.GracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("participant_grace_period: must not be negative, got %s", c.GracePeriod))
+	}
+	if c.AwayAfter < 0 {
+		errs = append(errs, fmt.Errorf("away_after: must not be negative, got %s", c.AwayAfter))
+	}
+	switch c.DuplicateNamePolicy {
+	case DuplicateNameSuffix, DuplicateNameReject, DuplicateNameToken:
+	default:
+		errs = append(errs, fmt.Errorf("duplicate_name_policy: unknown policy %q", c.DuplicateNamePolicy))
+	}
+	tokens := map[string]bool{c.AdminToken: c.AdminToken != ""}
+	for _, team := range slices.Sorted(maps.Keys(c.TeamTokens)) {
+		token := c.TeamTokens[team]
+		if !teamIDPattern.MatchString(team) {
+			errs = append(errs, fmt.Errorf("team_tokens: invalid team ID %q", team))
+		}
+		if token == "" {
+			errs = append(errs, fmt.Errorf("team_tokens.%s: token is required", team))
+		} else if tokens[token] {
+			errs = append(errs, fmt.Errorf("team_tokens.%s: token is already in use", team))
+		}
+		tokens[token] = true
+	}
+	if c.APIKeysFile != "" {
+		if _, err := OpenAPIKeyStore(c.APIKeysFile); err != nil {
+			errs = append(errs, fmt.Errorf("api_keys_file: %w", err))
+		}
+	}
+	if c.APIKeyRateLimit < 1 {
+		errs = append(errs, fmt.Errorf("api_key_rate_limit: must be positive, got %d", c.APIKeyRateLimit))
+	}
+	if c.MaxConnections < 0 {
+		errs = append(errs, fmt.Errorf("max_connections: must not be negative, got %d", c.MaxConnections))
+	}
+	if c.MaxConnectionsPerIP < 0 {
+		errs = append(errs, fmt.Errorf("max_connections_per_ip: must not be negative, got %d", c.MaxConnectionsPerIP))
+	}
+	if c.MessageRateLimit < 1 {
+		errs = append(errs, fmt.Errorf("message_rate_limit: must be positive, got %d", c.MessageRateLimit))
+	}
+	if c.MessageRateWindow <= 0 {
+		errs = append(errs, fmt.Errorf("message_rate_window: must be positive, got %s", c.MessageRateWindow))
+	}
+	if c.HTTPLogSampleRate < 0 || c.HTTPLogSampleRate > 1 {
+		errs = append(errs, fmt.Errorf("http_log_sample_rate: must be between 0 and 1, got %g", c.HTTPLogSampleRate))
+	}
+	if c.BroadcastWorkers < 1 {
+		errs = append(errs, fmt.Errorf("broadcast_workers: must be positive, got %d", c.BroadcastWorkers))
+	}
+	if c.SendBufferSize < 1 {
+		errs = append(errs, fmt.Errorf("send_buffer_size: must be positive, got %d", c.SendBufferSize))
+	}
+	switch c.SlowClientPolicy {
+	case SlowClientDrop, SlowClientEvict:
+	default:
+		errs = append(errs, fmt.Errorf("slow_client_policy: unknown policy %q", c.SlowClientPolicy))
+	}
+	if c.SentryDSN != "" {
+		if _, _, err := parseSentryDSN(c.SentryDSN); err != nil {
+			errs = append(errs, fmt.Errorf("sentry_dsn: %w", err))
+		}
+	}
+	switch c.RoomMetrics {
+	case RoomMetricsAggregate, RoomMetricsRooms, RoomMetricsHashed:
+	default:
+		errs = append(errs, fmt.Errorf("room_metrics: unknown mode %q", c.RoomMetrics))
+	}
+	if c.RoomMetricsLimit < 1 {
+		errs = append(errs, fmt.Errorf("room_metrics_limit: must be positive, got %d", c.RoomMetricsLimit))
+	}
+	if _, err := parseIPRanges(c.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("trusted_proxies: %w", err))
+	}
+	if _, err := parseIPRanges(c.IPAllowlist); err != nil {
+		errs = append(errs, fmt.Errorf("ip_allowlist: %w", err))
+	}
+	if _, err := parseIPRanges(c.IPDenylist); err != nil {
+		errs = append(errs, fmt.Errorf("ip_denylist: %w", err))
+	}
+	if c.SnapshotFile != "" && c.SnapshotRedisKey != "" {
+		errs = append(errs, errors.New("snapshot: use either snapshot_file or snapshot_redis_key, not both"))
+	}
+	if c.SnapshotRedisKey != "" && c.RedisURL == "" {
+		errs = append(errs, errors.New("snapshot_redis_key: requires redis_url"))
+	}
+	if c.SnapshotInterval <= 0 && (c.SnapshotFile != "" || c.SnapshotRedisKey != "") {
+		errs = append(errs, fmt.Errorf("snapshot_interval: must be positive, got %s", c.SnapshotInterval))
+	}
+	if c.Jira.BaseURL != "" {
+		if u, err := url.Parse(c.Jira.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("jira.base_url: invalid URL %q", c.Jira.BaseURL))
+		}
+		if c.Jira.APIToken == "" {
+			errs = append(errs, errors.New("jira.api_token: required when jira.base_url is set"))
+		}
+	}
+	if c.GitLab.BaseURL != "" && !isHTTPURL(c.GitLab.BaseURL) {
+		errs = append(errs, fmt.Errorf("gitlab.base_url: invalid URL %q", c.GitLab.BaseURL))
+	}
+	for _, domain := range c.LinkPreviews.Domains {
+		if domain == "" || strings.ContainsAny(domain, ":/ ") {
+			errs = append(errs, fmt.Errorf("link_previews.domains: %q must be a host name such as example.com", domain))
+		}
+	}
+	if _, err := parseIPRanges(c.Outbound.AllowedNetworks); err != nil {
+		errs = append(errs, fmt.Errorf("outbound.allowed_networks: %w", err))
+	}
+	if c.Outbound.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("outbound.timeout: must be positive, got %s", c.Outbound.Timeout))
+	}
+	if c.Outbound.MaxRedirects < 0 {
+		errs = append(errs, fmt.Errorf("outbound.max_redirects: must not be negative, got %d", c.Outbound.MaxRedirects))
+	}
+	for _, webhook := range c.Webhooks.URLs {
+		if !isHTTPURL(webhook) {
+			errs = append(errs, fmt.Errorf("webhooks.urls: invalid URL %q", redactURL(webhook)))
+		}
+	}
+	if c.Webhooks.SlackURL != "" && !isHTTPURL(c.Webhooks.SlackURL) {
+		errs = append(errs, errors.New("webhooks.slack_url: invalid URL"))
+	}
+	if c.Webhooks.TeamsURL != "" && !isHTTPURL(c.Webhooks.TeamsURL) {
+		errs = append(errs, errors.New("webhooks.teams_url: invalid URL"))
+	}
+	for i, webhook := range c.Webhooks.Templates {
+		if !isHTTPURL(webhook.URL) {
+			errs = append(errs, fmt.Errorf("webhooks.templates[%d].url: invalid URL %q", i, redactURL(webhook.URL)))
+		}
+		if _, err := parseWebhookTemplate(webhook.Body); err != nil {
+			errs = append(errs, fmt.Errorf("webhooks.templates[%d].body: %w", i, err))
+		}
+	}
+	errs = append(errs, c.TLS.validate(), c.RoomCodes.validate(), c.OIDC.validate())
+	return errors.Join(errs...)
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (c TLSConfig) validate() error {
+	var errs []error
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, errors.New("tls: cert_file and key_file must be set together"))
+	}
+	if c.CertFile != "" && len(c.AutocertHosts) > 0 {
+		errs = append(errs, errors.New("tls: use either certificate files or autocert_hosts, not both"))
+	}
+	if c.CertFile != "" && c.KeyFile != "" {
+		if _, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile); err != nil {
+			errs = append(errs, fmt.Errorf("tls: %w", err))
+		}
+	}
+	if len(c.AutocertHosts) > 0 {
+		if c.AutocertCacheDir == "" {
+			errs = append(errs, errors.New("tls.autocert_cache_dir: required with autocert_hosts"))
+		}
+		if port, err := strconv.Atoi(c.HTTPPort); err != nil || port < 1 || port > 65535 {
+			errs = append(errs, fmt.Errorf("tls.http_port: invalid port %q", c.HTTPPort))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c OIDCConfig) validate() error {
+	if !c.Enabled() {
+		if c.Required {
+			return errors.New("oidc.required: requires oidc.issuer")
+		}
+		return nil
+	}
+	var errs []error
+	if !isHTTPURL(c.Issuer) {
+		errs = append(errs, fmt.Errorf("oidc.issuer: invalid URL %q", c.Issuer))
+	}
+	if c.ClientID == "" {
+		errs = append(errs, errors.New("oidc.client_id: required when oidc.issuer is set"))
+	}
+	if !isHTTPURL(c.RedirectURL) {
+		errs = append(errs, fmt.Errorf("oidc.redirect_url: invalid URL %q", c.RedirectURL))
+	}
+	if !slices.Contains(c.Scopes, "openid") {
+		errs = append(errs, errors.New("oidc.scopes: must include openid"))
+	}
+	if c.SessionTTL <= 0 {
+		errs = append(errs, fmt.Errorf("oidc.session_ttl: must be positive, got %s", c.SessionTTL))
+	}
+	return errors.Join(errs...)
+}
+
+// String lists the settings for logging, with secrets redacted.
+func (c Config) String() string {
+	fields := []string{
+		"port=" + c.Port,
+		"allowed_origins=" + strings.Join(c.AllowedOrigins, ","),
+		"redis_url=" + redactURL(c.RedisURL),
+		"redis_encryption_key=" + redact(c.RedisEncryptionKey),
+		"database_url=" + redactURL(c.DatabaseURL),
+		"storage=" + c.Storage,
+		"sqlite_path=" + c.SQLitePath,
+		"participant_grace_period=" + c.GracePeriod.String(),
+		"away_after=" + c.AwayAfter.String(),
+		"duplicate_name_policy=" + string(c.DuplicateNamePolicy),
+		"admin_token=" + redact(c.AdminToken),
+		"team_tokens=" + strings.Join(slices.Sorted(maps.Keys(c.TeamTokens)), ","),
+		"api_keys_file=" + c.APIKeysFile,
+		"api_key_rate_limit=" + strconv.Itoa(c.APIKeyRateLimit),
+		"socketio_enabled=" + strconv.FormatBool(c.SocketIO),
+		"show_latency=" + strconv.FormatBool(c.ShowLatency),
+		"participant_stats=" + strconv.FormatBool(c.ParticipantStats),
+		"sentry_dsn=" + redact(c.SentryDSN),
+		"invite_secret=" + redact(c.InviteSecret),
+		"max_connections=" + strconv.Itoa(c.MaxConnections),
+		"max_connections_per_ip=" + strconv.Itoa(c.MaxConnectionsPerIP),
+		"message_rate_limit=" + strconv.Itoa(c.MessageRateLimit),
+		"message_rate_window=" + c.MessageRateWindow.String(),
+		"http_log_sample_rate=" + strconv.FormatFloat(c.HTTPLogSampleRate, 'g', -1, 64),
+		"broadcast_workers=" + strconv.Itoa(c.BroadcastWorkers),
+		"send_buffer_size=" + strconv.Itoa(c.SendBufferSize),
+		"slow_client_policy=" + string(c.SlowClientPolicy),
+		"room_metrics=" + string(c.RoomMetrics),
+		"room_metrics_limit=" + strconv.Itoa(c.RoomMetricsLimit),
+		"trust_proxy=" + strconv.FormatBool(c.TrustProxy),
+		"trusted_proxies=" + strings.Join(c.TrustedProxies, ","),
+		"ip_allowlist=" + strings.Join(c.IPAllowlist, ","),
+		"ip_denylist=" + strings.Join(c.IPDenylist, ","),
+		"snapshot_file=" + c.SnapshotFile,
+		"snapshot_redis_key=" + c.SnapshotRedisKey,
+		"snapshot_interval=" + c.SnapshotInterval.String(),
+		"room_lease_ttl=" + c.RoomLeaseTTL.String(),
+		"jira.base_url=" + c.Jira.BaseURL,
+		"jira.email=" + c.Jira.Email,
+		"jira.api_token=" + redact(c.Jira.APIToken),
+		"jira.story_points_field=" + c.Jira.StoryPointsField,
+		"linear.api_key=" + redact(c.Linear.APIKey),
+		"gitlab.base_url=" + c.GitLab.BaseURL,
+		"gitlab.token=" + redact(c.GitLab.Token),
+		"link_previews.domains=" + strings.Join(c.LinkPreviews.Domains, ","),
+		"outbound.allowed_networks=" + strings.Join(c.Outbound.AllowedNetworks, ","),
+		"outbound.timeout=" + c.Outbound.Timeout.String(),
+		"outbound.max_redirects=" + strconv.Itoa(c.Outbound.MaxRedirects),
+		// Webhook URLs usually embed their credentials
+		"webhooks.urls=" + redact(strings.Join(c.Webhooks.URLs, ",")),
+		"webhooks.slack_url=" + redact(c.Webhooks.SlackURL),
+		"webhooks.teams_url=" + redact(c.Webhooks.TeamsURL),
+		"webhooks.templates=" + strconv.Itoa(len(c.Webhooks.Templates)),
+		"tls.cert_file=" + c.TLS.CertFile,
+		"tls.key_file=" + c.TLS.KeyFile,
+		"tls.autocert_hosts=" + strings.Join(c.TLS.AutocertHosts, ","),
+		"tls.autocert_email=" + c.TLS.AutocertEmail,
+		"tls.autocert_cache_dir=" + c.TLS.AutocertCacheDir,
+		"tls.http_port=" + c.TLS.HTTPPort,
+		"room_codes.adjectives=" + strconv.Itoa(len(c.RoomCodes.Adjectives)),
+		"room_codes.nouns=" + strconv.Itoa(len(c.RoomCodes.Nouns)),
+		"room_codes.alphabet=" + c.RoomCodes.Alphabet,
+		"room_codes.suffix_length=" + strconv.Itoa(c.RoomCodes.SuffixLength),
+		"room_codes.reserved=" + strconv.Itoa(len(c.RoomCodes.Reserved)),
+		"oidc.issuer=" + c.OIDC.Issuer,
+		"oidc.client_id=" + c.OIDC.ClientID,
+		"oidc.client_secret=" + redact(c.OIDC.ClientSecret),
+		"oidc.redirect_url=" + c.OIDC.RedirectURL,
+		"oidc.scopes=" + strings.Join(c.OIDC.Scopes, ","),
+		"oidc.name_claim=" + c.OIDC.NameClaim,
+		"oidc.team_claim=" + c.OIDC.TeamClaim,
+		"oidc.required=" + strconv.FormatBool(c.OIDC.Required),
+		"oidc.session_secret=" + redact(c.OIDC.SessionSecret),
+		"oidc.session_ttl=" + c.OIDC.SessionTTL.String(),
+	}
+	return strings.Join(fields, " ")
+}
+
+// redactURL hides the password in a connection URL. Connection strings that
+// aren't URLs are hidden entirely, as they may carry one too.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return redact(raw)
+	}
+	return u.Redacted()
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "[redacted]"
+}
+
+// configFromEnv is the configuration a Server starts from before options are
+// applied. Problems are only logged: the standalone binary has already refused
+// to start with them, and embedders may fix them with options.
+func configFromEnv() Config {
+	cfg := DefaultConfig()
+	if err := cfg.applyEnv(); err != nil {
+		log.Printf("Ignoring invalid environment configuration: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Printf("Invalid configuration: %v", err)
+	}
+	return cfg
+}
+
+// applyConfig sets everything the configuration controls.
+func (s *Server) applyConfig(cfg Config) {
+	s.config = cfg
+	s.allowedOrigins = cfg.AllowedOrigins
+	s.redisURL = cfg.RedisURL
+	s.redisCipher, _ = hub.NewRedisCipher(cfg.RedisEncryptionKey)
+	s.leaseTTL = cfg.RoomLeaseTTL
+	s.gracePeriod = cfg.GracePeriod
+	s.awayAfter = cfg.AwayAfter
+	s.messageLimit = cfg.MessageRateLimit
+	s.messageWindow = cfg.MessageRateWindow
+	s.httpLogSampleRate = cfg.HTTPLogSampleRate
+	s.namePolicy = cfg.DuplicateNamePolicy
+	s.broadcastWorkers = cfg.BroadcastWorkers
+	s.sendBufferSize = cfg.SendBufferSize
+	s.slowClientPolicy = cfg.SlowClientPolicy
+	s.roomMetrics = cfg.RoomMetrics
+	s.roomMetricsLimit = cfg.RoomMetricsLimit
+	s.adminToken = cfg.AdminToken
+	s.teamTokens = cfg.TeamTokens
+	s.apiKeys = newAPIKeyStoreFromConfig(cfg.APIKeysFile)
+	s.apiKeyRateLimit = cfg.APIKeyRateLimit
+	s.socketIO = cfg.SocketIO
+	s.showLatency = cfg.ShowLatency
+	s.participantStats = cfg.ParticipantStats
+	s.inviteSecret = newSigningSecret(cfg.InviteSecret)
+	s.connLimiter = newConnectionLimiter(cfg.MaxConnections, cfg.MaxConnectionsPerIP)
+	s.trustProxy = cfg.TrustProxy
+	s.trustedProxies, _ = parseIPRanges(cfg.TrustedProxies)
+	s.ipAllow, _ = parseIPRanges(cfg.IPAllowlist)
+	s.ipDeny, _ = parseIPRanges(cfg.IPDenylist)
+	outbound := newOutboundPolicy(cfg.Outbound)
+	s.jira = newJiraClientFromConfig(cfg.Jira, outbound)
+	s.linear = newLinearClientFromConfig(cfg.Linear, outbound)
+	s.gitlab = newGitLabClientFromConfig(cfg.GitLab, outbound)
+	s.linkPreviewer = newLinkPreviewerFromConfig(cfg.LinkPreviews, outbound)
+	s.webhooks = newWebhookNotifier(cfg.Webhooks, outbound)
+	s.roomCodes = newRoomCodeGenerator(cfg.RoomCodes)
+	s.oidc = newOIDCProviderFromConfig(cfg.OIDC)
+}
+
+// Reload applies the settings that can change without dropping connections:
+// allowed origins, IP filters, connection and message rate limits, the
+// participant grace period, the away timeout and the HTTP log sample rate. Changes to other settings are logged and wait for
+// a restart.
+func (s *Server) Reload(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	ipAllow, _ := parseIPRanges(cfg.IPAllowlist)
+	ipDeny, _ := parseIPRanges(cfg.IPDenylist)
+
+	s.settingsMu.Lock()
+	previous := s.config
+	s.config = cfg
+	s.allowedOrigins = cfg.AllowedOrigins
+	s.gracePeriod = cfg.GracePeriod
+	s.awayAfter = cfg.AwayAfter
+	s.ipAllow = ipAllow
+	s.ipDeny = ipDeny
+	s.messageLimit = cfg.MessageRateLimit
+	s.messageWindow = cfg.MessageRateWindow
+	s.httpLogSampleRate = cfg.HTTPLogSampleRate
+	s.settingsMu.Unlock()
+
+	s.connLimiter.setLimits(cfg.MaxConnections, cfg.MaxConnectionsPerIP)
+
+	if pending := restartRequired(previous, cfg); len(pending) > 0 {
+		s.logger.Printf("⚠️ Restart required to apply %s", strings.Join(pending, ", "))
+	}
+	s.logger.Printf("✓ Configuration reloaded: %s", cfg)
+	return nil
+}
+
+// restartRequired lists the settings that differ between two configurations
+// but are only read at startup.
+func restartRequired(previous, cfg Config) []string {
+	checks := []struct {
+		name    string
+		changed bool
+	}{
+		{"port", previous.Port != cfg.Port},
+		{"redis_url", previous.RedisURL != cfg.RedisURL},
+		{"redis_encryption_key", previous.RedisEncryptionKey != cfg.RedisEncryptionKey},
+		{"database_url", previous.DatabaseURL != cfg.DatabaseURL},
+		{"storage", previous.Storage != cfg.Storage || previous.SQLitePath != cfg.SQLitePath},
+		{"duplicate_name_policy", previous.DuplicateNamePolicy != cfg.DuplicateNamePolicy},
+		{"admin_token", previous.AdminToken != cfg.AdminToken},
+		{"team_tokens", fmt.Sprint(previous.TeamTokens) != fmt.Sprint(cfg.TeamTokens)},
+		{"api_keys", previous.APIKeysFile != cfg.APIKeysFile || previous.APIKeyRateLimit != cfg.APIKeyRateLimit},
+		{"socketio_enabled", previous.SocketIO != cfg.SocketIO},
+		{"show_latency", previous.ShowLatency != cfg.ShowLatency},
+		{"participant_stats", previous.ParticipantStats != cfg.ParticipantStats},
+		{"sentry_dsn", previous.SentryDSN != cfg.SentryDSN},
+		{"invite_secret", previous.InviteSecret != cfg.InviteSecret},
+		{"broadcast", previous.BroadcastWorkers != cfg.BroadcastWorkers || previous.SendBufferSize != cfg.SendBufferSize || previous.SlowClientPolicy != cfg.SlowClientPolicy},
+		{"room_metrics", previous.RoomMetrics != cfg.RoomMetrics || previous.RoomMetricsLimit != cfg.RoomMetricsLimit},
+		{"trust_proxy", previous.TrustProxy != cfg.TrustProxy},
+		{"trusted_proxies", strings.Join(previous.TrustedProxies, ",") != strings.Join(cfg.TrustedProxies, ",")},
+		{"room_lease_ttl", previous.RoomLeaseTTL != cfg.RoomLeaseTTL},
+		{"snapshot", previous.SnapshotFile != cfg.SnapshotFile || previous.SnapshotRedisKey != cfg.SnapshotRedisKey || previous.SnapshotInterval != cfg.SnapshotInterval},
+		{"jira", previous.Jira != cfg.Jira},
+		{"linear", previous.Linear != cfg.Linear},
+		{"gitlab", previous.GitLab != cfg.GitLab},
+		{"link_previews", fmt.Sprint(previous.LinkPreviews) != fmt.Sprint(cfg.LinkPreviews)},
+		{"outbound", fmt.Sprint(previous.Outbound) != fmt.Sprint(cfg.Outbound)},
+		{"webhooks", fmt.Sprint(previous.Webhooks) != fmt.Sprint(cfg.Webhooks)},
+		{"tls", fmt.Sprint(previous.TLS) != fmt.Sprint(cfg.TLS)},
+		{"room_codes", fmt.Sprint(previous.RoomCodes) != fmt.Sprint(cfg.RoomCodes)},
+		{"oidc", fmt.Sprint(previous.OIDC) != fmt.Sprint(cfg.OIDC)},
+	}
+
+	var changed []string
+	for _, check := range checks {
+		if check.changed {
+			changed = append(changed, check.name)
+		}
+	}
+	return changed
+}
+
+// messageRateLimit returns the current per-connection message limit.
+func (s *Server) messageRateLimit() (int, time.Duration) {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return s.messageLimit, s.messageWindow
+}
@@ -0,0 +1,80 @@
+package pokerserver
+
+import (
+	"net/http"
+	"sync"
+)
+
+// connectionLimiter caps concurrent WebSocket connections overall and per
+// client IP, so a single misbehaving client can't exhaust file descriptors.
+// A limit of zero disables that cap.
+type connectionLimiter struct {
+	maxTotal int
+	maxPerIP int
+
+	mu    sync.Mutex
+	total int
+	perIP map[string]int
+}
+
+func newConnectionLimiter(maxTotal, maxPerIP int) *connectionLimiter {
+	return &connectionLimiter{
+		maxTotal: maxTotal,
+		maxPerIP: maxPerIP,
+		perIP:    make(map[string]int),
+	}
+}
+
+// acquire reserves a connection slot for ip, returning false when a cap has
+// been reached. Every successful acquire must be paired with a release.
+func (l *connectionLimiter) acquire(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxTotal > 0 && l.total >= l.maxTotal {
+		return false
+	}
+	if l.maxPerIP > 0 && l.perIP[ip] >= l.maxPerIP {
+		return false
+	}
+	l.total++
+	l.perIP[ip]++
+	return true
+}
+
+// setLimits changes the caps. Connections already over a lowered cap are kept.
+func (l *connectionLimiter) setLimits(maxTotal, maxPerIP int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxTotal = maxTotal
+	l.maxPerIP = maxPerIP
+}
+
+func (l *connectionLimiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.total--
+	if l.perIP[ip]--; l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+}
+
+// acceptConnection admits the request's client before the upgrade, answering
+// 403 for blocked addresses and 503 when the server is full. The returned release must
+// be called once the connection ends.
+func (s *Server) acceptConnection(w http.ResponseWriter, r *http.Request) (ip string, release func(), ok bool) {
+	ip = s.clientIP(r)
+	if !s.ipAllowed(ip) {
+		s.logger.Printf("⛔ Rejected connection from blocked address %s", ip)
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return ip, nil, false
+	}
+	if !s.connLimiter.acquire(ip) {
+		s.logger.Printf("⛔ Connection limit reached, rejecting %s", ip)
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "too many connections", http.StatusServiceUnavailable)
+		return ip, nil, false
+	}
+	return ip, func() { s.connLimiter.release(ip) }, true
+}
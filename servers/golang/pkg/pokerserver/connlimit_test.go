@@ -0,0 +1,71 @@
+package pokerserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestConnectionLimitPerIP(t *testing.T) {
+	server := New(WithConnectionLimits(0, 1))
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/api/ws"
+
+	first, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatal("Expected second connection from the same IP to be rejected")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected 503, got %v", resp)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+
+	// The slot is freed once the first client disconnects
+	first.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected connection slot to be released: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestConnectionLimiter(t *testing.T) {
+	limiter := newConnectionLimiter(2, 1)
+
+	if !limiter.acquire("10.0.0.1") || !limiter.acquire("10.0.0.2") {
+		t.Fatal("Expected connections under the caps to be accepted")
+	}
+	if limiter.acquire("10.0.0.3") {
+		t.Error("Expected total cap to reject a third connection")
+	}
+
+	limiter.release("10.0.0.2")
+	if limiter.acquire("10.0.0.1") {
+		t.Error("Expected per-IP cap to reject a second connection from the same IP")
+	}
+	if !limiter.acquire("10.0.0.3") {
+		t.Error("Expected released slot to be reusable")
+	}
+	if len(limiter.perIP) != 2 {
+		t.Errorf("Expected released IPs to be forgotten, got %v", limiter.perIP)
+	}
+}
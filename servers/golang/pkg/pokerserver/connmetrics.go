@@ -0,0 +1,67 @@
+package pokerserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Ways a participant comes back on a new connection
+const (
+	reconnectRejoin = "rejoin"
+	reconnectResume = "resume"
+)
+
+// connectionMetrics report how long connections last, why they end and how
+// often participants come back, so unusual churn such as a proxy cutting
+// idle connections shows.
+type connectionMetrics struct {
+	opened     metric.Int64Counter
+	duration   metric.Float64Histogram
+	reconnects metric.Int64Counter
+}
+
+func (s *Server) newConnectionMetrics() connectionMetrics {
+	var (
+		m    connectionMetrics
+		errs [3]error
+	)
+	m.opened, errs[0] = s.meter.Int64Counter("poker.connections.opened",
+		metric.WithDescription("WebSocket connections accepted"),
+	)
+	m.duration, errs[1] = s.meter.Float64Histogram("poker.connections.duration",
+		metric.WithDescription("How long WebSocket connections lasted, by the reason they ended"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(1, 10, 30, 60, 120, 300, 900, 1800, 3600, 7200, 14400),
+	)
+	m.reconnects, errs[2] = s.meter.Int64Counter("poker.connections.reconnects",
+		metric.WithDescription("Participants taken over by a new connection, by how they came back"),
+	)
+	if err := errors.Join(errs[:]...); err != nil {
+		s.logger.Printf("Error creating connection metrics: %v", err)
+	}
+	return m
+}
+
+// recordDisconnect logs and measures a connection that ended. Clients of
+// other instances are recorded there.
+func (s *Server) recordDisconnect(ws *ExtendedWebSocket) {
+	if ws.Conn == nil {
+		s.logger.Printf("❌ Client disconnected: %s", ws.ID)
+		return
+	}
+	reason := ws.DisconnectReason()
+	duration := s.clock.Now().Sub(ws.ConnectedAt)
+	s.logger.Printf("❌ Client disconnected: client=%s ip=%s room=%s duration=%s reason=%s",
+		ws.ID, ws.RemoteIP, ws.RoomID(), duration.Round(time.Millisecond), reason)
+	s.connMetrics.duration.Record(context.Background(), duration.Seconds(),
+		metric.WithAttributes(attribute.String("reason", reason)))
+}
+
+// recordReconnect counts a participant coming back on a new connection.
+func (s *Server) recordReconnect(ctx context.Context, kind string) {
+	s.connMetrics.reconnects.Add(ctx, 1, metric.WithAttributes(attribute.String("kind", kind)))
+}
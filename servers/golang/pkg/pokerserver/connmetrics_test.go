@@ -0,0 +1,87 @@
+package pokerserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// connectionMetric returns the data points of a metric, by their reason or
+// kind attribute.
+func connectionMetric(t *testing.T, reader *sdkmetric.ManualReader, name string) map[string]uint64 {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	points := make(map[string]uint64)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, point := range data.DataPoints {
+					reason, _ := point.Attributes.Value("reason")
+					points[reason.AsString()] = point.Count
+				}
+			case metricdata.Sum[int64]:
+				for _, point := range data.DataPoints {
+					kind, _ := point.Attributes.Value("kind")
+					points[kind.AsString()] = uint64(point.Value)
+				}
+			}
+		}
+	}
+	return points
+}
+
+func TestConnectionMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	server := New(WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+
+	httpServer, alice := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	sendMessage(t, alice, "join-room", map[string]interface{}{"roomId": "test-room", "name": "Alice", "participantId": "alice"})
+	readMessage(t, alice, 2*time.Second)
+	alice.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+	alice.Close()
+
+	// Alice comes back after a page refresh, and then misses a heartbeat
+	waitFor(t, func() bool {
+		return connectionMetric(t, reader, "poker.connections.duration")[transport.DisconnectClientClose] == 1
+	})
+	httpServer2, again := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer again.Close()
+	sendMessage(t, again, "join-room", map[string]interface{}{"roomId": "test-room", "name": "Alice", "participantId": "alice"})
+	readMessage(t, again, 2*time.Second)
+	for _, client := range server.hub.Clients() {
+		client.CloseWith(transport.CloseHeartbeat, "heartbeat timeout")
+	}
+
+	waitFor(t, func() bool { return connectionMetric(t, reader, "poker.connections.duration")["heartbeat"] == 1 })
+	if reconnects := connectionMetric(t, reader, "poker.connections.reconnects"); reconnects[reconnectRejoin] != 1 {
+		t.Errorf("Expected Alice's rejoin counted, got %v", reconnects)
+	}
+	if opened := connectionMetric(t, reader, "poker.connections.opened"); opened[""] != 2 {
+		t.Errorf("Expected two connections opened, got %v", opened)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
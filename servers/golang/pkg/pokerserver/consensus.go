@@ -0,0 +1,64 @@
+package pokerserver
+
+import (
+	"context"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// announceConsensus follows a reveal with a consensus or divergent event, so
+// clients can prompt a discussion or a reestimate.
+func (s *Server) announceConsensus(ctx context.Context, roomID string, result roompkg.Consensus) {
+	event := "divergent"
+	if result.Consensus {
+		event = "consensus"
+	}
+	s.broadcastToRoom(ctx, roomID, event, result)
+}
+
+// handleRequestExplanation lets the facilitator ask the outliers of the
+// revealed round to explain their votes first. Each of them is sent an
+// explanation-requested message saying which end they are at.
+func (s *Server) handleRequestExplanation(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.RLock()
+	if room.FacilitatorID != ws.ID || !room.Revealed || room.LastRound == nil || room.Settings.Mode != roompkg.ModePoints {
+		room.Mu.RUnlock()
+		s.logf(ws.Context(), "⚠️ Ignoring request-explanation from %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "request-explanation", errCodeNotAllowed, "Only the facilitator can ask for explanations, once a points round is revealed", nil)
+		return
+	}
+	roundID := room.LastRound.ID
+	consensus, _ := roompkg.CheckConsensus(roundID, room.LastRound.Participants, room.Settings)
+	room.Mu.RUnlock()
+
+	if consensus.Outliers == nil {
+		return
+	}
+	room.Mu.Lock()
+	room.Apply(&roompkg.DiscussEvent{})
+	room.Mu.Unlock()
+	s.broadcastRoomState(ws.Context(), roomID)
+	for side, outliers := range map[string][]roompkg.CastVote{"high": consensus.Outliers.High, "low": consensus.Outliers.Low} {
+		for _, outlier := range outliers {
+			client := s.hub.Client(outlier.ID)
+			if client == nil {
+				continue
+			}
+			s.sendToClient(client, "explanation-requested", map[string]interface{}{
+				"roomId":  roomID,
+				"roundId": roundID,
+				"side":    side,
+				"vote":    outlier.Vote,
+			})
+		}
+	}
+	s.logf(ws.Context(), "🗣️ %s asked the outliers of round %s in room %s to explain", ws.ID, roundID, roomID)
+}
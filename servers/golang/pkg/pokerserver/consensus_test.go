@@ -0,0 +1,90 @@
+package pokerserver
+
+import (
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestRevealAnnouncesDivergence(t *testing.T) {
+	server := New()
+	httpServer, ws1 := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws1.Close()
+	_, ws2 := createTestWSConnection(t, server)
+	defer ws2.Close()
+
+	roomID := "consensus-room"
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws1, 2*time.Second) // room-state
+	sendMessage(t, ws2, "join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	readRoomStateUntil(t, ws1, func(participants []interface{}) bool { return len(participants) == 2 })
+
+	sendMessage(t, ws1, "vote", map[string]interface{}{"roomId": roomID, "vote": "1"})
+	readUntilType(t, ws1, "participant-voted")
+	sendMessage(t, ws2, "vote", map[string]interface{}{"roomId": roomID, "vote": "13"})
+	readUntilType(t, ws1, "participant-voted")
+
+	sendMessage(t, ws1, "reveal", map[string]interface{}{"roomId": roomID})
+	readUntilType(t, ws1, "revealed")
+	msg := readMessage(t, ws1, 2*time.Second)
+	if msg.Type != "divergent" {
+		t.Fatalf("Expected divergent after the reveal, got %s", msg.Type)
+	}
+	outliers := msg.Data.(map[string]interface{})["outliers"].(map[string]interface{})
+	high := outliers["high"].([]interface{})[0].(map[string]interface{})
+	if high["name"] != "Bob" {
+		t.Errorf("Expected Bob as the high outlier, got %v", high)
+	}
+}
+
+func TestRequestExplanation(t *testing.T) {
+	server := New()
+	httpServer, ws1 := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws1.Close()
+	_, ws2 := createTestWSConnection(t, server)
+	defer ws2.Close()
+
+	roomID := "explanation-room"
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws1, 2*time.Second) // room-state
+	sendMessage(t, ws2, "join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	readRoomStateUntil(t, ws1, func(participants []interface{}) bool { return len(participants) == 2 })
+
+	sendMessage(t, ws1, "vote", map[string]interface{}{"roomId": roomID, "vote": "2"})
+	readUntilType(t, ws1, "participant-voted")
+	sendMessage(t, ws2, "vote", map[string]interface{}{"roomId": roomID, "vote": "20"})
+	readUntilType(t, ws1, "participant-voted")
+
+	sendMessage(t, ws1, "reveal", map[string]interface{}{"roomId": roomID})
+	revealed := readUntilType(t, ws2, "revealed").Data.(map[string]interface{})
+	if _, ok := revealed["outliers"]; !ok {
+		t.Errorf("Expected outliers in the revealed payload, got %v", revealed)
+	}
+
+	// Only the facilitator can ask
+	sendMessage(t, ws2, "request-explanation", map[string]interface{}{"roomId": roomID})
+	sendMessage(t, ws1, "request-explanation", map[string]interface{}{"roomId": roomID})
+
+	request := readUntilType(t, ws2, "explanation-requested").Data.(map[string]interface{})
+	if request["side"] != "high" || request["vote"] != "20" {
+		t.Errorf("Expected Bob to be asked as the high outlier, got %v", request)
+	}
+	request = readUntilType(t, ws1, "explanation-requested").Data.(map[string]interface{})
+	if request["side"] != "low" {
+		t.Errorf("Expected Alice to be asked as the low outlier, got %v", request)
+	}
+	// The room moves on to discussing the round
+	state := readUntilType(t, ws2, "room-state").Data.(map[string]interface{})
+	if state["phase"] != string(roompkg.PhaseDiscussing) {
+		t.Errorf("Expected the room to be discussing, got %v", state["phase"])
+	}
+	ws2.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, _, err := ws2.ReadMessage(); err == nil {
+		t.Error("Expected a single explanation request for Bob")
+	}
+}
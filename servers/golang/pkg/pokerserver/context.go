@@ -0,0 +1,49 @@
+package pokerserver
+
+import (
+	"context"
+	"time"
+)
+
+// How long the work for a message may take, and the calls it makes out of
+// the process. A message's handlers run on the connection's read loop, so
+// these bound how long a slow integration can hold it up.
+const (
+	messageTimeout = 10 * time.Second
+	publishTimeout = 2 * time.Second
+	trackerTimeout = 5 * time.Second
+	webhookTimeout = 15 * time.Second
+)
+
+type contextKey int
+
+const (
+	clientIDKey contextKey = iota
+	roomIDKey
+	adminTeamKey
+)
+
+// messageContext derives the context a message is handled in from the
+// server's: cancelled after messageTimeout or on shutdown, and carrying the
+// client and room it concerns.
+func messageContext(parent context.Context, clientID, roomID string) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(parent, messageTimeout)
+	ctx = context.WithValue(ctx, clientIDKey, clientID)
+	if roomID != "" {
+		ctx = context.WithValue(ctx, roomIDKey, roomID)
+	}
+	return ctx, cancel
+}
+
+// ClientIDFromContext returns the client whose message ctx is handling, for
+// middleware and handlers registered with Handle.
+func ClientIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(clientIDKey).(string)
+	return id
+}
+
+// RoomIDFromContext returns the room named by the message ctx is handling.
+func RoomIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(roomIDKey).(string)
+	return id
+}
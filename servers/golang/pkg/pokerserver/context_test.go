@@ -0,0 +1,36 @@
+package pokerserver
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMessageContext(t *testing.T) {
+	server := New()
+	var handled context.Context
+	server.Handle("inspect", func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		handled = ws.Context()
+		if ClientIDFromContext(handled) != ws.ID || RoomIDFromContext(handled) != "ctx-room" {
+			t.Errorf("Expected the client and room in the context, got %q and %q", ClientIDFromContext(handled), RoomIDFromContext(handled))
+		}
+		deadline, ok := handled.Deadline()
+		if !ok || time.Until(deadline) > messageTimeout {
+			t.Errorf("Expected a deadline within %s, got %v", messageTimeout, deadline)
+		}
+		if handled.Err() != nil {
+			t.Errorf("Expected the context live while handling, got %v", handled.Err())
+		}
+	})
+
+	client := server.ConnectMemory()
+	defer client.Close()
+	client.Send("join-room", map[string]interface{}{"roomId": "ctx-room", "name": "Alice"})
+	client.Send("inspect", map[string]interface{}{"roomId": "ctx-room"})
+	if handled == nil {
+		t.Fatal("Expected the handler to run")
+	}
+	if handled.Err() != context.Canceled {
+		t.Errorf("Expected the context cancelled once the message was handled, got %v", handled.Err())
+	}
+}
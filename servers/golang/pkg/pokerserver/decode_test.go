@@ -0,0 +1,97 @@
+package pokerserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+func TestMalformedFieldsLeaveRoomAlone(t *testing.T) {
+	server := New()
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": "strict-room", "name": "Alice"})
+	alice.Send("vote", map[string]interface{}{"roomId": "strict-room", "vote": "5"})
+	alice.Send("update-story", map[string]interface{}{"roomId": "strict-room", "story": map[string]interface{}{"title": "Login"}})
+	alice.Send("set-final-estimate", map[string]interface{}{"roomId": "strict-room", "estimate": "5"})
+	alice.Send("set-passcode", map[string]interface{}{"roomId": "strict-room", "passcode": "secret"})
+
+	room, _ := server.hub.Room("strict-room")
+	snapshot := func() string {
+		room.Mu.RLock()
+		defer room.Mu.RUnlock()
+		data, _ := json.Marshal(newRoomSnapshot(room))
+		return string(data)
+	}
+	before := snapshot()
+
+	for _, msg := range []struct {
+		msgType string
+		data    map[string]interface{}
+	}{
+		{"vote", map[string]interface{}{"vote": 8}},
+		{"update-story", map[string]interface{}{"story": "Logout"}},
+		{"set-final-estimate", map[string]interface{}{"estimate": 8}},
+		{"set-passcode", map[string]interface{}{}},
+		{"update-name", map[string]interface{}{"name": []interface{}{"Bob"}}},
+		{"set-mode", map[string]interface{}{"mode": "two-dimensional", "dimensions": "a,b"}},
+	} {
+		msg.data["roomId"] = "strict-room"
+		alice.Send(msg.msgType, msg.data)
+		if after := snapshot(); after != before {
+			t.Errorf("Expected malformed %s to leave the room alone:\n%s\n%s", msg.msgType, before, after)
+			before = after
+		}
+	}
+}
+
+// panicWatcher is a log destination noting whether a handler panicked.
+type panicWatcher struct {
+	panicked atomic.Bool
+}
+
+func (w *panicWatcher) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte("Panic handling")) {
+		w.panicked.Store(true)
+	}
+	return len(p), nil
+}
+
+// FuzzHandleMessage runs decoded messages through the handlers against a room
+// the client facilitates, failing on any panic the recover middleware caught.
+func FuzzHandleMessage(f *testing.F) {
+	for _, seed := range []string{
+		`{"type":"vote","data":{"roomId":"fuzz-room","vote":"5"}}`,
+		`{"type":"set-mode","data":{"roomId":"fuzz-room","mode":"dot-voting","options":["a","b"],"dots":3}}`,
+		`{"type":"vote","data":{"roomId":"fuzz-room","dots":{"a":2}}}`,
+		`{"type":"open-batch","data":{"roomId":"fuzz-room","stories":[{"title":"x"}]}}`,
+		`{"type":"import-stories","data":{"roomId":"fuzz-room","csv":"title\nx,https://example.com"}}`,
+		`{"type":"open-async-round","data":{"roomId":"fuzz-room","deadline":1e15}}`,
+		`{"type":"reveal","data":{"roomId":"fuzz-room"}}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	watcher := &panicWatcher{}
+	cfg := DefaultConfig()
+	// High enough that fuzzing rarely hits it; the limiter preallocates its window
+	cfg.MessageRateLimit = 1 << 16
+	server := New(WithConfig(cfg), WithLogger(log.New(watcher, "", 0)))
+	client := server.ConnectMemory()
+	client.Send("join-room", map[string]interface{}{"roomId": "fuzz-room", "name": "Fuzz"})
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		message, err := transport.DecodeMessage(payload)
+		if err != nil {
+			return
+		}
+		server.handleMessage(client.ws, message)
+		if watcher.panicked.Load() {
+			t.Fatalf("Handler panicked on %s", payload)
+		}
+	})
+}
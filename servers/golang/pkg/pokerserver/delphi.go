@@ -0,0 +1,45 @@
+package pokerserver
+
+import roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+
+// handleSetDelphi lets the facilitator estimate in Delphi runs of "rounds"
+// iterations, 3 unless given, or stop with 0. The current story starts
+// again from the first iteration.
+func (s *Server) handleSetDelphi(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+	if !exists {
+		return
+	}
+
+	rounds, err := roompkg.ParseDelphiRounds(data)
+	if err != nil {
+		s.logf(ws.Context(), "⚠️ Ignoring set-delphi from %s in room %s: %v", ws.ID, roomID, err)
+		s.sendError(ws, "set-delphi", errCodeInvalidInput, err.Error(), nil)
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring set-delphi from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "set-delphi", errCodeNotAllowed, "Only the facilitator can start Delphi runs", nil)
+		return
+	}
+	if room.Settings.Mode != roompkg.ModePoints && rounds > 0 {
+		mode := room.Settings.Mode
+		room.Mu.Unlock()
+		s.sendError(ws, "set-delphi", errCodeNotAllowed, "Delphi runs estimate story points", map[string]interface{}{"mode": mode})
+		return
+	}
+	event := &roompkg.DelphiEvent{}
+	if rounds > 0 {
+		event.Delphi = &roompkg.Delphi{Rounds: rounds, Iteration: 1}
+	}
+	room.Apply(event)
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "🔮 Room %s Delphi rounds set to %d by %s", roomID, rounds, ws.ID)
+	s.broadcastRoomState(ws.Context(), roomID)
+}
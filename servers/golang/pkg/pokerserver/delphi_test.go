@@ -0,0 +1,59 @@
+package pokerserver
+
+import "testing"
+
+func TestDelphiRunsUntilConvergence(t *testing.T) {
+	server := New()
+	roomID := "delphi-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+
+	bob.Send("set-delphi", map[string]interface{}{"roomId": roomID, "rounds": float64(3)})
+	alice.Send("set-delphi", map[string]interface{}{"roomId": roomID, "rounds": float64(1)})
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	delphi := room.Delphi
+	room.Mu.RUnlock()
+	if delphi != nil {
+		t.Fatalf("Expected Delphi runs to be left off, got %+v", delphi)
+	}
+	alice.Send("set-delphi", map[string]interface{}{"roomId": roomID, "rounds": float64(3)})
+
+	// Votes far apart start the next iteration, showing only their spread
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "3"})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	next := awaitMessage(t, bob, "delphi-round").Data.(map[string]interface{})
+	previous := next["previous"].(map[string]interface{})
+	if next["iteration"] != float64(2) || previous["3"] != float64(1) || previous["8"] != float64(1) {
+		t.Errorf("Expected iteration 2 with the spread of the first, got %v", next)
+	}
+	room.Mu.RLock()
+	if room.Revealed || room.LastRound != nil || room.Participants[alice.ID].Vote != nil {
+		t.Errorf("Expected a fresh, anonymous iteration, got revealed %t with last round %+v", room.Revealed, room.LastRound)
+	}
+	room.Mu.RUnlock()
+
+	// Close votes end the run with a full reveal
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	revealed := awaitMessage(t, bob, "revealed").Data.(map[string]interface{})
+	if result := revealed["delphi"].(map[string]interface{}); result["iteration"] != float64(2) || result["converged"] != true {
+		t.Errorf("Expected the run to converge at iteration 2, got %v", result)
+	}
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if len(room.History) != 2 || room.History[0].DelphiIteration != 1 || room.History[1].DelphiIteration != 2 {
+		t.Errorf("Expected both iterations in the history, got %+v", room.History)
+	}
+	if room.Delphi.Iteration != 1 || room.Delphi.Previous != nil {
+		t.Errorf("Expected the next story to start a new run, got %+v", room.Delphi)
+	}
+}
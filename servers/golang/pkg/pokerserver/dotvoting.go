@@ -0,0 +1,29 @@
+package pokerserver
+
+import roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+
+// voteDots replaces the participant's dots with the allocation in the
+// message's "dots" object. An empty object takes them all back.
+func (s *Server) voteDots(ws *ExtendedWebSocket, room *roompkg.State, data map[string]interface{}) {
+	raw, _ := data["dots"].(map[string]interface{})
+
+	room.Mu.Lock()
+	if _, ok := room.Participants[ws.ID]; !ok || room.Revealed {
+		room.Mu.Unlock()
+		return
+	}
+	dots, err := roompkg.ParseDots(room.Settings, raw)
+	if err != nil {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring dots from %s in room %s: %v", ws.ID, room.ID, err)
+		s.sendError(ws, "vote", errCodeInvalidInput, err.Error(), nil)
+		return
+	}
+	room.Apply(&roompkg.DotsEvent{ClientID: ws.ID, Dots: dots})
+	if len(dots) > 0 {
+		room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditVoted, ActorID: ws.ID})
+	}
+	room.Mu.Unlock()
+
+	s.broadcastToRoom(ws.Context(), room.ID, "participant-voted", map[string]interface{}{"id": ws.ID, "hasVote": len(dots) > 0})
+}
@@ -0,0 +1,47 @@
+package pokerserver
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sources of reported errors
+const (
+	ErrorSourcePanic   = "panic"
+	ErrorSourceRedis   = "redis"
+	ErrorSourceWebhook = "webhook"
+)
+
+// ErrorEvent is an error worth an operator's attention, with what is known
+// of where it happened.
+type ErrorEvent struct {
+	Err    error
+	Source string
+	// RoomID and ClientID are set when the error concerns a room or client
+	RoomID   string
+	ClientID string
+	// MessageType is the message being handled, for panics
+	MessageType string
+	// Stack is the goroutine's stack, for panics
+	Stack   []byte
+	TraceID string
+}
+
+// ErrorReporter sends errors to an error tracking service such as Sentry.
+// Report must not block, as it is called while handling messages.
+type ErrorReporter interface {
+	Report(event ErrorEvent)
+}
+
+// reportError hands an error to the reporter, if one is configured, with the
+// trace of ctx.
+func (s *Server) reportError(ctx context.Context, event ErrorEvent) {
+	if s.errorReporter == nil {
+		return
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		event.TraceID = sc.TraceID().String()
+	}
+	s.errorReporter.Report(event)
+}
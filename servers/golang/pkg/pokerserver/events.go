@@ -0,0 +1,26 @@
+package pokerserver
+
+import (
+	"net/http"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// handleAdminRoomEvents returns a room's event log, which room.Replay turns
+// back into the room.
+func (s *Server) handleAdminRoomEvents(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.adminRoom(w, r)
+	if !ok {
+		return
+	}
+	roomID := room.ID
+	room.Mu.RLock()
+	events := make([]roompkg.EventRecord, len(room.Events))
+	copy(events, room.Events)
+	room.Mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"roomId": roomID,
+		"events": events,
+	})
+}
@@ -0,0 +1,214 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// replayedState is what the events of a points round decide about a room, as
+// JSON to leave out secrets such as session tokens.
+func replayedState(room *roompkg.State) string {
+	participants := map[string]string{}
+	for id, p := range room.Participants {
+		vote := ""
+		if p.Vote != nil {
+			vote = *p.Vote
+		}
+		participants[id] = p.Name + "|" + vote + "|" + p.Confidence + "|" + map[bool]string{true: "paused"}[p.Paused]
+	}
+	state, _ := json.Marshal(map[string]interface{}{
+		"participants": participants,
+		"facilitator":  room.FacilitatorID,
+		"revealed":     room.Revealed,
+		"story":        room.Story,
+		"lastRound":    room.LastRound,
+		"history":      room.History,
+		"roundStarted": room.RoundStartedAt,
+	})
+	return string(state)
+}
+
+func TestReplayRoom(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	server := New(WithClock(clock), WithAdminToken("secret"))
+	roomID := "replay-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	carol := server.ConnectMemory()
+	defer carol.Close()
+	carol.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Carol"})
+
+	clock.Advance(time.Minute)
+	alice.Send("update-story", map[string]interface{}{"roomId": roomID, "story": map[string]interface{}{"title": "Login"}})
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5", "confidence": "high"})
+	clock.Advance(time.Minute)
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	carol.Send("suspend-voting", map[string]interface{}{"roomId": roomID})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	alice.Send("set-final-estimate", map[string]interface{}{"roomId": roomID, "estimate": "8"})
+	clock.Advance(time.Minute)
+	alice.Send("reestimate", map[string]interface{}{"roomId": roomID})
+	bob.Send("update-name", map[string]interface{}{"roomId": roomID, "name": "Robert"})
+	bob.Send("leave-room", map[string]interface{}{"roomId": roomID})
+
+	rec := adminRequest(server.Handler(), http.MethodGet, "/admin/rooms/"+roomID+"/events", "secret")
+	var body struct {
+		Events []roompkg.EventRecord `json:"events"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode the event log: %v", err)
+	}
+	if len(body.Events) != 12 {
+		t.Fatalf("Expected 12 events, got %d", len(body.Events))
+	}
+
+	replayed, err := roompkg.Replay(roomID, body.Events)
+	if err != nil {
+		t.Fatalf("ReplayRoom failed: %v", err)
+	}
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	live := replayedState(room)
+	room.Mu.RUnlock()
+	if got := replayedState(replayed); got != live {
+		t.Errorf("Expected the replayed room to match:\n%v\n%v", got, live)
+	}
+}
+
+// sessionState is everything the events of a session decide about a room,
+// as JSON to leave out secrets such as session tokens.
+func sessionState(room *roompkg.State) string {
+	votedAt := map[string]time.Time{}
+	for id, p := range room.Participants {
+		votedAt[id] = p.VotedAt
+	}
+	state, _ := json.Marshal(map[string]interface{}{
+		"participants":     room.Participants,
+		"votedAt":          votedAt,
+		"facilitator":      room.FacilitatorID,
+		"revealed":         room.Revealed,
+		"secondRevealed":   room.SecondRevealed,
+		"story":            room.Story,
+		"lastRound":        room.LastRound,
+		"history":          room.History,
+		"chat":             room.Chat,
+		"roundStarted":     room.RoundStartedAt,
+		"settings":         room.Settings,
+		"revealPolicy":     room.RevealPolicy,
+		"revealRequests":   room.RevealRequests,
+		"allowVoteChanges": room.AllowVoteChanges,
+		"delphi":           room.Delphi,
+		"team":             room.TeamID,
+		"teamScoped":       room.TeamScoped,
+		"backlog":          room.Backlog,
+		"batch":            room.Batch,
+		"deadline":         room.Deadline,
+		"closed":           room.Closed,
+	})
+	return string(state)
+}
+
+func TestReplayMixedSession(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	server := New(WithClock(clock), WithAdminToken("secret"))
+	roomID := "mixed-replay-room"
+	send := func(client *MemoryClient, msgType string, data map[string]interface{}) {
+		data["roomId"] = roomID
+		client.Send(msgType, data)
+		clock.Advance(time.Second)
+	}
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	carol := server.ConnectMemory()
+	send(alice, "join-room", map[string]interface{}{"name": "Alice"})
+	send(bob, "join-room", map[string]interface{}{"name": "Bob"})
+	send(carol, "join-room", map[string]interface{}{"name": "Carol"})
+	send(alice, "set-team", map[string]interface{}{"teamId": "core"})
+
+	// A points round from the backlog, with hands, statuses, chat and a
+	// reveal asked for by the room
+	send(alice, "import-stories", map[string]interface{}{"stories": []interface{}{
+		map[string]interface{}{"title": "Login"}, map[string]interface{}{"title": "Search"},
+	}})
+	send(alice, "next-story", map[string]interface{}{})
+	send(alice, "set-vote-changes", map[string]interface{}{"allowed": true})
+	send(alice, "set-reveal-policy", map[string]interface{}{"policy": "majority-request"})
+	send(alice, "vote", map[string]interface{}{"vote": "5"})
+	send(bob, "vote", map[string]interface{}{"vote": "8"})
+	send(carol, "raise-hand", map[string]interface{}{})
+	send(bob, "set-status", map[string]interface{}{"status": "On a call"})
+	send(alice, "chat-message", map[string]interface{}{"text": "Why 8?"})
+	send(bob, "clear-vote", map[string]interface{}{})
+	send(bob, "vote", map[string]interface{}{"vote": "3"})
+	send(carol, "request-reveal", map[string]interface{}{})
+	send(bob, "request-reveal", map[string]interface{}{})
+	send(bob, "vote", map[string]interface{}{"vote": "5"})
+	send(alice, "reopen-round", map[string]interface{}{})
+	send(alice, "set-reveal-policy", map[string]interface{}{"policy": "anyone"})
+	send(carol, "suspend-voting", map[string]interface{}{})
+	send(alice, "reveal", map[string]interface{}{})
+	send(alice, "set-final-estimate", map[string]interface{}{"estimate": "5"})
+
+	// Other modes
+	send(alice, "set-mode", map[string]interface{}{"mode": "two-dimensional", "dimensions": []interface{}{"effort", "risk"}})
+	send(alice, "vote", map[string]interface{}{"vote": "5", "secondVote": "3"})
+	send(bob, "vote", map[string]interface{}{"vote": "8"})
+	send(alice, "reveal", map[string]interface{}{"dimension": "effort"})
+	send(bob, "vote", map[string]interface{}{"secondVote": "2"})
+	send(alice, "reveal", map[string]interface{}{})
+	send(alice, "set-mode", map[string]interface{}{"mode": "pert"})
+	send(alice, "vote", map[string]interface{}{"optimistic": 1, "mostLikely": 2, "pessimistic": 9})
+	send(bob, "vote", map[string]interface{}{"optimistic": 3, "mostLikely": 4, "pessimistic": 5})
+	send(alice, "reveal", map[string]interface{}{})
+	send(alice, "set-mode", map[string]interface{}{"mode": "dot-voting", "options": []interface{}{"a", "b"}, "dots": 3})
+	send(alice, "vote", map[string]interface{}{"dots": map[string]interface{}{"1": 2, "2": 1}})
+	send(alice, "reveal", map[string]interface{}{})
+
+	// Delphi, then a batch
+	send(alice, "set-mode", map[string]interface{}{"mode": "points"})
+	send(alice, "set-delphi", map[string]interface{}{"rounds": float64(2)})
+	send(alice, "vote", map[string]interface{}{"vote": "3"})
+	send(bob, "vote", map[string]interface{}{"vote": "13"})
+	send(alice, "reveal", map[string]interface{}{})
+	send(alice, "open-batch", map[string]interface{}{"stories": []interface{}{
+		map[string]interface{}{"title": "Search"}, map[string]interface{}{"title": "Checkout"},
+	}})
+	send(alice, "vote", map[string]interface{}{"storyId": "1", "vote": "3"})
+	send(bob, "vote", map[string]interface{}{"storyId": "2", "vote": "8"})
+	send(alice, "reveal", map[string]interface{}{"storyId": "1"})
+	send(alice, "reveal", map[string]interface{}{})
+
+	carol.Close()
+	send(bob, "update-name", map[string]interface{}{"name": "Robert"})
+	send(bob, "leave-room", map[string]interface{}{})
+
+	rec := adminRequest(server.Handler(), http.MethodGet, "/admin/rooms/"+roomID+"/events", "secret")
+	var body struct {
+		Events []roompkg.EventRecord `json:"events"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode the event log: %v", err)
+	}
+	replayed, err := roompkg.Replay(roomID, body.Events)
+	if err != nil {
+		t.Fatalf("ReplayRoom failed: %v", err)
+	}
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	live := sessionState(room)
+	room.Mu.RUnlock()
+	if got := sessionState(replayed); got != live {
+		t.Errorf("Expected the replayed room to match:\n%v\n%v", got, live)
+	}
+}
@@ -0,0 +1,70 @@
+package pokerserver
+
+import (
+	"context"
+	"net/http"
+)
+
+// roomQueries are messages that ask about a room rather than act in it. The
+// room middleware passes them straight through: they don't claim the room,
+// keep it alive or need the client to have joined it.
+var roomQueries = map[string]bool{
+	"room-exists": true,
+}
+
+// ownerLookup is implemented by ownership stores that can tell which instance
+// holds a room without claiming it.
+type ownerLookup interface {
+	// Owner returns the instance holding roomID, or "" if nobody does.
+	Owner(ctx context.Context, roomID string) (string, error)
+}
+
+// roomExists reports whether roomID is open on this instance or, when rooms
+// are routed to their owners, on any instance. Without an ownership store
+// that supports lookups, only local rooms are seen.
+func (s *Server) roomExists(ctx context.Context, roomID string) (bool, error) {
+	if _, ok := s.hub.Room(roomID); ok {
+		return true, nil
+	}
+	if !s.ownershipEnabled() {
+		return false, nil
+	}
+	lookup, ok := s.ownership.(ownerLookup)
+	if !ok {
+		return false, nil
+	}
+	owner, err := lookup.Owner(ctx, roomID)
+	return owner != "", err
+}
+
+// handleRoomExists tells a client whether a room is already open, so joining
+// it can be told apart from creating it.
+func (s *Server) handleRoomExists(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID := data["roomId"].(string)
+	exists, err := s.roomExists(ws.Context(), roomID)
+	if err != nil {
+		s.logf(ws.Context(), "Error looking up room %s: %v", roomID, err)
+		s.sendError(ws, "room-exists", errCodeInternal, "Couldn't look the room up", nil)
+		return
+	}
+	s.sendToClient(ws, "room-exists", map[string]interface{}{
+		"roomId": roomID,
+		"exists": exists,
+	})
+}
+
+// handleRoomExistsHTTP answers HEAD /api/rooms/{id} with 200 for an open room
+// and 404 otherwise.
+func (s *Server) handleRoomExistsHTTP(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("id")
+	exists, err := s.roomExists(r.Context(), roomID)
+	switch {
+	case err != nil:
+		s.logger.Printf("Error looking up room %s: %v", roomID, err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case exists:
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
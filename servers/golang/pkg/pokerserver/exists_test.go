@@ -0,0 +1,51 @@
+package pokerserver
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRoomExists(t *testing.T) {
+	server := New()
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": "open-room", "name": "Alice"})
+
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	for roomID, want := range map[string]bool{"open-room": true, "opne-room": false} {
+		bob.Send("room-exists", map[string]interface{}{"roomId": roomID})
+		data := awaitMessage(t, bob, "room-exists").Data.(map[string]interface{})
+		if data["roomId"] != roomID || data["exists"] != want {
+			t.Errorf("Expected %s to exist: %v, got %v", roomID, want, data)
+		}
+	}
+	if _, ok := server.hub.Room("opne-room"); ok {
+		t.Error("Expected asking about a room not to create it")
+	}
+
+	handler := server.Handler()
+	if rec := adminRequest(handler, http.MethodHead, "/api/rooms/open-room", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for an open room, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodHead, "/api/rooms/opne-room", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing room, got %d", rec.Code)
+	}
+}
+
+func TestRoomExistsOnOtherInstance(t *testing.T) {
+	ownership := newMemoryOwnership()
+	ownership.owners["remote-room"] = "other-instance"
+	server := New(WithBroker(NewMemoryBroker()), WithRoomOwnership(ownership))
+
+	handler := server.Handler()
+	if rec := adminRequest(handler, http.MethodHead, "/api/rooms/remote-room", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a room owned elsewhere, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodHead, "/api/rooms/missing-room", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing room, got %d", rec.Code)
+	}
+	if owner, _ := ownership.Owner(t.Context(), "missing-room"); owner != "" {
+		t.Errorf("Expected the lookup not to claim the room, got owner %q", owner)
+	}
+}
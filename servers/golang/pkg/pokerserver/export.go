@@ -0,0 +1,138 @@
+package pokerserver
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("id")
+
+	rounds, passcodeHash, ok := s.roomHistory(r.Context(), roomID)
+	if !ok {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	if !s.authorizeRoomRead(w, r, passcodeHash) {
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+exportFilename(roomID, "json")+`"`)
+		export := map[string]interface{}{
+			"roomId":     roomID,
+			"exportedAt": s.clock.Now().UnixMilli(),
+			"rounds":     rounds,
+		}
+		// Only live rooms keep an audit log, stored sessions have their rounds alone
+		if audit, ok := s.roomAudit(roomID); ok {
+			export["audit"] = audit
+		}
+		json.NewEncoder(w).Encode(export)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+exportFilename(roomID, "csv")+`"`)
+		if err := writeRoundsCSV(w, rounds); err != nil {
+			s.logger.Printf("Error writing CSV export for room %s: %v", roomID, err)
+		}
+	default:
+		http.Error(w, "unsupported format: "+format, http.StatusBadRequest)
+	}
+}
+
+// roomHistory returns the rounds of a live room, or of its last stored session
+// once the room is gone, along with the passcode hash guarding them.
+func (s *Server) roomHistory(ctx context.Context, roomID string) ([]RoundRecord, []byte, bool) {
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		session, rounds, ok := s.loadStoredSession(ctx, roomID)
+		return rounds, session.PasscodeHash, ok
+	}
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	rounds := make([]RoundRecord, len(room.History))
+	copy(rounds, room.History)
+	return rounds, room.Passcode, true
+}
+
+// writeRoundsCSV writes one row per vote, repeating the round columns so the
+// file can be pivoted directly in a spreadsheet. The second_ columns are only
+// filled for two-dimensional rounds, and original_vote for votes changed
+// after the reveal.
+func writeRoundsCSV(w http.ResponseWriter, rounds []RoundRecord) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{
+		"round_id", "revealed_at", "story_title", "story_link", "final_estimate",
+		"participant", "vote", "average", "median", "min", "max", "confidence",
+		"second_vote", "second_average", "second_median", "second_min", "second_max",
+		"round_seconds", "vote_seconds", "original_vote",
+	})
+
+	for _, round := range rounds {
+		title, link := "", ""
+		if round.Story != nil {
+			title, link = round.Story.Title, round.Story.Link
+		}
+		revealedAt := time.UnixMilli(round.RevealedAt).UTC().Format(time.RFC3339)
+		second := roompkg.VoteStats{}
+		if round.SecondStats != nil {
+			second = *round.SecondStats
+		}
+		for _, vote := range round.Votes {
+			writer.Write([]string{
+				round.ID, revealedAt, title, link, round.FinalEstimate,
+				vote.Name, vote.Vote,
+				formatStat(round.Stats.Average), formatStat(round.Stats.Median),
+				formatStat(round.Stats.Min), formatStat(round.Stats.Max),
+				vote.Confidence,
+				vote.SecondVote,
+				formatStat(second.Average), formatStat(second.Median),
+				formatStat(second.Min), formatStat(second.Max),
+				formatSeconds(round.DurationMs), formatSeconds(vote.VoteTimeMs),
+				vote.OriginalVote,
+			})
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatStat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// formatSeconds writes a duration in milliseconds as seconds, or nothing for
+// an untimed one.
+func formatSeconds(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(ms)/1000, 'f', -1, 64)
+}
+
+// exportFilename keeps only filename-safe characters from the room ID so it
+// can't break out of the Content-Disposition header.
+func exportFilename(roomID, ext string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, roomID)
+	return "planning-poker-" + safe + "." + ext
+}
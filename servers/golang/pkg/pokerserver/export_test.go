@@ -0,0 +1,205 @@
+package pokerserver
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// playRound joins a room, votes, reveals and sets a final estimate
+func playRound(t *testing.T, server *Server) string {
+	httpServer, ws := createTestWSConnection(t, server)
+	t.Cleanup(httpServer.Close)
+	t.Cleanup(func() { ws.Close() })
+
+	roomID := "export-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "update-story", map[string]interface{}{
+		"roomId": roomID,
+		"story": map[string]interface{}{
+			"title": "Checkout flow",
+			"link":  "https://example.com/1",
+		},
+	})
+	readMessage(t, ws, 2*time.Second) // story-updated
+
+	sendMessage(t, ws, "vote", map[string]interface{}{
+		"roomId": roomID,
+		"vote":   "8",
+	})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+
+	sendMessage(t, ws, "reveal", map[string]interface{}{
+		"roomId": roomID,
+	})
+	readMessage(t, ws, 2*time.Second) // revealed
+	readMessage(t, ws, 2*time.Second) // consensus
+
+	sendMessage(t, ws, "set-final-estimate", map[string]interface{}{
+		"roomId":   roomID,
+		"estimate": "8",
+	})
+	readMessage(t, ws, 2*time.Second) // story-updated
+
+	return roomID
+}
+
+func exportRequest(server *Server, roomID, format string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/rooms/"+roomID+"/export?format="+format, nil)
+	req.SetPathValue("id", roomID)
+	rec := httptest.NewRecorder()
+	server.handleExport(rec, req)
+	return rec
+}
+
+func TestExportJSON(t *testing.T) {
+	server := New()
+	roomID := playRound(t, server)
+
+	rec := exportRequest(server, roomID, "json")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		RoomID string        `json:"roomId"`
+		Rounds []RoundRecord `json:"rounds"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode export: %v", err)
+	}
+	if len(body.Rounds) != 1 {
+		t.Fatalf("Expected 1 round, got %d", len(body.Rounds))
+	}
+	round := body.Rounds[0]
+	if round.Story == nil || round.Story.Title != "Checkout flow" {
+		t.Errorf("Expected story title in round, got %+v", round.Story)
+	}
+	if round.FinalEstimate != "8" {
+		t.Errorf("Expected final estimate 8, got %q", round.FinalEstimate)
+	}
+	if len(round.Votes) != 1 || round.Votes[0].Vote != "8" {
+		t.Errorf("Unexpected votes: %+v", round.Votes)
+	}
+	if round.Stats.Average == nil || *round.Stats.Average != 8 {
+		t.Errorf("Expected average 8, got %v", round.Stats.Average)
+	}
+	if round.StartedAt == 0 || round.DurationMs <= 0 {
+		t.Errorf("Expected the round to be timed, got startedAt %d, duration %d", round.StartedAt, round.DurationMs)
+	}
+	if len(round.Votes) == 1 && (round.Votes[0].VoteTimeMs <= 0 || round.Votes[0].VoteTimeMs > round.DurationMs) {
+		t.Errorf("Expected a vote time within the round's %dms, got %d", round.DurationMs, round.Votes[0].VoteTimeMs)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	server := New()
+	roomID := playRound(t, server)
+
+	rec := exportRequest(server, roomID, "csv")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Expected text/csv content type, got %s", ct)
+	}
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected header and 1 data row, got %d rows", len(rows))
+	}
+	if rows[1][2] != "Checkout flow" || rows[1][5] != "Alice" || rows[1][6] != "8" {
+		t.Errorf("Unexpected CSV row: %v", rows[1])
+	}
+	if n := len(rows[1]); rows[1][n-3] == "" || rows[1][n-2] == "" {
+		t.Errorf("Expected round and vote durations, got %v", rows[1])
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	server := New()
+
+	if rec := exportRequest(server, "missing", "json"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown room, got %d", rec.Code)
+	}
+
+	server.getOrCreateRoom("room")
+	if rec := exportRequest(server, "room", "xml"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unsupported format, got %d", rec.Code)
+	}
+}
+
+// fakeSessionStorage serves one stored session, as if the room had been
+// persisted before a restart.
+type fakeSessionStorage struct {
+	session Session
+	rounds  []RoundRecord
+}
+
+func (f *fakeSessionStorage) SaveRound(ctx context.Context, session Session, round RoundRecord) error {
+	return nil
+}
+
+func (f *fakeSessionStorage) LoadSession(ctx context.Context, roomID string) (Session, []RoundRecord, error) {
+	if roomID != f.session.RoomID {
+		return Session{}, nil, ErrSessionNotFound
+	}
+	return f.session, f.rounds, nil
+}
+
+func TestExportFromStoredSession(t *testing.T) {
+	hash, err := hashPasscode("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &fakeSessionStorage{
+		session: Session{RoomID: "old-room", StartedAt: time.Now().Add(-time.Hour), PasscodeHash: hash},
+		rounds: []RoundRecord{{
+			ID:            "1",
+			Story:         &roompkg.Story{Title: "Checkout"},
+			Votes:         []roompkg.RoundVote{{Name: "Alice", Vote: "5"}},
+			FinalEstimate: "5",
+			RevealedAt:    time.Now().UnixMilli(),
+		}},
+	}
+	server := New(WithStorage(storage))
+
+	if rec := exportRequest(server, "old-room", "json"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected stored private session to require the passcode, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/rooms/old-room/export?format=csv", nil)
+	req.Header.Set(passcodeHeader, "secret")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Checkout") {
+		t.Errorf("Expected stored rounds in export, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/rooms/old-room/report?passcode=secret", nil)
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Alice") {
+		t.Errorf("Expected stored rounds in report, got %d", rec.Code)
+	}
+
+	if rec := exportRequest(server, "missing", "json"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected unknown room to 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,55 @@
+package pokerserver
+
+import (
+	"net/http"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// handleAdminFirehose streams events to an operator until either side hangs
+// up. Operators only listen; what they send is ignored.
+func (s *Server) handleAdminFirehose(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Printf("Error upgrading admin stream: %v", err)
+		return
+	}
+	defer conn.Close()
+	ws := &ExtendedWebSocket{Conn: conn}
+
+	sub := s.firehose.Subscribe(adminTeam(r))
+	defer s.firehose.Unsubscribe(sub)
+	s.logger.Printf("📡 Admin stream opened from %s", r.RemoteAddr)
+
+	hungUp := make(chan struct{})
+	go func() {
+		defer close(hungUp)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := s.clock.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case message, ok := <-sub.Events:
+			if !ok {
+				ws.CloseWith(transport.CloseSlowClient, "too slow to keep up")
+				return
+			}
+			if err := ws.WriteJSON(message); err != nil {
+				return
+			}
+		case <-heartbeat.C():
+			ws.Ping(s.clock.Now())
+		case <-hungUp:
+			return
+		case <-s.ctx.Done():
+			ws.CloseWith(transport.CloseShutdown, "server shutting down")
+			return
+		}
+	}
+}
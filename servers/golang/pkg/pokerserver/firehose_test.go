@@ -0,0 +1,60 @@
+package pokerserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestAdminFirehose(t *testing.T) {
+	server := New(WithAdminToken("secret"))
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/admin/ws"
+
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected the stream to require the admin token, got %v", err)
+	}
+	stream, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"Bearer secret"}})
+	if err != nil {
+		t.Fatalf("Failed to open the admin stream: %v", err)
+	}
+	defer stream.Close()
+	// Give the stream a moment to subscribe
+	time.Sleep(50 * time.Millisecond)
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": "watched-room", "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+	alice.Send("vote", map[string]interface{}{"roomId": "watched-room", "vote": "13"})
+	alice.Send("vote", map[string]interface{}{"roomId": "watched-room", "vote": "banana"})
+
+	for _, expected := range []string{"joined", "voted", errCodeInvalidInput} {
+		msg := readMessage(t, stream, 2*time.Second)
+		data := msg.Data.(map[string]interface{})
+		if data["roomId"] != "watched-room" {
+			t.Errorf("Expected events of watched-room, got %v", data)
+		}
+		switch msg.Type {
+		case "audit":
+			entry := data["entry"].(map[string]interface{})
+			if entry["action"] != expected || entry["actor"] != "Alice" {
+				t.Errorf("Expected Alice %s, got %v", expected, entry)
+			}
+			if _, ok := entry["votes"]; ok {
+				t.Errorf("Expected the vote hidden until the reveal, got %v", entry)
+			}
+		case "error":
+			if code := data["error"].(map[string]interface{})["code"]; code != expected {
+				t.Errorf("Expected a %s error, got %v", expected, data)
+			}
+		default:
+			t.Errorf("Unexpected %s", msg.Type)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package pokerserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const defaultGitLabURL = "https://gitlab.com"
+
+var gitLabIssuePattern = regexp.MustCompile(`^/(.+?)/-/issues/([0-9]+)/?$`)
+
+// GitLabClient fetches issue details from the GitLab REST API, on gitlab.com
+// or a self-managed instance.
+type GitLabClient struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+}
+
+type GitLabIssue struct {
+	Reference   string
+	Title       string
+	Description string
+}
+
+// newGitLabClientFromConfig returns nil when the GitLab integration isn't
+// configured.
+func newGitLabClientFromConfig(cfg GitLabConfig, outbound outboundPolicy) *GitLabClient {
+	if cfg.Token == "" {
+		return nil
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultGitLabURL
+	}
+
+	client, err := newGitLabClient(baseURL, cfg.Token)
+	if err != nil {
+		log.Printf("GitLab integration disabled: %v", err)
+		return nil
+	}
+	client.httpClient = outbound.client()
+	return client
+}
+
+func newGitLabClient(baseURL, token string) (*GitLabClient, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab base URL: %w", err)
+	}
+	return &GitLabClient{
+		baseURL:    parsed,
+		token:      token,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// IssueKeyFromLink turns a link such as
+// https://gitlab.com/group/project/-/issues/42 into "group/project#42".
+// Links to other hosts are ignored.
+func (c *GitLabClient) IssueKeyFromLink(link string) (string, bool) {
+	parsed, err := url.Parse(link)
+	if err != nil || !strings.EqualFold(parsed.Host, c.baseURL.Host) {
+		return "", false
+	}
+
+	path := strings.TrimPrefix(parsed.Path, c.baseURL.Path)
+	matches := gitLabIssuePattern.FindStringSubmatch(path)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1] + "#" + matches[2], true
+}
+
+func (c *GitLabClient) FetchIssue(ctx context.Context, key string) (*GitLabIssue, error) {
+	project, iid, ok := strings.Cut(key, "#")
+	if !ok {
+		return nil, fmt.Errorf("invalid gitlab issue %q", key)
+	}
+	endpoint := c.baseURL.String() + "/api/v4/projects/" + url.PathEscape(project) + "/issues/" + url.PathEscape(iid)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gitlab returned status %d for %s", resp.StatusCode, key)
+	}
+
+	var body struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		References  struct {
+			Full string `json:"full"`
+		} `json:"references"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding gitlab issue %s: %w", key, err)
+	}
+
+	reference := body.References.Full
+	if reference == "" {
+		reference = key
+	}
+	return &GitLabIssue{
+		Reference:   reference,
+		Title:       body.Title,
+		Description: body.Description,
+	}, nil
+}
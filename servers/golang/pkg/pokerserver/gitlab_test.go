@@ -0,0 +1,80 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestGitLabIssueKeyFromLink(t *testing.T) {
+	client, err := newGitLabClient("https://gitlab.example.com/", "token")
+	if err != nil {
+		t.Fatalf("Failed to create GitLab client: %v", err)
+	}
+
+	tests := []struct {
+		link string
+		key  string
+		ok   bool
+	}{
+		{"https://gitlab.example.com/acme/web/-/issues/42", "acme/web#42", true},
+		{"https://gitlab.example.com/acme/platform/api/-/issues/7/", "acme/platform/api#7", true},
+		{"https://gitlab.example.com/acme/web/-/merge_requests/42", "", false},
+		{"https://gitlab.com/acme/web/-/issues/42", "", false},
+	}
+	for _, tt := range tests {
+		key, ok := client.IssueKeyFromLink(tt.link)
+		if key != tt.key || ok != tt.ok {
+			t.Errorf("IssueKeyFromLink(%q) = %q, %v; want %q, %v", tt.link, key, ok, tt.key, tt.ok)
+		}
+	}
+}
+
+func TestUpdateStoryEnrichesFromGitLab(t *testing.T) {
+	gitLabServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.EscapedPath() != "/api/v4/projects/acme%2Fweb/issues/42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"iid":         42,
+			"title":       "Dark mode",
+			"description": "Follow the system theme",
+			"references":  map[string]interface{}{"full": "acme/web#42"},
+		})
+	}))
+	defer gitLabServer.Close()
+
+	server := New()
+	client, err := newGitLabClient(gitLabServer.URL, "secret")
+	if err != nil {
+		t.Fatalf("Failed to create GitLab client: %v", err)
+	}
+	server.gitlab = client
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "gitlab-room"
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "update-story", map[string]interface{}{
+		"roomId": roomID,
+		"story":  map[string]interface{}{"title": "", "link": gitLabServer.URL + "/acme/web/-/issues/42"},
+	})
+	msg := readMessage(t, ws, 2*time.Second)
+	story := msg.Data.(map[string]interface{})["story"].(map[string]interface{})
+	if story["key"] != "acme/web#42" || story["tracker"] != roompkg.TrackerGitLab || story["title"] != "Dark mode" {
+		t.Errorf("Expected the story to be enriched from GitLab, got %v", story)
+	}
+}
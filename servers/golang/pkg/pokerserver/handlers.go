@@ -0,0 +1,1091 @@
+package pokerserver
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// Reactions are ephemeral, so a small burst is fine but floods are not
+const (
+	reactionLimit  = 5
+	reactionWindow = 3 * time.Second
+)
+
+// A client picking a card sends selecting continuously; others only need to
+// hear about it once in a while
+const selectingInterval = time.Second
+
+// Confidence a voter can attach to their vote
+var confidenceLevels = map[string]bool{"high": true, "medium": true, "low": true}
+
+var allowedReactions = map[string]bool{
+	"👍": true, "👎": true, "🎉": true, "☕": true, "🤔": true,
+	"😂": true, "😮": true, "👏": true, "🔥": true, "❤️": true,
+}
+
+func (s *Server) handleJoinRoom(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, ok := data["roomId"].(string)
+	if !ok {
+		s.logf(ws.Context(), "❌ Invalid roomId in join-room event")
+		return
+	}
+	name, _ := data["name"].(string)
+	participantId, _ := data["participantId"].(string)
+	sessionToken, _ := data["sessionToken"].(string)
+	passcode, _ := data["passcode"].(string)
+	inviteToken, _ := data["inviteToken"].(string)
+	teamID, _ := data["teamId"].(string)
+	resumable, _ := data["resumable"].(bool)
+	if ws.UserName != "" {
+		// Signed-in clients are named by their identity provider
+		name = ws.UserName
+	}
+	name, invalid := checkName(name)
+	if invalid != nil {
+		s.logf(ws.Context(), "⛔ Rejecting join for %s in room %s: %v", ws.ID, roomID, invalid)
+		details := invalid.details()
+		details["roomId"], details["reason"] = roomID, "invalid-name"
+		s.sendToClient(ws, "join-rejected", details)
+		return
+	}
+	s.logf(ws.Context(), "📥 join-room: roomId=%s, name=%s, participantId=%s, clientId=%s", roomID, name, participantId, ws.ID)
+
+	// Only create the room once the join is let in, so a refused one leaves
+	// no empty room, nor a lease on it, behind
+	room, exists := s.hub.Room(roomID)
+	if exists && !s.admitJoin(ws, room, sessionToken, participantId, passcode, inviteToken) {
+		return
+	}
+	if !exists {
+		// Another join may have created the room meanwhile, behind a passcode
+		if room = s.getOrCreateRoom(roomID); !s.admitJoin(ws, room, sessionToken, participantId, passcode, inviteToken) {
+			return
+		}
+	}
+
+	room.Mu.Lock()
+	// Whoever creates the room can make it private right away by joining
+	// with a passcode, or file it under a team. Connecting with a team's
+	// token makes the room the team's
+	if len(room.Participants) == 0 {
+		if passcode != "" && room.Passcode == nil {
+			hash, err := hashPasscode(passcode)
+			if err != nil {
+				// Such as a passcode longer than bcrypt takes
+				room.Mu.Unlock()
+				s.logf(ws.Context(), "❌ Failed to hash passcode for room %s: %v", roomID, err)
+				s.sendToClient(ws, "join-rejected", map[string]interface{}{
+					"roomId": roomID,
+					"reason": "invalid-passcode",
+				})
+				if !exists {
+					s.discardRoom(room)
+				}
+				return
+			}
+			room.Passcode = hash
+		}
+		if ws.TeamID != "" {
+			room.Apply(&roompkg.TeamEvent{TeamID: ws.TeamID, Scoped: true})
+		} else if teamID != "" && room.TeamID == "" && teamIDPattern.MatchString(teamID) {
+			room.Apply(&roompkg.TeamEvent{TeamID: teamID})
+		}
+	} else if !room.Admits(ws.TeamID) {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⛔ Rejecting join for %s: room %s belongs to another team", ws.ID, roomID)
+		s.sendToClient(ws, "join-rejected", map[string]interface{}{
+			"roomId": roomID,
+			"reason": "wrong-team",
+		})
+		return
+	}
+
+	// First, try to match by participantId if provided
+	var existingParticipant *roompkg.Participant
+	var oldID string
+
+	if participantId != "" {
+		for id, participant := range room.Participants {
+			if participant.ParticipantId == participantId {
+				existingParticipant = participant
+				oldID = id
+				break
+			}
+		}
+	}
+
+	// If no participantId match, fall back to matching by name (backwards compatibility)
+	if existingParticipant == nil {
+		for id, participant := range room.Participants {
+			if participant.Name == name {
+				existingParticipant = participant
+				oldID = id
+				break
+			}
+		}
+	}
+
+	// Check if this is a reconnection or a duplicate name from an active connection
+	oldClientStillConnected := oldID != "" && s.hub.Client(oldID) != nil
+
+	// With the token policy, taking over a disconnected participant requires
+	// presenting the session token that was issued to them
+	canReclaim := s.namePolicy != DuplicateNameToken ||
+		(existingParticipant != nil && sessionToken != "" && sessionToken == existingParticipant.SessionToken)
+
+	var conflict map[string]interface{}
+	join := &roompkg.JoinEvent{ClientID: ws.ID, Name: name, ParticipantID: participantId}
+
+	// Special case: if oldID == ws.ID, this is the same connection updating their info
+	// (e.g., after an update-name), so just update the participant in place
+	if existingParticipant != nil && oldID == ws.ID {
+		s.logf(ws.Context(), "🔄 Same connection updating info for %s (ID: %s)", name, ws.ID)
+		join.Replaces = ws.ID
+	} else if existingParticipant != nil && oldID != "" && !oldClientStillConnected && canReclaim {
+		// This is a legitimate reconnection - the old client is gone, the
+		// participant moves to the new ID keeping their votes
+		s.logf(ws.Context(), "🔄 Restoring participant data for %s (old ID: %s, new ID: %s)", name, oldID, ws.ID)
+		join.Replaces = oldID
+		s.recordReconnect(ws.Context(), reconnectRejoin)
+	} else if existingParticipant != nil && s.namePolicy == DuplicateNameReject && s.uniqueName(room, name, ws.ID, false) != name {
+		// Only someone else online under the name takes it, not an earlier
+		// connection of the same participant under another name
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⛔ Rejecting join for %s: name already taken in room %s", name, roomID)
+		s.sendToClient(ws, "join-rejected", map[string]interface{}{
+			"roomId": roomID,
+			"reason": "name-taken",
+			"name":   name,
+		})
+		return
+	} else if existingParticipant != nil && s.namePolicy != DuplicateNameReject {
+		// Duplicate name - generate unique name. Disconnected participants
+		// only block the name when their identity is protected by a token
+		uniqueName := s.uniqueName(room, name, ws.ID, s.namePolicy == DuplicateNameToken)
+
+		s.logf(ws.Context(), "⚠️ Duplicate name detected. Renaming %s to %s for client %s", name, uniqueName, ws.ID)
+
+		// Join as a new participant with the unique name
+		join.Name = uniqueName
+		conflict = map[string]interface{}{
+			"id":            ws.ID,
+			"requestedName": name,
+			"assignedName":  uniqueName,
+			"policy":        s.namePolicy,
+		}
+	}
+	room.Apply(join)
+
+	participant := room.Participants[ws.ID]
+	if oldID != ws.ID {
+		room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditJoined, ActorID: ws.ID})
+	}
+	if participant.SessionToken == "" {
+		participant.SessionToken = generateToken()
+	}
+	token := participant.SessionToken
+	room.Mu.Unlock()
+
+	ws.SetRoomID(roomID)
+
+	// Clients that resume after reconnecting need the token too
+	if s.namePolicy == DuplicateNameToken || resumable {
+		s.sendToClient(ws, "session", map[string]interface{}{
+			"roomId":       roomID,
+			"sessionToken": token,
+		})
+	}
+
+	s.broadcastRoomState(ws.Context(), roomID)
+	if oldID != ws.ID {
+		s.replayTo(ws, room)
+	}
+
+	if conflict != nil {
+		s.broadcastToRoom(ws.Context(), roomID, "name-conflict", conflict)
+	}
+}
+
+// admitJoin checks a join against the room's bans and passcode, rejecting it
+// with a join-rejected message.
+func (s *Server) admitJoin(ws *ExtendedWebSocket, room *roompkg.State, sessionToken, participantID, passcode, inviteToken string) bool {
+	return s.checkJoinBan(ws, room, sessionToken, participantID) &&
+		s.checkJoinPasscode(ws, room, passcode, inviteToken)
+}
+
+func (s *Server) handleVote(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	vote, _ := data["vote"].(string)
+	confidence, _ := data["confidence"].(string)
+	if confidence != "" && !confidenceLevels[confidence] {
+		s.logf(ws.Context(), "⚠️ Ignoring unsupported confidence %q from %s", confidence, ws.ID)
+		confidence = ""
+	}
+	if vote == "" {
+		confidence = ""
+	}
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+	// Voting brings back participants paused for being away, and clears
+	// their status
+	if s.returnToVoting(ws, room) {
+		defer s.broadcastRoomState(ws.Context(), roomID)
+	}
+
+	// Votes on a story of the batch are kept apart from the room's story
+	if storyID, ok := data["storyId"].(string); ok {
+		s.voteBatch(ws, room, storyID, vote)
+		return
+	}
+
+	room.Mu.RLock()
+	changing := room.TakesVoteChanges()
+	room.Mu.RUnlock()
+	if changing {
+		s.changeRevealedVote(ws, room, vote)
+		return
+	}
+
+	// Lock the room to safely update the participant's vote
+	room.Mu.Lock()
+	switch room.Settings.Mode {
+	case roompkg.ModeTwoDimensional:
+		room.Mu.Unlock()
+		s.voteTwoDimensional(ws, room, data, confidence)
+		return
+	case roompkg.ModeDotVoting:
+		room.Mu.Unlock()
+		s.voteDots(ws, room, data)
+		return
+	case roompkg.ModePERT:
+		room.Mu.Unlock()
+		s.votePERT(ws, room, data)
+		return
+	}
+	if !room.Settings.AcceptsVote(vote) {
+		room.Mu.Unlock()
+		s.sendInputError(ws, "vote", &inputError{Field: "vote", Reason: reasonNotInDeck})
+		return
+	}
+	if participant, ok := room.Participants[ws.ID]; ok {
+		// Prevent clearing vote if paused and cards are already revealed
+		// This guards against race conditions where pause action triggers vote clearing
+		if vote == "" && participant.Paused && room.Revealed && participant.Vote != nil && *participant.Vote != "" {
+			s.logf(ws.Context(), "⚠️ Prevented vote clearing for paused participant after reveal: %s", ws.ID)
+			room.Mu.Unlock()
+			return
+		}
+		room.Apply(&roompkg.VoteEvent{ClientID: ws.ID, Vote: vote, Confidence: confidence})
+		if vote != "" {
+			room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditVoted, ActorID: ws.ID})
+		}
+	}
+	room.Mu.Unlock()
+
+	// Broadcast that a participant has voted, but don't send the full state yet
+	// This is more efficient for just showing the checkmark icon
+	// Abstaining still counts as having voted, the flag lets clients tell
+	// them apart when checking whether everyone has estimated
+	s.broadcastToRoom(ws.Context(), roomID, "participant-voted", map[string]interface{}{
+		"id":        ws.ID,
+		"hasVote":   vote != "",
+		"abstained": roompkg.IsAbstention(vote),
+	})
+}
+
+// voteTwoDimensional updates whichever of the two values the message carries,
+// as "vote" and "secondVote". A value can't change once its dimension is
+// revealed.
+func (s *Server) voteTwoDimensional(ws *ExtendedWebSocket, room *roompkg.State, data map[string]interface{}, confidence string) {
+	vote, hasVote := data["vote"].(string)
+	secondVote, hasSecondVote := data["secondVote"].(string)
+
+	room.Mu.Lock()
+	participant, ok := room.Participants[ws.ID]
+	if !ok {
+		room.Mu.Unlock()
+		return
+	}
+	for field, value := range map[string]string{"vote": vote, "secondVote": secondVote} {
+		if !room.Settings.AcceptsVote(value) {
+			room.Mu.Unlock()
+			s.sendInputError(ws, "vote", &inputError{Field: field, Reason: reasonNotInDeck})
+			return
+		}
+	}
+	event := &roompkg.DimensionVoteEvent{ClientID: ws.ID, Confidence: confidence}
+	if hasVote && !room.Revealed {
+		event.Vote = &vote
+	}
+	if hasSecondVote && !room.SecondRevealed {
+		event.SecondVote = &secondVote
+	}
+	room.Apply(event)
+	if !participant.VotedAt.IsZero() {
+		room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditVoted, ActorID: ws.ID})
+	}
+	voted := map[string]interface{}{
+		"id":            ws.ID,
+		"hasVote":       participant.Vote != nil && *participant.Vote != "",
+		"hasSecondVote": participant.SecondVote != nil && *participant.SecondVote != "",
+	}
+	room.Mu.Unlock()
+
+	s.broadcastToRoom(ws.Context(), room.ID, "participant-voted", voted)
+}
+
+func (s *Server) handleClearVote(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if _, ok := room.Participants[ws.ID]; !ok {
+		room.Mu.Unlock()
+		return
+	}
+	// Once cards are revealed the vote is part of the round and can't be retracted
+	if room.Revealed || room.SecondRevealed {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring clear-vote after reveal: %s", ws.ID)
+		s.sendError(ws, "clear-vote", errCodeInvalidPhase, "Votes can't be cleared once revealed", nil)
+		return
+	}
+	room.Apply(&roompkg.ClearVoteEvent{ClientID: ws.ID})
+	room.Mu.Unlock()
+
+	s.broadcastToRoom(ws.Context(), roomID, "participant-voted", map[string]interface{}{"id": ws.ID, "hasVote": false})
+}
+
+// handleReveal shows the cards and records the round. In a two-dimensional
+// room an optional "dimension" label reveals just that value; the round is
+// recorded once both are shown.
+func (s *Server) handleReveal(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	dimension, _ := data["dimension"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if refusal := room.RevealRefusal(ws.ID); refusal != "" {
+		policy := room.RevealPolicy
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Rejecting reveal from %s in room %s: %s", ws.ID, roomID, refusal)
+		s.sendError(ws, "reveal", errCodeNotAllowed, refusal, map[string]interface{}{"revealPolicy": policy})
+		return
+	}
+	if len(room.Batch) > 0 {
+		room.Mu.Unlock()
+		storyID, _ := data["storyId"].(string)
+		s.revealBatch(ws.Context(), room, storyID, ws.ID)
+		return
+	}
+	if room.Settings.Mode == roompkg.ModeTwoDimensional && dimension != "" {
+		index := room.Settings.DimensionIndex(dimension)
+		if index < 0 {
+			room.Mu.Unlock()
+			s.logf(ws.Context(), "⚠️ Ignoring reveal of unknown dimension %q in room %s", dimension, roomID)
+			s.sendError(ws, "reveal", errCodeInvalidInput, "dimension must be one of the room's dimensions", nil)
+			return
+		}
+		room.Apply(&roompkg.RevealDimensionEvent{Index: index})
+		if !room.Revealed || !room.SecondRevealed {
+			participants := s.getParticipantsArray(room)
+			points := room.Settings.Points
+			room.Mu.Unlock()
+
+			stats := roompkg.ComputeVoteStats(participants, points)
+			if index == 1 {
+				stats = roompkg.ComputeVoteStats(roompkg.SecondDimensionVotes(participants), points)
+			}
+			s.broadcastToRoom(ws.Context(), roomID, "revealed", map[string]interface{}{
+				"participants": participants,
+				"dimension":    dimension,
+				"stats":        stats,
+			})
+			return
+		}
+	}
+	room.Mu.Unlock()
+
+	s.revealRound(ws.Context(), room, dimension, ws.ID)
+}
+
+// revealRound shows every card, records the round and tells the room, ending
+// an async round early if one is open. The actor is who revealed it, if anyone.
+func (s *Server) revealRound(ctx context.Context, room *roompkg.State, dimension, actorID string) RoundRecord {
+	roomID := room.ID
+
+	room.Mu.Lock()
+	roundID := strconv.FormatInt(room.Now().UnixMilli(), 10)
+	// A reopened round is revealed again under its own ID, replacing it
+	if room.ReopenedRoundID != "" {
+		roundID = room.ReopenedRoundID
+	}
+	room.Apply(&roompkg.RevealEvent{RoundID: roundID})
+	participants := room.LastRound.Participants
+	lastRound := room.LastRound
+	iteration, converged, nextDelphi := room.DelphiStep(roundID, participants)
+	if iteration > 0 {
+		room.Apply(&roompkg.DelphiStepEvent{Iteration: iteration, Next: nextDelphi})
+	}
+	round := room.History[len(room.History)-1]
+	room.AuditReveal(actorID, round)
+	session := room.Session()
+	settings := room.Settings
+	room.Mu.Unlock()
+
+	s.saveRound(session, round)
+
+	// Between Delphi iterations only the spread of the votes is shown
+	if nextDelphi != nil {
+		s.logf(ctx, "🔮 Room %s starts Delphi iteration %d of %d", roomID, nextDelphi.Iteration, nextDelphi.Rounds)
+		s.broadcastToRoom(ctx, roomID, "delphi-round", map[string]interface{}{
+			"roundId":   roundID,
+			"iteration": nextDelphi.Iteration,
+			"rounds":    nextDelphi.Rounds,
+			"previous":  nextDelphi.Previous,
+			"stats":     round.Stats,
+		})
+		s.broadcastRoomState(ctx, roomID)
+		return round
+	}
+
+	revealedData := map[string]interface{}{
+		"participants": participants,
+		"lastRound":    lastRound,
+		"stats":        round.Stats,
+		"abstained":    roompkg.Abstainers(participants),
+	}
+	if round.SecondStats != nil {
+		revealedData["dimension"] = dimension
+		revealedData["secondStats"] = round.SecondStats
+	}
+	if round.Ranking != nil {
+		revealedData["ranking"] = round.Ranking
+	}
+	if round.PERT != nil {
+		revealedData["pert"] = round.PERT
+	}
+	if iteration > 0 {
+		revealedData["delphi"] = map[string]interface{}{"iteration": iteration, "converged": converged}
+	}
+	consensus, ok := roompkg.CheckConsensus(roundID, participants, settings)
+	if round.Mode == "" && consensus.Outliers != nil {
+		revealedData["outliers"] = consensus.Outliers
+	}
+	s.broadcastToRoom(ctx, roomID, "revealed", revealedData)
+
+	if round.Mode == "" && ok {
+		s.announceConsensus(ctx, roomID, consensus)
+	}
+	s.notify(roomID, EventRoundRevealed, round)
+	return round
+}
+
+func (s *Server) handleReestimate(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	room.Apply(&roompkg.ResetEvent{Reestimate: true})
+	room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditReset, ActorID: ws.ID, Story: roompkg.AuditStory(room.Story)})
+	room.Mu.Unlock()
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+// handleReopenRound hides the cards of the last revealed round again, keeping
+// the votes so they can still be changed. Unlike reestimate nothing is
+// cleared, and revealing again replaces the round in the history.
+func (s *Server) handleReopenRound(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID || !room.Revealed || room.LastRound == nil || len(room.Batch) > 0 {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring reopen-round from %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "reopen-round", errCodeNotAllowed, "Only the facilitator can reopen a revealed round", nil)
+		return
+	}
+	room.Apply(&roompkg.ReopenEvent{})
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "↩️ %s reopened the last round in room %s", ws.ID, roomID)
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+func (s *Server) handleReset(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	s.resetRoom(ws.Context(), roomID, ws.ID)
+}
+
+// resetRoom clears votes, the last round and the story, keeping participants.
+// The actor is who reset it, empty for an operator. Returns false if the room
+// doesn't exist.
+func (s *Server) resetRoom(ctx context.Context, roomID, actorID string) bool {
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return false
+	}
+
+	room.Mu.Lock()
+	room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditReset, ActorID: actorID, Story: roompkg.AuditStory(room.Story)})
+	room.Apply(&roompkg.ResetEvent{})
+	participants := s.getParticipantsArray(room)
+	room.Mu.Unlock()
+
+	roomReset := map[string]interface{}{
+		"participants": participants,
+		"story":        nil,
+	}
+	s.broadcastToRoom(ctx, roomID, "room-reset", roomReset)
+	return true
+}
+
+func (s *Server) handleUpdateStory(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	storyData, _ := data["story"].(map[string]interface{})
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	var story *roompkg.Story
+	if storyData != nil {
+		title, _ := storyData["title"].(string)
+		link, _ := storyData["link"].(string)
+		checked, invalid := checkStory(title, link)
+		if invalid != nil {
+			s.sendInputError(ws, "update-story", invalid)
+			return
+		}
+		story = &checked
+		s.enrichStory(ws.Context(), story)
+	}
+
+	room.Mu.Lock()
+	room.Apply(&roompkg.StoryEvent{Story: story})
+	room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditStoryChanged, ActorID: ws.ID, Story: roompkg.AuditStory(story)})
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "📥 update-story received: roomId=%s, story=%+v", roomID, story)
+	storyUpdated := map[string]interface{}{
+		"story": story,
+	}
+	s.broadcastToRoom(ws.Context(), roomID, "story-updated", storyUpdated)
+}
+
+// enrichStory fills in issue details when the story links to a known tracker,
+// or a preview of the page when it links elsewhere. Lookup failures are logged and the story is kept as the client sent it.
+func (s *Server) enrichStory(ctx context.Context, story *roompkg.Story) {
+	if story.Link == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, trackerTimeout)
+	defer cancel()
+
+	var tracker, key, summary, description string
+	if ref, ok := s.jiraKey(story.Link); ok {
+		issue, err := s.jira.FetchIssue(ctx, ref)
+		if err != nil {
+			s.logf(ctx, "Jira lookup failed for %s: %v", ref, err)
+			return
+		}
+		tracker, key, summary, description = roompkg.TrackerJira, issue.Key, issue.Summary, issue.Description
+	} else if ref, ok := s.linearKey(story.Link); ok {
+		issue, err := s.linear.FetchIssue(ctx, ref)
+		if err != nil {
+			s.logf(ctx, "Linear lookup failed for %s: %v", ref, err)
+			return
+		}
+		tracker, key, summary, description = roompkg.TrackerLinear, issue.Identifier, issue.Title, issue.Description
+	} else if ref, ok := s.gitLabKey(story.Link); ok {
+		issue, err := s.gitlab.FetchIssue(ctx, ref)
+		if err != nil {
+			s.logf(ctx, "GitLab lookup failed for %s: %v", ref, err)
+			return
+		}
+		tracker, key, summary, description = roompkg.TrackerGitLab, issue.Reference, issue.Title, issue.Description
+	} else {
+		s.previewStory(ctx, story)
+		return
+	}
+
+	story.Tracker = tracker
+	story.Key = key
+	story.Summary = summary
+	story.Description = description
+	if story.Title == "" {
+		story.Title = summary
+	}
+}
+
+func (s *Server) jiraKey(link string) (string, bool) {
+	if s.jira == nil {
+		return "", false
+	}
+	return s.jira.IssueKeyFromLink(link)
+}
+
+func (s *Server) linearKey(link string) (string, bool) {
+	if s.linear == nil {
+		return "", false
+	}
+	return s.linear.IssueKeyFromLink(link)
+}
+
+func (s *Server) gitLabKey(link string) (string, bool) {
+	if s.gitlab == nil {
+		return "", false
+	}
+	return s.gitlab.IssueKeyFromLink(link)
+}
+
+// handleSetFinalEstimate lets the facilitator settle the story's estimate.
+func (s *Server) handleSetFinalEstimate(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	estimate, _ := data["estimate"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring set-final-estimate from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "set-final-estimate", errCodeNotAllowed, "Only the facilitator can set the final estimate", nil)
+		return
+	}
+	room.Apply(&roompkg.FinalEstimateEvent{Estimate: estimate})
+	story := *room.Story
+	room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditFinalEstimate, ActorID: ws.ID, Story: story.Title, Estimate: estimate})
+	var round *RoundRecord
+	if n := len(room.History); n > 0 && room.Revealed {
+		record := room.History[n-1]
+		round = &record
+	}
+	session := room.Session()
+	room.Mu.Unlock()
+
+	if round != nil {
+		s.saveRound(session, *round)
+	}
+	if estimate != "" {
+		// Without a revealed round the event carries just the story
+		event := RoundRecord{Story: &story, FinalEstimate: estimate}
+		if round != nil {
+			event = *round
+		}
+		s.notify(roomID, EventFinalEstimate, event)
+	}
+
+	s.logf(ws.Context(), "📥 set-final-estimate: roomId=%s, estimate=%s", roomID, estimate)
+	s.broadcastToRoom(ws.Context(), roomID, "story-updated", map[string]interface{}{
+		"story": &story,
+	})
+
+	if story.Key == "" || estimate == "" {
+		return
+	}
+	// Stories enriched before trackers were told apart all came from Jira
+	switch story.Tracker {
+	case roompkg.TrackerJira, "":
+		if s.jira != nil {
+			go s.syncEstimate(context.WithoutCancel(ws.Context()), roomID, "Jira", story.Key, estimate, s.jira.UpdateStoryPoints)
+		}
+	case roompkg.TrackerLinear:
+		if s.linear != nil {
+			go s.syncEstimate(context.WithoutCancel(ws.Context()), roomID, "Linear", story.Key, estimate, s.linear.UpdateEstimate)
+		}
+	}
+}
+
+// syncEstimate writes the final estimate back to the issue tracker and tells
+// the room whether it worked, as jira-sync or linear-sync, so the facilitator
+// knows if they need to update it by hand.
+func (s *Server) syncEstimate(ctx context.Context, roomID, tracker, key, estimate string, update func(context.Context, string, float64) error) {
+	result := map[string]interface{}{
+		"key":      key,
+		"estimate": estimate,
+		"success":  true,
+	}
+
+	points, err := strconv.ParseFloat(estimate, 64)
+	if err == nil {
+		updateCtx, cancel := context.WithTimeout(ctx, trackerTimeout)
+		err = update(updateCtx, key, points)
+		cancel()
+	} else {
+		err = fmt.Errorf("estimate %q is not numeric", estimate)
+	}
+
+	if err != nil {
+		s.logf(ctx, "❌ Failed to write estimate to %s for %s: %v", tracker, key, err)
+		result["success"] = false
+		result["error"] = err.Error()
+	} else {
+		s.logf(ctx, "✓ Wrote estimate %s to %s issue %s", estimate, tracker, key)
+	}
+
+	s.broadcastToRoom(ctx, roomID, strings.ToLower(tracker)+"-sync", result)
+}
+
+func (s *Server) handleSuspendVoting(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	room.Apply(&roompkg.PauseEvent{ClientID: ws.ID, Paused: true})
+	room.Mu.Unlock()
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+func (s *Server) handleResumeVoting(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	// Resuming keeps the vote
+	room.Apply(&roompkg.PauseEvent{ClientID: ws.ID, Paused: false})
+	room.Mu.Unlock()
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+func (s *Server) handleRaiseHand(ws *ExtendedWebSocket, data map[string]interface{}) {
+	s.setHandRaised(ws, data, true)
+}
+
+func (s *Server) handleLowerHand(ws *ExtendedWebSocket, data map[string]interface{}) {
+	s.setHandRaised(ws, data, false)
+}
+
+// setHandRaised raises or lowers the sender's hand. Revealing the votes
+// lowers every hand.
+func (s *Server) setHandRaised(ws *ExtendedWebSocket, data map[string]interface{}, raised bool) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	participant, ok := room.Participants[ws.ID]
+	if !ok || participant.HandRaised == raised {
+		room.Mu.Unlock()
+		return
+	}
+	room.Apply(&roompkg.HandEvent{ClientID: ws.ID, Raised: raised})
+	room.Mu.Unlock()
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+func (s *Server) handleReaction(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	emoji, _ := data["emoji"].(string)
+
+	if !allowedReactions[emoji] {
+		s.logf(ws.Context(), "⚠️ Ignoring unsupported reaction %q from %s", emoji, ws.ID)
+		s.sendError(ws, "reaction", errCodeInvalidInput, "emoji must be a supported reaction", nil)
+		return
+	}
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.RLock()
+	_, isParticipant := room.Participants[ws.ID]
+	room.Mu.RUnlock()
+	if !isParticipant {
+		return
+	}
+
+	if ws.ReactionLimiter == nil {
+		ws.ReactionLimiter = transport.NewRateLimiter(reactionLimit, reactionWindow)
+	}
+	if !ws.ReactionLimiter.Allow() {
+		s.logf(ws.Context(), "⚠️ Reaction rate limit exceeded for %s", ws.ID)
+		return
+	}
+
+	// Reactions are fire-and-forget and never touch room state
+	s.broadcastToRoom(ws.Context(), roomID, "reaction", map[string]interface{}{
+		"id":        ws.ID,
+		"emoji":     emoji,
+		"timestamp": s.clock.Now().UnixMilli(),
+	})
+}
+
+// handleSelecting tells the rest of the room that the sender is picking a
+// card, at most once per selectingInterval. Like reactions, it never touches
+// room state.
+func (s *Server) handleSelecting(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.RLock()
+	_, isParticipant := room.Participants[ws.ID]
+	revealed := room.Revealed
+	room.Mu.RUnlock()
+	if !isParticipant || revealed {
+		return
+	}
+
+	if ws.SelectingLimiter == nil {
+		ws.SelectingLimiter = transport.NewRateLimiter(1, selectingInterval)
+	}
+	if !ws.SelectingLimiter.Allow() {
+		return
+	}
+
+	s.broadcastToRoom(ws.Context(), roomID, "selecting", map[string]interface{}{
+		"id":        ws.ID,
+		"timestamp": s.clock.Now().UnixMilli(),
+	}, ws.ID)
+}
+
+func (s *Server) handleChatMessage(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	text, _ := data["text"].(string)
+
+	text = roompkg.CleanText(text, true)
+	if text == "" {
+		return
+	}
+	if runes := []rune(text); len(runes) > roompkg.MaxChatLength {
+		text = string(runes[:roompkg.MaxChatLength])
+	}
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	participant, ok := room.Participants[ws.ID]
+	if !ok {
+		room.Mu.Unlock()
+		return
+	}
+	chatMessage := roompkg.ChatMessage{
+		ID:         generateToken(),
+		SenderID:   ws.ID,
+		SenderName: participant.Name,
+		Text:       html.EscapeString(text),
+		Timestamp:  s.clock.Now().UnixMilli(),
+	}
+	room.Apply(&roompkg.ChatEvent{Message: chatMessage})
+	room.Mu.Unlock()
+
+	s.broadcastToRoom(ws.Context(), roomID, "chat-message", chatMessage)
+}
+
+func (s *Server) handleLeaveRoom(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	s.logf(ws.Context(), "📥 leave-room: roomId=%s, clientId=%s", roomID, ws.ID)
+
+	if s.keepForAsyncRound(roomID, ws.ID) {
+		ws.SwapRoomID(roomID, "")
+		s.broadcastRoomState(ws.Context(), roomID)
+		return
+	}
+	if !s.removeParticipant(roomID, ws.ID) {
+		return
+	}
+	ws.SwapRoomID(roomID, "")
+
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+// handleSync sends the full room state to the requesting client only, so a
+// client that missed a message can recover without a room-wide broadcast.
+func (s *Server) handleSync(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.RLock()
+	_, isParticipant := room.Participants[ws.ID]
+	var roomState map[string]interface{}
+	if isParticipant {
+		roomState = s.roomStatePayload(room)
+	}
+	room.Mu.RUnlock()
+
+	if !isParticipant {
+		s.logf(ws.Context(), "⚠️ Ignoring sync for room %s from non-participant %s", roomID, ws.ID)
+		return
+	}
+	// The state is as of the last broadcast, from which the client counts on
+	room.SendMu.Lock()
+	s.enqueue(ws, WebSocketMessage{Type: "room-state", Data: roomState, Seq: room.BroadcastSeq.Load()})
+	room.SendMu.Unlock()
+}
+
+func (s *Server) handleUpdateName(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	name, _ := data["name"].(string)
+	s.logf(ws.Context(), "📥 update-name: roomId=%s, newName=%s, clientId=%s", roomID, name, ws.ID)
+	if ws.UserName != "" {
+		s.logf(ws.Context(), "⚠️ Ignoring update-name from signed-in client %s", ws.ID)
+		s.sendError(ws, "update-name", errCodeNotAllowed, "Signed-in participants go by the name of their account", nil)
+		return
+	}
+	name, invalid := checkName(name)
+	if invalid == nil && name == "" {
+		invalid = &inputError{Field: "name", Reason: reasonRequired}
+	}
+	if invalid != nil {
+		s.sendInputError(ws, "update-name", invalid)
+		return
+	}
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if participant, ok := room.Participants[ws.ID]; ok {
+		// Check if the new name is already taken by another ACTIVE participant
+		// Only check connected participants to avoid conflicts with disconnected users
+		finalName := s.uniqueName(room, name, ws.ID, s.namePolicy == DuplicateNameToken)
+
+		if finalName != name {
+			s.logf(ws.Context(), "⚠️ Name '%s' already taken. Using '%s' instead for client %s", name, finalName, ws.ID)
+		}
+
+		s.logf(ws.Context(), "✏️ Updating participant name from '%s' to '%s'", participant.Name, finalName)
+		room.Apply(&roompkg.RenameEvent{ClientID: ws.ID, Name: finalName})
+	}
+	room.Mu.Unlock()
+
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+func (s *Server) handleClientDisconnect(ws *ExtendedWebSocket) {
+	s.recordDisconnect(ws)
+
+	s.hub.RemoveClient(ws.ID)
+	s.closeOutbox(ws)
+
+	roomID := ws.RoomID()
+	if relayedRoom := s.relayDisconnect(ws); relayedRoom != "" {
+		roomID = relayedRoom
+	}
+
+	// Note: We intentionally DO NOT remove participants from rooms on disconnect
+	// This allows their votes to persist when they reconnect (e.g., after page refresh)
+	// The participant will be updated with new ID when they rejoin with same name
+	// If they don't come back within the grace period, they are purged
+	if roomID != "" {
+		room, exists := s.hub.Room(roomID)
+
+		if exists {
+			room.Mu.Lock()
+			_, ok := room.Participants[ws.ID]
+			if ok {
+				room.Apply(&roompkg.PresenceEvent{ClientID: ws.ID, Online: false})
+			}
+			room.Mu.Unlock()
+			if ok {
+				s.logger.Printf("🔄 Keeping participant data for potential reconnection: %s", ws.ID)
+				s.broadcastRoomState(s.ctx, roomID)
+				s.scheduleParticipantCleanup(roomID, ws.ID)
+			}
+		}
+	}
+}
+
+// scheduleParticipantCleanup purges a disconnected participant once the grace
+// period elapses. A reconnect moves the participant to a new client ID, so the
+// timer becomes a no-op in that case.
+func (s *Server) scheduleParticipantCleanup(roomID, clientID string) {
+	s.settingsMu.RLock()
+	gracePeriod := s.gracePeriod
+	s.settingsMu.RUnlock()
+	if gracePeriod <= 0 {
+		return
+	}
+
+	s.clock.AfterFunc(gracePeriod, func() {
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		connected := s.hub.Client(clientID) != nil
+		if connected {
+			return
+		}
+		// Their vote counts until the async round closes, which purges them
+		if s.keepForAsyncRound(roomID, clientID) {
+			return
+		}
+
+		if s.removeParticipant(roomID, clientID) {
+			s.logger.Printf("🧹 Purged participant %s from room %s after grace period", clientID, roomID)
+			s.broadcastRoomState(s.ctx, roomID)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package pokerserver
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// Clients may start with a "hello" stating the protocol version they speak
+// and the optional features they want. Version 1 is the protocol spoken
+// before the handshake, with every feature; from version 2 a client gets the
+// features it asks for. Clients that never say hello speak version 1.
+const (
+	protocolVersion    = 2
+	minProtocolVersion = 1
+	// featuresVersion is the first version whose features are negotiated
+	featuresVersion = 2
+)
+
+// Optional protocol features a client can ask for in its hello
+const (
+	// FeatureTypedErrors sends "error" messages for messages that weren't handled
+	FeatureTypedErrors = "typed-errors"
+	// FeatureReplay sends "replay" with recent broadcasts after joining
+	FeatureReplay = "replay"
+	// FeatureMessageIDs drops repeated messages by their "messageId"
+	FeatureMessageIDs = "message-ids"
+	// FeatureMsgpack carries messages as MessagePack; it is chosen when
+	// connecting, so hello only reports whether the connection uses it
+	FeatureMsgpack = "msgpack"
+)
+
+var protocolFeatures = []string{FeatureTypedErrors, FeatureReplay, FeatureMessageIDs, FeatureMsgpack}
+
+// handleHello negotiates the connection's protocol: the highest version both
+// sides speak, and the features asked for that the server has. It answers
+// with a "hello" of its own, listing every feature it has as capabilities.
+// A hello without a version speaks version 1, and one without features asks
+// for all of them, so saying hello never gets a client less than skipping it.
+func (s *Server) handleHello(ws *ExtendedWebSocket, data map[string]interface{}) {
+	requested, given := data["version"].(float64)
+	if !given {
+		requested = minProtocolVersion
+	}
+	if requested != float64(int(requested)) || requested < minProtocolVersion {
+		s.logf(ws.Context(), "⚠️ Refusing hello from %s for protocol version %v", ws.ID, requested)
+		s.sendError(ws, "hello", errCodeNotAllowed, "Unsupported protocol version", map[string]interface{}{
+			"minVersion": minProtocolVersion,
+			"maxVersion": protocolVersion,
+		})
+		return
+	}
+	version := min(int(requested), protocolVersion)
+
+	features := make(map[string]bool)
+	wanted, listed := data["features"].([]interface{})
+	for _, feature := range protocolFeatures {
+		if !listed || version < featuresVersion || slices.Contains(wanted, interface{}(feature)) {
+			features[feature] = true
+		}
+	}
+	_, usesMsgpack := ws.Codec.(transport.MsgpackCodec)
+	features[FeatureMsgpack] = usesMsgpack
+	ws.Features = features
+
+	negotiated := make([]string, 0, len(features))
+	for feature, on := range features {
+		if on {
+			negotiated = append(negotiated, feature)
+		}
+	}
+	sort.Strings(negotiated)
+	s.logf(ws.Context(), "🤝 %s speaks protocol version %d with %v", ws.ID, version, negotiated)
+	s.sendToClient(ws, "hello", map[string]interface{}{
+		"version":      version,
+		"minVersion":   minProtocolVersion,
+		"maxVersion":   protocolVersion,
+		"features":     negotiated,
+		"capabilities": protocolFeatures,
+	})
+}
@@ -0,0 +1,64 @@
+package pokerserver
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHelloNegotiatesProtocol(t *testing.T) {
+	server := New()
+	roomID := "hello-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("hello", map[string]interface{}{"version": float64(2), "features": []interface{}{"replay", "deltas"}})
+	hello := awaitMessage(t, alice, "hello").Data.(map[string]interface{})
+	if hello["version"] != float64(2) || fmt.Sprint(hello["features"]) != "[replay]" {
+		t.Errorf("Expected version 2 with replay only, got %v", hello)
+	}
+
+	// Without typed errors, refused messages go unanswered
+	alice.Send("vote", map[string]interface{}{"roomId": "another-room", "vote": "3"})
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	for msg := range alice.Messages() {
+		if msg.Type == "error" {
+			t.Errorf("Expected no errors without typed errors, got %v", msg.Data)
+		}
+		if msg.Type == "room-state" {
+			break
+		}
+	}
+
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("hello", map[string]interface{}{"version": float64(protocolVersion + 1)})
+	hello = awaitMessage(t, bob, "hello").Data.(map[string]interface{})
+	if hello["version"] != float64(protocolVersion) || fmt.Sprint(hello["features"]) != "[message-ids replay typed-errors]" {
+		t.Errorf("Expected the server's version with every feature but msgpack, got %v", hello)
+	}
+	bob.Send("hello", map[string]interface{}{"version": float64(0)})
+	if data := awaitMessage(t, bob, "error").Data.(map[string]interface{}); data["maxVersion"] != float64(protocolVersion) {
+		t.Errorf("Expected an unsupported version to be refused, got %v", data)
+	}
+}
+
+func TestHelloWithoutVersionIsBaseline(t *testing.T) {
+	server := New()
+
+	for _, data := range []map[string]interface{}{
+		{},
+		{"version": float64(1), "features": []interface{}{"replay"}},
+	} {
+		client := server.ConnectMemory()
+		client.Send("hello", data)
+		hello := awaitMessage(t, client, "hello").Data.(map[string]interface{})
+		if hello["version"] != float64(1) || fmt.Sprint(hello["features"]) != "[message-ids replay typed-errors]" {
+			t.Errorf("Expected version 1 with every feature for %v, got %v", data, hello)
+		}
+
+		// Refused messages are answered, as they are without a hello
+		client.Send("vote", map[string]interface{}{"roomId": "another-room", "vote": "3"})
+		awaitMessage(t, client, "error")
+		client.Close()
+	}
+}
@@ -0,0 +1,81 @@
+package pokerserver
+
+import (
+	"bufio"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// statusRecorder keeps the status written to a response. WebSocket upgrades
+// hijack the connection instead, calling onHijack.
+type statusRecorder struct {
+	http.ResponseWriter
+	status   int
+	onHijack func()
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response can't be hijacked")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		r.status = http.StatusSwitchingProtocols
+		r.onHijack()
+	}
+	return conn, rw, err
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// requestLogMiddleware logs every HTTP request with its status, duration,
+// client address and origin; WebSocket upgrades as soon as the connection is
+// taken over. Failed requests are always logged, the others at the
+// configured sample rate. Query strings are left out, as they may carry
+// tokens.
+func (s *Server) requestLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		logRequest := func(status int) {
+			s.settingsMu.RLock()
+			sampleRate := s.httpLogSampleRate
+			s.settingsMu.RUnlock()
+			if status < http.StatusBadRequest && (sampleRate <= 0 || rand.Float64() >= sampleRate) {
+				return
+			}
+			s.logger.Printf("🌐 HTTP request: method=%s path=%s status=%d duration=%s ip=%s origin=%s",
+				r.Method, r.URL.Path, status, time.Since(start).Round(time.Microsecond), s.clientIP(r), r.Header.Get("Origin"))
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		recorder.onHijack = func() { logRequest(http.StatusSwitchingProtocols) }
+		next.ServeHTTP(recorder, r)
+		switch recorder.status {
+		case http.StatusSwitchingProtocols:
+		case 0:
+			logRequest(http.StatusOK)
+		default:
+			logRequest(recorder.status)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package pokerserver
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// lockedBuffer collects the logs of a server still serving connections.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRequestLogSampling(t *testing.T) {
+	var logs lockedBuffer
+	server := New(WithLogger(log.New(&logs, "", 0)), WithAllowedOrigins("https://poker.example.com"))
+	server.httpLogSampleRate = 0
+	handler := server.Handler()
+
+	request := func(origin string) {
+		req := httptest.NewRequest(http.MethodGet, "/?token=secret", nil)
+		req.Header.Set("Origin", origin)
+		req.RemoteAddr = "203.0.113.7:51234"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	request("https://poker.example.com")
+	if strings.Contains(logs.String(), "HTTP request") {
+		t.Errorf("Expected successful requests to be sampled out, got %q", logs.String())
+	}
+
+	request("https://evil.example.com")
+	expected := "method=GET path=/ status=403"
+	if !strings.Contains(logs.String(), expected) {
+		t.Errorf("Expected failed requests to always be logged with %q, got %q", expected, logs.String())
+	}
+	if !strings.Contains(logs.String(), "ip=203.0.113.7 origin=https://evil.example.com") {
+		t.Errorf("Expected the client address and origin to be logged, got %q", logs.String())
+	}
+	if strings.Contains(logs.String(), "secret") {
+		t.Errorf("Expected query strings to be left out, got %q", logs.String())
+	}
+
+	server.httpLogSampleRate = 1
+	request("https://poker.example.com")
+	if !strings.Contains(logs.String(), "status=200") {
+		t.Errorf("Expected successful requests to be logged at a sample rate of 1, got %q", logs.String())
+	}
+}
+
+func TestRequestLogUpgrades(t *testing.T) {
+	var logs lockedBuffer
+	server := New(WithLogger(log.New(&logs, "", 0)))
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/api/ws", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect websocket: %v", err)
+	}
+	defer ws.Close()
+
+	// The upgrade is logged when the connection is taken over, not when it
+	// ends
+	if !strings.Contains(logs.String(), "method=GET path=/api/ws status=101") {
+		t.Errorf("Expected the upgrade to be logged, got %q", logs.String())
+	}
+}
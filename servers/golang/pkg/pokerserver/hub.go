@@ -0,0 +1,193 @@
+package pokerserver
+
+import (
+	"context"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (s *Server) sendToClient(ws *ExtendedWebSocket, msgType string, data interface{}) {
+	s.enqueue(ws, WebSocketMessage{
+		Type: msgType,
+		Data: data,
+	})
+}
+
+func (s *Server) broadcastToRoom(ctx context.Context, roomID string, msgType string, data interface{}, excludeID ...string) {
+	ctx, span := s.tracer.Start(ctx, "ws.broadcast",
+		trace.WithAttributes(attrMessageType.String(msgType), attrRoomID.String(roomID)),
+	)
+	defer span.End()
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+	if msgType != "room-state" {
+		s.flushRoomState(ctx, room)
+	}
+
+	excludeMap := make(map[string]bool)
+	for _, id := range excludeID {
+		excludeMap[id] = true
+	}
+
+	// Collect the recipients under the locks but queue the message after
+	// releasing them, so fan-out never waits on the locks
+	room.Mu.RLock()
+	ids := make([]string, 0, len(room.Participants))
+	for _, participant := range room.Participants {
+		if !excludeMap[participant.ID] {
+			ids = append(ids, participant.ID)
+		}
+	}
+	recipients := s.hub.ClientsOf(ids)
+	room.Mu.RUnlock()
+
+	// A broadcast that leaves someone out isn't numbered, as they would take
+	// the missing number for a lost message
+	if len(excludeMap) > 0 {
+		s.sendToAll(recipients, WebSocketMessage{Type: msgType, Data: data})
+		room.PublishToSpectators(WebSocketMessage{Type: msgType, Data: data})
+	} else {
+		room.SendMu.Lock()
+		s.sendToAll(recipients, WebSocketMessage{Type: msgType, Data: data, Seq: room.BroadcastSeq.Add(1)})
+		room.PublishToSpectators(WebSocketMessage{Type: msgType, Data: data})
+		room.Replay.Record(WebSocketMessage{Type: msgType, Data: data})
+		room.SendMu.Unlock()
+	}
+	span.SetAttributes(attrRecipients.Int(len(recipients)))
+}
+
+// broadcastToAll sends a message to every client connected to this instance,
+// whether or not it has joined a room.
+func (s *Server) broadcastToAll(ctx context.Context, msgType string, data interface{}) {
+	_, span := s.tracer.Start(ctx, "ws.broadcast",
+		trace.WithAttributes(attrMessageType.String(msgType)),
+	)
+	defer span.End()
+
+	recipients := s.hub.Clients()
+	s.sendToAll(recipients, WebSocketMessage{Type: msgType, Data: data})
+	span.SetAttributes(attrRecipients.Int(len(recipients)))
+}
+
+// sendToAll queues message for each client; the broadcast workers write it.
+// The caller must not hold room or client locks, as queueing waits for a
+// worker when all of them are busy.
+func (s *Server) sendToAll(clients []*ExtendedWebSocket, message WebSocketMessage) {
+	for _, client := range clients {
+		s.enqueue(client, message)
+	}
+}
+
+func (s *Server) emitToRoom(ctx context.Context, roomID string, msgType string, data interface{}, excludeID string) {
+	s.broadcastToRoom(ctx, roomID, msgType, data, excludeID)
+
+	if s.broker != nil {
+		s.publishToBroker(ctx, roomID, msgType, data, excludeID)
+	}
+}
+
+// How often a room's full state is broadcast at most. Changes in quick
+// succession, like a join storm after a network blip, share one broadcast
+const roomStateInterval = 50 * time.Millisecond
+
+// broadcastRoomState sends the room's full state to its participants. A call
+// within roomStateInterval of the previous broadcast is deferred to the end of
+// the interval, together with any others made meanwhile, and then sends the
+// state as it is at that time.
+func (s *Server) broadcastRoomState(ctx context.Context, roomID string) {
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.StateMu.Lock()
+	defer room.StateMu.Unlock()
+
+	if room.StatePending != nil {
+		return
+	}
+	if wait := roomStateInterval - s.clock.Now().Sub(room.StateSentAt); wait > 0 {
+		ctx = context.WithoutCancel(ctx)
+		var timer Timer
+		timer = s.clock.AfterFunc(wait, func() {
+			room.StateMu.Lock()
+			defer room.StateMu.Unlock()
+			// Another message may have flushed it meanwhile
+			if room.StatePending != timer {
+				return
+			}
+			room.StatePending = nil
+			s.sendRoomState(ctx, room)
+		})
+		room.StatePending = timer
+		return
+	}
+	s.sendRoomState(ctx, room)
+}
+
+// flushRoomState sends a deferred room-state broadcast right away, so it
+// still reaches clients before the messages that followed it.
+func (s *Server) flushRoomState(ctx context.Context, room *roompkg.State) {
+	room.StateMu.Lock()
+	defer room.StateMu.Unlock()
+
+	if room.StatePending == nil {
+		return
+	}
+	room.StatePending.Stop()
+	room.StatePending = nil
+	s.sendRoomState(ctx, room)
+}
+
+// sendRoomState broadcasts the room's current state. The caller must hold
+// room.StateMu, which keeps broadcasts in the order their state was taken.
+func (s *Server) sendRoomState(ctx context.Context, room *roompkg.State) {
+	room.StateSentAt = s.clock.Now()
+
+	room.Mu.RLock()
+	roomState := s.roomStatePayload(room)
+	room.Mu.RUnlock()
+
+	s.broadcastToRoom(ctx, room.ID, "room-state", roomState)
+}
+
+// roomStatePayload builds the full room-state message. The caller must hold room.Mu.
+func (s *Server) roomStatePayload(room *roompkg.State) map[string]interface{} {
+	participants := s.getParticipantsArray(room)
+	if s.showLatency {
+		participants = s.withLatency(participants)
+	}
+	requestedBy, _ := roompkg.RevealRequests(room)
+	return map[string]interface{}{
+		"participants":     participants,
+		"revealed":         room.Revealed,
+		"story":            room.Story,
+		"lastRound":        room.LastRound,
+		"chat":             roompkg.RecentChat(room),
+		"facilitatorId":    room.FacilitatorID,
+		"private":          room.Passcode != nil,
+		"settings":         room.Settings,
+		"revealPolicy":     room.RevealPolicy,
+		"allowVoteChanges": room.AllowVoteChanges,
+		"delphi":           room.Delphi,
+		"revealRequests":   requestedBy,
+		"secondRevealed":   room.SecondRevealed,
+		"deadline":         roompkg.DeadlineMillis(room),
+		"batch":            roompkg.BatchState(room),
+		"teamId":           room.TeamID,
+		"backlog":          room.Backlog,
+		"phase":            room.Phase(),
+		"name":             room.Info.Name,
+		"description":      room.Info.Description,
+		"scheduledAt":      room.Info.ScheduledAtOrNil(),
+		"recurrence":       room.Recurrence,
+		"seq":              room.EventSeq,
+	}
+}
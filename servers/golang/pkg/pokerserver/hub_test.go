@@ -0,0 +1,215 @@
+package pokerserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestBroadcastWritesOutsideLocks(t *testing.T) {
+	server := New()
+	room := server.getOrCreateRoom("lock-room")
+
+	// A client whose write needs the room and client locks, as a slow write
+	// holding them would stall every other broadcast and connection
+	delivered := make(chan string, 2)
+	client := &ExtendedWebSocket{ID: "client-1"}
+	client.Relay = func(message WebSocketMessage) error {
+		room.Mu.Lock()
+		room.Mu.Unlock()
+		server.hub.AddClient(client)
+		delivered <- message.Type
+		return nil
+	}
+	server.hub.AddClient(client)
+	room.Participants[client.ID] = &roompkg.Participant{ID: client.ID, Name: "Alice", Online: true}
+
+	done := make(chan struct{})
+	go func() {
+		server.broadcastToRoom(context.Background(), "lock-room", "ping", nil)
+		server.broadcastToAll(context.Background(), "announcement", nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected broadcasts not to hold locks while writing")
+	}
+	if msg := <-delivered; msg != "ping" {
+		t.Errorf("Expected ping, got %s", msg)
+	}
+}
+
+// benchmarkRoom fills a room with n clients of another instance, whose
+// deliveries only encode the message, and returns them with a WaitGroup
+// counting deliveries down.
+func benchmarkRoom(b *testing.B, server *Server, roomID string, n int) ([]*ExtendedWebSocket, *sync.WaitGroup) {
+	b.Helper()
+	room := server.getOrCreateRoom(roomID)
+	delivered := &sync.WaitGroup{}
+	clients := make([]*ExtendedWebSocket, 0, n)
+	for i := 0; i < n; i++ {
+		client := &ExtendedWebSocket{ID: fmt.Sprintf("client-%d", i)}
+		client.Relay = func(message WebSocketMessage) error {
+			_, err := json.Marshal(message)
+			delivered.Done()
+			return err
+		}
+		vote := "5"
+		server.hub.AddClient(client)
+		room.Participants[client.ID] = &roompkg.Participant{ID: client.ID, Name: client.ID, Vote: &vote, Online: true}
+		clients = append(clients, client)
+	}
+	return clients, delivered
+}
+
+func BenchmarkBroadcastRoomState(b *testing.B) {
+	for _, size := range []int{10, 50, 200} {
+		b.Run(fmt.Sprintf("participants=%d", size), func(b *testing.B) {
+			server := New(WithLogger(log.New(io.Discard, "", 0)))
+			defer server.Shutdown(context.Background())
+			_, delivered := benchmarkRoom(b, server, "bench-room", size)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				delivered.Add(size)
+				server.broadcastRoomState(context.Background(), "bench-room")
+				delivered.Wait()
+			}
+		})
+	}
+}
+
+func BenchmarkHandleVote(b *testing.B) {
+	for _, size := range []int{10, 50, 200} {
+		b.Run(fmt.Sprintf("participants=%d", size), func(b *testing.B) {
+			server := New(WithLogger(log.New(io.Discard, "", 0)))
+			defer server.Shutdown(context.Background())
+			clients, delivered := benchmarkRoom(b, server, "bench-room", size)
+			votes := []string{"1", "2", "3", "5", "8"}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				delivered.Add(size)
+				server.handleVote(clients[i%size], map[string]interface{}{"roomId": "bench-room", "vote": votes[i%len(votes)]})
+				delivered.Wait()
+			}
+		})
+	}
+}
+
+func TestRoomStateBroadcastsCoalesce(t *testing.T) {
+	server := New()
+	defer server.Shutdown(context.Background())
+	room := server.getOrCreateRoom("busy-room")
+
+	received := make(chan map[string]interface{}, 16)
+	client := &ExtendedWebSocket{ID: "client-1"}
+	client.Relay = func(message WebSocketMessage) error {
+		if message.Type == "room-state" {
+			received <- message.Data.(map[string]interface{})
+		}
+		return nil
+	}
+	server.hub.AddClient(client)
+	room.Participants[client.ID] = &roompkg.Participant{ID: client.ID, Name: "Alice", Online: true}
+
+	// A join storm: the first change goes out at once, the rest together
+	for i := 0; i < 10; i++ {
+		room.Mu.Lock()
+		room.Story = &roompkg.Story{Title: fmt.Sprintf("Story %d", i)}
+		room.Mu.Unlock()
+		server.broadcastRoomState(context.Background(), "busy-room")
+	}
+
+	for _, expected := range []string{"Story 0", "Story 9"} {
+		select {
+		case state := <-received:
+			if title := state["story"].(*roompkg.Story).Title; title != expected {
+				t.Errorf("Expected the state with %s, got %s", expected, title)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a room-state with %s", expected)
+		}
+	}
+	select {
+	case state := <-received:
+		t.Errorf("Expected the broadcasts to be coalesced, got another with %v", state["story"])
+	case <-time.After(2 * roomStateInterval):
+	}
+}
+
+func TestRoomStateFlushedBeforeOtherMessages(t *testing.T) {
+	server := New()
+	defer server.Shutdown(context.Background())
+	room := server.getOrCreateRoom("busy-room")
+
+	received := make(chan string, 16)
+	client := &ExtendedWebSocket{ID: "client-1"}
+	client.Relay = func(message WebSocketMessage) error {
+		received <- message.Type
+		return nil
+	}
+	server.hub.AddClient(client)
+	room.Participants[client.ID] = &roompkg.Participant{ID: client.ID, Name: "Alice", Online: true}
+
+	server.broadcastRoomState(context.Background(), "busy-room")
+	server.broadcastRoomState(context.Background(), "busy-room")
+	server.broadcastToRoom(context.Background(), "busy-room", "name-conflict", nil)
+
+	for _, expected := range []string{"room-state", "room-state", "name-conflict"} {
+		select {
+		case msgType := <-received:
+			if msgType != expected {
+				t.Errorf("Expected %s, got %s", expected, msgType)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %s", expected)
+		}
+	}
+}
+
+func TestBroadcastsNumberedPerRoom(t *testing.T) {
+	server := New()
+	defer server.Shutdown(context.Background())
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": "numbered-room", "name": "Alice"})
+	joined := awaitMessage(t, alice, "room-state")
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": "numbered-room", "name": "Bob"})
+	awaitMessage(t, bob, "room-state")
+
+	// Bob picking a card leaves him out, so it isn't numbered
+	bob.Send("selecting", map[string]interface{}{"roomId": "numbered-room"})
+	if selecting := awaitMessage(t, alice, "selecting"); selecting.Seq != 0 {
+		t.Errorf("Expected a broadcast leaving someone out unnumbered, got %d", selecting.Seq)
+	}
+	bob.Send("vote", map[string]interface{}{"roomId": "numbered-room", "vote": "5"})
+	voted := awaitMessage(t, alice, "participant-voted")
+	if voted.Seq <= joined.Seq {
+		t.Errorf("Expected seqs to increase, got %d after %d", voted.Seq, joined.Seq)
+	}
+	if other := awaitMessage(t, bob, "participant-voted"); other.Seq != voted.Seq {
+		t.Errorf("Expected everyone to get the same seq, got %d and %d", voted.Seq, other.Seq)
+	}
+
+	// A sync answers with the state as of the last broadcast, from which
+	// Alice counts on
+	alice.Send("sync", map[string]interface{}{"roomId": "numbered-room"})
+	if state := awaitMessage(t, alice, "room-state"); state.Seq < voted.Seq {
+		t.Errorf("Expected the sync's seq to be at least %d, got %d", voted.Seq, state.Seq)
+	}
+}
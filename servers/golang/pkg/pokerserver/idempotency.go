@@ -0,0 +1,82 @@
+package pokerserver
+
+import roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+
+// Clients can give a message a "messageId" of their own, so a message sent
+// again, such as a vote retried after the connection dropped, is handled
+// once. IDs are remembered per room and sender, as a retry usually comes from
+// a new connection, on the instance that owns the room, and clients pick IDs
+// without knowing each other's.
+
+// maxMessageIDLength keeps message IDs to the size of a UUID or so
+const maxMessageIDLength = 64
+
+// idempotentMessages are handled whenever they are sent: joining and resuming
+// set up the connection they arrive on, which a retry needs as much as the
+// first attempt did
+var idempotentMessages = map[string]bool{
+	"join-room": true,
+	"resume":    true,
+	"sync":      true,
+}
+
+// dedupeMiddleware drops messages whose ID their sender used in the room in
+// the last MessageIDWindow. Messages without an ID are always handled, and an
+// ID is only kept once its message was: one the handler refused with an error
+// may be sent again. It runs after the phase check for the same reason.
+func (s *Server) dedupeMiddleware(msgType string, next HandlerFunc) HandlerFunc {
+	if roomQueries[msgType] || idempotentMessages[msgType] {
+		return next
+	}
+	return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		raw, ok := data["messageId"]
+		if !ok || !ws.Wants(FeatureMessageIDs) {
+			next(ws, data)
+			return
+		}
+		messageID, ok := raw.(string)
+		if !ok || messageID == "" || len(messageID) > maxMessageIDLength {
+			s.logf(ws.Context(), "❌ Invalid messageId in %s event from %s", msgType, ws.ID)
+			return
+		}
+
+		roomID, _ := data["roomId"].(string)
+		room, exists := s.hub.Room(roomID)
+		if !exists {
+			next(ws, data)
+			return
+		}
+		room.Mu.Lock()
+		key := roompkg.MessageKey{Sender: messageSender(room, ws), ID: messageID}
+		seen := room.SeeMessage(key, s.clock.Now())
+		room.Mu.Unlock()
+		if seen {
+			s.logf(ws.Context(), "🔁 Dropping repeated %s %s from %s in room %s", msgType, messageID, ws.ID, roomID)
+			return
+		}
+
+		ws.Refused = false
+		next(ws, data)
+		if ws.Refused {
+			room.Mu.Lock()
+			delete(room.MessageIDs, key)
+			room.Mu.Unlock()
+		}
+	}
+}
+
+// messageSender identifies who sent a message in a way that survives
+// reconnecting: the participant's ID, or else their session token. Clients
+// that aren't participants are told apart by connection. The caller must hold
+// room.Mu.
+func messageSender(room *roompkg.State, ws *ExtendedWebSocket) string {
+	if participant, ok := room.Participants[ws.ID]; ok {
+		if participant.ParticipantId != "" {
+			return "participant:" + participant.ParticipantId
+		}
+		if participant.SessionToken != "" {
+			return "session:" + participant.SessionToken
+		}
+	}
+	return "client:" + ws.ID
+}
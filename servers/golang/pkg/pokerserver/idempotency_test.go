@@ -0,0 +1,122 @@
+package pokerserver
+
+import (
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestRepeatedMessagesAreHandledOnce(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	server := New(WithClock(clock))
+	roomID := "retry-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	room, _ := server.hub.Room(roomID)
+	historyLength := func() int {
+		room.Mu.RLock()
+		defer room.Mu.RUnlock()
+		return len(room.History)
+	}
+
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "3"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID, "messageId": "reveal-1"})
+	alice.Send("reestimate", map[string]interface{}{"roomId": roomID})
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+
+	// A retry from a new connection is still a repeat
+	alice.Close()
+	retry := server.ConnectMemory()
+	defer retry.Close()
+	retry.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	retry.Send("reveal", map[string]interface{}{"roomId": roomID, "messageId": "reveal-1"})
+	if n := historyLength(); n != 1 {
+		t.Errorf("Expected the repeated reveal to be dropped, got %d rounds", n)
+	}
+
+	retry.Send("reveal", map[string]interface{}{"roomId": roomID, "messageId": "reveal-2"})
+	retry.Send("reestimate", map[string]interface{}{"roomId": roomID})
+	retry.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	clock.Advance(roompkg.MessageIDWindow)
+	retry.Send("reveal", map[string]interface{}{"roomId": roomID, "messageId": "reveal-1"})
+	if n := historyLength(); n != 3 {
+		t.Errorf("Expected new and expired message IDs to be handled, got %d rounds", n)
+	}
+}
+
+func TestMessageIDsArePerSender(t *testing.T) {
+	server := New()
+	roomID := "tabs-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice", "participantId": "alice"})
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob", "participantId": "bob"})
+
+	// Both count their messages from 1
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "3", "messageId": "1"})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5", "messageId": "1"})
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	for _, p := range room.Participants {
+		if !p.HasVoted() {
+			t.Errorf("Expected %s's vote to be handled", p.Name)
+		}
+	}
+}
+
+func TestRefusedMessagesCanBeRetried(t *testing.T) {
+	server := New()
+	roomID := "retry-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	room, _ := server.hub.Room(roomID)
+	historyLength := func() int {
+		room.Mu.RLock()
+		defer room.Mu.RUnlock()
+		return len(room.History)
+	}
+
+	// Nothing to reveal yet, so the phase check refuses it
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID, "messageId": "reveal-1"})
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "3"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID, "messageId": "reveal-1"})
+	if n := historyLength(); n != 1 {
+		t.Errorf("Expected the retried reveal to be handled, got %d rounds", n)
+	}
+
+	// The handler refuses a card that isn't on the deck
+	alice.Send("reestimate", map[string]interface{}{"roomId": roomID})
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "4", "messageId": "vote-1"})
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5", "messageId": "vote-1"})
+	room.Mu.RLock()
+	vote := room.Participants[alice.ID].Vote
+	room.Mu.RUnlock()
+	if vote == nil || *vote != "5" {
+		t.Errorf("Expected the retried vote to be handled, got %v", vote)
+	}
+
+	// Bob may only change the room's settings once he is facilitator
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	changes := map[string]interface{}{"roomId": roomID, "allowed": true, "messageId": "changes-1"}
+	bob.Send("set-vote-changes", changes)
+	alice.Send("leave-room", map[string]interface{}{"roomId": roomID})
+	bob.Send("set-vote-changes", changes)
+	room.Mu.RLock()
+	allowed := room.AllowVoteChanges
+	room.Mu.RUnlock()
+	if !allowed {
+		t.Error("Expected the facilitator's retried message to be handled")
+	}
+}
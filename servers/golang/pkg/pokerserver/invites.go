@@ -0,0 +1,168 @@
+package pokerserver
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Invites admit people to a private room without sharing its passcode. They
+// are signed with the server's invite secret and bound to the room's current
+// session, so they stop working once they expire or the room is closed.
+const (
+	defaultInviteTTL = 8 * time.Hour
+	maxInviteTTL     = 7 * 24 * time.Hour
+)
+
+var (
+	errInviteInvalid = errors.New("invalid invite")
+	errInviteExpired = errors.New("invite expired")
+)
+
+type invitePayload struct {
+	RoomID    string `json:"r"`
+	ExpiresAt int64  `json:"e"`
+	// Watch marks a spectator link, which only lets its holder watch
+	Watch bool `json:"w,omitempty"`
+}
+
+// Invite is returned when an invite is created.
+type Invite struct {
+	RoomID    string    `json:"roomId"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// newSigningSecret returns the configured secret. Without one a random secret
+// is used, so what it signs, such as invites, doesn't survive restarts and
+// only works on the issuing instance.
+func newSigningSecret(secret string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		log.Printf("Error generating signing secret: %v", err)
+	}
+	return random
+}
+
+// signInvite creates an invite token for the room session that started at createdAt.
+func (s *Server) signInvite(roomID string, createdAt, expiresAt time.Time) string {
+	return s.signRoomToken(invitePayload{RoomID: roomID, ExpiresAt: expiresAt.Unix()}, createdAt)
+}
+
+func (s *Server) signRoomToken(invite invitePayload, createdAt time.Time) string {
+	payload, _ := json.Marshal(invite)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(s.inviteMAC(payload, createdAt))
+}
+
+// verifyInvite checks that token was issued for the room session that started
+// at createdAt and hasn't expired.
+func (s *Server) verifyInvite(token, roomID string, createdAt time.Time) error {
+	return s.verifyRoomToken(token, roomID, createdAt, false)
+}
+
+// verifyRoomToken checks an invite, or a spectator link when watch is set.
+func (s *Server) verifyRoomToken(token, roomID string, createdAt time.Time, watch bool) error {
+	encodedPayload, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return errInviteInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return errInviteInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, s.inviteMAC(payload, createdAt)) {
+		return errInviteInvalid
+	}
+
+	var invite invitePayload
+	if err := json.Unmarshal(payload, &invite); err != nil || invite.RoomID != roomID || invite.Watch != watch {
+		return errInviteInvalid
+	}
+	if s.clock.Now().Unix() >= invite.ExpiresAt {
+		return errInviteExpired
+	}
+	return nil
+}
+
+func (s *Server) inviteMAC(payload []byte, createdAt time.Time) []byte {
+	mac := hmac.New(sha256.New, s.inviteSecret)
+	mac.Write(payload)
+	binary.Write(mac, binary.BigEndian, createdAt.UnixNano())
+	return mac.Sum(nil)
+}
+
+// handleCreateInvite issues an invite for a private room. Callers prove they
+// may share the room with its passcode or the admin token.
+func (s *Server) handleCreateInvite(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("id")
+	room, ok := s.hub.Room(roomID)
+	if !ok {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	if room.PasscodeHash() == nil {
+		http.Error(w, "invites are only needed for private rooms", http.StatusConflict)
+		return
+	}
+	if !s.isAdminRequest(r) && !s.authorizeRoomRead(w, r, room.PasscodeHash()) {
+		return
+	}
+
+	ttl, ok := readInviteTTL(w, r)
+	if !ok {
+		return
+	}
+
+	room.Mu.RLock()
+	createdAt := room.CreatedAt
+	room.Mu.RUnlock()
+
+	// Tokens carry whole seconds
+	expiresAt := s.clock.Now().Add(ttl).Truncate(time.Second)
+	invite := Invite{
+		RoomID:    roomID,
+		Token:     s.signInvite(roomID, createdAt, expiresAt),
+		ExpiresAt: expiresAt,
+	}
+	s.logger.Printf("🎟️ Invite created for room %s, expires %s", roomID, expiresAt.Format(time.RFC3339))
+
+	writeJSON(w, http.StatusCreated, invite)
+}
+
+// readInviteTTL reads how long a token should last from the optional request
+// body, answering the request itself when it is invalid.
+func readInviteTTL(w http.ResponseWriter, r *http.Request) (time.Duration, bool) {
+	var req struct {
+		TTL string `json:"ttl"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4<<10)).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return 0, false
+		}
+	}
+
+	if req.TTL == "" {
+		return defaultInviteTTL, true
+	}
+	ttl, err := time.ParseDuration(req.TTL)
+	if err != nil || ttl <= 0 || ttl > maxInviteTTL {
+		http.Error(w, "ttl must be a positive duration up to "+maxInviteTTL.String(), http.StatusBadRequest)
+		return 0, false
+	}
+	return ttl, true
+}
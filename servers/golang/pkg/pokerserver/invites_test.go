@@ -0,0 +1,126 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createInvite(handler http.Handler, roomID, passcode, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/rooms/"+roomID+"/invites", strings.NewReader(body))
+	if passcode != "" {
+		req.Header.Set(passcodeHeader, passcode)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestInviteAdmitsToPrivateRoom(t *testing.T) {
+	server := New(WithInviteSecret([]byte("test-secret")))
+	handler := server.Handler()
+
+	httpServer1, owner := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer owner.Close()
+
+	sendMessage(t, owner, "join-room", map[string]interface{}{
+		"roomId":   "private-room",
+		"name":     "Alice",
+		"passcode": "s3cret",
+	})
+	readMessage(t, owner, 2*time.Second) // room-state
+
+	if rec := createInvite(handler, "private-room", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without passcode, got %d", rec.Code)
+	}
+	if rec := createInvite(handler, "private-room", "s3cret", `{"ttl":"720h"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a TTL over the maximum, got %d", rec.Code)
+	}
+
+	rec := createInvite(handler, "private-room", "s3cret", `{"ttl":"1h"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var invite Invite
+	json.Unmarshal(rec.Body.Bytes(), &invite)
+	if invite.Token == "" || time.Until(invite.ExpiresAt) > time.Hour {
+		t.Errorf("Unexpected invite: %+v", invite)
+	}
+
+	httpServer2, guest := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer guest.Close()
+
+	sendMessage(t, guest, "join-room", map[string]interface{}{
+		"roomId":      "private-room",
+		"name":        "Bob",
+		"inviteToken": invite.Token + "x",
+	})
+	msg := readMessage(t, guest, 2*time.Second)
+	if msg.Type != "join-rejected" || msg.Data.(map[string]interface{})["reason"] != "invalid-invite" {
+		t.Errorf("Expected tampered invite to be rejected, got %s %v", msg.Type, msg.Data)
+	}
+
+	sendMessage(t, guest, "join-room", map[string]interface{}{
+		"roomId":      "private-room",
+		"name":        "Bob",
+		"inviteToken": invite.Token,
+	})
+	if msg := readMessage(t, guest, 2*time.Second); msg.Type != "room-state" {
+		t.Errorf("Expected invite to admit the guest, got %s", msg.Type)
+	}
+}
+
+func TestVerifyInvite(t *testing.T) {
+	server := New(WithInviteSecret([]byte("test-secret")))
+	createdAt := time.Now()
+
+	valid := server.signInvite("room", createdAt, time.Now().Add(time.Hour))
+	if err := server.verifyInvite(valid, "room", createdAt); err != nil {
+		t.Errorf("Expected valid invite, got %v", err)
+	}
+	if err := server.verifyInvite(valid, "other-room", createdAt); err != errInviteInvalid {
+		t.Errorf("Expected invite for another room to be invalid, got %v", err)
+	}
+
+	// A room recreated under the same ID is a new session
+	if err := server.verifyInvite(valid, "room", createdAt.Add(time.Second)); err != errInviteInvalid {
+		t.Errorf("Expected invite from a previous session to be invalid, got %v", err)
+	}
+
+	expired := server.signInvite("room", createdAt, time.Now().Add(-time.Second))
+	if err := server.verifyInvite(expired, "room", createdAt); err != errInviteExpired {
+		t.Errorf("Expected expired invite, got %v", err)
+	}
+
+	other := New(WithInviteSecret([]byte("other-secret")))
+	if err := other.verifyInvite(valid, "room", createdAt); err != errInviteInvalid {
+		t.Errorf("Expected invite signed with another secret to be invalid, got %v", err)
+	}
+}
+
+func TestInvitesOnlyForPrivateRooms(t *testing.T) {
+	server := New()
+	handler := server.Handler()
+
+	if rec := createInvite(handler, "missing", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown room, got %d", rec.Code)
+	}
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "public-room",
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	if rec := createInvite(handler, "public-room", "", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a public room, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,200 @@
+package pokerserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// customfield_10016 is the Story Points field on most Jira Cloud sites
+const defaultStoryPointsField = "customfield_10016"
+
+var jiraIssueKeyPattern = regexp.MustCompile(`^/browse/([A-Z][A-Z0-9_]+-[0-9]+)/?$`)
+
+// JiraClient fetches issue details from the Jira REST API so stories can be
+// enriched from a pasted issue link.
+type JiraClient struct {
+	baseURL          *url.URL
+	email            string
+	apiToken         string
+	storyPointsField string
+	httpClient       *http.Client
+	maxAttempts      int
+	retryBackoff     time.Duration
+}
+
+type JiraIssue struct {
+	Key         string
+	Summary     string
+	Description string
+}
+
+// newJiraClientFromConfig returns nil when the Jira integration isn't configured.
+func newJiraClientFromConfig(cfg JiraConfig, outbound outboundPolicy) *JiraClient {
+	if cfg.BaseURL == "" || cfg.APIToken == "" {
+		return nil
+	}
+
+	client, err := newJiraClient(cfg.BaseURL, cfg.Email, cfg.APIToken)
+	if err != nil {
+		log.Printf("Jira integration disabled: %v", err)
+		return nil
+	}
+	if cfg.StoryPointsField != "" {
+		client.storyPointsField = cfg.StoryPointsField
+	}
+	client.httpClient = outbound.client()
+	return client
+}
+
+func newJiraClient(baseURL, email, apiToken string) (*JiraClient, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jira base URL: %w", err)
+	}
+
+	return &JiraClient{
+		baseURL:          parsed,
+		email:            email,
+		apiToken:         apiToken,
+		storyPointsField: defaultStoryPointsField,
+		httpClient:       &http.Client{Timeout: 5 * time.Second},
+		maxAttempts:      3,
+		retryBackoff:     500 * time.Millisecond,
+	}, nil
+}
+
+// IssueKeyFromLink extracts the issue key from a link such as
+// https://example.atlassian.net/browse/PROJ-123. Links to other hosts are ignored.
+func (c *JiraClient) IssueKeyFromLink(link string) (string, bool) {
+	parsed, err := url.Parse(link)
+	if err != nil || !strings.EqualFold(parsed.Host, c.baseURL.Host) {
+		return "", false
+	}
+
+	path := strings.TrimPrefix(parsed.Path, c.baseURL.Path)
+	matches := jiraIssueKeyPattern.FindStringSubmatch(path)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+func (c *JiraClient) FetchIssue(ctx context.Context, key string) (*JiraIssue, error) {
+	endpoint := c.baseURL.String() + "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary,description"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.authorize(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jira returned status %d for %s", resp.StatusCode, key)
+	}
+
+	var body struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string `json:"summary"`
+			Description string `json:"description"`
+		} `json:"fields"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding jira issue %s: %w", key, err)
+	}
+
+	return &JiraIssue{
+		Key:         body.Key,
+		Summary:     body.Fields.Summary,
+		Description: body.Fields.Description,
+	}, nil
+}
+
+// UpdateStoryPoints writes the estimate into the configured story points
+// field, retrying transient failures with exponential backoff.
+func (c *JiraClient) UpdateStoryPoints(ctx context.Context, key string, points float64) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"fields": map[string]interface{}{c.storyPointsField: points},
+	})
+	if err != nil {
+		return err
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 1; ; attempt++ {
+		err = c.putIssue(ctx, key, payload)
+		if err == nil || attempt >= c.maxAttempts {
+			return err
+		}
+		if perr, ok := err.(*jiraStatusError); ok && !perr.retryable() {
+			return err
+		}
+
+		log.Printf("Jira update for %s failed (attempt %d/%d): %v", key, attempt, c.maxAttempts, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+type jiraStatusError struct {
+	key        string
+	statusCode int
+}
+
+func (e *jiraStatusError) Error() string {
+	return fmt.Sprintf("jira returned status %d for %s", e.statusCode, e.key)
+}
+
+// Rate limiting and server errors are worth retrying; client errors are not
+func (e *jiraStatusError) retryable() bool {
+	return e.statusCode == http.StatusTooManyRequests || e.statusCode >= 500
+}
+
+func (c *JiraClient) putIssue(ctx context.Context, key string, payload []byte) error {
+	endpoint := c.baseURL.String() + "/rest/api/2/issue/" + url.PathEscape(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	c.authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return &jiraStatusError{key: key, statusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// authorize uses basic auth for Jira Cloud (email + API token) and a bearer
+// personal access token for Jira Server/Data Center.
+func (c *JiraClient) authorize(req *http.Request) {
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.apiToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	}
+}
@@ -0,0 +1,234 @@
+package pokerserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func newTestJiraServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "bot@example.com" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/rest/api/2/issue/PROJ-42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"key": "PROJ-42",
+			"fields": map[string]interface{}{
+				"summary":     "Login with SSO",
+				"description": "As a user I want to log in with SSO",
+			},
+		})
+	}))
+}
+
+func TestJiraIssueKeyFromLink(t *testing.T) {
+	client, err := newJiraClient("https://example.atlassian.net/", "", "token")
+	if err != nil {
+		t.Fatalf("Failed to create Jira client: %v", err)
+	}
+
+	tests := []struct {
+		link string
+		key  string
+		ok   bool
+	}{
+		{"https://example.atlassian.net/browse/PROJ-123", "PROJ-123", true},
+		{"https://EXAMPLE.atlassian.net/browse/AB2-7/", "AB2-7", true},
+		{"https://other.atlassian.net/browse/PROJ-123", "", false},
+		{"https://example.atlassian.net/browse/proj-123", "", false},
+		{"https://example.atlassian.net/projects/PROJ", "", false},
+		{"not a url", "", false},
+	}
+
+	for _, tt := range tests {
+		key, ok := client.IssueKeyFromLink(tt.link)
+		if key != tt.key || ok != tt.ok {
+			t.Errorf("IssueKeyFromLink(%q) = %q, %v; want %q, %v", tt.link, key, ok, tt.key, tt.ok)
+		}
+	}
+}
+
+func TestJiraFetchIssue(t *testing.T) {
+	jiraServer := newTestJiraServer(t)
+	defer jiraServer.Close()
+
+	client, err := newJiraClient(jiraServer.URL, "bot@example.com", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create Jira client: %v", err)
+	}
+
+	issue, err := client.FetchIssue(context.Background(), "PROJ-42")
+	if err != nil {
+		t.Fatalf("FetchIssue failed: %v", err)
+	}
+	if issue.Key != "PROJ-42" || issue.Summary != "Login with SSO" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+
+	if _, err := client.FetchIssue(context.Background(), "PROJ-1"); err == nil {
+		t.Error("Expected error for missing issue")
+	}
+}
+
+func TestUpdateStoryEnrichesFromJira(t *testing.T) {
+	jiraServer := newTestJiraServer(t)
+	defer jiraServer.Close()
+
+	server := New()
+	client, err := newJiraClient(jiraServer.URL, "bot@example.com", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create Jira client: %v", err)
+	}
+	server.jira = client
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "update-story", map[string]interface{}{
+		"roomId": roomID,
+		"story": map[string]interface{}{
+			"title": "",
+			"link":  jiraServer.URL + "/browse/PROJ-42",
+		},
+	})
+
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "story-updated" {
+		t.Fatalf("Expected story-updated message, got %s", msg.Type)
+	}
+	story := msg.Data.(map[string]interface{})["story"].(map[string]interface{})
+	if story["key"] != "PROJ-42" {
+		t.Errorf("Expected key PROJ-42, got %v", story["key"])
+	}
+	if story["title"] != "Login with SSO" {
+		t.Errorf("Expected title from Jira summary, got %v", story["title"])
+	}
+	if story["description"] == "" {
+		t.Error("Expected description from Jira")
+	}
+}
+
+func TestSetFinalEstimateWritesBackToJira(t *testing.T) {
+	var attempts atomic.Int32
+	var writtenMu sync.Mutex
+	var written map[string]interface{}
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/rest/api/2/issue/PROJ-42" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// Fail the first attempt to exercise the retry path
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var body struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		writtenMu.Lock()
+		written = body.Fields
+		writtenMu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer jiraServer.Close()
+
+	server := New()
+	client, err := newJiraClient(jiraServer.URL, "", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create Jira client: %v", err)
+	}
+	client.retryBackoff = 10 * time.Millisecond
+	server.jira = client
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	room := server.getOrCreateRoom(roomID)
+	room.Mu.Lock()
+	room.Story = &roompkg.Story{Title: "Login with SSO", Key: "PROJ-42"}
+	room.Mu.Unlock()
+
+	sendMessage(t, ws, "set-final-estimate", map[string]interface{}{
+		"roomId":   roomID,
+		"estimate": "5",
+	})
+
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "story-updated" {
+		t.Fatalf("Expected story-updated message, got %s", msg.Type)
+	}
+	story := msg.Data.(map[string]interface{})["story"].(map[string]interface{})
+	if story["finalEstimate"] != "5" {
+		t.Errorf("Expected final estimate 5, got %v", story["finalEstimate"])
+	}
+
+	msg = readMessage(t, ws, 2*time.Second)
+	if msg.Type != "jira-sync" {
+		t.Fatalf("Expected jira-sync message, got %s", msg.Type)
+	}
+	if success := msg.Data.(map[string]interface{})["success"]; success != true {
+		t.Errorf("Expected successful Jira sync, got %v", msg.Data)
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts.Load())
+	}
+	writtenMu.Lock()
+	defer writtenMu.Unlock()
+	if written[defaultStoryPointsField] != float64(5) {
+		t.Errorf("Expected story points 5, got %v", written)
+	}
+}
+
+func TestJiraUpdateStoryPointsDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer jiraServer.Close()
+
+	client, err := newJiraClient(jiraServer.URL, "", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create Jira client: %v", err)
+	}
+	client.retryBackoff = time.Millisecond
+
+	if err := client.UpdateStoryPoints(context.Background(), "PROJ-42", 3); err == nil {
+		t.Error("Expected error for bad request")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("Expected 1 attempt for a client error, got %d", attempts.Load())
+	}
+}
@@ -0,0 +1,149 @@
+package pokerserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const linearAPIURL = "https://api.linear.app/graphql"
+
+var linearIssueKeyPattern = regexp.MustCompile(`^/[^/]+/issue/([A-Z][A-Z0-9]*-[0-9]+)(/|$)`)
+
+// LinearClient looks up issues with the Linear GraphQL API and writes final
+// estimates to their estimate field.
+type LinearClient struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+type LinearIssue struct {
+	Identifier  string
+	Title       string
+	Description string
+}
+
+// newLinearClientFromConfig returns nil when the Linear integration isn't
+// configured.
+func newLinearClientFromConfig(cfg LinearConfig, outbound outboundPolicy) *LinearClient {
+	if cfg.APIKey == "" {
+		return nil
+	}
+	client := newLinearClient(cfg.APIKey)
+	client.httpClient = outbound.client()
+	return client
+}
+
+func newLinearClient(apiKey string) *LinearClient {
+	return &LinearClient{
+		apiKey:     apiKey,
+		endpoint:   linearAPIURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// IssueKeyFromLink extracts the identifier from a link such as
+// https://linear.app/acme/issue/ENG-123/some-title.
+func (c *LinearClient) IssueKeyFromLink(link string) (string, bool) {
+	parsed, err := url.Parse(link)
+	if err != nil || !strings.EqualFold(parsed.Host, "linear.app") {
+		return "", false
+	}
+	matches := linearIssueKeyPattern.FindStringSubmatch(parsed.Path)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+func (c *LinearClient) FetchIssue(ctx context.Context, key string) (*LinearIssue, error) {
+	var data struct {
+		Issue *struct {
+			Identifier  string `json:"identifier"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		} `json:"issue"`
+	}
+	err := c.query(ctx, `query Issue($id: String!) { issue(id: $id) { identifier title description } }`,
+		map[string]interface{}{"id": key}, &data)
+	if err != nil {
+		return nil, fmt.Errorf("fetching linear issue %s: %w", key, err)
+	}
+	if data.Issue == nil {
+		return nil, fmt.Errorf("linear issue %s not found", key)
+	}
+	return &LinearIssue{
+		Identifier:  data.Issue.Identifier,
+		Title:       data.Issue.Title,
+		Description: data.Issue.Description,
+	}, nil
+}
+
+// UpdateEstimate sets the issue's estimate. Linear only takes whole numbers.
+func (c *LinearClient) UpdateEstimate(ctx context.Context, key string, points float64) error {
+	if points != float64(int(points)) {
+		return fmt.Errorf("linear estimates must be whole numbers, got %v", points)
+	}
+
+	var data struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+		} `json:"issueUpdate"`
+	}
+	err := c.query(ctx, `mutation Estimate($id: String!, $estimate: Int!) { issueUpdate(id: $id, input: {estimate: $estimate}) { success } }`,
+		map[string]interface{}{"id": key, "estimate": int(points)}, &data)
+	if err != nil {
+		return fmt.Errorf("updating linear issue %s: %w", key, err)
+	}
+	if !data.IssueUpdate.Success {
+		return fmt.Errorf("linear did not update issue %s", key)
+	}
+	return nil
+}
+
+// query runs a GraphQL request, decoding its data into out.
+func (c *LinearClient) query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	// Personal API keys are sent as they are, without a scheme
+	req.Header.Set("Authorization", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("linear returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	if len(body.Errors) > 0 {
+		return errors.New(body.Errors[0].Message)
+	}
+	return json.Unmarshal(body.Data, out)
+}
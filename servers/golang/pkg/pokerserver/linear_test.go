@@ -0,0 +1,141 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// newTestLinearServer answers the issue query for ENG-7 and records the
+// estimate of issueUpdate mutations.
+func newTestLinearServer(t *testing.T, estimates *[]float64, mu *sync.Mutex) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["id"] != "ENG-7" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data":   map[string]interface{}{"issue": nil},
+				"errors": []map[string]interface{}{{"message": "Entity not found"}},
+			})
+			return
+		}
+		if strings.HasPrefix(req.Query, "mutation") {
+			mu.Lock()
+			*estimates = append(*estimates, req.Variables["estimate"].(float64))
+			mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"issueUpdate": map[string]interface{}{"success": true}},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"issue": map[string]interface{}{
+				"identifier":  "ENG-7",
+				"title":       "Offline mode",
+				"description": "Keep voting through a flaky connection",
+			}},
+		})
+	}))
+}
+
+func TestLinearIssueKeyFromLink(t *testing.T) {
+	client := newLinearClient("key")
+
+	tests := []struct {
+		link string
+		key  string
+		ok   bool
+	}{
+		{"https://linear.app/acme/issue/ENG-123/offline-mode", "ENG-123", true},
+		{"https://linear.app/acme/issue/ENG-123", "ENG-123", true},
+		{"https://linear.app/acme/project/roadmap", "", false},
+		{"https://example.com/acme/issue/ENG-123", "", false},
+		{"https://linear.app/acme/issue/eng-123", "", false},
+	}
+	for _, tt := range tests {
+		key, ok := client.IssueKeyFromLink(tt.link)
+		if key != tt.key || ok != tt.ok {
+			t.Errorf("IssueKeyFromLink(%q) = %q, %v; want %q, %v", tt.link, key, ok, tt.key, tt.ok)
+		}
+	}
+}
+
+func TestLinearEnrichmentAndEstimateSync(t *testing.T) {
+	var mu sync.Mutex
+	var estimates []float64
+	linearServer := newTestLinearServer(t, &estimates, &mu)
+	defer linearServer.Close()
+
+	server := New()
+	server.linear = newLinearClient("lin_api_secret")
+	server.linear.endpoint = linearServer.URL
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "linear-room"
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "update-story", map[string]interface{}{
+		"roomId": roomID,
+		"story":  map[string]interface{}{"title": "", "link": "https://linear.app/acme/issue/ENG-7/offline-mode"},
+	})
+	msg := readMessage(t, ws, 2*time.Second)
+	story := msg.Data.(map[string]interface{})["story"].(map[string]interface{})
+	if story["key"] != "ENG-7" || story["tracker"] != roompkg.TrackerLinear || story["title"] != "Offline mode" {
+		t.Errorf("Expected the story to be enriched from Linear, got %v", story)
+	}
+
+	// Linear only takes whole numbers
+	sendMessage(t, ws, "set-final-estimate", map[string]interface{}{"roomId": roomID, "estimate": "0.5"})
+	readMessage(t, ws, 2*time.Second) // story-updated
+	msg = readMessage(t, ws, 2*time.Second)
+	if msg.Type != "linear-sync" || msg.Data.(map[string]interface{})["success"] != false {
+		t.Errorf("Expected a failed linear-sync, got %s %v", msg.Type, msg.Data)
+	}
+
+	sendMessage(t, ws, "set-final-estimate", map[string]interface{}{"roomId": roomID, "estimate": "5"})
+	readMessage(t, ws, 2*time.Second) // story-updated
+	msg = readMessage(t, ws, 2*time.Second)
+	if msg.Type != "linear-sync" || msg.Data.(map[string]interface{})["success"] != true {
+		t.Errorf("Expected a successful linear-sync, got %s %v", msg.Type, msg.Data)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(estimates) != 1 || estimates[0] != 5 {
+		t.Errorf("Expected estimate 5 written to Linear, got %v", estimates)
+	}
+}
+
+func TestLinearFetchIssueErrors(t *testing.T) {
+	var mu sync.Mutex
+	var estimates []float64
+	linearServer := newTestLinearServer(t, &estimates, &mu)
+	defer linearServer.Close()
+
+	client := newLinearClient("lin_api_secret")
+	client.endpoint = linearServer.URL
+	if _, err := client.FetchIssue(t.Context(), "ENG-8"); err == nil || !strings.Contains(err.Error(), "Entity not found") {
+		t.Errorf("Expected the GraphQL error, got %v", err)
+	}
+
+	client.apiKey = "wrong"
+	if _, err := client.FetchIssue(t.Context(), "ENG-7"); err == nil {
+		t.Error("Expected an unauthorized request to fail")
+	}
+}
@@ -0,0 +1,204 @@
+package pokerserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"golang.org/x/net/html"
+)
+
+// LinkPreview is what a story's page says about itself, from its OpenGraph
+// tags or title, for clients to show as a card.
+type LinkPreview = roompkg.LinkPreview
+
+const (
+	linkPreviewTimeout = 3 * time.Second
+	// Only the head of a page is read, and it is rarely this large
+	linkPreviewMaxBytes = 512 << 10
+
+	maxPreviewTitle       = 200
+	maxPreviewDescription = 500
+)
+
+// LinkPreviewer fetches the pages of story links that no tracker knows,
+// when their host is on the allow-list.
+type LinkPreviewer struct {
+	domains    []string
+	httpClient *http.Client
+}
+
+// newLinkPreviewerFromConfig returns nil when no domains are allowed.
+func newLinkPreviewerFromConfig(cfg LinkPreviewConfig, outbound outboundPolicy) *LinkPreviewer {
+	if len(cfg.Domains) == 0 {
+		return nil
+	}
+	return newLinkPreviewer(cfg.Domains, outbound)
+}
+
+func newLinkPreviewer(domains []string, outbound outboundPolicy) *LinkPreviewer {
+	p := &LinkPreviewer{domains: make([]string, len(domains))}
+	for i, domain := range domains {
+		p.domains[i] = strings.ToLower(strings.TrimPrefix(domain, "."))
+	}
+	p.httpClient = outbound.client()
+	// Stories are previewed while their update waits, so pages get less time
+	p.httpClient.Timeout = min(p.httpClient.Timeout, linkPreviewTimeout)
+	checkRedirect := p.httpClient.CheckRedirect
+	p.httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := checkRedirect(req, via); err != nil {
+			return err
+		}
+		if !p.Allowed(req.URL.String()) {
+			return fmt.Errorf("redirected to %s, which is not allowed", req.URL.Host)
+		}
+		return nil
+	}
+	return p
+}
+
+// Allowed reports whether link is a web address on an allowed domain or one
+// of its subdomains.
+func (p *LinkPreviewer) Allowed(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range p.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch reads the head of the page at link for its preview.
+func (p *LinkPreviewer) Fetch(ctx context.Context, link string) (*LinkPreview, error) {
+	if !p.Allowed(link) {
+		return nil, fmt.Errorf("%s is not on an allowed domain", link)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "planning-poker-link-preview/1.0")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("page returned status %d", resp.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" {
+		return nil, fmt.Errorf("page is %q, not HTML", mediaType)
+	}
+	preview := parseLinkPreview(io.LimitReader(resp.Body, linkPreviewMaxBytes), resp.Request.URL)
+	if preview == (LinkPreview{}) {
+		return nil, errors.New("page has nothing to preview")
+	}
+	return &preview, nil
+}
+
+// parseLinkPreview reads the OpenGraph tags of a page's head, falling back to
+// its title and description. Images are resolved against base and kept only
+// when they are web addresses.
+func parseLinkPreview(r io.Reader, base *url.URL) LinkPreview {
+	var preview, fallback LinkPreview
+	tokenizer := html.NewTokenizer(r)
+	for inTitle := false; ; {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return finishLinkPreview(preview, fallback, base)
+		case html.TextToken:
+			if inTitle {
+				fallback.Title += string(tokenizer.Text())
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = true
+			case "body":
+				return finishLinkPreview(preview, fallback, base)
+			case "meta":
+				var property, content string
+				for hasAttr {
+					var key, value []byte
+					key, value, hasAttr = tokenizer.TagAttr()
+					switch string(key) {
+					case "property", "name":
+						property = strings.ToLower(string(value))
+					case "content":
+						content = string(value)
+					}
+				}
+				switch property {
+				case "og:title":
+					preview.Title = content
+				case "og:description":
+					preview.Description = content
+				case "og:image":
+					preview.Image = content
+				case "og:site_name":
+					preview.SiteName = content
+				case "description":
+					fallback.Description = content
+				}
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return finishLinkPreview(preview, fallback, base)
+			}
+		}
+	}
+}
+
+func finishLinkPreview(preview, fallback LinkPreview, base *url.URL) LinkPreview {
+	if preview.Title == "" {
+		preview.Title = fallback.Title
+	}
+	if preview.Description == "" {
+		preview.Description = fallback.Description
+	}
+	preview.Title = roompkg.TruncateRunes(roompkg.CleanText(preview.Title, false), maxPreviewTitle)
+	preview.Description = roompkg.TruncateRunes(roompkg.CleanText(preview.Description, false), maxPreviewDescription)
+	preview.SiteName = roompkg.TruncateRunes(roompkg.CleanText(preview.SiteName, false), maxPreviewTitle)
+	image := strings.TrimSpace(preview.Image)
+	preview.Image = ""
+	if image != "" {
+		if resolved, err := base.Parse(image); err == nil && isHTTPURL(resolved.String()) && len(resolved.String()) <= maxStoryLinkLength {
+			preview.Image = resolved.String()
+		}
+	}
+	return preview
+}
+
+// previewStory fills in a preview of the page a story links to. Fetch
+// failures are logged and the story is kept without one.
+func (s *Server) previewStory(ctx context.Context, story *roompkg.Story) {
+	if s.linkPreviewer == nil || !s.linkPreviewer.Allowed(story.Link) {
+		return
+	}
+	preview, err := s.linkPreviewer.Fetch(ctx, story.Link)
+	if err != nil {
+		s.logf(ctx, "Link preview failed for %s: %v", story.Link, err)
+		return
+	}
+	story.Preview = preview
+}
@@ -0,0 +1,110 @@
+package pokerserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLinkPreview(t *testing.T) {
+	base, _ := url.Parse("https://docs.example.com/specs/login")
+	page := `<!doctype html><html><head>
+		<title>Login spec &amp; notes</title>
+		<meta name="description" content="How login works">
+		<meta property="og:site_name" content="Docs">
+		<meta property="og:image" content="/images/login.png">
+		</head><body><meta property="og:title" content="Not in the head"></body></html>`
+
+	preview := parseLinkPreview(strings.NewReader(page), base)
+	expected := LinkPreview{
+		Title:       "Login spec & notes",
+		Description: "How login works",
+		Image:       "https://docs.example.com/images/login.png",
+		SiteName:    "Docs",
+	}
+	if preview != expected {
+		t.Errorf("Expected %+v, got %+v", expected, preview)
+	}
+
+	page = `<head><title>Fallback</title><meta property="og:title" content="Login">
+		<meta property="og:image" content="javascript:alert(1)"></head>`
+	preview = parseLinkPreview(strings.NewReader(page), base)
+	if preview.Title != "Login" || preview.Image != "" {
+		t.Errorf("Expected OpenGraph title and no script image, got %+v", preview)
+	}
+}
+
+func TestLinkPreviewerAllowed(t *testing.T) {
+	previewer := newLinkPreviewer([]string{"example.com", ".Notion.so"}, newOutboundPolicy(DefaultConfig().Outbound))
+	for link, expected := range map[string]bool{
+		"https://example.com/page":          true,
+		"https://docs.example.com/page":     true,
+		"http://acme.notion.so/Login-1234":  true,
+		"https://notexample.com/page":       false,
+		"https://example.com.evil.net/page": false,
+		"ftp://example.com/file":            false,
+	} {
+		if allowed := previewer.Allowed(link); allowed != expected {
+			t.Errorf("Expected Allowed(%s) to be %v", link, expected)
+		}
+	}
+}
+
+func TestLinkPreviewRefusesInternalAddresses(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected the internal page not to be fetched")
+	}))
+	defer page.Close()
+
+	previewer := newLinkPreviewerFromConfig(LinkPreviewConfig{Domains: []string{"127.0.0.1"}}, newOutboundPolicy(DefaultConfig().Outbound))
+	if _, err := previewer.Fetch(context.Background(), page.URL); err == nil {
+		t.Error("Expected fetching a loopback address to fail")
+	}
+}
+
+func TestLinkPreviewRefusesRedirectsOffTheAllowList(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://localhost/", http.StatusFound)
+	}))
+	defer page.Close()
+
+	previewer := newLinkPreviewer([]string{"127.0.0.1"}, loopbackOutbound())
+	if _, err := previewer.Fetch(context.Background(), page.URL); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("Expected the redirect to be refused, got %v", err)
+	}
+}
+
+func TestStoryLinkPreview(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><head><meta property="og:title" content="Login spec"></head></html>`)
+	}))
+	defer page.Close()
+
+	server := New()
+	server.linkPreviewer = newLinkPreviewer([]string{"127.0.0.1"}, loopbackOutbound())
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "preview-room"
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "update-story", map[string]interface{}{
+		"roomId": roomID,
+		"story":  map[string]interface{}{"title": "Login", "link": page.URL + "/specs/login"},
+	})
+	msg := readMessage(t, ws, 2*time.Second)
+	story := msg.Data.(map[string]interface{})["story"].(map[string]interface{})
+	preview, _ := story["preview"].(map[string]interface{})
+	if preview["title"] != "Login spec" || story["title"] != "Login" {
+		t.Errorf("Expected the story to carry a preview of its page, got %v", story)
+	}
+}
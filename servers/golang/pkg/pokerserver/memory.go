@@ -0,0 +1,111 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// How many messages a MemoryClient holds before deliveries to it fail, as
+// they would for a client too slow to read
+const memoryClientBuffer = 1024
+
+// MemoryClient is a client connected in-process instead of over a WebSocket,
+// for tests and simulations that shouldn't depend on the network. Messages
+// are encoded to JSON and back both ways, so handlers and clients see what
+// they would on the wire.
+type MemoryClient struct {
+	ID string
+
+	server   *Server
+	ws       *ExtendedWebSocket
+	messages chan WebSocketMessage
+	// sendMu handles one message at a time, as a connection's read loop does
+	sendMu    sync.Mutex
+	closeOnce sync.Once
+	closed    atomic.Pointer[transport.CloseReason]
+}
+
+var errMemoryClientFull = errors.New("memory client buffer full")
+
+// ConnectMemory connects a new in-process client. Like a WebSocket client it
+// has to join a room, and is only told about rooms it is in.
+func (s *Server) ConnectMemory() *MemoryClient {
+	c := &MemoryClient{
+		ID:       generateToken(),
+		server:   s,
+		messages: make(chan WebSocketMessage, memoryClientBuffer),
+	}
+	c.ws = &ExtendedWebSocket{ID: c.ID}
+	c.ws.IsAlive.Store(true)
+	c.ws.Relay = c.deliver
+	c.ws.CloseRelay = func(reason transport.CloseReason) {
+		c.closed.Store(&reason)
+		c.Close()
+	}
+
+	s.hub.AddClient(c.ws)
+	return c
+}
+
+// Send handles a message from the client. It returns once the handler has
+// run; replies and broadcasts arrive on Messages.
+func (c *MemoryClient) Send(msgType string, data interface{}) error {
+	message, err := roundTrip(WebSocketMessage{Type: msgType, Data: data})
+	if err != nil {
+		return err
+	}
+
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	c.server.handleMessage(c.ws, message)
+	return nil
+}
+
+// Messages are the messages sent to the client, in order.
+func (c *MemoryClient) Messages() <-chan WebSocketMessage {
+	return c.messages
+}
+
+// Close disconnects the client, as if its connection dropped.
+func (c *MemoryClient) Close() {
+	c.closeOnce.Do(func() {
+		c.server.handleClientDisconnect(c.ws)
+	})
+}
+
+// CloseReason returns the close code and reason the server disconnected the
+// client with, and zero while it hasn't.
+func (c *MemoryClient) CloseReason() (int, string) {
+	if reason := c.closed.Load(); reason != nil {
+		return reason.Code, reason.Reason
+	}
+	return 0, ""
+}
+
+func (c *MemoryClient) deliver(message WebSocketMessage) error {
+	message, err := roundTrip(message)
+	if err != nil {
+		return err
+	}
+	select {
+	case c.messages <- message:
+		return nil
+	default:
+		return errMemoryClientFull
+	}
+}
+
+// roundTrip encodes a message as JSON and decodes it again.
+func roundTrip(message WebSocketMessage) (WebSocketMessage, error) {
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		return WebSocketMessage{}, err
+	}
+	var decoded WebSocketMessage
+	err = json.Unmarshal(encoded, &decoded)
+	return decoded, err
+}
@@ -0,0 +1,53 @@
+package pokerserver
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMemoryBrokerConnectsServers(t *testing.T) {
+	broker := NewMemoryBroker()
+	var servers []*Server
+	for i := 0; i < 2; i++ {
+		server := New(WithAdminToken("secret"), WithBroker(broker))
+		if err := server.Initialize(); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		defer server.Shutdown(t.Context())
+		servers = append(servers, server)
+	}
+	if broker.Subscribers() != 2 {
+		t.Fatalf("Expected both servers subscribed, got %d", broker.Subscribers())
+	}
+
+	alice := servers[0].ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": "cluster-room", "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+
+	// The room lives on the first server, the announcement reaches it through the broker
+	rec := postBroadcast(servers[1].Handler(), `{"message":"Hello","roomId":"cluster-room"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	data := awaitMessage(t, alice, "announcement").Data.(map[string]interface{})
+	if data["message"] != "Hello" {
+		t.Errorf("Unexpected announcement: %v", data)
+	}
+}
+
+func TestMemoryBrokerByDefault(t *testing.T) {
+	server := New(WithAdminToken("secret"))
+	if err := server.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer server.Shutdown(t.Context())
+
+	if _, ok := server.broker.(*MemoryBroker); !ok {
+		t.Fatalf("Expected a memory broker without Redis, got %T", server.broker)
+	}
+	// Alone, the server knows a room it doesn't have doesn't exist
+	if rec := postBroadcast(server.Handler(), `{"message":"Hello","roomId":"missing"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown room, got %d", rec.Code)
+	}
+}
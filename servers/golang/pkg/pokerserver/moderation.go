@@ -0,0 +1,246 @@
+package pokerserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// hashIP keys client addresses with the instance ID, so ban lists don't
+// hold raw addresses.
+func (s *Server) hashIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(s.instanceID))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) handleKickParticipant(ws *ExtendedWebSocket, data map[string]interface{}) {
+	s.removeFromRoom(ws, data, false)
+}
+
+// handleBanParticipant kicks a participant and keeps them from rejoining the
+// room with the same session, participant ID or address.
+func (s *Server) handleBanParticipant(ws *ExtendedWebSocket, data map[string]interface{}) {
+	s.removeFromRoom(ws, data, true)
+}
+
+func (s *Server) removeFromRoom(ws *ExtendedWebSocket, data map[string]interface{}, ban bool) {
+	roomID, _ := data["roomId"].(string)
+	targetID, _ := data["id"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	target := s.hub.Client(targetID)
+
+	room.Mu.Lock()
+	participant, isParticipant := room.Participants[targetID]
+	if room.FacilitatorID != ws.ID || !isParticipant || targetID == ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring removal of %s from room %s requested by %s", targetID, roomID, ws.ID)
+		msgType := "kick-participant"
+		if ban {
+			msgType = "ban-participant"
+		}
+		s.sendError(ws, msgType, errCodeNotAllowed, "Only the facilitator can remove other participants", nil)
+		return
+	}
+	action := roompkg.AuditKicked
+	if ban {
+		ipHash := ""
+		if target != nil {
+			ipHash = s.hashIP(target.RemoteIP)
+		}
+		room.Bans.Add(participant, ipHash)
+		action = roompkg.AuditBanned
+	}
+	room.RecordAudit(roompkg.AuditEntry{Action: action, ActorID: ws.ID, TargetID: targetID})
+	room.Mu.Unlock()
+
+	if target != nil {
+		s.sendToClient(target, "kicked", map[string]interface{}{
+			"roomId": roomID,
+			"banned": ban,
+		})
+		if ban {
+			s.disconnect(target, transport.CloseBanned, "banned from the room")
+		} else {
+			s.disconnect(target, transport.CloseKicked, "removed from the room")
+		}
+	}
+	s.removeParticipant(roomID, targetID)
+
+	if ban {
+		s.logf(ws.Context(), "🚫 %s banned %s from room %s", ws.ID, targetID, roomID)
+	} else {
+		s.logf(ws.Context(), "👢 %s kicked %s from room %s", ws.ID, targetID, roomID)
+	}
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+// checkJoinBan rejects banned clients with a join-rejected message.
+func (s *Server) checkJoinBan(ws *ExtendedWebSocket, room *roompkg.State, sessionToken, participantID string) bool {
+	ipHash := s.hashIP(ws.RemoteIP)
+
+	room.Mu.RLock()
+	banned := room.Bans.Matches(sessionToken, participantID, ipHash)
+	room.Mu.RUnlock()
+
+	if !banned {
+		return true
+	}
+
+	s.logf(ws.Context(), "⛔ Rejecting join for banned client %s in room %s", ws.ID, room.ID)
+	s.sendToClient(ws, "join-rejected", map[string]interface{}{
+		"roomId": room.ID,
+		"reason": "banned",
+	})
+	return false
+}
+
+// handleMoveParticipant lets the facilitator send a participant to another
+// room, as when an estimation event splits into teams. The participant starts
+// over there without a vote, and both rooms hear of the move. Private target
+// rooms need their passcode, and the move can't get anyone into a room that
+// is closed, waits for its scheduled start, bans them or belongs to another
+// team.
+func (s *Server) handleMoveParticipant(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	targetID, _ := data["id"].(string)
+	toRoomID, _ := data["targetRoomId"].(string)
+	passcode, _ := data["passcode"].(string)
+
+	room, exists := s.hub.Room(roomID)
+	if !exists {
+		return
+	}
+
+	target := s.hub.Client(targetID)
+
+	room.Mu.RLock()
+	participant, isParticipant := room.Participants[targetID]
+	allowed := room.FacilitatorID == ws.ID && isParticipant && targetID != ws.ID
+	var name, participantID, sessionToken string
+	if isParticipant {
+		name, participantID, sessionToken = participant.Name, participant.ParticipantId, participant.SessionToken
+	}
+	room.Mu.RUnlock()
+	if !allowed {
+		s.logf(ws.Context(), "⚠️ Ignoring move of %s from room %s requested by %s", targetID, roomID, ws.ID)
+		s.sendError(ws, "move-participant", errCodeNotAllowed, "Only the facilitator can move other participants", nil)
+		return
+	}
+
+	refuse := func(message string) {
+		s.logf(ws.Context(), "⛔ Refusing to move %s from room %s to %s: %s", targetID, roomID, toRoomID, message)
+		s.sendError(ws, "move-participant", errCodeNotAllowed, message, map[string]interface{}{"targetRoomId": toRoomID})
+	}
+	if toRoomID == roomID {
+		s.sendError(ws, "move-participant", errCodeInvalidInput, "targetRoomId must be another room", nil)
+		return
+	}
+	if target == nil || target.RoomID() != roomID {
+		refuse("Only participants who are online can be moved")
+		return
+	}
+	if s.ownershipEnabled() {
+		if owner, err := s.roomOwner(ws.Context(), toRoomID); err != nil || owner != s.instanceID {
+			refuse("The target room is held by another instance")
+			return
+		}
+	}
+
+	// Only create the target room once the move goes ahead, so a refused one
+	// leaves no empty room behind
+	toRoom, exists := s.hub.Room(toRoomID)
+	if exists {
+		if hash := toRoom.PasscodeHash(); hash != nil && bcrypt.CompareHashAndPassword(hash, []byte(passcode)) != nil {
+			refuse("The target room is private; send its passcode")
+			return
+		}
+	} else {
+		toRoom = s.getOrCreateRoom(toRoomID)
+	}
+	refuseLocked := func(message string) {
+		toRoom.Mu.Unlock()
+		if !exists {
+			s.discardRoom(toRoom)
+		}
+		refuse(message)
+	}
+
+	toRoom.Mu.Lock()
+	// Another join may have created the room meanwhile, behind a passcode
+	if !exists && toRoom.Passcode != nil && bcrypt.CompareHashAndPassword(toRoom.Passcode, []byte(passcode)) != nil {
+		refuseLocked("The target room is private; send its passcode")
+		return
+	}
+	if toRoom.Closed {
+		refuseLocked("The target room is closed")
+		return
+	}
+	if toRoom.WaitingToStart() {
+		refuseLocked("The target room hasn't started yet")
+		return
+	}
+	if toRoom.Bans.Matches(sessionToken, participantID, s.hashIP(target.RemoteIP)) {
+		refuseLocked("The participant is banned from the target room")
+		return
+	}
+	empty := len(toRoom.Participants) == 0
+	if !empty && !toRoom.Admits(target.TeamID) {
+		refuseLocked("The target room belongs to another team")
+		return
+	}
+	// The participant may have left or been moved since
+	if !target.SwapRoomID(roomID, toRoomID) {
+		refuseLocked("Only participants who are online can be moved")
+		return
+	}
+	if empty && target.TeamID != "" {
+		toRoom.Apply(&roompkg.TeamEvent{TeamID: target.TeamID, Scoped: true})
+	}
+	toRoom.Apply(&roompkg.JoinEvent{
+		ClientID:      targetID,
+		Name:          s.uniqueName(toRoom, name, targetID, s.namePolicy == DuplicateNameToken),
+		ParticipantID: participantID,
+	})
+	moved := toRoom.Participants[targetID]
+	moved.SessionToken = generateToken()
+	movedName, token := moved.Name, moved.SessionToken
+	toRoom.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditJoined, ActorID: targetID})
+	toRoom.Mu.Unlock()
+
+	room.Mu.Lock()
+	room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditMoved, ActorID: ws.ID, TargetID: targetID})
+	room.Mu.Unlock()
+	s.removeParticipant(roomID, targetID)
+
+	s.logf(ws.Context(), "🚚 %s moved %s from room %s to %s", ws.ID, targetID, roomID, toRoomID)
+	s.sendToClient(target, "moved", map[string]interface{}{
+		"fromRoomId":   roomID,
+		"roomId":       toRoomID,
+		"name":         movedName,
+		"sessionToken": token,
+	})
+	notice := map[string]interface{}{
+		"id":         targetID,
+		"name":       name,
+		"fromRoomId": roomID,
+		"toRoomId":   toRoomID,
+	}
+	s.broadcastToRoom(ws.Context(), roomID, "participant-moved", notice)
+	s.broadcastToRoom(ws.Context(), toRoomID, "participant-moved", notice)
+	s.broadcastRoomState(ws.Context(), roomID)
+	s.broadcastRoomState(ws.Context(), toRoomID)
+}
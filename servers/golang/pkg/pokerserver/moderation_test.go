@@ -0,0 +1,276 @@
+package pokerserver
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// joinAliceAndBob joins Alice (the facilitator) and then Bob to test-room and
+// returns Bob's client ID.
+func joinAliceAndBob(t *testing.T, alice, bob *websocket.Conn) string {
+	t.Helper()
+
+	sendMessage(t, alice, "join-room", map[string]interface{}{
+		"roomId":        "test-room",
+		"name":          "Alice",
+		"participantId": "alice",
+	})
+	readMessage(t, alice, 2*time.Second) // room-state
+
+	sendMessage(t, bob, "join-room", map[string]interface{}{
+		"roomId":        "test-room",
+		"name":          "Bob",
+		"participantId": "bob",
+	})
+	readMessage(t, bob, 2*time.Second) // room-state
+	msg := readMessage(t, alice, 2*time.Second)
+
+	for _, p := range msg.Data.(map[string]interface{})["participants"].([]interface{}) {
+		if p := p.(map[string]interface{}); p["name"] == "Bob" {
+			return p["id"].(string)
+		}
+	}
+	t.Fatal("Bob not found in room state")
+	return ""
+}
+
+func TestKickParticipant(t *testing.T) {
+	server := New()
+
+	httpServer1, alice := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer alice.Close()
+
+	httpServer2, bob := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer bob.Close()
+
+	bobID := joinAliceAndBob(t, alice, bob)
+
+	sendMessage(t, alice, "kick-participant", map[string]interface{}{
+		"roomId": "test-room",
+		"id":     bobID,
+	})
+
+	msg := readMessage(t, bob, 2*time.Second)
+	if msg.Type != "kicked" || msg.Data.(map[string]interface{})["banned"] != false {
+		t.Fatalf("Expected kicked message, got %s %v", msg.Type, msg.Data)
+	}
+	expectClose(t, bob, transport.CloseKicked)
+	msg = readMessage(t, alice, 2*time.Second)
+	if participants := msg.Data.(map[string]interface{})["participants"].([]interface{}); len(participants) != 1 {
+		t.Errorf("Expected Bob to be removed, got %d participants", len(participants))
+	}
+
+	// A kicked participant may come back
+	bob, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer2.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to reconnect: %v", err)
+	}
+	defer bob.Close()
+	sendMessage(t, bob, "join-room", map[string]interface{}{
+		"roomId":        "test-room",
+		"name":          "Bob",
+		"participantId": "bob",
+	})
+	if msg := readMessage(t, bob, 2*time.Second); msg.Type != "room-state" {
+		t.Errorf("Expected kicked participant to rejoin, got %s", msg.Type)
+	}
+}
+
+func TestBanParticipant(t *testing.T) {
+	server := New()
+
+	httpServer1, alice := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer alice.Close()
+
+	httpServer2, bob := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer bob.Close()
+
+	bobID := joinAliceAndBob(t, alice, bob)
+
+	// Only the facilitator can ban
+	sendMessage(t, bob, "ban-participant", map[string]interface{}{
+		"roomId": "test-room",
+		"id":     bobID,
+	})
+	sendMessage(t, alice, "ban-participant", map[string]interface{}{
+		"roomId": "test-room",
+		"id":     bobID,
+	})
+
+	msg := readMessage(t, bob, 2*time.Second)
+	if msg.Type != "kicked" || msg.Data.(map[string]interface{})["banned"] != true {
+		t.Fatalf("Expected kicked message with ban, got %s %v", msg.Type, msg.Data)
+	}
+	expectClose(t, bob, transport.CloseBanned)
+
+	bob, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer2.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to reconnect: %v", err)
+	}
+	defer bob.Close()
+	sendMessage(t, bob, "join-room", map[string]interface{}{
+		"roomId":        "test-room",
+		"name":          "Bobby",
+		"participantId": "bob",
+	})
+	msg = readMessage(t, bob, 2*time.Second)
+	if msg.Type != "join-rejected" || msg.Data.(map[string]interface{})["reason"] != "banned" {
+		t.Errorf("Expected banned participant to be rejected, got %s %v", msg.Type, msg.Data)
+	}
+}
+
+func TestMoveParticipant(t *testing.T) {
+	server := New()
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	carol := server.ConnectMemory()
+	defer carol.Close()
+
+	alice.Send("join-room", map[string]interface{}{"roomId": "event-room", "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+	bob.Send("join-room", map[string]interface{}{"roomId": "event-room", "name": "Bob"})
+	awaitMessage(t, bob, "room-state")
+	bob.Send("vote", map[string]interface{}{"roomId": "event-room", "vote": "5"})
+	carol.Send("join-room", map[string]interface{}{"roomId": "team-b", "name": "Carol", "passcode": "s3cret"})
+	awaitMessage(t, carol, "room-state")
+
+	alice.Send("move-participant", map[string]interface{}{"roomId": "event-room", "id": bob.ID, "targetRoomId": "team-b"})
+	if data := awaitMessage(t, alice, "error").Data.(map[string]interface{}); data["code"] != errCodeNotAllowed {
+		t.Errorf("Expected the move into a private room to be refused, got %v", data)
+	}
+
+	alice.Send("move-participant", map[string]interface{}{
+		"roomId": "event-room", "id": bob.ID, "targetRoomId": "team-b", "passcode": "s3cret",
+	})
+	moved := awaitMessage(t, bob, "moved").Data.(map[string]interface{})
+	if moved["roomId"] != "team-b" || moved["fromRoomId"] != "event-room" || moved["sessionToken"] == "" {
+		t.Errorf("Expected Bob to hear where he was moved, got %v", moved)
+	}
+	for _, client := range []*MemoryClient{alice, carol} {
+		notice := awaitMessage(t, client, "participant-moved").Data.(map[string]interface{})
+		if notice["id"] != bob.ID || notice["toRoomId"] != "team-b" {
+			t.Errorf("Expected both rooms to hear of the move, got %v", notice)
+		}
+	}
+
+	from, _ := server.hub.Room("event-room")
+	from.Mu.RLock()
+	_, stayed := from.Participants[bob.ID]
+	from.Mu.RUnlock()
+	to, _ := server.hub.Room("team-b")
+	to.Mu.RLock()
+	participant, arrived := to.Participants[bob.ID]
+	to.Mu.RUnlock()
+	if stayed || !arrived || participant.HasVoted() {
+		t.Fatalf("Expected Bob in team-b without a vote, got stayed=%v arrived=%v", stayed, arrived)
+	}
+
+	// Bob now acts in the room he was moved to
+	bob.Send("vote", map[string]interface{}{"roomId": "team-b", "vote": "8"})
+	if voted := awaitMessage(t, carol, "participant-voted").Data.(map[string]interface{}); voted["id"] != bob.ID {
+		t.Errorf("Expected Bob's vote in team-b, got %v", voted)
+	}
+}
+
+func TestMoveParticipantRefusesClosedAndScheduledRooms(t *testing.T) {
+	server := New()
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	carol := server.ConnectMemory()
+	defer carol.Close()
+
+	alice.Send("join-room", map[string]interface{}{"roomId": "event-room", "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+	bob.Send("join-room", map[string]interface{}{"roomId": "event-room", "name": "Bob"})
+	awaitMessage(t, bob, "room-state")
+	carol.Send("join-room", map[string]interface{}{"roomId": "later-room", "name": "Carol"})
+	awaitMessage(t, carol, "room-state")
+	carol.Send("update-room", map[string]interface{}{
+		"roomId": "later-room", "scheduledAt": float64(time.Now().Add(time.Hour).UnixMilli()),
+	})
+
+	alice.Send("move-participant", map[string]interface{}{"roomId": "event-room", "id": bob.ID, "targetRoomId": "later-room"})
+	if data := awaitMessage(t, alice, "error").Data.(map[string]interface{}); data["code"] != errCodeNotAllowed {
+		t.Errorf("Expected the move into a room waiting to start to be refused, got %v", data)
+	}
+
+	// A room found just as its session ends is closed but still at hand
+	closed := server.getOrCreateRoom("closed-room")
+	closed.Mu.Lock()
+	closed.Closed = true
+	closed.Mu.Unlock()
+	alice.Send("move-participant", map[string]interface{}{"roomId": "event-room", "id": bob.ID, "targetRoomId": "closed-room"})
+	if data := awaitMessage(t, alice, "error").Data.(map[string]interface{}); data["code"] != errCodeNotAllowed {
+		t.Errorf("Expected the move into a closed room to be refused, got %v", data)
+	}
+
+	if bob.ws.RoomID() != "event-room" {
+		t.Errorf("Expected Bob to stay in event-room, got %q", bob.ws.RoomID())
+	}
+	closed.Mu.RLock()
+	_, arrived := closed.Participants[bob.ID]
+	closed.Mu.RUnlock()
+	if arrived {
+		t.Error("Expected Bob not to join the closed room")
+	}
+
+	// A refused move into a room that doesn't exist yet leaves none behind
+	dave := server.ConnectMemory()
+	dave.Send("join-room", map[string]interface{}{"roomId": "event-room", "name": "Dave"})
+	awaitMessage(t, dave, "room-state")
+	dave.Close()
+	alice.Send("move-participant", map[string]interface{}{"roomId": "event-room", "id": dave.ID, "targetRoomId": "new-room"})
+	if data := awaitMessage(t, alice, "error").Data.(map[string]interface{}); data["code"] != errCodeNotAllowed {
+		t.Errorf("Expected the move of an offline participant to be refused, got %v", data)
+	}
+	if _, ok := server.hub.Room("new-room"); ok {
+		t.Error("Expected the refused move not to create the target room")
+	}
+}
+
+func TestMoveParticipantWhileVoting(t *testing.T) {
+	server := New()
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	bob := server.ConnectMemory()
+	defer bob.Close()
+
+	alice.Send("join-room", map[string]interface{}{"roomId": "event-room", "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+	bob.Send("join-room", map[string]interface{}{"roomId": "event-room", "name": "Bob"})
+	awaitMessage(t, bob, "room-state")
+
+	// Bob keeps voting in whichever room he is in while he is moved
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			bob.Send("vote", map[string]interface{}{"roomId": bob.ws.RoomID(), "vote": "5"})
+		}
+	}()
+	alice.Send("move-participant", map[string]interface{}{"roomId": "event-room", "id": bob.ID, "targetRoomId": "team-b"})
+	<-done
+
+	if bob.ws.RoomID() != "team-b" {
+		t.Fatalf("Expected Bob in team-b, got %q", bob.ws.RoomID())
+	}
+	from, _ := server.hub.Room("event-room")
+	from.Mu.RLock()
+	_, stayed := from.Participants[bob.ID]
+	from.Mu.RUnlock()
+	if stayed {
+		t.Error("Expected Bob to have left event-room")
+	}
+}
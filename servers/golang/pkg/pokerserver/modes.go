@@ -0,0 +1,35 @@
+package pokerserver
+
+import roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+
+// handleSetMode switches the voting mode of a room. Only the facilitator may
+// do so, and it starts the round over as the votes cast so far no longer fit.
+func (s *Server) handleSetMode(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	settings, err := roompkg.ParseRoomSettings(data)
+	if err != nil {
+		s.logf(ws.Context(), "⚠️ Ignoring set-mode from %s in room %s: %v", ws.ID, roomID, err)
+		s.sendError(ws, "set-mode", errCodeInvalidInput, err.Error(), nil)
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring set-mode from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "set-mode", errCodeNotAllowed, "Only the facilitator can change the voting mode", nil)
+		return
+	}
+	room.Apply(&roompkg.SettingsEvent{Settings: settings})
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "🎛️ Room %s switched to %s voting by %s", roomID, settings.Mode, ws.ID)
+	s.broadcastRoomState(ws.Context(), roomID)
+}
@@ -0,0 +1,188 @@
+package pokerserver
+
+import (
+	"encoding/csv"
+	"testing"
+	"time"
+)
+
+func TestVoteOffDeck(t *testing.T) {
+	server := New()
+	roomID := "deck-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "7"})
+	data := awaitMessage(t, alice, "error").Data.(map[string]interface{})
+	if data["messageType"] != "vote" || data["code"] != errCodeInvalidInput || data["reason"] != reasonNotInDeck {
+		t.Errorf("Expected the vote refused, got %v", data)
+	}
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "☕"})
+	if voted := awaitMessage(t, alice, "participant-voted").Data.(map[string]interface{}); voted["abstained"] != true {
+		t.Errorf("Expected the coffee card to abstain, got %v", voted)
+	}
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": ""})
+	if voted := awaitMessage(t, alice, "participant-voted").Data.(map[string]interface{}); voted["hasVote"] != false {
+		t.Errorf("Expected an empty vote to clear it, got %v", voted)
+	}
+
+	alice.Send("set-mode", map[string]interface{}{"roomId": roomID, "mode": "points", "deck": []interface{}{"S", "M", "L"}})
+	settings := awaitMessage(t, alice, "room-state").Data.(map[string]interface{})["settings"].(map[string]interface{})
+	if deck, _ := settings["deck"].([]interface{}); len(deck) != 3 {
+		t.Fatalf("Expected the deck in the room state, got %v", settings)
+	}
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	awaitMessage(t, alice, "error")
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "L"})
+	awaitMessage(t, alice, "participant-voted")
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	vote := room.Participants[alice.ws.ID].Vote
+	room.Mu.RUnlock()
+	if vote == nil || *vote != "L" {
+		t.Errorf("Expected only the card from the deck kept, got %v", vote)
+	}
+}
+
+func TestTwoDimensionalVoting(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "two-dimensional-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "set-mode", map[string]interface{}{
+		"roomId":     roomID,
+		"mode":       "two-dimensional",
+		"dimensions": []interface{}{"effort", "risk"},
+	})
+	msg := readMessage(t, ws, 2*time.Second)
+	settings := msg.Data.(map[string]interface{})["settings"].(map[string]interface{})
+	if msg.Type != "room-state" || settings["mode"] != "two-dimensional" {
+		t.Fatalf("Expected room-state in two-dimensional mode, got %s %v", msg.Type, settings)
+	}
+
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "secondVote": "3"})
+	msg = readMessage(t, ws, 2*time.Second)
+	voted := msg.Data.(map[string]interface{})
+	if voted["hasVote"] != true || voted["hasSecondVote"] != true {
+		t.Errorf("Expected both values to be cast, got %v", voted)
+	}
+
+	// Revealing one dimension shows its stats without finishing the round
+	sendMessage(t, ws, "reveal", map[string]interface{}{"roomId": roomID, "dimension": "effort"})
+	msg = readMessage(t, ws, 2*time.Second)
+	revealed := msg.Data.(map[string]interface{})
+	if msg.Type != "revealed" || revealed["dimension"] != "effort" || revealed["stats"].(map[string]interface{})["average"] != float64(5) {
+		t.Fatalf("Expected effort to be revealed, got %s %v", msg.Type, revealed)
+	}
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	rounds := len(room.History)
+	room.Mu.RUnlock()
+	if rounds != 0 {
+		t.Errorf("Expected no round to be recorded yet, got %d", rounds)
+	}
+
+	// The revealed value is locked, the other can still change
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "13", "secondVote": "8"})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+
+	sendMessage(t, ws, "reveal", map[string]interface{}{"roomId": roomID, "dimension": "risk"})
+	msg = readMessage(t, ws, 2*time.Second)
+	revealed = msg.Data.(map[string]interface{})
+	if revealed["stats"].(map[string]interface{})["average"] != float64(5) ||
+		revealed["secondStats"].(map[string]interface{})["average"] != float64(8) {
+		t.Errorf("Expected both dimensions' stats, got %v", revealed)
+	}
+
+	rec := exportRequest(server, roomID, "csv")
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(rows) != 2 || rows[1][6] != "5" || rows[1][12] != "8" || rows[1][13] != "8" {
+		t.Errorf("Unexpected CSV rows: %v", rows)
+	}
+}
+
+func TestThumbsMode(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "thumbs-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+	sendMessage(t, ws, "set-mode", map[string]interface{}{"roomId": roomID, "mode": "thumbs"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// A story point isn't a thumb, so only the second vote counts
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	if msg := readMessage(t, ws, 2*time.Second); msg.Type != "error" {
+		t.Fatalf("Expected the story point to be refused, got %s", msg.Type)
+	}
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "up"})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+
+	sendMessage(t, ws, "reveal", map[string]interface{}{"roomId": roomID})
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "revealed" {
+		t.Fatalf("Expected revealed message, got %s", msg.Type)
+	}
+	stats := msg.Data.(map[string]interface{})["stats"].(map[string]interface{})
+	if stats["voteCount"] != float64(1) || stats["agreement"] != float64(100) {
+		t.Errorf("Expected one agreeing vote, got %v", stats)
+	}
+}
+
+func TestDotVoting(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "dot-voting-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+	sendMessage(t, ws, "set-mode", map[string]interface{}{
+		"roomId":  roomID,
+		"mode":    "dot-voting",
+		"options": []interface{}{"Search", "Checkout", "Wishlist"},
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "dots": map[string]interface{}{"3": 2, "1": 1}})
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "participant-voted" || msg.Data.(map[string]interface{})["hasVote"] != true {
+		t.Fatalf("Expected the dots to be accepted, got %s %v", msg.Type, msg.Data)
+	}
+
+	sendMessage(t, ws, "reveal", map[string]interface{}{"roomId": roomID})
+	msg = readMessage(t, ws, 2*time.Second)
+	ranking, _ := msg.Data.(map[string]interface{})["ranking"].([]interface{})
+	if len(ranking) != 3 {
+		t.Fatalf("Expected a ranking of all options, got %v", msg.Data)
+	}
+	for i, expected := range []string{"Wishlist", "Search", "Checkout"} {
+		if title := ranking[i].(map[string]interface{})["title"]; title != expected {
+			t.Errorf("Expected %s at position %d, got %v", expected, i+1, title)
+		}
+	}
+	if dots := ranking[0].(map[string]interface{})["dots"]; dots != float64(2) {
+		t.Errorf("Expected 2 dots for the top option, got %v", dots)
+	}
+}
@@ -0,0 +1,122 @@
+package pokerserver
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func dialMsgpack(t *testing.T, server *Server, useQuery bool) (*httptest.Server, *websocket.Conn) {
+	httpServer := httptest.NewServer(server.Handler())
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/api/ws"
+
+	dialer := *websocket.DefaultDialer
+	if useQuery {
+		wsURL += "?format=msgpack"
+	} else {
+		dialer.Subprotocols = []string{transport.MsgpackSubprotocol}
+	}
+
+	conn, _, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		httpServer.Close()
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if !useQuery && conn.Subprotocol() != transport.MsgpackSubprotocol {
+		t.Fatalf("Expected msgpack subprotocol, got %q", conn.Subprotocol())
+	}
+	return httpServer, conn
+}
+
+func sendMsgpack(t *testing.T, conn *websocket.Conn, msgType string, data interface{}) {
+	payload, err := msgpack.Marshal(map[string]interface{}{"type": msgType, "data": data})
+	if err != nil {
+		t.Fatalf("Failed to encode message: %v", err)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, payload); err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+}
+
+func readMsgpack(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	messageType, payload, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	if messageType != websocket.BinaryMessage {
+		t.Fatalf("Expected binary frame, got type %d", messageType)
+	}
+
+	var message map[string]interface{}
+	if err := msgpack.Unmarshal(payload, &message); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	return message
+}
+
+func TestMsgpackNegotiation(t *testing.T) {
+	for _, useQuery := range []bool{false, true} {
+		server := New()
+		httpServer, conn := dialMsgpack(t, server, useQuery)
+
+		sendMsgpack(t, conn, "join-room", map[string]interface{}{
+			"roomId": "test-room",
+			"name":   "Alice",
+		})
+
+		message := readMsgpack(t, conn)
+		if message["type"] != "room-state" {
+			t.Errorf("Expected room-state, got %v", message["type"])
+		}
+		data := message["data"].(map[string]interface{})
+		participants := data["participants"].([]interface{})
+		alice := participants[0].(map[string]interface{})
+		if alice["name"] != "Alice" || alice["online"] != true {
+			t.Errorf("Expected participant encoded with JSON field names, got %v", alice)
+		}
+		if _, leaked := alice["SessionToken"]; leaked {
+			t.Error("Expected fields hidden from JSON to be hidden from msgpack too")
+		}
+
+		conn.Close()
+		httpServer.Close()
+	}
+}
+
+func TestMsgpackAndJSONClientsShareRoom(t *testing.T) {
+	server := New()
+
+	httpServer, conn := dialMsgpack(t, server, false)
+	defer httpServer.Close()
+	defer conn.Close()
+	sendMsgpack(t, conn, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMsgpack(t, conn) // room-state
+
+	jsonServer, ws := createTestWSConnection(t, server)
+	defer jsonServer.Close()
+	defer ws.Close()
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Bob",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+	readMsgpack(t, conn)              // room-state with Bob
+
+	sendMsgpack(t, conn, "vote", map[string]interface{}{
+		"roomId": "test-room",
+		"vote":   "8",
+	})
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "participant-voted" {
+		t.Errorf("Expected JSON client to see the msgpack vote, got %s", msg.Type)
+	}
+}
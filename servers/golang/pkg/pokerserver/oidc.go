@@ -0,0 +1,506 @@
+package pokerserver
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// Single sign-on follows the OpenID Connect authorization code flow with
+// PKCE. /auth/login sends the browser to the provider, and /auth/callback
+// turns the ID token it comes back with into a session token signed by this
+// server. The session token is set as a cookie and handed to the page that
+// started the login in the URL fragment, for clients served from another
+// origin to connect with.
+const (
+	sessionCookieName = "poker_session"
+	loginCookieName   = "poker_login"
+	loginTTL          = 10 * time.Minute
+	// Unknown signing keys refetch the provider's keys at most this often
+	jwksRefreshInterval = time.Minute
+	// Clock skew tolerated when checking ID token expiry
+	idTokenLeeway = time.Minute
+)
+
+// Audiences keeping the tokens this server signs from standing in for each other
+const (
+	sessionAudience = "session"
+	loginAudience   = "login"
+)
+
+// sessionClaims are carried by the session token a client connects with.
+type sessionClaims struct {
+	Audience  string `json:"aud"`
+	Subject   string `json:"sub"`
+	Name      string `json:"name,omitempty"`
+	TeamID    string `json:"team,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func (c sessionClaims) identity() clientIdentity {
+	return clientIdentity{TeamID: c.TeamID, UserID: c.Subject, UserName: c.Name}
+}
+
+// loginState ties the provider's callback to the browser that started the
+// login. It travels in a signed cookie, so nothing is kept server-side.
+type loginState struct {
+	Audience  string `json:"aud"`
+	State     string `json:"state"`
+	Nonce     string `json:"nonce"`
+	Verifier  string `json:"verifier"`
+	Return    string `json:"return"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// oidcProvider talks to the OpenID provider and signs session tokens. The
+// provider's configuration and keys are fetched on first use and cached.
+type oidcProvider struct {
+	cfg           OIDCConfig
+	sessionSecret []byte
+	httpClient    *http.Client
+
+	mu          sync.Mutex
+	discovery   *oidcDiscovery
+	keys        map[string]*rsa.PublicKey
+	keysFetched time.Time
+}
+
+// newOIDCProviderFromConfig returns nil when single sign-on isn't configured.
+func newOIDCProviderFromConfig(cfg OIDCConfig) *oidcProvider {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &oidcProvider{
+		cfg:           cfg,
+		sessionSecret: newSigningSecret(cfg.SessionSecret),
+		httpClient:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("identity provider returned status %d for %s", resp.StatusCode, endpoint)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// discover returns the provider's endpoints from its discovery document.
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("fetching provider configuration: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(p.cfg.Issuer, "/") {
+		return nil, fmt.Errorf("provider configuration is for issuer %q", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("provider configuration is missing endpoints")
+	}
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// key returns the provider's signing key kid, refetching the keys when it is
+// unknown, as providers rotate them. A token without kid may use the only key.
+func (p *oidcProvider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysFetched) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, discovery.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("fetching signing keys: %w", err)
+	}
+	p.keys = make(map[string]*rsa.PublicKey)
+	p.keysFetched = time.Now()
+	for _, jwk := range set.Keys {
+		if key, ok := jwk.rsaKey(); ok {
+			p.keys[jwk.Kid] = key
+		}
+	}
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a cached key. The caller must hold p.mu.
+func (p *oidcProvider) lookupKey(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (k jsonWebKey) rsaKey() (*rsa.PublicKey, bool) {
+	if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+		return nil, false
+	}
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, false
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, false
+	}
+	exponent := 0
+	for _, b := range e {
+		exponent = exponent<<8 | int(b)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, true
+}
+
+// authURL is where the browser goes to log in.
+func (p *oidcProvider) authURL(ctx context.Context, login loginState) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(login.Verifier))
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", login.State)
+	query.Set("nonce", login.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// exchange trades an authorization code for the user's ID token.
+func (p *oidcProvider) exchange(ctx context.Context, code, verifier string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+	var body struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("decoding token response: %w", err)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+	return body.IDToken, nil
+}
+
+// verifyIDToken checks an ID token's signature, issuer, audience, expiry and
+// nonce, and returns its claims.
+func (p *oidcProvider) verifyIDToken(ctx context.Context, raw, nonce string, now time.Time) (map[string]interface{}, error) {
+	parsed, err := parseJWT(raw)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.key(ctx, parsed.header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := parsed.verifyRS256(key); err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(parsed.payload, &claims); err != nil {
+		return nil, errInvalidToken
+	}
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if issuer, _ := claims["iss"].(string); issuer != discovery.Issuer {
+		return nil, fmt.Errorf("ID token issued by %q", issuer)
+	}
+	if !audienceContains(claims["aud"], p.cfg.ClientID) {
+		return nil, errors.New("ID token is for another client")
+	}
+	if exp, _ := claims["exp"].(float64); now.Add(-idTokenLeeway).Unix() >= int64(exp) {
+		return nil, errors.New("ID token expired")
+	}
+	if got, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(got), []byte(nonce)) != 1 {
+		return nil, errors.New("ID token nonce mismatch")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+	return claims, nil
+}
+
+func audienceContains(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		return slices.Contains(aud, interface{}(clientID))
+	}
+	return false
+}
+
+// newSession maps ID token claims to a session: the name from the name
+// claim, falling back to the username and email, and the team from the team
+// claim, which may list several teams of which the first valid one is used.
+func (p *oidcProvider) newSession(claims map[string]interface{}, now time.Time) sessionClaims {
+	session := sessionClaims{
+		Audience:  sessionAudience,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(p.cfg.SessionTTL).Unix(),
+	}
+	session.Subject, _ = claims["sub"].(string)
+	for _, claim := range []string{p.cfg.NameClaim, "preferred_username", "email"} {
+		if name, _ := claims[claim].(string); cleanName(name) != "" {
+			// Longer names from the provider are cut off
+			session.Name = roompkg.TruncateRunes(cleanName(name), maxNameLength)
+			break
+		}
+	}
+	switch team := claims[p.cfg.TeamClaim].(type) {
+	case string:
+		if teamIDPattern.MatchString(team) {
+			session.TeamID = team
+		}
+	case []interface{}:
+		for _, value := range team {
+			if team, ok := value.(string); ok && teamIDPattern.MatchString(team) {
+				session.TeamID = team
+				break
+			}
+		}
+	}
+	return session
+}
+
+// errSessionExpired is returned for a session token this server signed that
+// has run out.
+var errSessionExpired = errors.New("session expired")
+
+// verifySession checks a session token and returns its claims.
+func (p *oidcProvider) verifySession(token string, now time.Time) (sessionClaims, error) {
+	var claims sessionClaims
+	if err := verifyHS256(token, p.sessionSecret, &claims); err != nil {
+		return sessionClaims{}, err
+	}
+	if claims.Audience != sessionAudience || claims.Subject == "" {
+		return sessionClaims{}, errInvalidToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return sessionClaims{}, errSessionExpired
+	}
+	return claims, nil
+}
+
+// secureCookies reports whether cookies should be limited to HTTPS, as the
+// server is reached over it.
+func (p *oidcProvider) secureCookies() bool {
+	return strings.HasPrefix(p.cfg.RedirectURL, "https://")
+}
+
+// handleLogin starts a login, sending the browser to the provider. The
+// redirect parameter is where it comes back to afterwards: a path on this
+// server or a page on one of the allowed origins.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	login := loginState{
+		Audience:  loginAudience,
+		State:     generateToken(),
+		Nonce:     generateToken(),
+		Verifier:  generateToken() + generateToken(),
+		Return:    s.loginReturnURL(r.URL.Query().Get("redirect")),
+		ExpiresAt: s.clock.Now().Add(loginTTL).Unix(),
+	}
+	authURL, err := s.oidc.authURL(r.Context(), login)
+	if err != nil {
+		s.logger.Printf("❌ Failed to start login: %v", err)
+		http.Error(w, "identity provider unavailable", http.StatusBadGateway)
+		return
+	}
+	cookie, err := signHS256(login, s.oidc.sessionSecret)
+	if err != nil {
+		http.Error(w, "failed to start login", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     loginCookieName,
+		Value:    cookie,
+		Path:     "/auth/",
+		MaxAge:   int(loginTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   s.oidc.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// handleAuthCallback finishes a login: it checks that the browser started
+// it, exchanges the code for an ID token, and issues a session token.
+func (s *Server) handleAuthCallback(w http.ResponseWriter, r *http.Request) {
+	now := s.clock.Now()
+	var login loginState
+	cookie, err := r.Cookie(loginCookieName)
+	if err != nil || verifyHS256(cookie.Value, s.oidc.sessionSecret, &login) != nil ||
+		login.Audience != loginAudience || now.Unix() >= login.ExpiresAt {
+		http.Error(w, "login expired, please try again", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: loginCookieName, Path: "/auth/", MaxAge: -1})
+
+	query := r.URL.Query()
+	if reason := query.Get("error"); reason != "" {
+		s.logger.Printf("⛔ Login refused by identity provider: %s", reason)
+		http.Error(w, "login failed", http.StatusUnauthorized)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(login.State)) != 1 {
+		http.Error(w, "login state mismatch", http.StatusBadRequest)
+		return
+	}
+
+	idToken, err := s.oidc.exchange(r.Context(), query.Get("code"), login.Verifier)
+	if err != nil {
+		s.logger.Printf("❌ Failed to exchange login code: %v", err)
+		http.Error(w, "identity provider unavailable", http.StatusBadGateway)
+		return
+	}
+	claims, err := s.oidc.verifyIDToken(r.Context(), idToken, login.Nonce, now)
+	if err != nil {
+		s.logger.Printf("⛔ Rejected ID token: %v", err)
+		http.Error(w, "login failed", http.StatusUnauthorized)
+		return
+	}
+
+	session := s.oidc.newSession(claims, now)
+	token, err := signHS256(session, s.oidc.sessionSecret)
+	if err != nil {
+		http.Error(w, "failed to issue session", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(s.oidc.cfg.SessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   s.oidc.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	s.logger.Printf("🔑 %s signed in as %q (team %q)", session.Subject, session.Name, session.TeamID)
+	http.Redirect(w, r, login.Return+"#session="+url.QueryEscape(token), http.StatusFound)
+}
+
+// loginReturnURL returns where a login may send the browser back to, falling
+// back to the root so the login can't be used as an open redirect.
+func (s *Server) loginReturnURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" || strings.Contains(raw, `\`) || u.User != nil {
+		return "/"
+	}
+	u.Fragment = ""
+	if u.Scheme == "" && u.Host == "" && strings.HasPrefix(u.Path, "/") {
+		return u.String()
+	}
+	if (u.Scheme == "http" || u.Scheme == "https") && s.originAllowed(u.Scheme+"://"+u.Host) {
+		return u.String()
+	}
+	s.logger.Printf("⚠️ Ignoring login redirect to %q", raw)
+	return "/"
+}
@@ -0,0 +1,260 @@
+package pokerserver
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeIdP is an OpenID provider issuing ID tokens for whoever logs in.
+type fakeIdP struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]interface{}
+
+	mu        sync.Mutex
+	challenge string
+	nonce     string
+}
+
+func newFakeIdP(t *testing.T, claims map[string]interface{}) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	idp := &fakeIdP{key: key, claims: claims}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		idp.mu.Lock()
+		challenge, nonce := idp.challenge, idp.nonce
+		idp.mu.Unlock()
+
+		verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if id, secret, _ := r.BasicAuth(); id != "poker" || secret != "client-secret" ||
+			r.FormValue("code") != "good-code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != challenge {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		claims := map[string]interface{}{"iss": idp.URL, "aud": "poker", "exp": time.Now().Add(time.Hour).Unix(), "nonce": nonce}
+		for name, value := range idp.claims {
+			claims[name] = value
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"id_token": idp.sign(t, claims)})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func (idp *fakeIdP) sign(t *testing.T, claims map[string]interface{}) string {
+	header, _ := json.Marshal(jwtHeader{Alg: "RS256", Kid: "key-1", Typ: "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign ID token: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newSSOServer(idp *fakeIdP, required bool) *Server {
+	cfg := DefaultConfig()
+	cfg.AllowedOrigins = []string{"http://localhost:3000"}
+	cfg.OIDC = OIDCConfig{
+		Issuer:       idp.URL,
+		ClientID:     "poker",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://poker.test/auth/callback",
+		Scopes:       []string{"openid", "profile"},
+		NameClaim:    "name",
+		TeamClaim:    "groups",
+		Required:     required,
+		SessionTTL:   time.Hour,
+	}
+	return New(WithConfig(cfg))
+}
+
+// login goes through the login flow, returning the callback's response.
+func login(t *testing.T, idp *fakeIdP, handler http.Handler, redirect string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/login?redirect="+url.QueryEscape(redirect), nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("Expected a redirect to the provider, got %d: %s", rec.Code, rec.Body.String())
+	}
+	authURL, _ := url.Parse(rec.Header().Get("Location"))
+	query := authURL.Query()
+	if authURL.Path != "/authorize" || query.Get("client_id") != "poker" || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("Unexpected authorization URL %s", authURL)
+	}
+	idp.mu.Lock()
+	idp.challenge, idp.nonce = query.Get("code_challenge"), query.Get("nonce")
+	idp.mu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/callback?code=good-code&state="+query.Get("state"), nil)
+	for _, cookie := range rec.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSingleSignOn(t *testing.T) {
+	idp := newFakeIdP(t, map[string]interface{}{"sub": "user-1", "name": "Ada Lovelace", "groups": []interface{}{"Not a team", "payments"}})
+	server := newSSOServer(idp, false)
+	handler := server.Handler()
+
+	rec := login(t, idp, handler, "http://localhost:3000/room/abc")
+	returnURL, session, _ := strings.Cut(rec.Header().Get("Location"), "#session=")
+	if rec.Code != http.StatusFound || returnURL != "http://localhost:3000/room/abc" || session == "" {
+		t.Fatalf("Expected a redirect back with the session, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	claims, err := server.oidc.verifySession(session, time.Now())
+	if err != nil || claims.Subject != "user-1" || claims.Name != "Ada Lovelace" || claims.TeamID != "payments" {
+		t.Errorf("Expected the ID token's claims in the session, got %+v, %v", claims, err)
+	}
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"?token="+session, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect with the session: %v", err)
+	}
+	defer conn.Close()
+	sendMessage(t, conn, "join-room", map[string]interface{}{"roomId": "sso-room", "name": "Someone else"})
+	readUntilType(t, conn, "room-state")
+
+	room, _ := server.hub.Room("sso-room")
+	room.Mu.RLock()
+	var names []string
+	for _, participant := range room.Participants {
+		names = append(names, participant.Name)
+	}
+	team, scoped := room.TeamID, room.TeamScoped
+	room.Mu.RUnlock()
+	if len(names) != 1 || names[0] != "Ada Lovelace" {
+		t.Errorf("Expected the participant named by the provider, got %v", names)
+	}
+	if team != "payments" || !scoped {
+		t.Errorf("Expected the room scoped to the team claim, got %q (scoped %v)", team, scoped)
+	}
+
+	if _, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"?token=forged", nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected an invalid token to be refused with 401, got %v", err)
+	}
+	// Anonymous clients are still welcome unless sign-on is required
+	anonymous, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Expected an anonymous connection, got %v", err)
+	}
+	anonymous.Close()
+
+	// An expired session leaves the client a guest rather than locking it out
+	expired, _ := signHS256(sessionClaims{
+		Audience:  sessionAudience,
+		Subject:   "user-1",
+		Name:      "Ada Lovelace",
+		ExpiresAt: time.Now().Add(-time.Minute).Unix(),
+	}, server.oidc.sessionSecret)
+	guest, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"?token="+expired, nil)
+	if err != nil {
+		t.Fatalf("Expected an expired session to connect anonymously, got %v", err)
+	}
+	defer guest.Close()
+	sendMessage(t, guest, "join-room", map[string]interface{}{"roomId": "guest-room", "name": "Guest"})
+	readUntilType(t, guest, "room-state")
+	room, _ = server.hub.Room("guest-room")
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	for _, participant := range room.Participants {
+		if participant.Name != "Guest" {
+			t.Errorf("Expected the expired session to be ignored, got %q", participant.Name)
+		}
+	}
+}
+
+func TestSingleSignOnRejections(t *testing.T) {
+	idp := newFakeIdP(t, map[string]interface{}{"sub": "user-1", "preferred_username": "ada"})
+	server := newSSOServer(idp, true)
+	handler := server.Handler()
+
+	rec := login(t, idp, handler, "https://evil.example/phish")
+	if location := rec.Header().Get("Location"); !strings.HasPrefix(location, "/#session=") {
+		t.Errorf("Expected a foreign redirect to fall back to the root, got %q", location)
+	}
+	_, session, _ := strings.Cut(rec.Header().Get("Location"), "#session=")
+	if claims, _ := server.oidc.verifySession(session, time.Now()); claims.Name != "ada" || claims.TeamID != "" {
+		t.Errorf("Expected the username as name and no team, got %+v", claims)
+	}
+	if _, err := server.oidc.verifySession(session, time.Now().Add(2*time.Hour)); err == nil {
+		t.Error("Expected the session to expire")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/callback?code=good-code&state=guess", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a callback without the login cookie to fail, got %d", rec.Code)
+	}
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+	if _, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a connection without a session to be refused, got %v", err)
+	}
+	expired, _ := signHS256(sessionClaims{Audience: sessionAudience, Subject: "user-1", ExpiresAt: time.Now().Add(-time.Minute).Unix()}, server.oidc.sessionSecret)
+	if _, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"?token="+expired, nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected an expired session to be refused, got %v", err)
+	}
+	header := http.Header{"Cookie": {sessionCookieName + "=" + session}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), header)
+	if err != nil {
+		t.Fatalf("Expected the session cookie to be accepted, got %v", err)
+	}
+	conn.Close()
+}
+
+func TestOIDCConfig(t *testing.T) {
+	t.Setenv("OIDC_ISSUER", "not a url")
+	t.Setenv("OIDC_SCOPES", "profile")
+	_, err := LoadConfig("")
+	if err == nil {
+		t.Fatal("Expected an invalid OIDC configuration to fail")
+	}
+	for _, want := range []string{"oidc.issuer: invalid URL", "oidc.client_id: required", "oidc.redirect_url", "oidc.scopes: must include openid"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got: %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package pokerserver
+
+import (
+	"io/fs"
+	"log"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configures a Server created with New.
+type Option func(*Server)
+
+// WithConfig applies a loaded configuration, taking precedence over the
+// environment. Options after it override individual settings.
+func WithConfig(cfg Config) Option {
+	return func(s *Server) {
+		s.applyConfig(cfg)
+	}
+}
+
+// WithLogger sets the logger used for connection and room events.
+// Defaults to the standard library's default logger.
+func WithLogger(logger *log.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithBroker sets the broker used to relay broadcasts between instances,
+// taking precedence over REDIS_URL.
+func WithBroker(broker Broker) Option {
+	return func(s *Server) {
+		s.broker = broker
+	}
+}
+
+// WithRoomOwnership sets how instances sharing the broker agree on which of
+// them holds each room, taking precedence over the Redis leases used when
+// REDIS_URL is set. It has no effect without a broker.
+func WithRoomOwnership(ownership RoomOwnership) Option {
+	return func(s *Server) {
+		s.ownership = ownership
+	}
+}
+
+// WithErrorReporter sets where handler panics, Redis failures and webhook
+// delivery errors are reported, taking precedence over SENTRY_DSN. A reporter
+// that is an io.Closer is closed on shutdown.
+func WithErrorReporter(reporter ErrorReporter) Option {
+	return func(s *Server) {
+		s.errorReporter = reporter
+	}
+}
+
+// WithStorage sets where completed rounds are persisted.
+func WithStorage(storage Storage) Option {
+	return func(s *Server) {
+		s.storage = storage
+	}
+}
+
+// WithSnapshotStore sets where room snapshots are saved periodically and on
+// shutdown, taking precedence over SNAPSHOT_FILE and SNAPSHOT_REDIS_KEY. Rooms
+// in the latest snapshot are restored by Initialize.
+func WithSnapshotStore(store SnapshotStore) Option {
+	return func(s *Server) {
+		s.snapshots = store
+	}
+}
+
+// WithAllowedOrigins sets the origins allowed to open WebSocket connections
+// and call the REST API, taking precedence over ALLOWED_ORIGINS.
+func WithAllowedOrigins(origins ...string) Option {
+	return func(s *Server) {
+		s.allowedOrigins = origins
+	}
+}
+
+// WithAdminToken sets the bearer token required by the /admin endpoints,
+// taking precedence over ADMIN_TOKEN. The admin API is disabled without one.
+func WithAdminToken(token string) Option {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+// WithTeamTokens sets the token of each team, by team ID, taking precedence
+// over TEAM_TOKENS. Clients connecting with a team's token create rooms only
+// the team can join, and the token opens the admin API on those rooms.
+func WithTeamTokens(tokens map[string]string) Option {
+	return func(s *Server) {
+		s.teamTokens = tokens
+	}
+}
+
+// WithSocketIO enables the Socket.IO compatible endpoint under /socket.io/,
+// taking precedence over SOCKETIO_ENABLED.
+func WithSocketIO(enabled bool) Option {
+	return func(s *Server) {
+		s.socketIO = enabled
+	}
+}
+
+// WithInviteSecret sets the key used to sign room invites, taking precedence
+// over INVITE_SECRET. Instances sharing rooms need the same secret.
+func WithInviteSecret(secret []byte) Option {
+	return func(s *Server) {
+		s.inviteSecret = secret
+	}
+}
+
+// WithConnectionLimits caps concurrent WebSocket connections in total and per
+// client IP, taking precedence over MAX_CONNECTIONS and MAX_CONNECTIONS_PER_IP.
+// Zero disables a cap.
+func WithConnectionLimits(total, perIP int) Option {
+	return func(s *Server) {
+		s.connLimiter = newConnectionLimiter(total, perIP)
+	}
+}
+
+// WithTrustProxy sets whether every peer may identify the client with
+// X-Forwarded-For or X-Real-IP, taking precedence over TRUST_PROXY. Enable it
+// only behind a reverse proxy.
+func WithTrustProxy(trust bool) Option {
+	return func(s *Server) {
+		s.trustProxy = trust
+		s.trustedProxies = nil
+	}
+}
+
+// WithTrustedProxies limits forwarding headers to the given proxy addresses
+// or CIDRs, taking precedence over TRUST_PROXY.
+func WithTrustedProxies(proxies ...string) Option {
+	return func(s *Server) {
+		s.trustProxy = false
+		s.trustedProxies, _ = parseIPRanges(proxies)
+	}
+}
+
+// WithIPFilter sets the CIDRs allowed and denied WebSocket connections,
+// taking precedence over IP_ALLOWLIST and IP_DENYLIST. Denials win; an empty
+// allow list admits every address that isn't denied.
+func WithIPFilter(allow, deny []string) Option {
+	return func(s *Server) {
+		s.ipAllow, _ = parseIPRanges(allow)
+		s.ipDeny, _ = parseIPRanges(deny)
+	}
+}
+
+// WithStaticFiles serves the frontend from fsys at the paths the API doesn't
+// use, falling back to index.html for client-side routes.
+func WithStaticFiles(fsys fs.FS) Option {
+	return func(s *Server) {
+		s.static = fsys
+	}
+}
+
+// WithClock sets the clock behind heartbeats, grace periods, deadlines and
+// the timestamps the server records. Tests use a FakeClock to step through
+// them without sleeping.
+func WithClock(clock Clock) Option {
+	return func(s *Server) {
+		s.clock = clock
+	}
+}
+
+// WithTracerProvider sets where message handling spans are recorded.
+// Defaults to the global OpenTelemetry provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(s *Server) {
+		s.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// WithMeterProvider sets where broadcast queue metrics are reported.
+// Defaults to the global OpenTelemetry provider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(s *Server) {
+		s.meter = provider.Meter(tracerName)
+	}
+}
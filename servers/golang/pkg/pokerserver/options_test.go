@@ -0,0 +1,172 @@
+package pokerserver
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeBroker struct {
+	mu        sync.Mutex
+	published []BrokerMessage
+	handle    func(BrokerMessage)
+}
+
+func (b *fakeBroker) Publish(ctx context.Context, msg BrokerMessage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, msg)
+	return nil
+}
+
+func (b *fakeBroker) Subscribe(ctx context.Context, handle func(BrokerMessage)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handle = handle
+	return nil
+}
+
+func (b *fakeBroker) Close() error {
+	return nil
+}
+
+type fakeStorage struct {
+	rounds chan RoundRecord
+}
+
+func (f *fakeStorage) SaveRound(ctx context.Context, session Session, round RoundRecord) error {
+	f.rounds <- round
+	return nil
+}
+
+func TestWithAllowedOrigins(t *testing.T) {
+	server := New(WithAllowedOrigins("https://poker.example.com"))
+	handler := server.Handler()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://poker.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected allowed origin to get 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://poker.example.com" {
+		t.Errorf("Expected CORS header for allowed origin, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected default origin to be rejected when origins are configured, got %d", rec.Code)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	server := New(WithLogger(log.New(&buf, "", 0)))
+
+	if err := server.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	server.Shutdown(context.Background())
+
+	if !strings.Contains(buf.String(), "WebSocket server initialized") {
+		t.Errorf("Expected server logs to go to the injected logger, got %q", buf.String())
+	}
+}
+
+func TestWithBroker(t *testing.T) {
+	broker := &fakeBroker{}
+	server := New(WithBroker(broker))
+	if err := server.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Messages from other instances are delivered to local clients
+	broker.mu.Lock()
+	handle := broker.handle
+	broker.mu.Unlock()
+	if handle == nil {
+		t.Fatal("Expected server to subscribe to the broker")
+	}
+	handle(BrokerMessage{Type: "test-message", RoomID: "test-room", Data: map[string]interface{}{}})
+
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "test-message" {
+		t.Errorf("Expected test-message from broker, got %s", msg.Type)
+	}
+
+	// Emitted messages are published for other instances
+	server.emitToRoom(context.Background(), "test-room", "emitted", nil, "")
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if len(broker.published) != 1 || broker.published[0].Type != "emitted" {
+		t.Errorf("Expected emitted message to be published, got %+v", broker.published)
+	}
+}
+
+func TestWithStorage(t *testing.T) {
+	storage := &fakeStorage{rounds: make(chan RoundRecord, 2)}
+	server := New(WithStorage(storage))
+
+	roomID := playRound(t, server)
+	if roomID == "" {
+		t.Fatal("Expected round to be played")
+	}
+
+	// Saved once on reveal, and again when the final estimate is set
+	for _, wantEstimate := range []string{"", "8"} {
+		select {
+		case round := <-storage.rounds:
+			if round.FinalEstimate != wantEstimate {
+				t.Errorf("Expected saved final estimate %q, got %q", wantEstimate, round.FinalEstimate)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected round to be saved")
+		}
+	}
+}
+
+func TestBrokerSkipsOwnMessages(t *testing.T) {
+	broker := &fakeBroker{}
+	server := New(WithBroker(broker))
+	if err := server.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	broker.mu.Lock()
+	handle := broker.handle
+	broker.mu.Unlock()
+
+	handle(BrokerMessage{Type: "echo", Origin: server.instanceID})
+	handle(BrokerMessage{Type: "announcement", Origin: "other-instance"})
+
+	if msg := readMessage(t, ws, 2*time.Second); msg.Type != "announcement" {
+		t.Errorf("Expected only the remote announcement, got %s", msg.Type)
+	}
+}
@@ -0,0 +1,111 @@
+package pokerserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultOutboundTimeout      = 5 * time.Second
+	defaultOutboundMaxRedirects = 3
+)
+
+// Ranges that aren't private by netip's definition but aren't on the public
+// internet either
+var reservedRanges, _ = parseIPRanges([]string{
+	"100.64.0.0/10",  // carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"64:ff9b::/96",   // NAT64, which would reach IPv4 addresses behind it
+	"64:ff9b:1::/48", // local-use NAT64
+})
+
+// outboundPolicy decides where the requests the server makes on its own,
+// to issue trackers, webhooks and story links, may go. Only public addresses
+// are reached unless their network is allowed, so the server can't be used to
+// probe the network it runs in, however a host name resolves.
+type outboundPolicy struct {
+	allowedNetworks ipRanges
+	timeout         time.Duration
+	maxRedirects    int
+}
+
+func newOutboundPolicy(cfg OutboundConfig) outboundPolicy {
+	allowed, _ := parseIPRanges(cfg.AllowedNetworks)
+	return outboundPolicy{
+		allowedNetworks: allowed,
+		timeout:         cfg.Timeout,
+		maxRedirects:    cfg.MaxRedirects,
+	}
+}
+
+// publicAddress reports whether ip is reachable on the internet, rather than
+// a loopback, private, link-local or otherwise reserved address.
+func publicAddress(ip netip.Addr) bool {
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reservedRanges {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// allows reports whether the policy lets requests reach ip.
+func (p outboundPolicy) allows(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if publicAddress(ip) {
+		return true
+	}
+	for _, prefix := range p.allowedNetworks {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// client returns an HTTP client that keeps to the policy. Addresses are
+// checked as connections are made, after any DNS lookup, and redirects may
+// only lead to other web addresses.
+func (p outboundPolicy) client() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: p.timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !p.allows(addrPort.Addr()) {
+				return fmt.Errorf("address %s is not public", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: p.timeout,
+		// Without a proxy, which would make the connections instead
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: p.timeout,
+			MaxIdleConnsPerHost: 4,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > p.maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", p.maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return errors.New("redirected away from the web")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,99 @@
+package pokerserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// loopbackOutbound lets requests reach the test servers on this machine.
+func loopbackOutbound() outboundPolicy {
+	cfg := DefaultConfig().Outbound
+	cfg.AllowedNetworks = []string{"127.0.0.0/8", "::1"}
+	return newOutboundPolicy(cfg)
+}
+
+func TestPublicAddress(t *testing.T) {
+	for addr, expected := range map[string]bool{
+		"93.184.215.14":        true,
+		"2606:4700::6810:84e5": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"fd00::1":              false,
+		"64:ff9b::a00:1":       false,
+	} {
+		if public := publicAddress(netip.MustParseAddr(addr)); public != expected {
+			t.Errorf("Expected publicAddress(%s) to be %v", addr, expected)
+		}
+	}
+}
+
+func TestOutboundPolicyAllowedNetworks(t *testing.T) {
+	policy := newOutboundPolicy(OutboundConfig{AllowedNetworks: []string{"10.20.0.0/16", "192.168.1.5"}})
+	for addr, expected := range map[string]bool{
+		"10.20.3.4":        true,
+		"::ffff:10.20.3.4": true,
+		"10.21.0.1":        false,
+		"192.168.1.5":      true,
+		"192.168.1.6":      false,
+		"93.184.215.14":    true,
+	} {
+		if allowed := policy.allows(netip.MustParseAddr(addr)); allowed != expected {
+			t.Errorf("Expected allows(%s) to be %v", addr, expected)
+		}
+	}
+}
+
+func TestOutboundClientRefusesInternalAddresses(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected the internal service not to be reached")
+	}))
+	defer internal.Close()
+
+	// A webhook pointed at the server's own network is refused
+	notifier := newWebhookNotifier(WebhooksConfig{URLs: []string{internal.URL}}, newOutboundPolicy(DefaultConfig().Outbound))
+	err := notifier.Notify(context.Background(), Event{Type: EventSessionEnded, RoomID: "room", Data: SessionSummary{}})
+	if err == nil || !strings.Contains(err.Error(), "not public") {
+		t.Errorf("Expected the webhook to be refused, got %v", err)
+	}
+}
+
+func TestOutboundClientLimitsRedirects(t *testing.T) {
+	hops := 0
+	loop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hops++
+		http.Redirect(w, r, "/again", http.StatusFound)
+	}))
+	defer loop.Close()
+
+	client := loopbackOutbound().client()
+	resp, err := client.Get(loop.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected a redirect loop to fail")
+	}
+	if hops != defaultOutboundMaxRedirects+1 {
+		t.Errorf("Expected %d requests, got %d", defaultOutboundMaxRedirects+1, hops)
+	}
+
+}
+
+func TestOutboundClientRefusesRedirectsOffTheWeb(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+	}))
+	defer page.Close()
+
+	resp, err := loopbackOutbound().client().Get(page.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected a redirect to a file to fail")
+	}
+}
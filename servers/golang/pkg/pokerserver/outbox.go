@@ -0,0 +1,198 @@
+package pokerserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// SlowClientPolicy controls what happens to a client that doesn't keep up
+// with its messages, once its send buffer is full.
+type SlowClientPolicy string
+
+const (
+	// SlowClientDrop discards the messages that don't fit in the buffer.
+	SlowClientDrop SlowClientPolicy = "drop"
+	// SlowClientEvict disconnects the client, which gets the full room state
+	// again when it reconnects.
+	SlowClientEvict SlowClientPolicy = "evict"
+)
+
+const (
+	defaultBroadcastWorkers = 32
+	defaultSendBufferSize   = 256
+)
+
+// senderMetrics reports how far the broadcast workers are behind.
+type senderMetrics struct {
+	dropped   metric.Int64Counter
+	evictions metric.Int64Counter
+}
+
+// enqueue hands a message to the broadcast workers, so callers never wait on
+// the client's connection.
+func (s *Server) enqueue(ws *ExtendedWebSocket, message WebSocketMessage) {
+	s.enqueueOutgoing(ws, transport.Outgoing{Message: message})
+}
+
+// enqueueFrame queues a text frame behind the messages already waiting for
+// the client.
+func (s *Server) enqueueFrame(ws *ExtendedWebSocket, frame []byte) {
+	s.enqueueOutgoing(ws, transport.Outgoing{Frame: frame})
+}
+
+// disconnect closes a client's connection with a close frame, after the
+// messages already waiting for it, such as the one explaining why.
+func (s *Server) disconnect(ws *ExtendedWebSocket, code int, reason string) {
+	s.enqueueOutgoing(ws, transport.Outgoing{Close: &transport.CloseReason{Code: code, Reason: reason}})
+}
+
+func (s *Server) enqueueOutgoing(ws *ExtendedWebSocket, item transport.Outgoing) {
+	s.sendersOnce.Do(s.startSenders)
+
+	ws.Outbox.Mu.Lock()
+	if ws.Outbox.Closed {
+		ws.Outbox.Mu.Unlock()
+		return
+	}
+	if len(ws.Outbox.Queue) >= s.sendBufferSize && item.Close == nil {
+		ws.Outbox.Mu.Unlock()
+		s.handleSlowClient(ws)
+		return
+	}
+	ws.Outbox.Queue = append(ws.Outbox.Queue, item)
+	s.queuedMessages.Add(1)
+	schedule := !ws.Outbox.Scheduled
+	ws.Outbox.Scheduled = true
+	ws.Outbox.Mu.Unlock()
+
+	if schedule {
+		select {
+		case s.sendQueue <- ws:
+		case <-s.ctx.Done():
+		}
+	}
+}
+
+// handleSlowClient applies the slow client policy to a client whose buffer is
+// full.
+func (s *Server) handleSlowClient(ws *ExtendedWebSocket) {
+	policy := attribute.String("policy", string(s.slowClientPolicy))
+	s.senderMetrics.dropped.Add(s.ctx, 1, metric.WithAttributes(policy))
+
+	// Clients of other instances are evicted there
+	if s.slowClientPolicy != SlowClientEvict || ws.Conn == nil {
+		s.logger.Printf("⚠️ Send buffer full, dropping message for client %s", ws.ID)
+		return
+	}
+
+	s.logger.Printf("⚠️ Send buffer full, evicting client %s", ws.ID)
+	s.senderMetrics.evictions.Add(s.ctx, 1)
+	s.closeOutbox(ws)
+	ws.CloseWith(transport.CloseSlowClient, "too slow to keep up")
+}
+
+// closeOutbox discards the messages still waiting for a client that is gone.
+func (s *Server) closeOutbox(ws *ExtendedWebSocket) {
+	ws.Outbox.Mu.Lock()
+	defer ws.Outbox.Mu.Unlock()
+
+	s.queuedMessages.Add(-int64(len(ws.Outbox.Queue)))
+	ws.Outbox.Queue = nil
+	ws.Outbox.Closed = true
+}
+
+// startSenders starts the broadcast workers and their metrics. They stop with
+// the server.
+func (s *Server) startSenders() {
+	s.sendQueue = make(chan *ExtendedWebSocket, s.broadcastWorkers)
+
+	var (
+		queueDepth, pendingClients metric.Int64ObservableGauge
+		errs                       [4]error
+	)
+	s.senderMetrics.dropped, errs[0] = s.meter.Int64Counter("poker.send.dropped",
+		metric.WithDescription("Messages dropped because a client's send buffer was full"),
+	)
+	s.senderMetrics.evictions, errs[1] = s.meter.Int64Counter("poker.send.evictions",
+		metric.WithDescription("Clients disconnected for not keeping up with their messages"),
+	)
+	queueDepth, errs[2] = s.meter.Int64ObservableGauge("poker.send.queue.depth",
+		metric.WithDescription("Messages waiting in client send buffers"),
+	)
+	pendingClients, errs[3] = s.meter.Int64ObservableGauge("poker.send.queue.clients",
+		metric.WithDescription("Clients waiting for a broadcast worker"),
+	)
+	if err := errors.Join(errs[:]...); err != nil {
+		s.logger.Printf("Error creating broadcast metrics: %v", err)
+	}
+	registration, err := s.meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		o.ObserveInt64(queueDepth, s.queuedMessages.Load())
+		o.ObserveInt64(pendingClients, int64(len(s.sendQueue)))
+		return nil
+	}, queueDepth, pendingClients)
+	if err != nil {
+		s.logger.Printf("Error registering broadcast metrics: %v", err)
+	}
+
+	for i := 0; i < s.broadcastWorkers; i++ {
+		go s.sendWorker()
+	}
+	go func() {
+		<-s.ctx.Done()
+		if registration != nil {
+			registration.Unregister()
+		}
+	}()
+}
+
+func (s *Server) sendWorker() {
+	for {
+		select {
+		case ws := <-s.sendQueue:
+			s.drainOutbox(ws)
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// drainOutbox writes a client's messages until its outbox is empty.
+func (s *Server) drainOutbox(ws *ExtendedWebSocket) {
+	for {
+		ws.Outbox.Mu.Lock()
+		batch := ws.Outbox.Queue
+		ws.Outbox.Queue = nil
+		if len(batch) == 0 {
+			ws.Outbox.Scheduled = false
+			ws.Outbox.Mu.Unlock()
+			return
+		}
+		ws.Outbox.Mu.Unlock()
+		s.queuedMessages.Add(-int64(len(batch)))
+
+		for _, item := range batch {
+			if ws.Relay == nil && (ws.Conn == nil || ws.Conn.UnderlyingConn() == nil) {
+				continue
+			}
+			if item.Close != nil {
+				s.closeOutbox(ws)
+				ws.CloseWith(item.Close.Code, item.Close.Reason)
+				return
+			}
+			var err error
+			if item.Frame != nil {
+				err = ws.WriteMessage(websocket.TextMessage, item.Frame)
+			} else {
+				err = ws.Send(item.Message)
+			}
+			if err != nil {
+				s.logger.Printf("Error sending message to client %s: %v", ws.ID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package pokerserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// stalledClient is a client of another instance whose deliveries wait until
+// release is closed.
+type stalledClient struct {
+	*ExtendedWebSocket
+	started   chan struct{}
+	release   chan struct{}
+	delivered chan string
+}
+
+func newStalledClient() *stalledClient {
+	c := &stalledClient{
+		ExtendedWebSocket: &ExtendedWebSocket{ID: "stalled-client"},
+		started:           make(chan struct{}, 1),
+		release:           make(chan struct{}),
+		delivered:         make(chan string, 16),
+	}
+	c.Relay = func(message WebSocketMessage) error {
+		select {
+		case c.started <- struct{}{}:
+		default:
+		}
+		<-c.release
+		c.delivered <- message.Type
+		return nil
+	}
+	return c
+}
+
+func TestSlowClientDropsWhenBufferFull(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SendBufferSize = 2
+	cfg.SlowClientPolicy = SlowClientDrop
+	server := New(WithConfig(cfg))
+	defer server.Shutdown(context.Background())
+
+	client := newStalledClient()
+	server.sendToClient(client.ExtendedWebSocket, "first", nil)
+	<-client.started
+	for _, msgType := range []string{"second", "third", "dropped"} {
+		server.sendToClient(client.ExtendedWebSocket, msgType, nil)
+	}
+	close(client.release)
+
+	for _, expected := range []string{"first", "second", "third"} {
+		select {
+		case msgType := <-client.delivered:
+			if msgType != expected {
+				t.Errorf("Expected %s, got %s", expected, msgType)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected %s to be delivered", expected)
+		}
+	}
+	select {
+	case msgType := <-client.delivered:
+		t.Errorf("Expected the message over the buffer to be dropped, got %s", msgType)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSlowClientEvictedWhenBufferFull(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SendBufferSize = 1
+	server := New(WithConfig(cfg))
+	defer server.Shutdown(context.Background())
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": "slow-room", "name": "Alice"})
+	readMessage(t, ws, time.Second)
+
+	// Stall the connection's writes as a client that stopped reading would
+	clients := server.hub.Clients()
+	if len(clients) != 1 {
+		t.Fatalf("Expected one client, got %d", len(clients))
+	}
+	client := clients[0]
+	client.WriteMu.Lock()
+	for i := 0; i < 3; i++ {
+		server.sendToClient(client, "filler", nil)
+	}
+	client.WriteMu.Unlock()
+
+	// Whatever was written before the eviction, the connection then closes
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := ws.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			t.Fatal("Expected the slow client to be disconnected")
+		}
+		break
+	}
+}
+
+func TestSendQueueDepthMetric(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	server := New(WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	defer server.Shutdown(context.Background())
+
+	client := newStalledClient()
+	defer close(client.release)
+	server.sendToClient(client.ExtendedWebSocket, "first", nil)
+	<-client.started
+	server.sendToClient(client.ExtendedWebSocket, "second", nil)
+	server.sendToClient(client.ExtendedWebSocket, "third", nil)
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != "poker.send.queue.depth" {
+				continue
+			}
+			if gauge := m.Data.(metricdata.Gauge[int64]); gauge.DataPoints[0].Value != 2 {
+				t.Errorf("Expected a queue depth of 2, got %d", gauge.DataPoints[0].Value)
+			}
+			return
+		}
+	}
+	t.Error("Expected a queue depth metric")
+}
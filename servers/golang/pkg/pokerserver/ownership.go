@@ -0,0 +1,363 @@
+package pokerserver
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/hub"
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// RoomOwnership decides which instance holds a room's state when several
+// share a broker. The owner handles every message for the room; the other
+// instances relay their clients' messages to it and its replies back.
+type RoomOwnership = hub.RoomOwnership
+
+// ownershipEnabled reports whether rooms are routed to their owners. The
+// caller must not hold any locks.
+func (s *Server) ownershipEnabled() bool {
+	return s.ownership != nil && s.broker != nil
+}
+
+// roomOwner returns the instance holding roomID, claiming the room when
+// nobody does. A claimed room starts from the state its previous owner handed
+// off, if any.
+func (s *Server) roomOwner(ctx context.Context, roomID string) (string, error) {
+	if _, ok := s.hub.Room(roomID); ok {
+		return s.instanceID, nil
+	}
+
+	owner, err := s.claimRoom(ctx, roomID)
+	if err != nil || owner != s.instanceID {
+		return owner, err
+	}
+
+	state, err := s.ownership.LoadState(ctx, roomID)
+	if err != nil {
+		s.logf(ctx, "Error loading handed-off state for room %s: %v", roomID, err)
+		s.reportError(ctx, ErrorEvent{Err: err, Source: ErrorSourceRedis, RoomID: roomID})
+		return s.instanceID, nil
+	}
+	if state != nil {
+		var snapshot roomSnapshot
+		if err := json.Unmarshal(state, &snapshot); err != nil {
+			s.logf(ctx, "Error decoding handed-off state for room %s: %v", roomID, err)
+		} else if s.restoreRoom(snapshot) {
+			s.logf(ctx, "🤝 Took over room %s", roomID)
+		}
+	}
+	return s.instanceID, nil
+}
+
+// claimRoom takes the lease on roomID unless another instance holds it, and
+// returns the holder either way.
+func (s *Server) claimRoom(ctx context.Context, roomID string) (string, error) {
+	owner, err := s.ownership.Claim(ctx, roomID, s.instanceID, s.leaseTTL)
+	if err != nil || owner != s.instanceID {
+		return owner, err
+	}
+	s.leasesMu.Lock()
+	s.leases[roomID] = true
+	s.leasesMu.Unlock()
+	return owner, nil
+}
+
+// routingMiddleware relays messages for rooms owned by another instance to
+// that instance instead of handling them against a local copy.
+func (s *Server) routingMiddleware(msgType string, next HandlerFunc) HandlerFunc {
+	if roomQueries[msgType] {
+		return next
+	}
+	return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		roomID, _ := data["roomId"].(string)
+		if roomID == "" || ws.Relay != nil || !s.ownershipEnabled() {
+			next(ws, data)
+			return
+		}
+
+		owner, err := s.roomOwner(ws.Context(), roomID)
+		if err != nil {
+			s.logf(ws.Context(), "⚠️ Ownership lookup failed for room %s, handling locally: %v", roomID, err)
+			s.reportError(ws.Context(), ErrorEvent{Err: err, Source: ErrorSourceRedis, RoomID: roomID, ClientID: ws.ID, MessageType: msgType})
+			next(ws, data)
+			return
+		}
+		if owner == s.instanceID {
+			next(ws, data)
+			return
+		}
+
+		if msgType == "join-room" || msgType == "resume" {
+			s.relayedMu.Lock()
+			s.relayed[ws.ID] = roomID
+			s.relayedMu.Unlock()
+		}
+		s.relay(ws.Context(), owner, hub.RelayClientMessage, roomID, ws, &WebSocketMessage{Type: msgType, Data: data})
+	}
+}
+
+// relay sends a local client's message, attachment or disconnect to owner.
+func (s *Server) relay(ctx context.Context, owner, msgType, roomID string, ws *ExtendedWebSocket, message *WebSocketMessage) {
+	err := s.publish(ctx, BrokerMessage{
+		Type:   msgType,
+		RoomID: roomID,
+		Target: owner,
+		Relay:  &hub.RelayMessage{ClientID: ws.ID, RemoteIP: ws.RemoteIP, TeamID: ws.TeamID, UserID: ws.UserID, UserName: ws.UserName, Message: message},
+	})
+	if err != nil {
+		s.logf(ctx, "Error relaying %s for %s to %s: %v", msgType, ws.ID, owner, err)
+		s.reportError(ctx, ErrorEvent{Err: err, Source: ErrorSourceRedis, RoomID: roomID, ClientID: ws.ID})
+	}
+}
+
+// relayDisconnect tells the owner of the room a client was relayed to that
+// the client is gone. It returns the room, or "" if the client wasn't relayed.
+func (s *Server) relayDisconnect(ws *ExtendedWebSocket) string {
+	s.relayedMu.Lock()
+	roomID, ok := s.relayed[ws.ID]
+	delete(s.relayed, ws.ID)
+	s.relayedMu.Unlock()
+	if !ok {
+		return ""
+	}
+
+	owner, err := s.roomOwner(s.ctx, roomID)
+	if err != nil {
+		s.logger.Printf("Error relaying disconnect of %s: %v", ws.ID, err)
+	} else if owner != s.instanceID {
+		s.relay(s.ctx, owner, hub.RelayClientDisconnect, roomID, ws, nil)
+	}
+	return roomID
+}
+
+// handleRelay handles traffic addressed to this instance: messages from
+// clients of rooms it owns, and replies to its own clients.
+func (s *Server) handleRelay(ctx context.Context, msg BrokerMessage) {
+	relayed := msg.Relay
+	if relayed == nil {
+		return
+	}
+
+	switch msg.Type {
+	case hub.RelayClientDeliver:
+		ws := s.hub.Client(relayed.ClientID)
+		if ws != nil && relayed.Message != nil {
+			s.enqueue(ws, *relayed.Message)
+		}
+	case hub.RelayClientClose:
+		ws := s.hub.Client(relayed.ClientID)
+		if ws != nil && ws.Relay == nil && relayed.Close != nil {
+			s.disconnect(ws, relayed.Close.Code, relayed.Close.Reason)
+		}
+	case hub.RelayClientMessage:
+		if relayed.Message != nil {
+			s.handleMessage(s.remoteClient(msg.Origin, relayed), *relayed.Message)
+		}
+	case hub.RelayClientAttach:
+		s.attachClient(ctx, s.remoteClient(msg.Origin, relayed), msg.RoomID)
+	case hub.RelayClientDisconnect:
+		ws := s.hub.Client(relayed.ClientID)
+		if ws != nil && ws.Relay != nil {
+			s.handleClientDisconnect(ws)
+		}
+	}
+}
+
+// remoteClient returns the stand-in for a client connected to instance,
+// creating it on first contact. Messages sent to it are relayed back.
+func (s *Server) remoteClient(instance string, relayed *hub.RelayMessage) *ExtendedWebSocket {
+	return s.hub.ClientOrAdd(relayed.ClientID, func() *ExtendedWebSocket {
+		return s.newRemoteClient(instance, relayed)
+	})
+}
+
+func (s *Server) newRemoteClient(instance string, relayed *hub.RelayMessage) *ExtendedWebSocket {
+	ws := &ExtendedWebSocket{ID: relayed.ClientID, RemoteIP: relayed.RemoteIP, TeamID: relayed.TeamID, UserID: relayed.UserID, UserName: relayed.UserName}
+	ws.IsAlive.Store(true)
+	ws.Relay = func(message WebSocketMessage) error {
+		return s.publish(s.ctx, BrokerMessage{
+			Type:   hub.RelayClientDeliver,
+			Target: instance,
+			Relay:  &hub.RelayMessage{ClientID: ws.ID, Message: &message},
+		})
+	}
+	ws.CloseRelay = func(reason transport.CloseReason) {
+		err := s.publish(s.ctx, BrokerMessage{
+			Type:   hub.RelayClientClose,
+			Target: instance,
+			Relay:  &hub.RelayMessage{ClientID: ws.ID, Close: &reason},
+		})
+		if err != nil {
+			s.logger.Printf("Error relaying close to client %s: %v", ws.ID, err)
+		}
+	}
+	return ws
+}
+
+// attachClient brings a client that was in a handed-off room back online.
+func (s *Server) attachClient(ctx context.Context, ws *ExtendedWebSocket, roomID string) {
+	room, ok := s.hub.Room(roomID)
+	if !ok {
+		return
+	}
+
+	room.Mu.Lock()
+	_, ok = room.Participants[ws.ID]
+	if ok {
+		room.Apply(&roompkg.PresenceEvent{ClientID: ws.ID, Online: true})
+	}
+	room.Mu.Unlock()
+
+	if ok {
+		ws.SetRoomID(roomID)
+		s.broadcastRoomState(ctx, roomID)
+	}
+}
+
+// handOffRooms passes the rooms this instance owns on when it shuts down. Each
+// room's state is saved for whoever claims it next, and instances with
+// clients in it are told to claim it right away.
+func (s *Server) handOffRooms(ctx context.Context) {
+	rooms := s.hub.Rooms()
+
+	for _, room := range rooms {
+		room.Mu.RLock()
+		state, err := json.Marshal(newRoomSnapshot(room))
+		room.Mu.RUnlock()
+		if err != nil {
+			s.logger.Printf("Error encoding room %s for handoff: %v", room.ID, err)
+			continue
+		}
+
+		if err := s.ownership.SaveState(ctx, room.ID, state); err != nil {
+			s.logger.Printf("Error handing off room %s: %v", room.ID, err)
+			continue
+		}
+		if err := s.ownership.Release(ctx, room.ID, s.instanceID); err != nil {
+			s.logger.Printf("Error releasing room %s: %v", room.ID, err)
+		}
+		if err := s.publish(ctx, BrokerMessage{Type: hub.RoomHandoffMessage, RoomID: room.ID}); err != nil {
+			s.logger.Printf("Error announcing handoff of room %s: %v", room.ID, err)
+		}
+	}
+
+	s.leasesMu.Lock()
+	s.leases = make(map[string]bool)
+	s.leasesMu.Unlock()
+	if len(rooms) > 0 {
+		s.logger.Printf("🤝 Handed off %d rooms", len(rooms))
+	}
+}
+
+// handleRoomHandoff claims a room another instance gave up, or points the
+// new owner at this instance's clients in it.
+func (s *Server) handleRoomHandoff(ctx context.Context, roomID string) {
+	var clients []*ExtendedWebSocket
+	s.relayedMu.Lock()
+	for id, relayedRoom := range s.relayed {
+		if ws := s.hub.Client(id); ws != nil && relayedRoom == roomID {
+			clients = append(clients, ws)
+		}
+	}
+	s.relayedMu.Unlock()
+
+	// Without clients here, the room is claimed by the next join instead
+	if len(clients) == 0 {
+		return
+	}
+
+	owner, err := s.roomOwner(ctx, roomID)
+	if err != nil {
+		s.logf(ctx, "Error claiming handed-off room %s: %v", roomID, err)
+		return
+	}
+	if owner != s.instanceID {
+		for _, ws := range clients {
+			s.relay(ctx, owner, hub.RelayClientAttach, roomID, ws, nil)
+		}
+		return
+	}
+
+	for _, ws := range clients {
+		s.attachClient(ctx, ws, roomID)
+	}
+}
+
+// startLeaseRenewal keeps the leases on local rooms alive, claims rooms that
+// were created without one and releases those of rooms that are gone.
+func (s *Server) startLeaseRenewal() {
+	ticker := time.NewTicker(s.leaseTTL / 3)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.renewLeases(s.ctx)
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *Server) renewLeases(ctx context.Context) {
+	rooms := s.hub.Rooms()
+	local := make(map[string]bool, len(rooms))
+	for _, room := range rooms {
+		local[room.ID] = true
+	}
+
+	s.leasesMu.Lock()
+	held := make(map[string]bool, len(s.leases))
+	for roomID := range s.leases {
+		held[roomID] = true
+	}
+	s.leasesMu.Unlock()
+
+	// Talk to the ownership store without holding leasesMu, which joins take
+	setLease := func(roomID string, ok bool) {
+		s.leasesMu.Lock()
+		defer s.leasesMu.Unlock()
+		if ok {
+			s.leases[roomID] = true
+		} else {
+			delete(s.leases, roomID)
+		}
+	}
+
+	for roomID := range local {
+		if !held[roomID] {
+			owner, err := s.ownership.Claim(ctx, roomID, s.instanceID, s.leaseTTL)
+			if err != nil {
+				s.logger.Printf("Error claiming room %s: %v", roomID, err)
+				s.reportError(ctx, ErrorEvent{Err: err, Source: ErrorSourceRedis, RoomID: roomID})
+			} else if owner == s.instanceID {
+				setLease(roomID, true)
+			} else {
+				s.logger.Printf("⚠️ Room %s is also owned by instance %s", roomID, owner)
+			}
+			continue
+		}
+
+		renewed, err := s.ownership.Renew(ctx, roomID, s.instanceID, s.leaseTTL)
+		if err != nil {
+			s.logger.Printf("Error renewing lease on room %s: %v", roomID, err)
+			s.reportError(ctx, ErrorEvent{Err: err, Source: ErrorSourceRedis, RoomID: roomID})
+		} else if !renewed {
+			s.logger.Printf("⚠️ Lost the lease on room %s", roomID)
+			setLease(roomID, false)
+		}
+	}
+
+	for roomID := range held {
+		if local[roomID] {
+			continue
+		}
+		if err := s.ownership.Release(ctx, roomID, s.instanceID); err != nil {
+			s.logger.Printf("Error releasing room %s: %v", roomID, err)
+		}
+		setLease(roomID, false)
+	}
+}
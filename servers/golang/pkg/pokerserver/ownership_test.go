@@ -0,0 +1,209 @@
+package pokerserver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+type memoryOwnership struct {
+	mu     sync.Mutex
+	owners map[string]string
+	states map[string][]byte
+}
+
+func newMemoryOwnership() *memoryOwnership {
+	return &memoryOwnership{owners: make(map[string]string), states: make(map[string][]byte)}
+}
+
+func (o *memoryOwnership) Claim(ctx context.Context, roomID, instanceID string, ttl time.Duration) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if owner, ok := o.owners[roomID]; ok {
+		return owner, nil
+	}
+	o.owners[roomID] = instanceID
+	return instanceID, nil
+}
+
+func (o *memoryOwnership) Renew(ctx context.Context, roomID, instanceID string, ttl time.Duration) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.owners[roomID] == instanceID, nil
+}
+
+func (o *memoryOwnership) Release(ctx context.Context, roomID, instanceID string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.owners[roomID] == instanceID {
+		delete(o.owners, roomID)
+	}
+	return nil
+}
+
+func (o *memoryOwnership) Owner(ctx context.Context, roomID string) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.owners[roomID], nil
+}
+
+func (o *memoryOwnership) SaveState(ctx context.Context, roomID string, state []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.states[roomID] = state
+	return nil
+}
+
+func (o *memoryOwnership) LoadState(ctx context.Context, roomID string) ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	state := o.states[roomID]
+	delete(o.states, roomID)
+	return state, nil
+}
+
+// readRoomStateUntil reads room-state messages until one satisfies match.
+func readRoomStateUntil(t *testing.T, ws *websocket.Conn, match func(participants []interface{}) bool) []interface{} {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		msg := readMessage(t, ws, time.Until(deadline))
+		if msg.Type != "room-state" {
+			continue
+		}
+		participants, _ := msg.Data.(map[string]interface{})["participants"].([]interface{})
+		if match(participants) {
+			return participants
+		}
+	}
+	t.Fatal("Expected a matching room-state")
+	return nil
+}
+
+func readUntilType(t *testing.T, ws *websocket.Conn, msgType string) *WebSocketMessage {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if msg := readMessage(t, ws, time.Until(deadline)); msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func participantNamed(participants []interface{}, name string) map[string]interface{} {
+	for _, p := range participants {
+		if participant := p.(map[string]interface{}); participant["name"] == name {
+			return participant
+		}
+	}
+	return nil
+}
+
+func TestRoomOwnershipRelaysToOwner(t *testing.T) {
+	broker := NewMemoryBroker()
+	ownership := newMemoryOwnership()
+	owner := New(WithBroker(broker), WithRoomOwnership(ownership))
+	other := New(WithBroker(broker), WithRoomOwnership(ownership))
+	for _, server := range []*Server{owner, other} {
+		if err := server.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize server: %v", err)
+		}
+		defer server.Shutdown(context.Background())
+	}
+
+	httpServer1, alice := createTestWSConnection(t, owner)
+	defer httpServer1.Close()
+	defer alice.Close()
+	httpServer2, bob := createTestWSConnection(t, other)
+	defer httpServer2.Close()
+	defer bob.Close()
+
+	sendMessage(t, alice, "join-room", map[string]interface{}{"roomId": "shared-room", "name": "Alice"})
+	readRoomStateUntil(t, alice, func(p []interface{}) bool { return len(p) == 1 })
+
+	// Bob joins through the other instance but ends up in the same room
+	sendMessage(t, bob, "join-room", map[string]interface{}{"roomId": "shared-room", "name": "Bob"})
+	readRoomStateUntil(t, bob, func(p []interface{}) bool { return len(p) == 2 })
+	readRoomStateUntil(t, alice, func(p []interface{}) bool { return len(p) == 2 })
+
+	if _, ok := other.hub.Room("shared-room"); ok {
+		t.Error("Expected no local copy of the room on the other instance")
+	}
+
+	sendMessage(t, bob, "vote", map[string]interface{}{"roomId": "shared-room", "vote": "5"})
+	voted := readUntilType(t, alice, "participant-voted")
+	if voted.Data.(map[string]interface{})["hasVote"] != true {
+		t.Errorf("Expected Bob's relayed vote to reach Alice, got %v", voted.Data)
+	}
+	if relayed := readUntilType(t, bob, "participant-voted"); relayed.Seq == 0 || relayed.Seq != voted.Seq {
+		t.Errorf("Expected Bob to get the owner's seq %d, got %d", voted.Seq, relayed.Seq)
+	}
+
+	// The owner has Bob's own instance close his connection
+	room, _ := owner.hub.Room("shared-room")
+	var bobID string
+	room.Mu.RLock()
+	for id, participant := range room.Participants {
+		if participant.Name == "Bob" {
+			bobID = id
+		}
+	}
+	room.Mu.RUnlock()
+	sendMessage(t, alice, "kick-participant", map[string]interface{}{"roomId": "shared-room", "id": bobID})
+	readUntilType(t, bob, "kicked")
+	expectClose(t, bob, transport.CloseKicked)
+}
+
+func TestRoomOwnershipHandoffOnShutdown(t *testing.T) {
+	broker := NewMemoryBroker()
+	ownership := newMemoryOwnership()
+	owner := New(WithBroker(broker), WithRoomOwnership(ownership))
+	other := New(WithBroker(broker), WithRoomOwnership(ownership))
+	for _, server := range []*Server{owner, other} {
+		if err := server.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize server: %v", err)
+		}
+	}
+	defer other.Shutdown(context.Background())
+
+	httpServer1, alice := createTestWSConnection(t, owner)
+	defer httpServer1.Close()
+	defer alice.Close()
+	httpServer2, bob := createTestWSConnection(t, other)
+	defer httpServer2.Close()
+	defer bob.Close()
+
+	sendMessage(t, alice, "join-room", map[string]interface{}{"roomId": "handoff-room", "name": "Alice"})
+	readRoomStateUntil(t, alice, func(p []interface{}) bool { return len(p) == 1 })
+	sendMessage(t, bob, "join-room", map[string]interface{}{"roomId": "handoff-room", "name": "Bob"})
+	readRoomStateUntil(t, bob, func(p []interface{}) bool { return len(p) == 2 })
+	sendMessage(t, bob, "vote", map[string]interface{}{"roomId": "handoff-room", "vote": "8"})
+	readUntilType(t, bob, "participant-voted")
+
+	owner.Shutdown(context.Background())
+
+	// The other instance takes over, keeping Bob online with his vote
+	participants := readRoomStateUntil(t, bob, func(p []interface{}) bool {
+		bob := participantNamed(p, "Bob")
+		return bob != nil && bob["online"] == true
+	})
+	if alice := participantNamed(participants, "Alice"); alice == nil || alice["online"] != false {
+		t.Errorf("Expected Alice to be kept offline, got %v", alice)
+	}
+
+	room, ok := other.hub.Room("handoff-room")
+	if !ok {
+		t.Fatal("Expected the other instance to own the room")
+	}
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	for _, p := range room.Participants {
+		if p.Name == "Bob" && (p.Vote == nil || *p.Vote != "8") {
+			t.Errorf("Expected Bob's vote to survive the handoff, got %v", p.Vote)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package pokerserver
+
+import (
+	"cmp"
+	"math"
+	"net/http"
+	"slices"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// ParticipantAccuracy compares one participant's votes with the final
+// estimates their team settled on. Deviation is the vote minus the final
+// estimate, in points, so a positive mean deviation is a habit of
+// overestimating.
+type ParticipantAccuracy struct {
+	Name          string  `json:"name"`
+	Votes         int     `json:"votes"`
+	Exact         int     `json:"exact"`
+	Over          int     `json:"over"`
+	Under         int     `json:"under"`
+	MeanDeviation float64 `json:"meanDeviation"`
+	// MeanAbsoluteDeviation is how far off the votes were either way
+	MeanAbsoluteDeviation float64 `json:"meanAbsoluteDeviation"`
+}
+
+// TeamParticipantStats is the accuracy of each participant of a team's
+// recent sessions, by name.
+type TeamParticipantStats struct {
+	TeamID       string                `json:"teamId"`
+	Sessions     int                   `json:"sessions"`
+	Participants []ParticipantAccuracy `json:"participants"`
+}
+
+// handleParticipantStats serves how the votes of each participant of the
+// team's stored sessions compared with the final estimates. Unlike velocity
+// it tells people apart, so the server must be configured to serve it.
+func (s *Server) handleParticipantStats(w http.ResponseWriter, r *http.Request) {
+	if !s.participantStats {
+		http.Error(w, "participant stats are not enabled", http.StatusNotFound)
+		return
+	}
+	teamID := r.PathValue("id")
+	if !teamIDPattern.MatchString(teamID) {
+		http.Error(w, "invalid team ID", http.StatusBadRequest)
+		return
+	}
+
+	sessions, rounds, ok := s.loadTeamSessions(w, r, teamID)
+	if !ok {
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
+	writeJSON(w, http.StatusOK, teamParticipantStats(teamID, sessions, rounds))
+}
+
+// teamParticipantStats compares the votes of every round given a numeric
+// final estimate. Abstentions and votes that aren't a number of points are
+// left out, as are the changed votes of adjusted rounds: it is the vote
+// first revealed that shows how someone estimates.
+func teamParticipantStats(teamID string, sessions []Session, rounds [][]RoundRecord) TeamParticipantStats {
+	type totals struct {
+		ParticipantAccuracy
+		deviation, absolute float64
+	}
+	byName := make(map[string]*totals)
+	for _, sessionRounds := range rounds {
+		for _, round := range sessionRounds {
+			final, ok := roompkg.PointsOf(round.FinalEstimate, round.Points)
+			if !ok || round.Mode != "" {
+				continue
+			}
+			for _, v := range round.Votes {
+				vote := v.Vote
+				if v.OriginalVote != "" {
+					vote = v.OriginalVote
+				}
+				if roompkg.IsAbstention(vote) {
+					continue
+				}
+				points, ok := roompkg.PointsOf(vote, round.Points)
+				if !ok {
+					continue
+				}
+				t := byName[v.Name]
+				if t == nil {
+					t = &totals{ParticipantAccuracy: ParticipantAccuracy{Name: v.Name}}
+					byName[v.Name] = t
+				}
+				t.Votes++
+				deviation := points - final
+				switch {
+				case deviation > 0:
+					t.Over++
+				case deviation < 0:
+					t.Under++
+				default:
+					t.Exact++
+				}
+				t.deviation += deviation
+				t.absolute += math.Abs(deviation)
+			}
+		}
+	}
+
+	stats := TeamParticipantStats{TeamID: teamID, Sessions: len(sessions), Participants: make([]ParticipantAccuracy, 0, len(byName))}
+	for _, t := range byName {
+		accuracy := t.ParticipantAccuracy
+		accuracy.MeanDeviation = math.Round(t.deviation/float64(t.Votes)*100) / 100
+		accuracy.MeanAbsoluteDeviation = math.Round(t.absolute/float64(t.Votes)*100) / 100
+		stats.Participants = append(stats.Participants, accuracy)
+	}
+	slices.SortFunc(stats.Participants, func(a, b ParticipantAccuracy) int { return cmp.Compare(a.Name, b.Name) })
+	return stats
+}
@@ -0,0 +1,63 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestParticipantStats(t *testing.T) {
+	start := time.Now().Add(-48 * time.Hour)
+	storage := &fakeTeamStorage{
+		teamID:   "platform",
+		sessions: []Session{{RoomID: "sprint-1", StartedAt: start, TeamID: "platform"}},
+		rounds: [][]RoundRecord{{
+			{ID: "1", FinalEstimate: "5", Votes: []roompkg.RoundVote{{Name: "Alice", Vote: "8"}, {Name: "Bob", Vote: "5"}}},
+			{ID: "2", FinalEstimate: "3", Votes: []roompkg.RoundVote{{Name: "Alice", Vote: "5"}, {Name: "Bob", Vote: "2"}, {Name: "Carol", Vote: "?"}}},
+			// The vote first revealed counts, not the one changed to
+			{ID: "3", FinalEstimate: "8", Votes: []roompkg.RoundVote{{Name: "Alice", Vote: "8", OriginalVote: "13"}}},
+			// Rounds without a numeric final estimate are left out
+			{ID: "4", FinalEstimate: "?", Votes: []roompkg.RoundVote{{Name: "Bob", Vote: "40"}}},
+			{ID: "5", Votes: []roompkg.RoundVote{{Name: "Bob", Vote: "40"}}},
+		}},
+	}
+
+	if rec := velocityRequest(New(WithStorage(storage)), "/api/teams/platform/participants/stats"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 unless enabled, got %d", rec.Code)
+	}
+
+	cfg := DefaultConfig()
+	cfg.ParticipantStats = true
+	server := New(WithConfig(cfg), WithStorage(storage))
+	rec := velocityRequest(server, "/api/teams/platform/participants/stats")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var stats TeamParticipantStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Failed to decode participant stats: %v", err)
+	}
+	if len(stats.Participants) != 2 {
+		t.Fatalf("Expected Alice and Bob, got %+v", stats.Participants)
+	}
+	alice, bob := stats.Participants[0], stats.Participants[1]
+	if alice.Name != "Alice" || alice.Votes != 3 || alice.Over != 3 || alice.MeanDeviation != 3.33 {
+		t.Errorf("Expected Alice to overestimate by 3.33 points, got %+v", alice)
+	}
+	if bob.Name != "Bob" || bob.Votes != 2 || bob.Exact != 1 || bob.Under != 1 || bob.MeanDeviation != -0.5 || bob.MeanAbsoluteDeviation != 0.5 {
+		t.Errorf("Expected Bob to underestimate by half a point, got %+v", bob)
+	}
+
+	for path, want := range map[string]int{
+		"/api/teams/unknown/participants/stats":               http.StatusNotFound,
+		"/api/teams/bad%20team/participants/stats":            http.StatusBadRequest,
+		"/api/teams/platform/participants/stats?sessions=101": http.StatusBadRequest,
+	} {
+		if rec := velocityRequest(server, path); rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package pokerserver
+
+import (
+	"net/http"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// REST clients present a room passcode in this header or the passcode query
+// parameter, which is easier for links to the printable report.
+const passcodeHeader = "X-Room-Passcode"
+
+func hashPasscode(passcode string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(passcode), bcrypt.DefaultCost)
+}
+
+// checkJoinPasscode rejects the join when the room is private and neither a
+// valid invite nor the right passcode is presented. Hashes are compared
+// without holding the room lock, as bcrypt is deliberately slow.
+func (s *Server) checkJoinPasscode(ws *ExtendedWebSocket, room *roompkg.State, passcode, inviteToken string) bool {
+	hash := room.PasscodeHash()
+	if hash == nil {
+		return true
+	}
+
+	reason := ""
+	if inviteToken != "" && passcode == "" {
+		room.Mu.RLock()
+		createdAt := room.CreatedAt
+		room.Mu.RUnlock()
+
+		switch err := s.verifyInvite(inviteToken, room.ID, createdAt); err {
+		case nil:
+		case errInviteExpired:
+			reason = "invite-expired"
+		default:
+			reason = "invalid-invite"
+		}
+	} else if passcode == "" {
+		reason = "passcode-required"
+	} else if bcrypt.CompareHashAndPassword(hash, []byte(passcode)) != nil {
+		reason = "invalid-passcode"
+	}
+	if reason == "" {
+		return true
+	}
+
+	s.logf(ws.Context(), "⛔ Rejecting join for %s to room %s: %s", ws.ID, room.ID, reason)
+	s.sendToClient(ws, "join-rejected", map[string]interface{}{
+		"roomId": room.ID,
+		"reason": reason,
+	})
+	return false
+}
+
+// authorizeRoomRead enforces a private room's passcode hash on REST reads,
+// writing a 401 when the request doesn't present the passcode.
+func (s *Server) authorizeRoomRead(w http.ResponseWriter, r *http.Request, hash []byte) bool {
+	if hash == nil {
+		return true
+	}
+
+	passcode := r.Header.Get(passcodeHeader)
+	if passcode == "" {
+		passcode = r.URL.Query().Get("passcode")
+	}
+	if passcode == "" || bcrypt.CompareHashAndPassword(hash, []byte(passcode)) != nil {
+		http.Error(w, "room passcode required", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleSetPasscode(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	passcode, _ := data["passcode"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.RLock()
+	isFacilitator := room.FacilitatorID == ws.ID
+	room.Mu.RUnlock()
+	if !isFacilitator {
+		s.logf(ws.Context(), "⚠️ Ignoring set-passcode from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "set-passcode", errCodeNotAllowed, "Only the facilitator can set the passcode", nil)
+		return
+	}
+
+	// An empty passcode makes the room public again
+	var hash []byte
+	if passcode != "" {
+		var err error
+		if hash, err = hashPasscode(passcode); err != nil {
+			s.logf(ws.Context(), "❌ Failed to hash passcode for room %s: %v", roomID, err)
+			s.sendError(ws, "set-passcode", errCodeInvalidInput, "The passcode can't be used", nil)
+			return
+		}
+	}
+
+	room.Mu.Lock()
+	room.Passcode = hash
+	room.Mu.Unlock()
+
+	if hash != nil {
+		s.logf(ws.Context(), "🔒 Room %s made private by %s", roomID, ws.ID)
+	} else {
+		s.logf(ws.Context(), "🔓 Room %s made public by %s", roomID, ws.ID)
+	}
+	s.broadcastRoomState(ws.Context(), roomID)
+}
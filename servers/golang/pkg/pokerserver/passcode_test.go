@@ -0,0 +1,205 @@
+package pokerserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrivateRoomJoin(t *testing.T) {
+	server := New()
+
+	httpServer1, owner := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer owner.Close()
+
+	sendMessage(t, owner, "join-room", map[string]interface{}{
+		"roomId":   "private-room",
+		"name":     "Alice",
+		"passcode": "s3cret",
+	})
+	msg := readMessage(t, owner, 2*time.Second)
+	data := msg.Data.(map[string]interface{})
+	if data["private"] != true {
+		t.Error("Expected room created with a passcode to be private")
+	}
+	if data["facilitatorId"] == "" {
+		t.Error("Expected the room creator to be facilitator")
+	}
+
+	room, _ := server.hub.Room("private-room")
+	if string(room.Passcode) == "s3cret" {
+		t.Fatal("Expected passcode to be stored hashed")
+	}
+
+	httpServer2, guest := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer guest.Close()
+
+	for _, tt := range []struct {
+		passcode string
+		reason   string
+	}{
+		{"", "passcode-required"},
+		{"wrong", "invalid-passcode"},
+	} {
+		sendMessage(t, guest, "join-room", map[string]interface{}{
+			"roomId":   "private-room",
+			"name":     "Bob",
+			"passcode": tt.passcode,
+		})
+		msg := readMessage(t, guest, 2*time.Second)
+		if msg.Type != "join-rejected" {
+			t.Fatalf("Expected join-rejected, got %s", msg.Type)
+		}
+		if reason := msg.Data.(map[string]interface{})["reason"]; reason != tt.reason {
+			t.Errorf("Expected reason %s, got %v", tt.reason, reason)
+		}
+	}
+
+	sendMessage(t, guest, "join-room", map[string]interface{}{
+		"roomId":   "private-room",
+		"name":     "Bob",
+		"passcode": "s3cret",
+	})
+	if msg := readMessage(t, guest, 2*time.Second); msg.Type != "room-state" {
+		t.Errorf("Expected room-state with the right passcode, got %s", msg.Type)
+	}
+}
+
+func TestJoinWithUnhashablePasscode(t *testing.T) {
+	server := New()
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	// bcrypt refuses passcodes over 72 bytes
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId":   "private-room",
+		"name":     "Alice",
+		"passcode": strings.Repeat("x", 73),
+	})
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "join-rejected" {
+		t.Fatalf("Expected join-rejected, got %s", msg.Type)
+	}
+	if reason := msg.Data.(map[string]interface{})["reason"]; reason != "invalid-passcode" {
+		t.Errorf("Expected reason invalid-passcode, got %v", reason)
+	}
+	if _, ok := server.hub.Room("private-room"); ok {
+		t.Error("Expected the refused join not to leave the room behind")
+	}
+}
+
+func TestSetPasscodeRequiresFacilitator(t *testing.T) {
+	server := New()
+
+	httpServer1, owner := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer owner.Close()
+
+	httpServer2, guest := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer guest.Close()
+
+	sendMessage(t, owner, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, owner, 2*time.Second) // room-state
+
+	sendMessage(t, guest, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Bob",
+	})
+	readMessage(t, owner, 2*time.Second) // room-state
+	readMessage(t, guest, 2*time.Second) // room-state
+
+	sendMessage(t, guest, "set-passcode", map[string]interface{}{
+		"roomId":   "test-room",
+		"passcode": "hijack",
+	})
+	if msg := readMessage(t, guest, 2*time.Second); msg.Type != "error" || msg.Data.(map[string]interface{})["code"] != errCodeNotAllowed {
+		t.Fatalf("Expected a guest's passcode to be refused, got %s %v", msg.Type, msg.Data)
+	}
+	sendMessage(t, owner, "set-passcode", map[string]interface{}{
+		"roomId":   "test-room",
+		"passcode": "s3cret",
+	})
+
+	msg := readMessage(t, guest, 2*time.Second)
+	if msg.Type != "room-state" || msg.Data.(map[string]interface{})["private"] != true {
+		t.Fatalf("Expected room to become private, got %s %v", msg.Type, msg.Data)
+	}
+
+	room, _ := server.hub.Room("test-room")
+	handler := server.Handler()
+	for _, tt := range []struct {
+		header string
+		query  string
+		want   int
+	}{
+		{"", "", http.StatusUnauthorized},
+		{"hijack", "", http.StatusUnauthorized},
+		{"s3cret", "", http.StatusOK},
+		{"", "?passcode=s3cret", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/rooms/test-room/export"+tt.query, nil)
+		if tt.header != "" {
+			req.Header.Set(passcodeHeader, tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("header=%q query=%q: expected %d, got %d", tt.header, tt.query, tt.want, rec.Code)
+		}
+	}
+
+	// Clearing the passcode makes the room public again
+	sendMessage(t, owner, "set-passcode", map[string]interface{}{
+		"roomId":   "test-room",
+		"passcode": "",
+	})
+	readMessage(t, owner, 2*time.Second) // room-state (private)
+	readMessage(t, owner, 2*time.Second) // room-state (public)
+	if room.PasscodeHash() != nil {
+		t.Error("Expected empty passcode to clear protection")
+	}
+}
+
+func TestFacilitatorHandover(t *testing.T) {
+	server := New()
+
+	httpServer1, owner := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer owner.Close()
+
+	httpServer2, guest := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer guest.Close()
+
+	sendMessage(t, owner, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, owner, 2*time.Second) // room-state
+
+	sendMessage(t, guest, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Bob",
+	})
+	readMessage(t, guest, 2*time.Second) // room-state
+
+	sendMessage(t, owner, "leave-room", map[string]interface{}{
+		"roomId": "test-room",
+	})
+	msg := readMessage(t, guest, 2*time.Second)
+	participants := msg.Data.(map[string]interface{})["participants"].([]interface{})
+	bobID := participants[0].(map[string]interface{})["id"]
+	if facilitator := msg.Data.(map[string]interface{})["facilitatorId"]; facilitator != bobID {
+		t.Errorf("Expected Bob to take over as facilitator, got %v", facilitator)
+	}
+}
@@ -0,0 +1,58 @@
+package pokerserver
+
+import (
+	"slices"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// parseThreePoint reads the three values of a PERT vote. A message with none
+// of them takes the vote back.
+func parseThreePoint(data map[string]interface{}) (*roompkg.ThreePointEstimate, *inputError) {
+	fields := []string{"optimistic", "mostLikely", "pessimistic"}
+	if !slices.ContainsFunc(fields, func(field string) bool { return data[field] != nil }) {
+		return nil, nil
+	}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, ok := data[field].(float64)
+		if !ok {
+			return nil, &inputError{Field: field, Reason: reasonRequired}
+		}
+		if value < 0 {
+			return nil, &inputError{Field: field, Reason: reasonNegative}
+		}
+		values[i] = value
+	}
+	if values[0] > values[1] {
+		return nil, &inputError{Field: "optimistic", Reason: reasonOutOfOrder}
+	}
+	if values[1] > values[2] {
+		return nil, &inputError{Field: "pessimistic", Reason: reasonOutOfOrder}
+	}
+	estimate := roompkg.NewThreePointEstimate(values[0], values[1], values[2])
+	return &estimate, nil
+}
+
+// votePERT replaces the participant's three-point estimate with the one in
+// the message, or takes it back when the message has no values.
+func (s *Server) votePERT(ws *ExtendedWebSocket, room *roompkg.State, data map[string]interface{}) {
+	estimate, invalid := parseThreePoint(data)
+	if invalid != nil {
+		s.sendInputError(ws, "vote", invalid)
+		return
+	}
+
+	room.Mu.Lock()
+	if _, ok := room.Participants[ws.ID]; !ok || room.Revealed {
+		room.Mu.Unlock()
+		return
+	}
+	room.Apply(&roompkg.ThreePointEvent{ClientID: ws.ID, Estimate: estimate})
+	if estimate != nil {
+		room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditVoted, ActorID: ws.ID})
+	}
+	room.Mu.Unlock()
+
+	s.broadcastToRoom(ws.Context(), room.ID, "participant-voted", map[string]interface{}{"id": ws.ID, "hasVote": estimate != nil})
+}
@@ -0,0 +1,58 @@
+package pokerserver
+
+import (
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestParseThreePoint(t *testing.T) {
+	estimate, invalid := parseThreePoint(map[string]interface{}{"optimistic": 2.0, "mostLikely": 3.0, "pessimistic": 10.0})
+	if invalid != nil || estimate.Expected != 4 || estimate.StdDev != 1.33 {
+		t.Errorf("Expected an expected value of 4 ± 1.33, got %+v %v", estimate, invalid)
+	}
+	if estimate, invalid := parseThreePoint(map[string]interface{}{}); estimate != nil || invalid != nil {
+		t.Errorf("Expected no values to take the vote back, got %+v %v", estimate, invalid)
+	}
+
+	for reason, data := range map[string]map[string]interface{}{
+		reasonRequired:   {"optimistic": 2.0, "pessimistic": 10.0},
+		reasonNegative:   {"optimistic": -1.0, "mostLikely": 3.0, "pessimistic": 10.0},
+		reasonOutOfOrder: {"optimistic": 5.0, "mostLikely": 3.0, "pessimistic": 10.0},
+	} {
+		if _, invalid := parseThreePoint(data); invalid == nil || invalid.Reason != reason {
+			t.Errorf("Expected %v to be refused as %s, got %v", data, reason, invalid)
+		}
+	}
+}
+
+func TestPERTMode(t *testing.T) {
+	server := New()
+	roomID := "pert-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	alice.Send("set-mode", map[string]interface{}{"roomId": roomID, "mode": "pert"})
+
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "optimistic": 1, "mostLikely": 2, "pessimistic": 9})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "optimistic": 3, "mostLikely": 4, "pessimistic": 5})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+
+	pert := awaitMessage(t, alice, "revealed").Data.(map[string]interface{})["pert"].(map[string]interface{})
+	estimate := pert["estimate"].(map[string]interface{})
+	if pert["voteCount"] != float64(2) || estimate["mostLikely"] != float64(3) || estimate["expected"] != float64(3.5) || estimate["stdDev"] != float64(0.83) {
+		t.Errorf("Expected the room's estimate of 3.5 ± 0.83, got %v", pert)
+	}
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	round := room.History[len(room.History)-1]
+	if round.PERT == nil || round.Mode != roompkg.ModePERT || round.Votes[0].ThreePoint == nil {
+		t.Errorf("Expected the round's estimates in the history, got %+v", round)
+	}
+}
@@ -0,0 +1,53 @@
+package pokerserver
+
+import roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+
+// phaseMiddleware rejects messages the room's phase doesn't allow, and reveals
+// with nothing to reveal, answering with an error carrying a code and the
+// phase. Messages for rooms that don't exist yet are left to their handlers.
+func (s *Server) phaseMiddleware(msgType string, next HandlerFunc) HandlerFunc {
+	if roomQueries[msgType] {
+		return next
+	}
+	return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		roomID, _ := data["roomId"].(string)
+		room, exists := s.hub.Room(roomID)
+		if !exists {
+			next(ws, data)
+			return
+		}
+
+		room.Mu.RLock()
+		phase := room.Phase()
+		voted := roompkg.HasVotes(room)
+		waiting, scheduledAt := room.WaitingToStart(), room.Info.ScheduledAt
+		voteChanges := room.TakesVoteChanges()
+		room.Mu.RUnlock()
+
+		allowed := phase != roompkg.PhaseClosed
+		if phases, ok := roompkg.PhaseRules[msgType]; ok && allowed {
+			allowed = false
+			for _, p := range phases {
+				allowed = allowed || p == phase
+			}
+			// Rooms may let revealed votes change
+			allowed = allowed || msgType == "vote" && voteChanges
+		}
+		if waiting && scheduledLobbyRules[msgType] {
+			s.logf(ws.Context(), "⚠️ Rejecting %s from %s in room %s before its scheduled start", msgType, ws.ID, roomID)
+			s.sendError(ws, msgType, errCodeInvalidPhase, "Voting opens at the scheduled start", map[string]interface{}{"phase": phase, "scheduledAt": scheduledAt})
+			return
+		}
+		if !allowed {
+			s.logf(ws.Context(), "⚠️ Rejecting %s from %s in room %s while %s", msgType, ws.ID, roomID, phase)
+			s.sendError(ws, msgType, errCodeInvalidPhase, "Not allowed while the room is "+string(phase), map[string]interface{}{"phase": phase})
+			return
+		}
+		if (msgType == "reveal" || msgType == "request-reveal") && !voted {
+			s.logf(ws.Context(), "⚠️ Rejecting %s from %s in room %s with no votes", msgType, ws.ID, roomID)
+			s.sendError(ws, msgType, errCodeNoVotes, "Nobody has voted yet", map[string]interface{}{"phase": phase})
+			return
+		}
+		next(ws, data)
+	}
+}
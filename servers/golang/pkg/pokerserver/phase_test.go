@@ -0,0 +1,100 @@
+package pokerserver
+
+import (
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestRoomPhases(t *testing.T) {
+	server := New()
+	roomID := "phase-room"
+	alice := server.ConnectMemory()
+	defer alice.Close()
+
+	phase := func() roompkg.Phase {
+		room, _ := server.hub.Room(roomID)
+		room.Mu.RLock()
+		defer room.Mu.RUnlock()
+		return room.Phase()
+	}
+	expectError := func(code string, phase roompkg.Phase) {
+		t.Helper()
+		data := awaitMessage(t, alice, "error").Data.(map[string]interface{})
+		if data["code"] != code || data["phase"] != string(phase) {
+			t.Errorf("Expected a %s error in %s, got %v", code, phase, data)
+		}
+	}
+
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	state := awaitMessage(t, alice, "room-state").Data.(map[string]interface{})
+	if state["phase"] != string(roompkg.PhaseLobby) {
+		t.Errorf("Expected a new room in the lobby, got %v", state["phase"])
+	}
+
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	expectError(errCodeNoVotes, roompkg.PhaseLobby)
+	alice.Send("request-explanation", map[string]interface{}{"roomId": roomID})
+	expectError(errCodeInvalidPhase, roompkg.PhaseLobby)
+
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	if phase() != roompkg.PhaseVoting {
+		t.Errorf("Expected voting once someone voted, got %s", phase())
+	}
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	if phase() != roompkg.PhaseRevealed {
+		t.Errorf("Expected revealed, got %s", phase())
+	}
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	expectError(errCodeInvalidPhase, roompkg.PhaseRevealed)
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	vote := *room.Participants[alice.ID].Vote
+	room.Mu.RUnlock()
+	if vote != "5" {
+		t.Errorf("Expected the vote kept after reveal, got %s", vote)
+	}
+
+	alice.Send("reestimate", map[string]interface{}{"roomId": roomID})
+	if phase() != roompkg.PhaseVoting {
+		t.Errorf("Expected voting again after a reestimate, got %s", phase())
+	}
+
+	alice.Send("end-session", map[string]interface{}{"roomId": roomID})
+	room.Mu.RLock()
+	closed := room.Phase()
+	room.Mu.RUnlock()
+	if closed != roompkg.PhaseClosed {
+		t.Errorf("Expected the ended room closed, got %s", closed)
+	}
+}
+
+func TestDiscussingPhase(t *testing.T) {
+	server := New()
+	roomID := "discuss-room"
+	clients := make([]*MemoryClient, 3)
+	for i, vote := range []string{"1", "3", "20"} {
+		clients[i] = server.ConnectMemory()
+		defer clients[i].Close()
+		clients[i].Send("join-room", map[string]interface{}{"roomId": roomID, "name": "P" + vote})
+		clients[i].Send("vote", map[string]interface{}{"roomId": roomID, "vote": vote})
+	}
+	clients[0].Send("reveal", map[string]interface{}{"roomId": roomID})
+	clients[0].Send("request-explanation", map[string]interface{}{"roomId": roomID})
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	phase := room.Phase()
+	room.Mu.RUnlock()
+	if phase != roompkg.PhaseDiscussing {
+		t.Fatalf("Expected discussing after asking for explanations, got %s", phase)
+	}
+
+	clients[0].Send("reopen-round", map[string]interface{}{"roomId": roomID})
+	room.Mu.RLock()
+	phase = room.Phase()
+	room.Mu.RUnlock()
+	if phase != roompkg.PhaseVoting {
+		t.Errorf("Expected voting after reopening the round, got %s", phase)
+	}
+}
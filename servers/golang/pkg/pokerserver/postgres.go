@@ -0,0 +1,19 @@
+package pokerserver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// NewPostgresStorage connects to the database at dsn (a postgres:// URL or
+// key=value connection string) and creates the schema if needed.
+func NewPostgresStorage(ctx context.Context, dsn string) (*SQLStorage, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening Postgres: %w", err)
+	}
+	return newSQLStorage(ctx, db, nil, "Postgres")
+}
@@ -0,0 +1,123 @@
+package pokerserver
+
+import (
+	"context"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// handleSetRecurrence lets the facilitator make the room recurring, or stop
+// it recurring with an empty schedule.
+func (s *Server) handleSetRecurrence(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	spec, _ := data["schedule"].(string)
+
+	room, exists := s.hub.Room(roomID)
+	if !exists {
+		return
+	}
+
+	if spec != "" {
+		if err := roompkg.CheckRecurrence(spec, s.clock.Now()); err != nil {
+			s.logf(ws.Context(), "⚠️ Invalid schedule %q from %s in room %s: %v", spec, ws.ID, roomID, err)
+			s.sendInputError(ws, "set-recurrence", &inputError{Field: "schedule", Reason: reasonInvalidSchedule})
+			return
+		}
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring set-recurrence from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "set-recurrence", errCodeNotAllowed, "Only the facilitator can schedule the room", nil)
+		return
+	}
+	var recurrence *roompkg.Recurrence
+	if spec != "" {
+		recurrence = &roompkg.Recurrence{Schedule: spec}
+	}
+	s.scheduleRecurrence(room, recurrence)
+	room.Mu.Unlock()
+
+	if spec == "" {
+		s.logf(ws.Context(), "🔁 Room %s no longer recurs", roomID)
+	} else {
+		s.logf(ws.Context(), "🔁 Room %s recurs on %q", roomID, spec)
+	}
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+// scheduleRecurrence makes the room recur as given, or stop recurring when
+// recurrence is nil, and arms the timer for its next occurrence, replacing
+// any armed before. The caller must hold room.Mu.
+func (s *Server) scheduleRecurrence(room *roompkg.State, recurrence *roompkg.Recurrence) {
+	if room.RecurrenceTimer != nil {
+		room.RecurrenceTimer.Stop()
+		room.RecurrenceTimer = nil
+	}
+	if recurrence == nil {
+		if room.Recurrence != nil {
+			room.Apply(&roompkg.RecurrenceEvent{})
+		}
+		return
+	}
+	schedule, err := roompkg.ParseCron(recurrence.Schedule)
+	if err != nil {
+		s.logger.Printf("Error parsing the schedule of room %s: %v", room.ID, err)
+		return
+	}
+	now := s.clock.Now()
+	next, err := schedule.Next(now)
+	if err != nil {
+		s.logger.Printf("Room %s has no further occurrence: %v", room.ID, err)
+		room.Apply(&roompkg.RecurrenceEvent{Recurrence: &roompkg.Recurrence{Schedule: recurrence.Schedule}})
+		return
+	}
+	room.Apply(&roompkg.RecurrenceEvent{Recurrence: &roompkg.Recurrence{Schedule: recurrence.Schedule, NextAt: next.UnixMilli()}})
+	room.RecurrenceTimer = s.clock.AfterFunc(next.Sub(now), func() {
+		s.recur(room, next.UnixMilli())
+	})
+}
+
+// recur starts the room's next occurrence: the session so far is summed up,
+// stored and posted to the webhooks like an ended one, and the room is
+// cleared for a new session, keeping its participants, settings and backlog.
+func (s *Server) recur(room *roompkg.State, at int64) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	live := s.hub.Holds(room)
+	if !live {
+		return
+	}
+
+	// The schedule may have changed in the meantime
+	room.Mu.Lock()
+	if room.Recurrence == nil || room.Recurrence.NextAt != at {
+		room.Mu.Unlock()
+		return
+	}
+	room.RecurrenceTimer = nil
+	now := s.clock.Now()
+	session := room.Session()
+	history := room.History
+	summary := roompkg.SummarizeSession(session, history, now)
+
+	room.Apply(&roompkg.NewSessionEvent{})
+	s.scheduleRecurrence(room, room.Recurrence)
+	nextAt := room.Recurrence.NextAt
+	room.Mu.Unlock()
+
+	ctx := context.Background()
+	if len(history) > 0 {
+		s.saveSummary(session, summary)
+		s.notify(room.ID, EventSessionEnded, summary)
+		s.broadcastToRoom(ctx, room.ID, "session-summary", summary)
+	}
+	s.logger.Printf("🔁 Room %s started its next session after %d round(s)", room.ID, len(history))
+	s.broadcastToRoom(ctx, room.ID, "session-recurred", map[string]interface{}{
+		"startedAt": now.UnixMilli(),
+		"nextAt":    nextAt,
+	})
+	s.broadcastRoomState(ctx, room.ID)
+}
@@ -0,0 +1,52 @@
+package pokerserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurringRoom(t *testing.T) {
+	// A Monday
+	clock := NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	server := New(WithClock(clock))
+	roomID := "weekly-refinement"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+
+	alice.Send("set-recurrence", map[string]interface{}{"roomId": roomID, "schedule": "* * * * *"})
+	if data := awaitMessage(t, alice, "error").Data.(map[string]interface{}); data["reason"] != reasonInvalidSchedule {
+		t.Errorf("Expected a schedule recurring every minute to be refused, got %v", data)
+	}
+	alice.Send("set-recurrence", map[string]interface{}{"roomId": roomID, "schedule": "0 10 * * MON"})
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	awaitMessage(t, alice, "revealed")
+
+	// Everyone leaving keeps the room for its next occurrence
+	alice.Send("leave-room", map[string]interface{}{"roomId": roomID})
+	room, ok := server.hub.Room(roomID)
+	if !ok {
+		t.Fatal("Expected the recurring room to be kept while empty")
+	}
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+
+	clock.Advance(time.Hour)
+	summary := awaitMessage(t, alice, "session-summary").Data.(map[string]interface{})
+	if summary["rounds"] != float64(1) {
+		t.Errorf("Expected the previous session summed up, got %v", summary)
+	}
+	recurred := awaitMessage(t, alice, "session-recurred").Data.(map[string]interface{})
+	nextWeek := time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC).UnixMilli()
+	if recurred["nextAt"] != float64(nextWeek) {
+		t.Errorf("Expected the next occurrence a week later, got %v", recurred)
+	}
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if len(room.History) != 0 || room.LastRound != nil || !room.CreatedAt.Equal(clock.Now()) || room.Recurrence.NextAt != nextWeek {
+		t.Errorf("Expected the room to start a new session, got %d rounds from %v", len(room.History), room.CreatedAt)
+	}
+}
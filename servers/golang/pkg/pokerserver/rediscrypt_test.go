@@ -0,0 +1,20 @@
+package pokerserver
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestRedisEncryptionKeyValidation(t *testing.T) {
+	t.Setenv("REDIS_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString([]byte("too short")))
+	_, err := LoadConfig("")
+	if err == nil {
+		t.Fatal("Expected an invalid encryption key to fail")
+	}
+	for _, want := range []string{"redis_encryption_key: requires redis_url", "redis_encryption_key: must be 32 bytes"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got: %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package pokerserver
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HandlerFunc handles one client message whose data has been decoded into an object.
+type HandlerFunc func(ws *ExtendedWebSocket, data map[string]interface{})
+
+// Middleware wraps message handling with cross-cutting behavior. It is given
+// the message type so it can make per-type decisions.
+type Middleware func(msgType string, next HandlerFunc) HandlerFunc
+
+type messageHandler struct {
+	handle   HandlerFunc
+	required []string
+}
+
+// Default per-connection cap on inbound messages, well above what a person can produce
+const (
+	defaultMessageLimit  = 200
+	defaultMessageWindow = 10 * time.Second
+)
+
+// Handlers slower than this are logged by the logging middleware
+const slowHandlerThreshold = 100 * time.Millisecond
+
+// Handle registers (or replaces) the handler for a message type. Messages
+// missing any of the required string fields are dropped before reaching it.
+func (s *Server) Handle(msgType string, handler HandlerFunc, required ...string) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	s.handlers[msgType] = messageHandler{handle: handler, required: required}
+}
+
+// Use appends middleware to the pipeline. Middleware added later runs closer
+// to the handler.
+func (s *Server) Use(middleware ...Middleware) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	s.middleware = append(s.middleware, middleware...)
+}
+
+func (s *Server) registerDefaultHandlers() {
+	s.Handle("hello", s.handleHello)
+	s.Handle("join-room", s.handleJoinRoom, "roomId")
+	s.Handle("resume", s.handleResume, "roomId", "sessionToken")
+	s.Handle("vote", s.handleVote, "roomId")
+	s.Handle("clear-vote", s.handleClearVote, "roomId")
+	s.Handle("reveal", s.handleReveal, "roomId")
+	s.Handle("request-reveal", s.handleRequestReveal, "roomId")
+	s.Handle("reestimate", s.handleReestimate, "roomId")
+	s.Handle("reopen-round", s.handleReopenRound, "roomId")
+	s.Handle("reset", s.handleReset, "roomId")
+	s.Handle("end-session", s.handleEndSession, "roomId")
+	s.Handle("set-team", s.handleSetTeam, "roomId")
+	s.Handle("import-stories", s.handleImportStories, "roomId")
+	s.Handle("next-story", s.handleNextStory, "roomId")
+	s.Handle("update-story", s.handleUpdateStory, "roomId")
+	s.Handle("set-final-estimate", s.handleSetFinalEstimate, "roomId")
+	s.Handle("set-mode", s.handleSetMode, "roomId", "mode")
+	s.Handle("set-reveal-policy", s.handleSetRevealPolicy, "roomId", "policy")
+	s.Handle("set-vote-changes", s.handleSetVoteChanges, "roomId")
+	s.Handle("set-delphi", s.handleSetDelphi, "roomId")
+	s.Handle("request-explanation", s.handleRequestExplanation, "roomId")
+	s.Handle("open-async-round", s.handleOpenAsyncRound, "roomId")
+	s.Handle("open-batch", s.handleOpenBatch, "roomId")
+	s.Handle("update-name", s.handleUpdateName, "roomId", "name")
+	s.Handle("suspend-voting", s.handleSuspendVoting, "roomId")
+	s.Handle("resume-voting", s.handleResumeVoting, "roomId")
+	s.Handle("raise-hand", s.handleRaiseHand, "roomId")
+	s.Handle("lower-hand", s.handleLowerHand, "roomId")
+	s.Handle("set-status", s.handleSetStatus, "roomId", "status")
+	s.Handle("reaction", s.handleReaction, "roomId", "emoji")
+	s.Handle("selecting", s.handleSelecting, "roomId")
+	s.Handle("chat-message", s.handleChatMessage, "roomId", "text")
+	s.Handle("leave-room", s.handleLeaveRoom, "roomId")
+	s.Handle("sync", s.handleSync, "roomId")
+	s.Handle("set-passcode", s.handleSetPasscode, "roomId", "passcode")
+	s.Handle("kick-participant", s.handleKickParticipant, "roomId", "id")
+	s.Handle("ban-participant", s.handleBanParticipant, "roomId", "id")
+	s.Handle("move-participant", s.handleMoveParticipant, "roomId", "id", "targetRoomId")
+	s.Handle("room-exists", s.handleRoomExists, "roomId")
+	s.Handle("update-room", s.handleUpdateRoom, "roomId")
+	s.Handle("set-recurrence", s.handleSetRecurrence, "roomId", "schedule")
+
+	// Membership, phase and repeats are checked on the instance that owns the room
+	s.Use(s.recoverMiddleware, s.loggingMiddleware, s.rateLimitMiddleware, s.validationMiddleware, s.activityMiddleware, s.routingMiddleware, s.membershipMiddleware, s.phaseMiddleware, s.dedupeMiddleware)
+}
+
+func (s *Server) handleMessage(ws *ExtendedWebSocket, message WebSocketMessage) {
+	ctx, span := s.tracer.Start(s.ctx, "ws.message "+message.Type,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrMessageType.String(message.Type), attrClientID.String(ws.ID)),
+	)
+	defer span.End()
+	data, isObject := message.Data.(map[string]interface{})
+	roomID, _ := data["roomId"].(string)
+	ctx, cancel := messageContext(ctx, ws.ID, roomID)
+	defer cancel()
+	ws.SetContext(ctx)
+	defer ws.SetContext(nil)
+
+	s.handlersMu.RLock()
+	entry, ok := s.handlers[message.Type]
+	middleware := s.middleware
+	s.handlersMu.RUnlock()
+
+	if !ok {
+		span.SetStatus(codes.Error, "unknown message type")
+		s.logf(ctx, "Unknown message type: %s", message.Type)
+		return
+	}
+
+	if !isObject {
+		span.SetStatus(codes.Error, "message data is not an object")
+		return
+	}
+	if roomID != "" {
+		span.SetAttributes(attrRoomID.String(roomID))
+	}
+
+	handler := entry.handle
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](message.Type, handler)
+	}
+	handler(ws, data)
+}
+
+// Codes of the error message a client is sent for a message that wasn't handled
+const (
+	errCodeInternal     = "internal"
+	errCodeNotMember    = "not-member"
+	errCodeInvalidPhase = "invalid-phase"
+	errCodeNoVotes      = "no-votes"
+	errCodeInvalidInput = "invalid-input"
+	errCodeNotAllowed   = "not-allowed"
+)
+
+// sendError tells a client its message wasn't handled, with a code to act on
+// and any details.
+func (s *Server) sendError(ws *ExtendedWebSocket, msgType, code, message string, details map[string]interface{}) {
+	data := map[string]interface{}{
+		"messageType": msgType,
+		"code":        code,
+		"message":     message,
+	}
+	for key, value := range details {
+		data[key] = value
+	}
+	ws.Refused = true
+	if ws.Wants(FeatureTypedErrors) {
+		s.sendToClient(ws, "error", data)
+	}
+	s.firehose.Publish(ws.TeamID, WebSocketMessage{Type: "error", Data: map[string]interface{}{
+		"roomId":   ws.RoomID(),
+		"clientId": ws.ID,
+		"error":    data,
+	}})
+}
+
+// recoverMiddleware keeps a panicking handler from taking down the
+// connection's read loop. The stack is logged and the client is sent an error
+// for the message, so it can tell it wasn't handled.
+func (s *Server) recoverMiddleware(msgType string, next HandlerFunc) HandlerFunc {
+	return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		defer func() {
+			if r := recover(); r != nil {
+				roomID, _ := data["roomId"].(string)
+				trace.SpanFromContext(ws.Context()).SetStatus(codes.Error, "handler panicked")
+				stack := debug.Stack()
+				s.logf(ws.Context(), "❌ Panic handling %s from %s in room %q: %v\n%s", msgType, ws.ID, roomID, r, stack)
+				s.reportError(ws.Context(), ErrorEvent{
+					Err:         fmt.Errorf("panic handling %s: %v", msgType, r),
+					Source:      ErrorSourcePanic,
+					RoomID:      roomID,
+					ClientID:    ws.ID,
+					MessageType: msgType,
+					Stack:       stack,
+				})
+				s.sendError(ws, msgType, errCodeInternal, "The server failed to handle this message", nil)
+			}
+		}()
+		next(ws, data)
+	}
+}
+
+func (s *Server) loggingMiddleware(msgType string, next HandlerFunc) HandlerFunc {
+	return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		start := time.Now()
+		next(ws, data)
+		if elapsed := time.Since(start); elapsed > slowHandlerThreshold {
+			s.logf(ws.Context(), "🐢 Slow handler for %s from %s: %s", msgType, ws.ID, elapsed)
+		}
+	}
+}
+
+func (s *Server) rateLimitMiddleware(msgType string, next HandlerFunc) HandlerFunc {
+	return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		limit, window := s.messageRateLimit()
+		if ws.MessageLimiter == nil {
+			ws.MessageLimiter = transport.NewRateLimiter(limit, window)
+		} else {
+			ws.MessageLimiter.SetLimit(limit, window)
+		}
+		if !ws.MessageLimiter.Allow() {
+			s.logf(ws.Context(), "⚠️ Message rate limit exceeded for %s (%s), dropping %s", ws.ID, ws.RemoteIP, msgType)
+			return
+		}
+		next(ws, data)
+	}
+}
+
+func (s *Server) validationMiddleware(msgType string, next HandlerFunc) HandlerFunc {
+	return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		s.handlersMu.RLock()
+		required := s.handlers[msgType].required
+		s.handlersMu.RUnlock()
+
+		for _, field := range required {
+			if _, ok := data[field].(string); !ok {
+				s.logf(ws.Context(), "❌ Invalid %s in %s event from %s", field, msgType, ws.ID)
+				return
+			}
+		}
+		if field, ok := transport.CheckFields(data, transport.MessageFields[msgType]); !ok {
+			s.logf(ws.Context(), "❌ Invalid %s in %s event from %s", field, msgType, ws.ID)
+			return
+		}
+		next(ws, data)
+	}
+}
+
+// membershipMiddleware drops messages for a room other than the one the
+// client joined, so nobody can act in a room they aren't in. Joining is how a
+// client gets in, and messages naming no room are left to their handlers.
+func (s *Server) membershipMiddleware(msgType string, next HandlerFunc) HandlerFunc {
+	if roomQueries[msgType] {
+		return next
+	}
+	return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		roomID, _ := data["roomId"].(string)
+		if msgType == "join-room" || msgType == "resume" || roomID == "" || ws.RoomID() == roomID {
+			next(ws, data)
+			return
+		}
+		s.logf(ws.Context(), "⚠️ Rejecting %s from %s for room %s, which they haven't joined", msgType, ws.ID, roomID)
+		s.sendError(ws, msgType, errCodeNotMember, "Join the room first", nil)
+	}
+}
+
+// activityMiddleware records when a room last saw a message, for the admin
+// API, and when the sender did, to tell when they are away.
+func (s *Server) activityMiddleware(msgType string, next HandlerFunc) HandlerFunc {
+	if roomQueries[msgType] {
+		return next
+	}
+	return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		next(ws, data)
+
+		roomID, _ := data["roomId"].(string)
+		room, exists := s.hub.Room(roomID)
+
+		if exists {
+			room.Mu.Lock()
+			room.LastActivity = s.clock.Now()
+			room.MessageRate.Add(room.LastActivity)
+			if participant, ok := room.Participants[ws.ID]; ok {
+				participant.LastActiveAt = room.LastActivity
+				s.watchAway(room, participant)
+			}
+			room.Mu.Unlock()
+		}
+	}
+}
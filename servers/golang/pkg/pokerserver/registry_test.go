@@ -0,0 +1,132 @@
+package pokerserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHandleRegistersCustomMessageType(t *testing.T) {
+	server := New()
+	server.Handle("ping-room", func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		server.sendToClient(ws, "pong-room", map[string]interface{}{"roomId": data["roomId"]})
+	}, "roomId")
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": "room-1", "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Missing required field is dropped by validation
+	sendMessage(t, ws, "ping-room", map[string]interface{}{})
+	sendMessage(t, ws, "ping-room", map[string]interface{}{"roomId": "room-1"})
+
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "pong-room" {
+		t.Fatalf("Expected pong-room message, got %s", msg.Type)
+	}
+	if roomID := msg.Data.(map[string]interface{})["roomId"]; roomID != "room-1" {
+		t.Errorf("Expected roomId room-1, got %v", roomID)
+	}
+}
+
+func TestUseAddsMiddleware(t *testing.T) {
+	server := New()
+
+	seen := make(chan string, 1)
+	server.Use(func(msgType string, next HandlerFunc) HandlerFunc {
+		return func(ws *ExtendedWebSocket, data map[string]interface{}) {
+			seen <- msgType
+			next(ws, data)
+		}
+	})
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	select {
+	case msgType := <-seen:
+		if msgType != "join-room" {
+			t.Errorf("Expected middleware to see join-room, got %s", msgType)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected middleware to run")
+	}
+}
+
+func TestRecoverMiddlewareKeepsConnectionAlive(t *testing.T) {
+	server := New()
+	server.Handle("explode", func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		panic("boom")
+	})
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "explode", map[string]interface{}{})
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "error" || msg.Data.(map[string]interface{})["messageType"] != "explode" {
+		t.Errorf("Expected an error for the message that panicked, got %s %v", msg.Type, msg.Data)
+	}
+	msg = readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Errorf("Expected room-state after recovered panic, got %s", msg.Type)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	server := New()
+	calls := 0
+	handler := server.rateLimitMiddleware("vote", func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		calls++
+	})
+
+	ws := &ExtendedWebSocket{ID: "client-1"}
+	for i := 0; i < defaultMessageLimit+5; i++ {
+		handler(ws, map[string]interface{}{})
+	}
+
+	if calls != defaultMessageLimit {
+		t.Errorf("Expected %d calls to pass the rate limit, got %d", defaultMessageLimit, calls)
+	}
+}
+
+func TestMembershipRequiredToAct(t *testing.T) {
+	server := New()
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": "room-a", "name": "Alice"})
+	alice.Send("update-story", map[string]interface{}{"roomId": "room-a", "story": map[string]interface{}{"title": "Login"}})
+	mallory := server.ConnectMemory()
+	defer mallory.Close()
+	mallory.Send("join-room", map[string]interface{}{"roomId": "room-b", "name": "Mallory"})
+
+	for _, msgType := range []string{"reset", "update-story", "kick-participant"} {
+		mallory.Send(msgType, map[string]interface{}{"roomId": "room-a", "id": alice.ID})
+		data := awaitMessage(t, mallory, "error").Data.(map[string]interface{})
+		if data["code"] != errCodeNotMember || data["messageType"] != msgType {
+			t.Errorf("Expected a not-member error for %s, got %v", msgType, data)
+		}
+	}
+
+	room, _ := server.hub.Room("room-a")
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if room.Story == nil || len(room.Participants) != 1 {
+		t.Errorf("Expected room-a untouched, got story %v and %d participants", room.Story, len(room.Participants))
+	}
+}
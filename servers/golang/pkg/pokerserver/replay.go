@@ -0,0 +1,44 @@
+package pokerserver
+
+import roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+
+// Clients that join mid-round get the room's state and recent chat like
+// everyone else, but not how the last reveal went. Each room keeps its recent
+// broadcasts, and joining clients are sent the ones still relevant in a
+// "replay" message.
+
+// roundMessages tell how the round went, so they stop being relevant once
+// the round is no longer revealed
+var roundMessages = map[string]bool{
+	"revealed":       true,
+	"batch-revealed": true,
+	"vote-changed":   true,
+}
+
+// replayTo sends a client that joined the room the recent broadcasts that
+// still matter: the revealed round while the cards are up, the countdown to
+// a scheduled start, the Delphi iteration being voted on and the latest
+// announcement. It follows the room-state the join broadcast.
+func (s *Server) replayTo(ws *ExtendedWebSocket, room *roompkg.State) {
+	if !ws.Wants(FeatureReplay) {
+		return
+	}
+	room.Mu.RLock()
+	revealed := room.Revealed
+	counting := room.StartTimer != nil
+	delphi := room.Delphi != nil && room.Delphi.Iteration > 1 && !room.Revealed
+	room.Mu.RUnlock()
+
+	room.SendMu.Lock()
+	messages := room.Replay.Replay(func(msgType string) bool {
+		return roundMessages[msgType] && !revealed ||
+			msgType == "countdown" && !counting ||
+			msgType == "delphi-round" && !delphi
+	})
+	room.SendMu.Unlock()
+
+	if len(messages) > 0 {
+		s.flushRoomState(ws.Context(), room)
+		s.sendToClient(ws, "replay", map[string]interface{}{"roomId": room.ID, "messages": messages})
+	}
+}
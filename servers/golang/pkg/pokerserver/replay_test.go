@@ -0,0 +1,56 @@
+package pokerserver
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLateJoinersGetRecentBroadcasts(t *testing.T) {
+	server := New()
+	roomID := "replay-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "3"})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	awaitMessage(t, bob, "revealed")
+
+	carol := server.ConnectMemory()
+	defer carol.Close()
+	carol.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Carol"})
+	if types := replayedTypes(t, carol); fmt.Sprint(types) != "[revealed]" {
+		t.Fatalf("Expected the reveal, got %v", types)
+	}
+
+	// The result stops being relevant once the round is reset
+	alice.Send("reset", map[string]interface{}{"roomId": roomID})
+	dave := server.ConnectMemory()
+	defer dave.Close()
+	dave.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Dave"})
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	for msg := range dave.Messages() {
+		if msg.Type == "replay" {
+			t.Errorf("Expected nothing to replay after the reset, got %v", msg.Data)
+		}
+		if msg.Type == "participant-voted" {
+			break
+		}
+	}
+}
+
+// replayedTypes waits for the client's replay and lists the types of the
+// broadcasts in it.
+func replayedTypes(t *testing.T, c *MemoryClient) []string {
+	t.Helper()
+	var types []string
+	for _, message := range awaitMessage(t, c, "replay").Data.(map[string]interface{})["messages"].([]interface{}) {
+		types = append(types, message.(map[string]interface{})["type"].(string))
+	}
+	return types
+}
@@ -0,0 +1,212 @@
+package pokerserver
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"stat": formatStat,
+	"time": func(ms int64) string {
+		return time.UnixMilli(ms).UTC().Format("2006-01-02 15:04 MST")
+	},
+	"duration": formatDuration,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Planning Poker report – {{.RoomID}}</title>
+<style>
+  body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 52rem; color: #1f2937; }
+  h1 { margin-bottom: 0.25rem; }
+  .meta { color: #6b7280; margin-bottom: 2rem; }
+  section { border-top: 1px solid #e5e7eb; padding: 1rem 0; page-break-inside: avoid; }
+  table { border-collapse: collapse; width: 100%; }
+  td, th { text-align: left; padding: 0.25rem 0.5rem; }
+  .bar { background: #6366f1; height: 0.9rem; border-radius: 2px; }
+  .estimate { font-weight: 600; }
+  @media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Planning Poker session report</h1>
+<div class="meta">
+  Room {{.RoomID}} · started {{time .StartedAt}} · duration {{.Duration}} · {{len .Rounds}} round(s)
+</div>
+
+<h2>Participants</h2>
+<ul>{{range .Participants}}<li>{{.}}</li>{{else}}<li>No participants</li>{{end}}</ul>
+
+<h2>Rounds</h2>
+{{range $round := .Rounds}}
+<section>
+  <h3>{{if .Story}}{{if .Story.Link}}<a href="{{.Story.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}{{else}}{{.Title}}{{end}}</h3>
+  <p>Revealed {{time .RevealedAt}}{{with .DurationMs}} after {{duration .}}{{end}}{{with .SlowestVote}} · last vote by {{.}}{{end}} · <span class="estimate">Final estimate: {{if .FinalEstimate}}{{.FinalEstimate}}{{else}}–{{end}}</span></p>
+  <p>{{if .Dimensions}}{{index .Dimensions 0}} votes{{else}}Votes{{end}}: {{.Stats.VoteCount}}{{if .Stats.Average}} · average {{stat .Stats.Average}} · median {{stat .Stats.Median}} · range {{stat .Stats.Min}}–{{stat .Stats.Max}}{{end}}{{with .Stats.Confidence}} · confidence{{range $level, $count := .}} {{$level}} {{$count}}{{end}}{{end}}{{with .Stats.Agreement}} · agreement {{stat .}}%{{end}}</p>
+  {{with .SecondStats}}<p>{{index $round.Dimensions 1}} votes: {{.VoteCount}}{{if .Average}} · average {{stat .Average}} · median {{stat .Median}} · range {{stat .Min}}–{{stat .Max}}{{end}}</p>{{end}}
+  <table>
+    <tr><th>Vote</th><th>Count</th><th style="width:60%"></th></tr>
+    {{range .Bars}}<tr><td>{{.Value}}</td><td>{{.Count}}</td><td><div class="bar" style="width: {{.Percent}}%"></div></td></tr>{{end}}
+  </table>
+</section>
+{{else}}
+<p>No rounds have been revealed yet.</p>
+{{end}}
+</body>
+</html>
+`))
+
+type reportData struct {
+	RoomID       string
+	StartedAt    int64
+	Duration     string
+	Participants []string
+	Rounds       []reportRound
+}
+
+type reportRound struct {
+	RoundRecord
+	Title string
+	Bars  []reportBar
+	// SlowestVote names who voted last in a timed round, and when
+	SlowestVote string
+}
+
+type reportBar struct {
+	Value   string
+	Count   int
+	Percent int
+}
+
+// handleReport renders a printable HTML summary of the session for
+// stakeholders who weren't in the room.
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("id")
+
+	room, exists := s.hub.Room(roomID)
+
+	var data reportData
+	if exists {
+		if !s.authorizeRoomRead(w, r, room.PasscodeHash()) {
+			return
+		}
+		room.Mu.RLock()
+		present := make([]string, 0, len(room.Participants))
+		for _, p := range room.Participants {
+			present = append(present, p.Name)
+		}
+		data = buildReport(roomID, room.CreatedAt, present, room.History)
+		room.Mu.RUnlock()
+	} else {
+		session, rounds, ok := s.loadStoredSession(r.Context(), roomID)
+		if !ok {
+			http.Error(w, "room not found", http.StatusNotFound)
+			return
+		}
+		if !s.authorizeRoomRead(w, r, session.PasscodeHash) {
+			return
+		}
+		data = buildReport(roomID, session.StartedAt, nil, rounds)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := reportTemplate.Execute(w, data); err != nil {
+		s.logger.Printf("Error rendering report for room %s: %v", roomID, err)
+	}
+}
+
+// buildReport collects everything the template needs from a session's rounds
+// and the names of anyone still in the room.
+func buildReport(roomID string, startedAt time.Time, present []string, history []RoundRecord) reportData {
+	data := reportData{
+		RoomID:    roomID,
+		StartedAt: startedAt.UnixMilli(),
+		Rounds:    make([]reportRound, 0, len(history)),
+	}
+
+	end := time.Now()
+	if n := len(history); n > 0 {
+		end = time.UnixMilli(history[n-1].RevealedAt)
+	}
+	data.Duration = end.Sub(startedAt).Round(time.Minute).String()
+
+	// Everyone who voted in any round, plus whoever is still in the room
+	seen := make(map[string]bool)
+	for _, name := range present {
+		seen[name] = true
+	}
+	for _, round := range history {
+		for _, vote := range round.Votes {
+			seen[vote.Name] = true
+		}
+		data.Rounds = append(data.Rounds, newReportRound(round))
+	}
+	for name := range seen {
+		data.Participants = append(data.Participants, name)
+	}
+	sort.Strings(data.Participants)
+
+	return data
+}
+
+func newReportRound(round RoundRecord) reportRound {
+	title := "Untitled story"
+	if round.Story != nil && round.Story.Title != "" {
+		title = round.Story.Title
+	}
+	slowest := slowestVote(round.Votes)
+
+	// A dot-voting round charts the dots per option instead of the votes
+	if round.Ranking != nil {
+		bars := make([]reportBar, 0, len(round.Ranking))
+		highest := max(round.Ranking[0].Dots, 1)
+		for _, result := range round.Ranking {
+			bars = append(bars, reportBar{Value: result.Title, Count: result.Dots, Percent: result.Dots * 100 / highest})
+		}
+		return reportRound{RoundRecord: round, Title: title, Bars: bars, SlowestVote: slowest}
+	}
+
+	values := make([]string, 0, len(round.Stats.Distribution))
+	highest := 0
+	for value, count := range round.Stats.Distribution {
+		values = append(values, value)
+		if count > highest {
+			highest = count
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return round.Stats.Distribution[values[i]] > round.Stats.Distribution[values[j]] ||
+			(round.Stats.Distribution[values[i]] == round.Stats.Distribution[values[j]] && values[i] < values[j])
+	})
+
+	bars := make([]reportBar, 0, len(values))
+	for _, value := range values {
+		count := round.Stats.Distribution[value]
+		bars = append(bars, reportBar{Value: value, Count: count, Percent: count * 100 / highest})
+	}
+
+	return reportRound{RoundRecord: round, Title: title, Bars: bars, SlowestVote: slowest}
+}
+
+// slowestVote describes the last vote cast in a round, or is empty when the
+// votes weren't timed.
+func slowestVote(votes []roompkg.RoundVote) string {
+	var slowest *roompkg.RoundVote
+	for i := range votes {
+		if votes[i].VoteTimeMs > 0 && (slowest == nil || votes[i].VoteTimeMs > slowest.VoteTimeMs) {
+			slowest = &votes[i]
+		}
+	}
+	if slowest == nil {
+		return ""
+	}
+	return slowest.Name + " after " + formatDuration(slowest.VoteTimeMs)
+}
+
+func formatDuration(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+}
@@ -0,0 +1,54 @@
+package pokerserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestReport(t *testing.T) {
+	server := New()
+	roomID := playRound(t, server)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/rooms/"+roomID+"/report", nil)
+	req.SetPathValue("id", roomID)
+	rec := httptest.NewRecorder()
+	server.handleReport(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Expected HTML content type, got %s", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{"Checkout flow", "Final estimate: 8", "<li>Alice</li>", "1 round(s)"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected report to contain %q", want)
+		}
+	}
+}
+
+func TestReportEscapesStoryTitles(t *testing.T) {
+	server := New()
+	room := server.getOrCreateRoom("room")
+	room.Story = &roompkg.Story{Title: "<script>alert(1)</script>", Link: "javascript:alert(1)"}
+	roompkg.RecordRound(room, "1", []roompkg.Participant{{ID: "1", Name: "Alice", Vote: strPtr("3")}}, room.CreatedAt)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/rooms/room/report", nil)
+	req.SetPathValue("id", "room")
+	rec := httptest.NewRecorder()
+	server.handleReport(rec, req)
+
+	body := rec.Body.String()
+	if strings.Contains(body, "<script>alert(1)</script>") {
+		t.Error("Expected story title to be HTML-escaped")
+	}
+	if strings.Contains(body, `href="javascript:`) {
+		t.Error("Expected unsafe story link to be sanitized")
+	}
+}
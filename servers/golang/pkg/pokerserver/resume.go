@@ -0,0 +1,104 @@
+package pokerserver
+
+import (
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// Reasons a resume fails, after which the client joins the room again
+const (
+	resumeUnknownRoom    = "unknown-room"
+	resumeUnknownSession = "unknown-session"
+	resumeBanned         = "banned"
+	resumeWrongTeam      = "wrong-team"
+)
+
+// handleResume takes a client back into the room it was in before its
+// connection dropped. It presents the session token it was given on joining
+// and the seq of the last room-state it saw; it is sent the room's events
+// since, as far as the log still holds them, before a fresh room-state.
+// Unlike join-room it never creates the room or a new participant.
+func (s *Server) handleResume(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	sessionToken, _ := data["sessionToken"].(string)
+	lastSeq, _ := data["lastSeq"].(float64)
+
+	room, exists := s.hub.Room(roomID)
+	if !exists {
+		s.rejectResume(ws, roomID, resumeUnknownRoom)
+		return
+	}
+
+	room.Mu.Lock()
+	var oldID string
+	var participant *roompkg.Participant
+	for id, p := range room.Participants {
+		if p.SessionToken == sessionToken {
+			oldID, participant = id, p
+			break
+		}
+	}
+	var reason string
+	switch {
+	case participant == nil || room.Closed:
+		reason = resumeUnknownSession
+	case room.Bans.Matches(sessionToken, participant.ParticipantId, s.hashIP(ws.RemoteIP)):
+		reason = resumeBanned
+	case !room.Admits(ws.TeamID):
+		reason = resumeWrongTeam
+	}
+	if reason != "" {
+		room.Mu.Unlock()
+		s.rejectResume(ws, roomID, reason)
+		return
+	}
+
+	if oldID != ws.ID {
+		room.Apply(&roompkg.JoinEvent{ClientID: ws.ID, Name: participant.Name, ParticipantID: participant.ParticipantId, Replaces: oldID})
+		room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditJoined, ActorID: ws.ID})
+		s.recordReconnect(ws.Context(), reconnectResume)
+	}
+	missed, complete := eventsSince(room, int64(lastSeq))
+	seq := room.EventSeq
+	room.Mu.Unlock()
+
+	ws.SetRoomID(roomID)
+	s.logf(ws.Context(), "🔄 Resumed %s in room %s (old ID: %s, %d missed events)", ws.ID, roomID, oldID, len(missed))
+
+	// The token was presented here, so a connection still holding the
+	// participant is a stale one
+	stale := s.hub.Client(oldID)
+	if stale != nil && stale != ws {
+		s.disconnect(stale, transport.CloseResumed, "resumed on another connection")
+	}
+
+	s.sendToClient(ws, "resumed", map[string]interface{}{
+		"roomId":   roomID,
+		"seq":      seq,
+		"events":   missed,
+		"complete": complete,
+	})
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+func (s *Server) rejectResume(ws *ExtendedWebSocket, roomID, reason string) {
+	s.logf(ws.Context(), "⚠️ Can't resume %s in room %s: %s", ws.ID, roomID, reason)
+	s.sendToClient(ws, "resume-failed", map[string]interface{}{
+		"roomId": roomID,
+		"reason": reason,
+	})
+}
+
+// eventsSince returns the room's events after seq, and whether the log still
+// holds all of them. The caller must hold room.Mu.
+func eventsSince(room *roompkg.State, seq int64) ([]roompkg.EventRecord, bool) {
+	missed := []roompkg.EventRecord{}
+	for i := len(room.Events) - 1; i >= 0 && room.Events[i].Seq > seq; i-- {
+		missed = append(missed, room.Events[i])
+	}
+	for i, j := 0, len(missed)-1; i < j; i, j = i+1, j-1 {
+		missed[i], missed[j] = missed[j], missed[i]
+	}
+	complete := seq >= room.EventSeq || len(room.Events) > 0 && room.Events[0].Seq <= seq+1
+	return missed, complete
+}
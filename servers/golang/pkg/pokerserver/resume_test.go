@@ -0,0 +1,109 @@
+package pokerserver
+
+import (
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+func TestResume(t *testing.T) {
+	server := New()
+	roomID := "resume-room"
+
+	alice := server.ConnectMemory()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice", "resumable": true})
+	token := awaitMessage(t, alice, "session").Data.(map[string]interface{})["sessionToken"].(string)
+	lastSeq := awaitMessage(t, alice, "room-state").Data.(map[string]interface{})["seq"].(float64)
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	awaitMessage(t, alice, "participant-voted")
+	alice.Close()
+
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	awaitMessage(t, bob, "room-state")
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+
+	again := server.ConnectMemory()
+	defer again.Close()
+	again.Send("resume", map[string]interface{}{"roomId": roomID, "sessionToken": token, "lastSeq": lastSeq})
+	resumed := awaitMessage(t, again, "resumed").Data.(map[string]interface{})
+	events := resumed["events"].([]interface{})
+	if resumed["complete"] != true || len(events) != 5 {
+		t.Fatalf("Expected the vote, Alice going offline, Bob's join and vote and the rejoin, got %v", resumed)
+	}
+	if first := events[0].(map[string]interface{}); first["type"] != "vote" || first["seq"] != lastSeq+1 {
+		t.Errorf("Expected the missed events in order, got %v", first)
+	}
+
+	state := awaitMessage(t, again, "room-state").Data.(map[string]interface{})
+	if state["facilitatorId"] != again.ID || state["seq"] != resumed["seq"] {
+		t.Errorf("Expected Alice back as facilitator on her new connection, got %v", state)
+	}
+	for _, p := range state["participants"].([]interface{}) {
+		if p := p.(map[string]interface{}); p["name"] == "Alice" && (p["id"] != again.ID || p["vote"] != "5") {
+			t.Errorf("Expected Alice's vote kept, got %v", p)
+		}
+	}
+
+	// A connection that hasn't noticed it dropped is closed
+	third := server.ConnectMemory()
+	defer third.Close()
+	third.Send("resume", map[string]interface{}{"roomId": roomID, "sessionToken": token, "lastSeq": resumed["seq"]})
+	if events := awaitMessage(t, third, "resumed").Data.(map[string]interface{})["events"].([]interface{}); len(events) != 1 {
+		t.Errorf("Expected just the rejoin since the last resume, got %v", events)
+	}
+	// The close frame is queued behind the messages sent to the old connection
+	waitFor(t, func() bool {
+		code, _ := again.CloseReason()
+		return code != 0
+	})
+	if code, _ := again.CloseReason(); code != transport.CloseResumed {
+		t.Errorf("Expected the stale connection closed with %d, got %d", transport.CloseResumed, code)
+	}
+}
+
+func TestResumeFailures(t *testing.T) {
+	server := New()
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": "resume-room", "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+
+	for _, tt := range []struct {
+		roomID string
+		reason string
+	}{
+		{"resume-room", resumeUnknownSession},
+		{"no-such-room", resumeUnknownRoom},
+	} {
+		client := server.ConnectMemory()
+		client.Send("resume", map[string]interface{}{"roomId": tt.roomID, "sessionToken": "guess"})
+		if failed := awaitMessage(t, client, "resume-failed").Data.(map[string]interface{}); failed["reason"] != tt.reason {
+			t.Errorf("Expected %s, got %v", tt.reason, failed)
+		}
+		client.Close()
+	}
+	if _, ok := server.hub.Room("no-such-room"); ok {
+		t.Error("Expected resume not to create a room")
+	}
+}
+
+func TestEventsSince(t *testing.T) {
+	room := &roompkg.State{Participants: make(map[string]*roompkg.Participant)}
+	for i := 0; i < roompkg.MaxEventLog+2; i++ {
+		room.Apply(&roompkg.RenameEvent{ClientID: "nobody", Name: "x"})
+	}
+
+	if missed, complete := eventsSince(room, int64(roompkg.MaxEventLog)); !complete || len(missed) != 2 {
+		t.Errorf("Expected the last two events, got %d (complete %v)", len(missed), complete)
+	}
+	if missed, complete := eventsSince(room, 1); complete || len(missed) != roompkg.MaxEventLog {
+		t.Errorf("Expected the log to no longer reach back, got %d (complete %v)", len(missed), complete)
+	}
+	if missed, complete := eventsSince(room, room.EventSeq); !complete || len(missed) != 0 {
+		t.Errorf("Expected nothing missed, got %d", len(missed))
+	}
+}
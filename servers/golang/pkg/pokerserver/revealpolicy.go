@@ -0,0 +1,86 @@
+package pokerserver
+
+import (
+	"slices"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// handleRequestReveal counts a participant's request to reveal in rooms that
+// reveal by majority, telling the room who asked. The request that tips the
+// count over the threshold reveals the round, or the whole batch.
+func (s *Server) handleRequestReveal(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if room.RevealPolicy.Mode != roompkg.RevealMajorityRequest {
+		policy := room.RevealPolicy
+		room.Mu.Unlock()
+		s.sendError(ws, "request-reveal", errCodeNotAllowed, "This room doesn't reveal by request", map[string]interface{}{"revealPolicy": policy})
+		return
+	}
+	if p, ok := room.Participants[ws.ID]; !ok || p.Paused {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring request-reveal from %s sitting out the round in room %s", ws.ID, roomID)
+		s.sendError(ws, "request-reveal", errCodeNotAllowed, "Participants sitting out the round can't ask for a reveal", nil)
+		return
+	}
+	if !slices.Contains(room.RevealRequests, ws.ID) {
+		room.Apply(&roompkg.RevealRequestEvent{ClientID: ws.ID})
+	}
+	// The reveal that follows starts the requests over
+	requestedBy, needed := roompkg.RevealRequests(room)
+	reveal := len(requestedBy) >= needed
+	batch := len(room.Batch) > 0
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "🙋 Reveal requested by %s in room %s: %d of %d needed", ws.ID, roomID, len(requestedBy), needed)
+	s.broadcastToRoom(ws.Context(), roomID, "reveal-requested", map[string]interface{}{
+		"requestedBy": requestedBy,
+		"needed":      needed,
+		"revealing":   reveal,
+	})
+	if !reveal {
+		return
+	}
+	if batch {
+		s.revealBatch(ws.Context(), room, "", ws.ID)
+	} else {
+		s.revealRound(ws.Context(), room, "", ws.ID)
+	}
+}
+
+// handleSetRevealPolicy lets the facilitator choose who may reveal.
+func (s *Server) handleSetRevealPolicy(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+	if !exists {
+		return
+	}
+
+	policy, err := roompkg.ParseRevealPolicy(data)
+	if err != nil {
+		s.logf(ws.Context(), "⚠️ Ignoring set-reveal-policy from %s in room %s: %v", ws.ID, roomID, err)
+		s.sendError(ws, "set-reveal-policy", errCodeInvalidInput, err.Error(), nil)
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring set-reveal-policy from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "set-reveal-policy", errCodeNotAllowed, "Only the facilitator can set the reveal policy", nil)
+		return
+	}
+	room.Apply(&roompkg.RevealPolicyEvent{Policy: policy})
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "🔓 Room %s reveal policy set to %s by %s", roomID, policy.Mode, ws.ID)
+	s.broadcastRoomState(ws.Context(), roomID)
+}
@@ -0,0 +1,131 @@
+package pokerserver
+
+import (
+	"strings"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestRevealPolicy(t *testing.T) {
+	server := New()
+	roomID := "policy-room"
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	carol := server.ConnectMemory()
+	defer carol.Close()
+
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	awaitMessage(t, bob, "room-state")
+	carol.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Carol"})
+	awaitMessage(t, carol, "room-state")
+
+	expectRefused := func(client *MemoryClient) {
+		t.Helper()
+		data := awaitMessage(t, client, "error").Data.(map[string]interface{})
+		if data["code"] != errCodeNotAllowed || data["messageType"] != "reveal" {
+			t.Errorf("Expected the reveal to be refused, got %v", data)
+		}
+	}
+	revealed := func() bool {
+		room, _ := server.hub.Room(roomID)
+		room.Mu.RLock()
+		defer room.Mu.RUnlock()
+		return room.Revealed
+	}
+
+	// Only the facilitator may change the policy
+	bob.Send("set-reveal-policy", map[string]interface{}{"roomId": roomID, "policy": "anyone"})
+	alice.Send("set-reveal-policy", map[string]interface{}{"roomId": roomID, "policy": "facilitator-only"})
+	for {
+		// Earlier states, from Carol joining, may come first
+		state := awaitMessage(t, bob, "room-state").Data.(map[string]interface{})
+		policy, _ := state["revealPolicy"].(map[string]interface{})
+		if policy["mode"] == string(roompkg.RevealFacilitatorOnly) {
+			break
+		}
+	}
+
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	bob.Send("reveal", map[string]interface{}{"roomId": roomID})
+	expectRefused(bob)
+
+	// Two of three have to vote before anyone reveals
+	alice.Send("set-reveal-policy", map[string]interface{}{"roomId": roomID, "policy": "vote-threshold", "threshold": 0.6})
+	bob.Send("reveal", map[string]interface{}{"roomId": roomID})
+	expectRefused(bob)
+	carol.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	bob.Send("reveal", map[string]interface{}{"roomId": roomID})
+	if !revealed() {
+		t.Error("Expected the reveal once the threshold was reached")
+	}
+
+	// The facilitator may always reveal
+	alice.Send("reset", map[string]interface{}{"roomId": roomID})
+	alice.Send("set-reveal-policy", map[string]interface{}{"roomId": roomID, "policy": "facilitator-only"})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "3"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	if !revealed() {
+		t.Error("Expected the facilitator to reveal under any policy")
+	}
+}
+
+func TestRequestReveal(t *testing.T) {
+	server := New()
+	roomID := "request-room"
+	clients := make([]*MemoryClient, 4)
+	for i, name := range []string{"Alice", "Bob", "Carol", "Dave"} {
+		clients[i] = server.ConnectMemory()
+		defer clients[i].Close()
+		clients[i].Send("join-room", map[string]interface{}{"roomId": roomID, "name": name})
+		awaitMessage(t, clients[i], "room-state")
+	}
+	alice, bob, carol, dave := clients[0], clients[1], clients[2], clients[3]
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+
+	bob.Send("request-reveal", map[string]interface{}{"roomId": roomID})
+	if data := awaitMessage(t, bob, "error").Data.(map[string]interface{}); data["code"] != errCodeNotAllowed {
+		t.Errorf("Expected requests to be refused under the anyone policy, got %v", data)
+	}
+
+	alice.Send("set-reveal-policy", map[string]interface{}{"roomId": roomID, "policy": "majority-request"})
+	bob.Send("reveal", map[string]interface{}{"roomId": roomID})
+	if data := awaitMessage(t, bob, "error").Data.(map[string]interface{}); data["code"] != errCodeNotAllowed {
+		t.Errorf("Expected a direct reveal to be refused, got %v", data)
+	}
+
+	// More than half of four is three
+	bob.Send("request-reveal", map[string]interface{}{"roomId": roomID})
+	bob.Send("request-reveal", map[string]interface{}{"roomId": roomID})
+	carol.Send("request-reveal", map[string]interface{}{"roomId": roomID})
+	for range 3 {
+		requested := awaitMessage(t, dave, "reveal-requested").Data.(map[string]interface{})
+		if requested["revealing"] != false || requested["needed"] != float64(3) {
+			t.Errorf("Expected no reveal before three requests, got %v", requested)
+		}
+	}
+	dave.Send("request-reveal", map[string]interface{}{"roomId": roomID})
+	requested := awaitMessage(t, alice, "reveal-requested").Data.(map[string]interface{})
+	for requested["revealing"] != true {
+		requested = awaitMessage(t, alice, "reveal-requested").Data.(map[string]interface{})
+	}
+	var names []string
+	for _, requester := range requested["requestedBy"].([]interface{}) {
+		names = append(names, requester.(map[string]interface{})["name"].(string))
+	}
+	if strings.Join(names, ",") != "Bob,Carol,Dave" {
+		t.Errorf("Expected who requested the reveal in order, got %v", names)
+	}
+	awaitMessage(t, alice, "revealed")
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if !room.Revealed || room.RevealRequests != nil {
+		t.Errorf("Expected the round revealed and the requests cleared")
+	}
+}
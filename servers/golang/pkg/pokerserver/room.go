@@ -0,0 +1,143 @@
+package pokerserver
+
+import (
+	"strconv"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// DuplicateNamePolicy controls what happens when someone joins a room with a
+// name that is already in use.
+type DuplicateNamePolicy string
+
+const (
+	// DuplicateNameSuffix renames the newcomer (e.g. "Alice 2").
+	DuplicateNameSuffix DuplicateNamePolicy = "suffix"
+	// DuplicateNameReject refuses the join with a join-rejected message.
+	DuplicateNameReject DuplicateNamePolicy = "reject"
+	// DuplicateNameToken only lets a newcomer take over an existing
+	// participant when they present that participant's session token.
+	DuplicateNameToken DuplicateNamePolicy = "token"
+)
+
+func (s *Server) getOrCreateRoom(roomID string) *roompkg.State {
+	return s.hub.RoomOrCreate(roomID, func() *roompkg.State {
+		return s.newRoom(roomID)
+	})
+}
+
+func (s *Server) newRoom(roomID string) *roompkg.State {
+	now := s.clock.Now()
+	room := &roompkg.State{
+		ID:           roomID,
+		Participants: make(map[string]*roompkg.Participant),
+		Revealed:     false,
+		Story:        nil,
+		LastRound:    nil,
+		CreatedAt:    now,
+		LastActivity: now,
+		Settings:     roompkg.Settings{Mode: roompkg.ModePoints},
+		RevealPolicy: roompkg.RevealPolicy{Mode: roompkg.RevealAnyone},
+
+		RoundStartedAt: now,
+		Clock:          s.clock,
+		Firehose:       s.firehose,
+		Spectators:     roompkg.NewFirehose(),
+	}
+	// Details posted with the room's code are its first event
+	if info, ok := s.roomCodes.take(roomID); ok && info != (roompkg.Info{}) {
+		room.Mu.Lock()
+		room.Apply(&roompkg.InfoEvent{Info: info})
+		s.scheduleStart(room)
+		room.Mu.Unlock()
+	}
+	return room
+}
+
+// uniqueName appends a numeric suffix to name until no other participant in
+// the room uses it. Disconnected participants are only considered when
+// includeOffline is set. The caller must hold room.Mu.
+func (s *Server) uniqueName(room *roompkg.State, name string, selfID string, includeOffline bool) string {
+	finalName := name
+	counter := 2
+
+	for {
+		nameExists := false
+		for _, p := range room.Participants {
+			if p.ID == selfID || p.Name != finalName {
+				continue
+			}
+			if includeOffline || s.hub.Client(p.ID) != nil {
+				nameExists = true
+				break
+			}
+		}
+		if !nameExists {
+			return finalName
+		}
+		finalName = name + " " + strconv.Itoa(counter)
+		counter++
+	}
+}
+
+// removeParticipant deletes a participant from a room, dropping the room
+// entirely once nobody is left in it. Returns false if there was nothing to remove.
+func (s *Server) removeParticipant(roomID, clientID string) bool {
+	removed := false
+	s.hub.UpdateRoom(roomID, func(room *roompkg.State) bool {
+		room.Mu.Lock()
+		defer room.Mu.Unlock()
+
+		if _, ok := room.Participants[clientID]; !ok {
+			return false
+		}
+		room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditLeft, ActorID: clientID})
+		room.Apply(&roompkg.LeaveEvent{ClientID: clientID})
+		removed = true
+
+		// Recurring rooms wait for their next occurrence
+		if len(room.Participants) == 0 && room.Recurrence == nil {
+			room.Spectators.Close()
+			s.logger.Printf("🗑️ Room %s is empty, removing it", roomID)
+			return true
+		}
+		return false
+	})
+	return removed
+}
+
+// discardRoom drops a room created for a join or move that was refused after
+// all, unless someone got in meanwhile.
+func (s *Server) discardRoom(room *roompkg.State) {
+	s.hub.UpdateRoom(room.ID, func(held *roompkg.State) bool {
+		if held != room {
+			return false
+		}
+		room.Mu.RLock()
+		defer room.Mu.RUnlock()
+		if len(room.Participants) > 0 || room.Recurrence != nil {
+			return false
+		}
+		room.Spectators.Close()
+		return true
+	})
+}
+
+func (s *Server) getParticipantsArray(room *roompkg.State) []roompkg.Participant {
+	participants := make([]roompkg.Participant, 0, len(room.Participants))
+	for _, p := range room.Participants {
+		participants = append(participants, *p)
+	}
+	return participants
+}
+
+// withLatency sets the round trip of participants connected to this instance.
+// Those connected through another one have none here.
+func (s *Server) withLatency(participants []roompkg.Participant) []roompkg.Participant {
+	for i := range participants {
+		if ws := s.hub.Client(participants[i].ID); ws != nil {
+			participants[i].LatencyMs = ws.RTT().Milliseconds()
+		}
+	}
+	return participants
+}
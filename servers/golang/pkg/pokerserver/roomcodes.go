@@ -0,0 +1,234 @@
+package pokerserver
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// Room codes are handed out by POST /api/rooms, such as "brave-otter-42". A
+// code stays set aside for roomCodeTTL so two callers don't get the same one
+// before either has joined.
+const (
+	roomCodeTTL          = 10 * time.Minute
+	maxRoomCodeAttempts  = 20
+	maxIssuedRoomCodes   = 10000
+	defaultRoomCodeChars = "0123456789"
+)
+
+var (
+	defaultRoomCodeAdjectives = []string{
+		"amber", "bold", "brave", "bright", "calm", "clever", "cosmic", "crisp",
+		"eager", "fancy", "fuzzy", "gentle", "golden", "happy", "jolly", "keen",
+		"lively", "lucky", "mellow", "merry", "mighty", "misty", "noble", "plucky",
+		"polite", "proud", "quick", "quiet", "rapid", "rosy", "rustic", "shiny",
+		"silent", "silver", "snowy", "spicy", "steady", "sunny", "swift", "tidy",
+		"vivid", "warm", "wild", "windy", "witty", "zesty",
+	}
+	defaultRoomCodeNouns = []string{
+		"badger", "beaver", "bison", "cactus", "comet", "cedar", "crane", "dolphin",
+		"eagle", "falcon", "ferret", "finch", "fox", "gecko", "heron", "ibis",
+		"koala", "lemur", "lynx", "maple", "meadow", "moose", "narwhal", "ocelot",
+		"otter", "owl", "panda", "pebble", "penguin", "pine", "puffin", "quokka",
+		"raven", "river", "robin", "salmon", "seal", "sparrow", "tiger", "tulip",
+		"walrus", "willow", "wombat", "yak", "zebra",
+	}
+	// Codes are read out and typed in, so nothing rude or easily mistaken
+	// for part of the app is handed out
+	defaultReservedRoomCodeWords = []string{
+		"admin", "api", "null", "undefined",
+		"ass", "fuck", "nazi", "porn", "sex", "shit",
+	}
+)
+
+var errNoFreeRoomCode = errors.New("no free room code")
+
+// roomCodeGenerator makes room codes out of an adjective, a noun and a random
+// suffix, skipping reserved words and codes already in use.
+type roomCodeGenerator struct {
+	adjectives   []string
+	nouns        []string
+	alphabet     string
+	suffixLength int
+	reserved     []string
+
+	mu sync.Mutex
+	// Codes handed out recently and not joined yet
+	issued map[string]issuedRoomCode
+}
+
+// issuedRoomCode is set aside until expires, with the details the room is
+// created with.
+type issuedRoomCode struct {
+	expires time.Time
+	info    roompkg.Info
+}
+
+func newRoomCodeGenerator(cfg RoomCodesConfig) *roomCodeGenerator {
+	g := &roomCodeGenerator{
+		alphabet:     cfg.Alphabet,
+		suffixLength: cfg.SuffixLength,
+		reserved:     append(append([]string(nil), defaultReservedRoomCodeWords...), cfg.Reserved...),
+		issued:       make(map[string]issuedRoomCode),
+	}
+	for i := range g.reserved {
+		g.reserved[i] = strings.ToLower(g.reserved[i])
+	}
+	adjectives, nouns := cfg.Adjectives, cfg.Nouns
+	if len(adjectives) == 0 && len(nouns) == 0 {
+		adjectives, nouns = defaultRoomCodeAdjectives, defaultRoomCodeNouns
+	}
+	g.adjectives = g.allowedWords(adjectives)
+	g.nouns = g.allowedWords(nouns)
+	return g
+}
+
+// allowedWords leaves out the words that contain a reserved one.
+func (g *roomCodeGenerator) allowedWords(words []string) []string {
+	var allowed []string
+	for _, word := range words {
+		if !g.isReserved(word) {
+			allowed = append(allowed, word)
+		}
+	}
+	return allowed
+}
+
+func (g *roomCodeGenerator) isReserved(code string) bool {
+	code = strings.ToLower(code)
+	for _, word := range g.reserved {
+		if word != "" && strings.Contains(code, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// random makes one code, which may be reserved or taken.
+func (g *roomCodeGenerator) random() string {
+	var parts []string
+	if len(g.adjectives) > 0 {
+		parts = append(parts, g.adjectives[rand.IntN(len(g.adjectives))])
+	}
+	if len(g.nouns) > 0 {
+		parts = append(parts, g.nouns[rand.IntN(len(g.nouns))])
+	}
+	if g.suffixLength > 0 && g.alphabet != "" {
+		suffix := make([]byte, g.suffixLength)
+		for i := range suffix {
+			suffix[i] = g.alphabet[rand.IntN(len(g.alphabet))]
+		}
+		parts = append(parts, string(suffix))
+	}
+	return strings.Join(parts, "-")
+}
+
+// generate returns a code that isn't reserved, handed out recently or taken,
+// and sets it aside with info until now plus roomCodeTTL.
+func (g *roomCodeGenerator) generate(now time.Time, info roompkg.Info, taken func(code string) (bool, error)) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for code, issued := range g.issued {
+		if !now.Before(issued.expires) {
+			delete(g.issued, code)
+		}
+	}
+	if len(g.issued) >= maxIssuedRoomCodes {
+		return "", errNoFreeRoomCode
+	}
+
+	for attempt := 0; attempt < maxRoomCodeAttempts; attempt++ {
+		code := g.random()
+		if _, issued := g.issued[code]; issued || code == "" || g.isReserved(code) {
+			continue
+		}
+		inUse, err := taken(code)
+		if err != nil {
+			return "", err
+		}
+		if !inUse {
+			g.issued[code] = issuedRoomCode{expires: now.Add(roomCodeTTL), info: info}
+			return code, nil
+		}
+	}
+	return "", errNoFreeRoomCode
+}
+
+// take forgets a code once its room is created, returning the details
+// posted with it if it was handed out.
+func (g *roomCodeGenerator) take(code string) (roompkg.Info, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	issued, ok := g.issued[code]
+	delete(g.issued, code)
+	return issued.info, ok
+}
+
+// validate reports settings that would keep codes from being generated.
+func (c RoomCodesConfig) validate() error {
+	var errs []error
+	for _, words := range []struct {
+		key  string
+		list []string
+	}{{"adjectives", c.Adjectives}, {"nouns", c.Nouns}} {
+		for _, word := range words.list {
+			if !isRoomCodeWord(word) {
+				errs = append(errs, fmt.Errorf("room_codes.%s: %q must be lowercase letters", words.key, word))
+			}
+		}
+	}
+	for _, ch := range c.Alphabet {
+		if !(ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9') {
+			errs = append(errs, fmt.Errorf("room_codes.alphabet: %q is not a lowercase letter or digit", ch))
+			break
+		}
+	}
+	if c.SuffixLength < 0 || c.SuffixLength > 16 {
+		errs = append(errs, fmt.Errorf("room_codes.suffix_length: must be between 0 and 16, got %d", c.SuffixLength))
+	}
+	if c.SuffixLength > 0 && c.Alphabet == "" {
+		errs = append(errs, errors.New("room_codes.alphabet: required with a suffix"))
+	}
+	return errors.Join(errs...)
+}
+
+func isRoomCodeWord(word string) bool {
+	if word == "" {
+		return false
+	}
+	for _, ch := range word {
+		if ch < 'a' || ch > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// handleCreateRoom hands out a free room code. The room itself is created by
+// whoever joins it first, with the name, description and scheduled start
+// optionally posted here.
+func (s *Server) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
+	info, ok := decodeRoomInfo(w, r)
+	if !ok {
+		return
+	}
+	code, err := s.roomCodes.generate(s.clock.Now(), info, func(code string) (bool, error) {
+		return s.roomExists(r.Context(), code)
+	})
+	if err != nil {
+		s.logger.Printf("Error generating a room code: %v", err)
+		http.Error(w, "couldn't find a free room code, try again", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusCreated, struct {
+		ID string `json:"roomId"`
+		roompkg.Info
+	}{code, info})
+}
@@ -0,0 +1,103 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestRoomCodeFormat(t *testing.T) {
+	generator := newRoomCodeGenerator(DefaultConfig().RoomCodes)
+	pattern := regexp.MustCompile(`^[a-z]+-[a-z]+-[0-9]{2}$`)
+	now := time.Now()
+	for i := 0; i < 50; i++ {
+		code, err := generator.generate(now, roompkg.Info{}, func(string) (bool, error) { return false, nil })
+		if err != nil {
+			t.Fatalf("generate failed: %v", err)
+		}
+		if !pattern.MatchString(code) {
+			t.Errorf("Expected a code like brave-otter-42, got %q", code)
+		}
+	}
+}
+
+func TestRoomCodesSkipReservedAndTaken(t *testing.T) {
+	generator := newRoomCodeGenerator(RoomCodesConfig{
+		Adjectives: []string{"brave", "grumpy"},
+		Nouns:      []string{"otter"},
+		Alphabet:   "ab",
+		Reserved:   []string{"GRUMPY"},
+	})
+	if len(generator.adjectives) != 1 {
+		t.Errorf("Expected the reserved adjective left out, got %v", generator.adjectives)
+	}
+
+	now := time.Now()
+	taken := map[string]bool{"brave-otter": true}
+	if _, err := generator.generate(now, roompkg.Info{}, func(code string) (bool, error) { return taken[code], nil }); err != errNoFreeRoomCode {
+		t.Errorf("Expected no free code when the only one is taken, got %v", err)
+	}
+
+	taken = nil
+	code, err := generator.generate(now, roompkg.Info{}, func(code string) (bool, error) { return taken[code], nil })
+	if err != nil || code != "brave-otter" {
+		t.Fatalf("Expected brave-otter, got %q, %v", code, err)
+	}
+	if _, err := generator.generate(now, roompkg.Info{}, func(string) (bool, error) { return false, nil }); err != errNoFreeRoomCode {
+		t.Errorf("Expected a handed out code to be set aside, got %v", err)
+	}
+	if code, _ := generator.generate(now.Add(roomCodeTTL), roompkg.Info{}, func(string) (bool, error) { return false, nil }); code != "brave-otter" {
+		t.Errorf("Expected the code free again once set aside long enough, got %q", code)
+	}
+}
+
+func TestCreateRoom(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RoomCodes = RoomCodesConfig{Adjectives: []string{"brave"}, Nouns: []string{"otter"}}
+	server := New(WithConfig(cfg))
+
+	rec := adminRequest(server.Handler(), http.MethodPost, "/api/rooms", "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		RoomID string `json:"roomId"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.RoomID != "brave-otter" {
+		t.Errorf("Expected the only code, got %q", body.RoomID)
+	}
+	if rec := adminRequest(server.Handler(), http.MethodPost, "/api/rooms", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 once the only code is handed out, got %d", rec.Code)
+	}
+
+	// A room somebody already joined is never handed out
+	busy := New(WithConfig(cfg))
+	alice := busy.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": "brave-otter", "name": "Alice"})
+	if rec := adminRequest(busy.Handler(), http.MethodPost, "/api/rooms", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 when the only code is in use, got %d", rec.Code)
+	}
+}
+
+func TestRoomCodesConfigValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.RoomCodes = RoomCodesConfig{Adjectives: []string{"Brave"}, Alphabet: "AB", SuffixLength: 20}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected invalid room codes to fail")
+	}
+	for _, want := range []string{"room_codes.adjectives", "room_codes.alphabet", "room_codes.suffix_length"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got: %v", want, err)
+		}
+	}
+}
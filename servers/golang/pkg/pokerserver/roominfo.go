@@ -0,0 +1,99 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// RoomView is the REST representation of a live room.
+type RoomView struct {
+	ID string `json:"roomId"`
+	roompkg.Info
+	Phase        roompkg.Phase `json:"phase"`
+	Participants int           `json:"participants"`
+	Private      bool          `json:"private"`
+	CreatedAt    time.Time     `json:"createdAt"`
+}
+
+// handleUpdateRoom lets the facilitator change the room's name, description
+// and scheduled start. Fields left out of the message are kept.
+func (s *Server) handleUpdateRoom(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring update-room from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "update-room", errCodeNotAllowed, "Only the facilitator can update the room", nil)
+		return
+	}
+	info, ok := room.Info.WithFields(data)
+	if !ok {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring update-room from %s in room %s: invalid scheduledAt", ws.ID, roomID)
+		s.sendError(ws, "update-room", errCodeInvalidInput, "scheduledAt must be a time in milliseconds", nil)
+		return
+	}
+	room.Apply(&roompkg.InfoEvent{Info: info})
+	s.scheduleStart(room)
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "🏷️ Room %s is now %q", roomID, info.Name)
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+// handleGetRoom serves a live room's name, description, schedule and phase.
+// Private rooms need their passcode.
+func (s *Server) handleGetRoom(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.hub.Room(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	if !s.authorizeRoomRead(w, r, room.PasscodeHash()) {
+		return
+	}
+
+	room.Mu.RLock()
+	view := RoomView{
+		ID:           room.ID,
+		Info:         room.Info,
+		Phase:        room.Phase(),
+		Participants: len(room.Participants),
+		Private:      room.Passcode != nil,
+		CreatedAt:    room.CreatedAt,
+	}
+	room.Mu.RUnlock()
+	writeJSON(w, http.StatusOK, view)
+}
+
+// decodeRoomInfo reads the optional room details posted with POST /api/rooms,
+// writing a 400 when they are invalid.
+func decodeRoomInfo(w http.ResponseWriter, r *http.Request) (roompkg.Info, bool) {
+	if r.ContentLength == 0 {
+		return roompkg.Info{}, true
+	}
+	var data map[string]interface{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16<<10)).Decode(&data); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return roompkg.Info{}, false
+	}
+	if field, ok := transport.CheckFields(data, transport.MessageFields["update-room"]); !ok {
+		http.Error(w, "invalid "+field, http.StatusBadRequest)
+		return roompkg.Info{}, false
+	}
+	info, ok := roompkg.Info{}.WithFields(data)
+	if !ok {
+		http.Error(w, "invalid scheduledAt", http.StatusBadRequest)
+	}
+	return info, ok
+}
@@ -0,0 +1,93 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestRoomInfoFromCreation(t *testing.T) {
+	server := New()
+	handler := server.Handler()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/rooms", strings.NewReader(`{"name": " Sprint 42 planning ", "description": "Checkout stories", "scheduledAt": 1767261600000}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		RoomID string `json:"roomId"`
+		Name   string `json:"name"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.Name != "Sprint 42 planning" {
+		t.Errorf("Expected the trimmed name echoed, got %q", created.Name)
+	}
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": created.RoomID, "name": "Alice"})
+	state := awaitMessage(t, alice, "room-state").Data.(map[string]interface{})
+	if state["name"] != "Sprint 42 planning" || state["description"] != "Checkout stories" || state["scheduledAt"] != float64(1767261600000) {
+		t.Errorf("Expected the posted details in room-state, got %v %v %v", state["name"], state["description"], state["scheduledAt"])
+	}
+
+	rec = adminRequest(handler, http.MethodGet, "/api/rooms/"+created.RoomID, "")
+	var view RoomView
+	if err := json.Unmarshal(rec.Body.Bytes(), &view); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected the room, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if view.Name != "Sprint 42 planning" || view.ScheduledAt != 1767261600000 || view.Participants != 1 || view.Phase != roompkg.PhaseLobby {
+		t.Errorf("Unexpected room view: %+v", view)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/rooms", strings.NewReader(`{"scheduledAt": "tomorrow"}`))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid scheduledAt, got %d", rec.Code)
+	}
+}
+
+func TestUpdateRoom(t *testing.T) {
+	server := New()
+	roomID := "info-room"
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+
+	info := func() roompkg.Info {
+		room, _ := server.hub.Room(roomID)
+		room.Mu.RLock()
+		defer room.Mu.RUnlock()
+		return room.Info
+	}
+
+	alice.Send("update-room", map[string]interface{}{"roomId": roomID, "name": "Refinement", "description": "Backlog grooming", "scheduledAt": float64(1767261600000)})
+	bob.Send("update-room", map[string]interface{}{"roomId": roomID, "name": "Hijacked"})
+	if got := info(); got != (roompkg.Info{Name: "Refinement", Description: "Backlog grooming", ScheduledAt: 1767261600000}) {
+		t.Errorf("Expected only the facilitator's update, got %+v", got)
+	}
+
+	alice.Send("update-room", map[string]interface{}{"roomId": roomID, "scheduledAt": float64(0)})
+	if got := info(); got != (roompkg.Info{Name: "Refinement", Description: "Backlog grooming"}) {
+		t.Errorf("Expected the schedule cleared and the rest kept, got %+v", got)
+	}
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	events := append([]roompkg.EventRecord(nil), room.Events...)
+	room.Mu.RUnlock()
+	replayed, err := roompkg.Replay(roomID, events)
+	if err != nil || replayed.Info != info() {
+		t.Errorf("Expected the details to replay, got %+v, %v", replayed.Info, err)
+	}
+}
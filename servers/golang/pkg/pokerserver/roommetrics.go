@@ -0,0 +1,146 @@
+package pokerserver
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RoomMetricsMode decides how the room gauges are labelled. Every labelled
+// room is a time series of its own, which large deployments can't afford.
+type RoomMetricsMode string
+
+const (
+	// RoomMetricsAggregate reports totals over all rooms only.
+	RoomMetricsAggregate RoomMetricsMode = "aggregate"
+	// RoomMetricsRooms labels the busiest rooms with their IDs.
+	RoomMetricsRooms RoomMetricsMode = "rooms"
+	// RoomMetricsHashed labels them with a hash of their IDs instead, as
+	// knowing a room's ID is enough to join it.
+	RoomMetricsHashed RoomMetricsMode = "hashed"
+)
+
+const defaultRoomMetricsLimit = 100
+
+// otherRooms labels the rooms past the limit, summed together
+const otherRooms = "other"
+
+// roomGauges are the gauges of one room, or of several summed together.
+type roomGauges struct {
+	label             string
+	participants      int64
+	observers         int64
+	messagesPerMinute int64
+}
+
+func (g *roomGauges) add(other roomGauges) {
+	g.participants += other.participants
+	g.observers += other.observers
+	g.messagesPerMinute += other.messagesPerMinute
+}
+
+// startRoomMetrics reports the participants, observers and message rate of
+// the rooms on this instance. Observers are participants sitting out the
+// vote. They stop with the server.
+func (s *Server) startRoomMetrics() {
+	var (
+		participants, observers, messages metric.Int64ObservableGauge
+		errs                              [3]error
+	)
+	participants, errs[0] = s.meter.Int64ObservableGauge("poker.room.participants",
+		metric.WithDescription("Participants in rooms, including those within their grace period"),
+	)
+	observers, errs[1] = s.meter.Int64ObservableGauge("poker.room.observers",
+		metric.WithDescription("Participants of rooms sitting out the vote"),
+	)
+	messages, errs[2] = s.meter.Int64ObservableGauge("poker.room.messages",
+		metric.WithDescription("Messages received from the clients of rooms in the last full minute"),
+		metric.WithUnit("{message}/min"),
+	)
+	if err := errors.Join(errs[:]...); err != nil {
+		s.logger.Printf("Error creating room metrics: %v", err)
+	}
+	registration, err := s.meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for _, gauges := range s.roomGauges() {
+			var opts []metric.ObserveOption
+			if gauges.label != "" {
+				opts = append(opts, metric.WithAttributes(attrRoomID.String(gauges.label)))
+			}
+			o.ObserveInt64(participants, gauges.participants, opts...)
+			o.ObserveInt64(observers, gauges.observers, opts...)
+			o.ObserveInt64(messages, gauges.messagesPerMinute, opts...)
+		}
+		return nil
+	}, participants, observers, messages)
+	if err != nil {
+		s.logger.Printf("Error registering room metrics: %v", err)
+		return
+	}
+	go func() {
+		<-s.ctx.Done()
+		registration.Unregister()
+	}()
+}
+
+// roomGauges measures the rooms as the metrics mode asks: all summed, or the
+// roomMetricsLimit rooms with the most participants labelled and the rest
+// summed as otherRooms.
+func (s *Server) roomGauges() []roomGauges {
+	rooms := s.hub.Rooms()
+
+	now := s.clock.Now()
+	measured := make([]roomGauges, 0, len(rooms))
+	for _, room := range rooms {
+		room.Mu.RLock()
+		gauges := roomGauges{
+			label:             room.ID,
+			participants:      int64(len(room.Participants)),
+			messagesPerMinute: room.MessageRate.PerMinute(now),
+		}
+		for _, p := range room.Participants {
+			if p.Paused {
+				gauges.observers++
+			}
+		}
+		room.Mu.RUnlock()
+		measured = append(measured, gauges)
+	}
+
+	if s.roomMetrics != RoomMetricsRooms && s.roomMetrics != RoomMetricsHashed {
+		total := roomGauges{}
+		for _, gauges := range measured {
+			total.add(gauges)
+		}
+		return []roomGauges{total}
+	}
+
+	slices.SortFunc(measured, func(a, b roomGauges) int {
+		return cmp.Or(cmp.Compare(b.participants, a.participants), cmp.Compare(a.label, b.label))
+	})
+	if len(measured) > s.roomMetricsLimit {
+		other := roomGauges{label: otherRooms}
+		for _, gauges := range measured[s.roomMetricsLimit:] {
+			other.add(gauges)
+		}
+		measured = append(measured[:s.roomMetricsLimit], other)
+	}
+	if s.roomMetrics == RoomMetricsHashed {
+		for i := range measured {
+			if measured[i].label != otherRooms {
+				measured[i].label = hashRoomLabel(measured[i].label)
+			}
+		}
+	}
+	return measured
+}
+
+// hashRoomLabel shortens a hash of the room ID, enough to tell rooms apart.
+func hashRoomLabel(roomID string) string {
+	sum := sha256.Sum256([]byte(roomID))
+	return hex.EncodeToString(sum[:6])
+}
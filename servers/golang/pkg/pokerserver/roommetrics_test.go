@@ -0,0 +1,92 @@
+package pokerserver
+
+import (
+	"context"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// collectGauge returns the values of an int64 gauge by room label.
+func collectGauge(t *testing.T, reader *sdkmetric.ManualReader, name string) map[string]int64 {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	values := make(map[string]int64)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, point := range m.Data.(metricdata.Gauge[int64]).DataPoints {
+				label, _ := point.Attributes.Value(attrRoomID)
+				values[label.AsString()] = point.Value
+			}
+		}
+	}
+	return values
+}
+
+func newRoomMetricsServer(t *testing.T, mode RoomMetricsMode, limit int) (*Server, *sdkmetric.ManualReader) {
+	reader := sdkmetric.NewManualReader()
+	server := New(WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	server.roomMetrics, server.roomMetricsLimit = mode, limit
+	if err := server.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	t.Cleanup(func() { server.Shutdown(context.Background()) })
+
+	for roomID, names := range map[string][]string{
+		"big-room":   {"Alice", "Bob", "Carol"},
+		"small-room": {"Dave", "Erin"},
+		"tiny-room":  {"Frank"},
+	} {
+		room := server.getOrCreateRoom(roomID)
+		for _, name := range names {
+			room.Participants[name] = &roompkg.Participant{ID: name, Name: name, Paused: name == "Bob"}
+		}
+	}
+	return server, reader
+}
+
+func TestRoomMetricsAggregate(t *testing.T) {
+	_, reader := newRoomMetricsServer(t, RoomMetricsAggregate, defaultRoomMetricsLimit)
+
+	if participants := collectGauge(t, reader, "poker.room.participants"); len(participants) != 1 || participants[""] != 6 {
+		t.Errorf("Expected one unlabelled total of 6, got %v", participants)
+	}
+	if observers := collectGauge(t, reader, "poker.room.observers"); observers[""] != 1 {
+		t.Errorf("Expected Bob sitting out, got %v", observers)
+	}
+}
+
+func TestRoomMetricsLimit(t *testing.T) {
+	_, reader := newRoomMetricsServer(t, RoomMetricsRooms, 2)
+
+	participants := collectGauge(t, reader, "poker.room.participants")
+	expected := map[string]int64{"big-room": 3, "small-room": 2, otherRooms: 1}
+	if len(participants) != len(expected) {
+		t.Fatalf("Expected the two biggest rooms and the rest, got %v", participants)
+	}
+	for label, value := range expected {
+		if participants[label] != value {
+			t.Errorf("Expected %d participants for %s, got %d", value, label, participants[label])
+		}
+	}
+}
+
+func TestRoomMetricsHashed(t *testing.T) {
+	_, reader := newRoomMetricsServer(t, RoomMetricsHashed, defaultRoomMetricsLimit)
+
+	participants := collectGauge(t, reader, "poker.room.participants")
+	if _, ok := participants["big-room"]; ok {
+		t.Errorf("Expected room IDs hashed, got %v", participants)
+	}
+	if participants[hashRoomLabel("big-room")] != 3 {
+		t.Errorf("Expected big-room's participants under its hash, got %v", participants)
+	}
+}
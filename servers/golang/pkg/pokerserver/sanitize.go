@@ -0,0 +1,101 @@
+package pokerserver
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Names, stories and chat reach every client in the room, so they are cleaned
+// up before they are kept: normalized, stripped of control characters and of
+// the invisible ones that reorder text, and held to sizes clients can lay out.
+const (
+	maxNameLength      = 50
+	maxStoryLinkLength = 2000
+)
+
+// inputError is a field a client sent that can't be used as is. It is sent
+// back as the details of an invalid-input error.
+type inputError struct {
+	Field  string
+	Reason string
+	Limit  int
+}
+
+// Reasons of an inputError
+const (
+	reasonRequired   = "required"
+	reasonTooLong    = "too-long"
+	reasonInvalidURL = "invalid-url"
+	reasonNotInDeck  = "not-in-deck"
+	// A cron expression that doesn't parse or recurs too often
+	reasonInvalidSchedule = "invalid-schedule"
+	reasonNegative        = "negative"
+	// Three-point estimates go from optimistic to pessimistic
+	reasonOutOfOrder = "out-of-order"
+)
+
+func (e *inputError) Error() string {
+	switch e.Reason {
+	case reasonTooLong:
+		return fmt.Sprintf("%s is longer than %d characters", e.Field, e.Limit)
+	case reasonInvalidURL:
+		return e.Field + " must be an http(s) URL"
+	case reasonNotInDeck:
+		return e.Field + " is not a card of the room's deck"
+	case reasonInvalidSchedule:
+		return e.Field + " must be a cron expression recurring at most hourly"
+	case reasonNegative:
+		return e.Field + " must not be negative"
+	case reasonOutOfOrder:
+		return e.Field + " is out of order: optimistic must not exceed most likely, nor most likely pessimistic"
+	}
+	return e.Field + " is required"
+}
+
+func (e *inputError) details() map[string]interface{} {
+	details := map[string]interface{}{"field": e.Field, "reason": e.Reason}
+	if e.Limit > 0 {
+		details["limit"] = e.Limit
+	}
+	return details
+}
+
+// sendInputError tells a client which field of its message was refused.
+func (s *Server) sendInputError(ws *ExtendedWebSocket, msgType string, err *inputError) {
+	s.logf(ws.Context(), "⚠️ Ignoring %s from %s: %v", msgType, ws.ID, err)
+	s.sendError(ws, msgType, errCodeInvalidInput, err.Error(), err.details())
+}
+
+// cleanName cleans a participant name. Compatibility normalization folds
+// lookalikes such as full-width letters, and runs of spaces collapse.
+func cleanName(name string) string {
+	return strings.Join(strings.Fields(roompkg.CleanText(norm.NFKC.String(name), false)), " ")
+}
+
+// checkName cleans a participant name, refusing it when too long.
+func checkName(name string) (string, *inputError) {
+	name = cleanName(name)
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return "", &inputError{Field: "name", Reason: reasonTooLong, Limit: maxNameLength}
+	}
+	return name, nil
+}
+
+// checkStory cleans a story's title and link, refusing a title that is too
+// long and a link that isn't a web address.
+func checkStory(title, link string) (roompkg.Story, *inputError) {
+	story := roompkg.Story{Title: roompkg.CleanText(title, false), Link: strings.TrimSpace(link)}
+	switch {
+	case utf8.RuneCountInString(story.Title) > maxStoryTitle:
+		return roompkg.Story{}, &inputError{Field: "title", Reason: reasonTooLong, Limit: maxStoryTitle}
+	case len(story.Link) > maxStoryLinkLength:
+		return roompkg.Story{}, &inputError{Field: "link", Reason: reasonTooLong, Limit: maxStoryLinkLength}
+	case story.Link != "" && !isHTTPURL(story.Link):
+		return roompkg.Story{}, &inputError{Field: "link", Reason: reasonInvalidURL}
+	}
+	return story, nil
+}
@@ -0,0 +1,89 @@
+package pokerserver
+
+import (
+	"strings"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		multiline bool
+		want      string
+	}{
+		{"trims", "  hello  ", false, "hello"},
+		{"drops control characters", "a\x00b\x1bc\x7f", false, "abc"},
+		{"drops bidi overrides", "user\u202egpj.exe", false, "usergpj.exe"},
+		{"keeps emoji sequences", "👩\u200d💻 team", false, "👩\u200d💻 team"},
+		{"flattens line breaks", "one\r\ntwo\tthree", false, "one two three"},
+		{"keeps line breaks when multiline", "one\r\ntwo", true, "one\ntwo"},
+		{"composes accents", "e\u0301", false, "\u00e9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roompkg.CleanText(tt.input, tt.multiline); got != tt.want {
+				t.Errorf("cleanText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if got := cleanName("  \uff21\uff4c\uff49\uff43\uff45 \n  Smith "); got != "Alice Smith" {
+		t.Errorf("Expected a folded, single-spaced name, got %q", got)
+	}
+}
+
+func TestInputLimits(t *testing.T) {
+	server := New()
+	roomID := "limits-room"
+
+	mallory := server.ConnectMemory()
+	defer mallory.Close()
+	mallory.Send("join-room", map[string]interface{}{"roomId": roomID, "name": strings.Repeat("m", maxNameLength+1)})
+	rejected := awaitMessage(t, mallory, "join-rejected").Data.(map[string]interface{})
+	if rejected["reason"] != "invalid-name" || rejected["field"] != "name" || rejected["limit"] != float64(maxNameLength) {
+		t.Errorf("Expected an overlong name to be rejected with details, got %v", rejected)
+	}
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice\u202e\x07"})
+	awaitMessage(t, alice, "room-state")
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	participant := room.Participants[alice.ws.ID]
+	room.Mu.RUnlock()
+	if participant == nil || participant.Name != "Alice" {
+		t.Errorf("Expected the name cleaned up, got %+v", participant)
+	}
+
+	alice.Send("update-story", map[string]interface{}{"roomId": roomID, "story": map[string]interface{}{"title": "Checkout", "link": "javascript:alert(1)"}})
+	data := awaitMessage(t, alice, "error").Data.(map[string]interface{})
+	if data["code"] != errCodeInvalidInput || data["field"] != "link" || data["reason"] != reasonInvalidURL {
+		t.Errorf("Expected the link refused, got %v", data)
+	}
+	alice.Send("update-story", map[string]interface{}{"roomId": roomID, "story": map[string]interface{}{"title": strings.Repeat("t", maxStoryTitle+1)}})
+	data = awaitMessage(t, alice, "error").Data.(map[string]interface{})
+	if data["field"] != "title" || data["reason"] != reasonTooLong {
+		t.Errorf("Expected the title refused, got %v", data)
+	}
+	room.Mu.RLock()
+	story := room.Story
+	room.Mu.RUnlock()
+	if story != nil {
+		t.Errorf("Expected the story left alone, got %+v", story)
+	}
+
+	alice.Send("update-name", map[string]interface{}{"roomId": roomID, "name": "\x00 \t"})
+	if data := awaitMessage(t, alice, "error").Data.(map[string]interface{}); data["reason"] != reasonRequired {
+		t.Errorf("Expected an empty name refused, got %v", data)
+	}
+
+	alice.Send("chat-message", map[string]interface{}{"roomId": roomID, "text": "hi\x1b[2J\nthere"})
+	chat := awaitMessage(t, alice, "chat-message").Data.(map[string]interface{})
+	if chat["text"] != "hi[2J\nthere" {
+		t.Errorf("Expected control characters stripped from chat, got %q", chat["text"])
+	}
+}
@@ -0,0 +1,96 @@
+package pokerserver
+
+import (
+	"context"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// Within this long of a scheduled start the countdown is broadcast every
+// second; before that, every whole minute
+const countdownFinal = 10 * time.Second
+
+// Messages refused while a scheduled room waits for its start, when voting
+// isn't open yet
+var scheduledLobbyRules = map[string]bool{
+	"vote":             true,
+	"reveal":           true,
+	"request-reveal":   true,
+	"open-async-round": true,
+	"open-batch":       true,
+}
+
+// nextCountdown is the time left at which the countdown is next broadcast:
+// the whole minute before remaining, then each of the last seconds, and
+// finally 0 when the session starts.
+func nextCountdown(remaining time.Duration) time.Duration {
+	step := time.Second
+	if remaining > countdownFinal {
+		step = time.Minute
+	}
+	next := remaining.Truncate(step)
+	if next == remaining {
+		next -= step
+	}
+	if remaining > countdownFinal {
+		return max(next, countdownFinal)
+	}
+	return max(next, 0)
+}
+
+// scheduleStart arms the countdown of a room scheduled to start later,
+// replacing any armed for an earlier schedule. The caller must hold room.Mu.
+func (s *Server) scheduleStart(room *roompkg.State) {
+	if room.StartTimer != nil {
+		room.StartTimer.Stop()
+		room.StartTimer = nil
+	}
+	startsAt := room.Info.ScheduledAt
+	remaining := time.UnixMilli(startsAt).Sub(s.clock.Now())
+	if startsAt == 0 || remaining <= 0 {
+		return
+	}
+	room.StartTimer = s.clock.AfterFunc(remaining-nextCountdown(remaining), func() {
+		s.countDown(room, startsAt)
+	})
+}
+
+// countDown broadcasts the time left until the room's scheduled start, or
+// opens voting once it is reached and notifies the webhooks.
+func (s *Server) countDown(room *roompkg.State, startsAt int64) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	live := s.hub.Holds(room)
+	if !live {
+		return
+	}
+
+	// The room may have been rescheduled in the meantime
+	room.Mu.Lock()
+	if room.Info.ScheduledAt != startsAt {
+		room.Mu.Unlock()
+		return
+	}
+	room.StartTimer = nil
+	remaining := time.UnixMilli(startsAt).Sub(s.clock.Now())
+	if remaining > 0 {
+		s.scheduleStart(room)
+	}
+	info := room.Info
+	room.Mu.Unlock()
+
+	ctx := context.Background()
+	if remaining > 0 {
+		s.broadcastToRoom(ctx, room.ID, "countdown", map[string]interface{}{
+			"startsAt":  startsAt,
+			"remaining": remaining.Round(time.Second).Milliseconds(),
+		})
+		return
+	}
+	s.logger.Printf("🔔 Scheduled session in room %s started", room.ID)
+	s.broadcastToRoom(ctx, room.ID, "session-started", map[string]interface{}{"startsAt": startsAt})
+	s.broadcastRoomState(ctx, room.ID)
+	s.notify(room.ID, EventSessionStarted, info)
+}
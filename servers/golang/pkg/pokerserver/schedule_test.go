@@ -0,0 +1,55 @@
+package pokerserver
+
+import (
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestNextCountdown(t *testing.T) {
+	for remaining, expected := range map[time.Duration]time.Duration{
+		5*time.Minute + 30*time.Second: 5 * time.Minute,
+		5 * time.Minute:                4 * time.Minute,
+		50 * time.Second:               countdownFinal,
+		countdownFinal:                 9 * time.Second,
+		1500 * time.Millisecond:        time.Second,
+		time.Second:                    0,
+		300 * time.Millisecond:         0,
+	} {
+		if next := nextCountdown(remaining); next != expected {
+			t.Errorf("nextCountdown(%v) = %v, want %v", remaining, next, expected)
+		}
+	}
+}
+
+func TestScheduledStart(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	server := New(WithClock(clock))
+	roomID := "scheduled-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+
+	startsAt := clock.Now().Add(2*time.Minute + 30*time.Second)
+	alice.Send("update-room", map[string]interface{}{"roomId": roomID, "scheduledAt": float64(startsAt.UnixMilli())})
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	data := awaitMessage(t, alice, "error").Data.(map[string]interface{})
+	if data["code"] != errCodeInvalidPhase || data["phase"] != string(roompkg.PhaseLobby) {
+		t.Errorf("Expected votes to wait for the scheduled start, got %v", data)
+	}
+
+	clock.Advance(30 * time.Second)
+	if countdown := awaitMessage(t, alice, "countdown").Data.(map[string]interface{}); countdown["remaining"] != float64(2*time.Minute/time.Millisecond) {
+		t.Errorf("Expected two minutes left, got %v", countdown)
+	}
+
+	clock.Advance(2 * time.Minute)
+	awaitMessage(t, alice, "session-started")
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	if voted := awaitMessage(t, alice, "participant-voted").Data.(map[string]interface{}); voted["hasVote"] != true {
+		t.Errorf("Expected the vote once the session started, got %v", voted)
+	}
+}
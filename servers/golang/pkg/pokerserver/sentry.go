@@ -0,0 +1,187 @@
+package pokerserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How many reports may wait for delivery before new ones are dropped
+const sentryQueueSize = 100
+
+const sentryTimeout = 5 * time.Second
+
+// sentryReporter sends errors to Sentry's envelope endpoint. Reports are
+// delivered by a single goroutine, dropping them when Sentry can't keep up.
+type sentryReporter struct {
+	dsn        string
+	endpoint   string
+	publicKey  string
+	httpClient *http.Client
+	logger     *log.Logger
+
+	// mu keeps reports from being queued once Close has closed the queue
+	mu     sync.RWMutex
+	closed bool
+	queue  chan ErrorEvent
+	done   chan struct{}
+}
+
+// newSentryReporter parses a DSN such as https://key@host/project and starts
+// delivering reports; it returns nil when no DSN is configured.
+func newSentryReporter(dsn string, logger *log.Logger) (*sentryReporter, error) {
+	if dsn == "" {
+		return nil, nil
+	}
+	endpoint, publicKey, err := parseSentryDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	r := &sentryReporter{
+		dsn:        dsn,
+		endpoint:   endpoint,
+		publicKey:  publicKey,
+		httpClient: &http.Client{Timeout: sentryTimeout},
+		logger:     logger,
+		queue:      make(chan ErrorEvent, sentryQueueSize),
+		done:       make(chan struct{}),
+	}
+	go r.deliver()
+	return r, nil
+}
+
+// parseSentryDSN returns the envelope endpoint and public key of a DSN.
+func parseSentryDSN(dsn string) (endpoint, publicKey string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.User == nil || u.User.Username() == "" {
+		return "", "", errors.New("must be a URL such as https://key@o0.ingest.sentry.io/0")
+	}
+	// Self-hosted Sentry may be served under a path, before the project
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if i < 0 || i == len(path)-1 {
+		return "", "", errors.New("must name the project, as in https://key@o0.ingest.sentry.io/0")
+	}
+	path, project := path[:i], path[i+1:]
+	endpoint = fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, path, project)
+	return endpoint, u.User.Username(), nil
+}
+
+func (r *sentryReporter) Report(event ErrorEvent) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.queue <- event:
+	default:
+		r.logger.Printf("⚠️ Sentry queue full, dropping %s error: %v", event.Source, event.Err)
+	}
+}
+
+func (r *sentryReporter) deliver() {
+	defer close(r.done)
+	for event := range r.queue {
+		if err := r.send(event); err != nil {
+			r.logger.Printf("❌ Reporting %s error to Sentry failed: %v", event.Source, err)
+		}
+	}
+}
+
+// Close delivers the reports already queued.
+func (r *sentryReporter) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	<-r.done
+	return nil
+}
+
+// sentryEvent is the part of Sentry's event payload the server fills in.
+type sentryEvent struct {
+	EventID   string            `json:"event_id"`
+	Timestamp string            `json:"timestamp"`
+	Level     string            `json:"level"`
+	Platform  string            `json:"platform"`
+	Logger    string            `json:"logger"`
+	Message   string            `json:"message"`
+	Tags      map[string]string `json:"tags"`
+	Extra     map[string]string `json:"extra,omitempty"`
+}
+
+// newSentryEvent turns an ErrorEvent into Sentry's payload, with the room,
+// client and message as tags so they can be searched.
+func newSentryEvent(event ErrorEvent, now time.Time) sentryEvent {
+	id := make([]byte, 16)
+	rand.Read(id)
+	tags := map[string]string{"source": event.Source}
+	for key, value := range map[string]string{
+		"room_id":      event.RoomID,
+		"client_id":    event.ClientID,
+		"message_type": event.MessageType,
+		"trace_id":     event.TraceID,
+	} {
+		if value != "" {
+			tags[key] = value
+		}
+	}
+	var extra map[string]string
+	if len(event.Stack) > 0 {
+		extra = map[string]string{"stack": string(event.Stack)}
+	}
+	return sentryEvent{
+		EventID:   hex.EncodeToString(id),
+		Timestamp: now.UTC().Format(time.RFC3339Nano),
+		Level:     "error",
+		Platform:  "go",
+		Logger:    "planning-poker",
+		Message:   event.Err.Error(),
+		Tags:      tags,
+		Extra:     extra,
+	}
+}
+
+func (r *sentryReporter) send(event ErrorEvent) error {
+	payload, err := json.Marshal(newSentryEvent(event, time.Now()))
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	header, _ := json.Marshal(map[string]string{"dsn": r.dsn})
+	body.Write(header)
+	body.WriteString("\n{\"type\":\"event\"}\n")
+	body.Write(payload)
+	body.WriteString("\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), sentryTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", "Sentry sentry_version=7, sentry_client=planning-poker/1.0, sentry_key="+r.publicKey)
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sentry responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package pokerserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSentryDSN(t *testing.T) {
+	for dsn, expected := range map[string]string{
+		"https://abc@o1.ingest.sentry.io/42":       "https://o1.ingest.sentry.io/api/42/envelope/",
+		"http://abc@sentry.internal:9000/tools/7/": "http://sentry.internal:9000/tools/api/7/envelope/",
+	} {
+		endpoint, key, err := parseSentryDSN(dsn)
+		if err != nil || endpoint != expected || key != "abc" {
+			t.Errorf("Expected %s to post to %s with key abc, got %s %s %v", dsn, expected, endpoint, key, err)
+		}
+	}
+	for _, dsn := range []string{"https://o1.ingest.sentry.io/42", "https://abc@o1.ingest.sentry.io/", "ftp://abc@host/1"} {
+		if _, _, err := parseSentryDSN(dsn); err == nil {
+			t.Errorf("Expected %s to be refused", dsn)
+		}
+	}
+}
+
+func TestSentryReporterDelivers(t *testing.T) {
+	received := make(chan sentryEvent, 1)
+	sentry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/envelope/" || !strings.Contains(r.Header.Get("X-Sentry-Auth"), "sentry_key=abc") {
+			t.Errorf("Unexpected request to %s with %s", r.URL.Path, r.Header.Get("X-Sentry-Auth"))
+		}
+		// The envelope's header and item header come before the event
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+		var lines []string
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		var event sentryEvent
+		if len(lines) != 3 || json.Unmarshal([]byte(lines[2]), &event) != nil {
+			t.Errorf("Expected an envelope with one event, got %q", lines)
+		}
+		received <- event
+	}))
+	defer sentry.Close()
+
+	dsn := strings.Replace(sentry.URL, "http://", "http://abc@", 1) + "/42"
+	reporter, err := newSentryReporter(dsn, log.Default())
+	if err != nil {
+		t.Fatalf("Failed to create reporter: %v", err)
+	}
+	reporter.Report(ErrorEvent{Err: errors.New("boom"), Source: ErrorSourcePanic, RoomID: "room-1", ClientID: "client-1", Stack: []byte("main.go:1")})
+	reporter.Close()
+	reporter.Report(ErrorEvent{Err: errors.New("too late"), Source: ErrorSourceRedis})
+
+	select {
+	case event := <-received:
+		if event.Message != "boom" || event.Tags["room_id"] != "room-1" || event.Tags["client_id"] != "client-1" || event.Tags["source"] != ErrorSourcePanic {
+			t.Errorf("Expected the error with its room and client, got %+v", event)
+		}
+		if event.Extra["stack"] != "main.go:1" || len(event.EventID) != 32 {
+			t.Errorf("Expected the stack and an event ID, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the error to reach Sentry")
+	}
+}
+
+type recordingReporter struct {
+	events chan ErrorEvent
+}
+
+func (r *recordingReporter) Report(event ErrorEvent) {
+	r.events <- event
+}
+
+func TestPanicsAreReported(t *testing.T) {
+	reporter := &recordingReporter{events: make(chan ErrorEvent, 1)}
+	server := New(WithErrorReporter(reporter))
+	server.Handle("explode", func(ws *ExtendedWebSocket, data map[string]interface{}) {
+		panic("boom")
+	})
+
+	client := server.ConnectMemory()
+	defer client.Close()
+	client.Send("join-room", map[string]interface{}{"roomId": "room-1", "name": "Alice"})
+	awaitMessage(t, client, "room-state")
+	client.Send("explode", map[string]interface{}{"roomId": "room-1"})
+
+	select {
+	case event := <-reporter.events:
+		if event.Source != ErrorSourcePanic || event.RoomID != "room-1" || event.ClientID != client.ID || event.MessageType != "explode" || len(event.Stack) == 0 {
+			t.Errorf("Expected the panic with its room, client and stack, got %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the panic to be reported")
+	}
+}
@@ -0,0 +1,356 @@
+// Package pokerserver implements the planning-poker realtime engine: rooms,
+// voting rounds and the WebSocket protocol spoken by the web client. It can be
+// embedded in other Go services or run through the standalone binary.
+package pokerserver
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/hub"
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Server is the planning-poker engine: it owns the rooms, the connected
+// clients and the optional cross-instance broker. Use Handler to mount it.
+type Server struct {
+	hub              *hub.Hub
+	broker           Broker
+	storage          Storage
+	storageOnce      sync.Once
+	storageQueue     chan pendingRound
+	snapshots        SnapshotStore
+	snapshotted      map[string]bool
+	snapshotMu       sync.Mutex
+	ownership        RoomOwnership
+	leaseTTL         time.Duration
+	leases           map[string]bool
+	leasesMu         sync.Mutex
+	relayed          map[string]string
+	relayedMu        sync.Mutex
+	sendQueue        chan *ExtendedWebSocket
+	sendersOnce      sync.Once
+	queuedMessages   atomic.Int64
+	senderMetrics    senderMetrics
+	connMetrics      connectionMetrics
+	logger           *log.Logger
+	config           Config
+	redisURL         string
+	redisCipher      *hub.RedisCipher
+	upgrader         websocket.Upgrader
+	ctx              context.Context
+	cancel           context.CancelFunc
+	heartbeat        Ticker
+	clock            Clock
+	namePolicy       DuplicateNamePolicy
+	jira             *JiraClient
+	linear           *LinearClient
+	gitlab           *GitLabClient
+	linkPreviewer    *LinkPreviewer
+	webhooks         *webhookNotifier
+	webhookWG        sync.WaitGroup
+	errorReporter    ErrorReporter
+	firehose         *roompkg.Firehose
+	roomCodes        *roomCodeGenerator
+	apiKeys          *APIKeyStore
+	oidc             *oidcProvider
+	handlers         map[string]messageHandler
+	middleware       []Middleware
+	handlersMu       sync.RWMutex
+	adminToken       string
+	teamTokens       map[string]string
+	instanceID       string
+	tracer           trace.Tracer
+	meter            metric.Meter
+	socketIO         bool
+	showLatency      bool
+	participantStats bool
+	static           fs.FS
+	inviteSecret     []byte
+	connLimiter      *connectionLimiter
+	trustProxy       bool
+	trustedProxies   ipRanges
+
+	broadcastWorkers int
+	sendBufferSize   int
+	slowClientPolicy SlowClientPolicy
+
+	roomMetrics      RoomMetricsMode
+	roomMetricsLimit int
+
+	// Settings that Reload can change while clients are connected
+	settingsMu     sync.RWMutex
+	allowedOrigins []string
+	gracePeriod    time.Duration
+	awayAfter      time.Duration
+	ipAllow        ipRanges
+	ipDeny         ipRanges
+	messageLimit   int
+	messageWindow  time.Duration
+	// httpLogSampleRate is the share of successful HTTP requests logged
+	httpLogSampleRate float64
+
+	apiKeyRateLimit  int
+	apiKeyLimiters   map[string]*transport.RateLimiter
+	apiKeyLimitersMu sync.Mutex
+}
+
+// How often clients are pinged; a client that missed the previous ping is dropped
+const heartbeatInterval = 30 * time.Second
+
+// New creates a Server. Settings not provided as options, individually or
+// through WithConfig, fall back to the environment as when running the
+// standalone binary.
+func New(opts ...Option) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		hub:            hub.New(),
+		logger:         log.Default(),
+		ctx:            ctx,
+		cancel:         cancel,
+		handlers:       make(map[string]messageHandler),
+		leases:         make(map[string]bool),
+		relayed:        make(map[string]string),
+		apiKeyLimiters: make(map[string]*transport.RateLimiter),
+		instanceID:     generateToken(),
+		clock:          roompkg.RealClock{},
+		firehose:       roompkg.NewFirehose(),
+		tracer:         defaultTracer(),
+		meter:          defaultMeter(),
+	}
+	s.applyConfig(configFromEnv())
+	s.registerDefaultHandlers()
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.connMetrics = s.newConnectionMetrics()
+
+	// Configure WebSocket upgrader with origin validation
+	s.upgrader = websocket.Upgrader{
+		Subprotocols: []string{transport.MsgpackSubprotocol},
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true // Allow connections without Origin header (e.g., native clients)
+			}
+
+			if s.originAllowed(origin) {
+				return true
+			}
+
+			s.logger.Printf("Rejected WebSocket connection from origin: %s", origin)
+			return false
+		},
+	}
+
+	return s
+}
+
+func (s *Server) startHeartbeat() {
+	s.heartbeat = s.clock.NewTicker(heartbeatInterval)
+
+	go func() {
+		for {
+			select {
+			case <-s.heartbeat.C():
+				for _, client := range s.hub.Clients() {
+					// Clients of other instances are pinged there
+					if client.Relay != nil {
+						continue
+					}
+					if !client.IsAlive.Load() {
+						client.CloseWith(transport.CloseHeartbeat, "heartbeat timeout")
+					} else {
+						client.IsAlive.Store(false)
+						client.Ping(s.clock.Now())
+					}
+				}
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *Server) Initialize() error {
+	if s.broker == nil {
+		if s.redisURL != "" {
+			broker, err := hub.NewRedisBroker(s.ctx, s.redisURL, s.redisCipher, s.logger)
+			if err != nil {
+				s.logger.Printf("%v", err)
+			} else {
+				s.broker = broker
+			}
+		}
+	}
+	// A single instance broadcasts through the same code paths, in memory
+	if s.broker == nil {
+		s.broker = NewMemoryBroker()
+	}
+	s.subscribeToBroker()
+
+	if s.ownership == nil && s.broker != nil && s.redisURL != "" {
+		ownership, err := hub.NewRedisOwnership(s.ctx, s.redisURL, s.redisCipher)
+		if err != nil {
+			s.logger.Printf("Room ownership disabled: %v", err)
+		} else {
+			s.ownership = ownership
+		}
+	}
+	if s.ownershipEnabled() {
+		s.startLeaseRenewal()
+		s.logger.Println("✓ Room ownership enabled")
+	}
+
+	if s.storage == nil {
+		storage, backend, err := newStorageFromConfig(s.ctx, s.config)
+		if err != nil {
+			s.logger.Printf("%s storage disabled: %v", backend, err)
+		} else if storage != nil {
+			s.storage = storage
+			s.logger.Printf("✓ %s storage connected", backend)
+		}
+	}
+
+	if s.snapshots == nil {
+		store, err := newSnapshotStoreFromConfig(s.config)
+		if err != nil {
+			s.logger.Printf("Snapshots disabled: %v", err)
+		}
+		s.snapshots = store
+	}
+	if s.snapshots != nil {
+		s.restoreSnapshot()
+		s.startSnapshots(s.config.SnapshotInterval)
+	}
+
+	if s.errorReporter == nil {
+		reporter, err := newSentryReporter(s.config.SentryDSN, s.logger)
+		if err != nil {
+			s.logger.Printf("Error reporting disabled: %v", err)
+		} else if reporter != nil {
+			s.errorReporter = reporter
+			s.logger.Printf("✓ Reporting errors to Sentry")
+		}
+	}
+	if s.webhooks != nil {
+		s.logger.Printf("✓ Webhook notifications enabled")
+	}
+	if s.jira != nil {
+		s.logger.Printf("✓ Jira integration enabled for %s", s.jira.baseURL.Host)
+	}
+	if s.linear != nil {
+		s.logger.Printf("✓ Linear integration enabled")
+	}
+	if s.gitlab != nil {
+		s.logger.Printf("✓ GitLab integration enabled for %s", s.gitlab.baseURL.Host)
+	}
+	if s.linkPreviewer != nil {
+		s.logger.Printf("✓ Link previews enabled for %s", strings.Join(s.linkPreviewer.domains, ", "))
+	}
+
+	s.startRoomMetrics()
+
+	// Start heartbeat mechanism
+	s.startHeartbeat()
+
+	s.logger.Println("✓ WebSocket server initialized")
+	return nil
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.Println("Starting graceful shutdown...")
+
+	// Cancel context to stop all goroutines
+	s.cancel()
+
+	// Stop heartbeat
+	if s.heartbeat != nil {
+		s.heartbeat.Stop()
+	}
+
+	// Save rooms while they are still there, so the next start can restore them
+	if s.snapshots != nil {
+		s.saveSnapshot(ctx)
+		if closer, ok := s.snapshots.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+
+	s.waitForWebhooks(ctx)
+
+	if closer, ok := s.storage.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			s.logger.Printf("Error closing storage: %v", err)
+		}
+	}
+
+	// Hand rooms to the remaining instances before leaving the broker
+	if s.ownershipEnabled() {
+		s.handOffRooms(ctx)
+	}
+	if closer, ok := s.ownership.(io.Closer); ok {
+		closer.Close()
+	}
+
+	// Close broker connections
+	if s.broker != nil {
+		s.logger.Println("Closing broker...")
+		if err := s.broker.Close(); err != nil {
+			s.logger.Printf("Error closing broker: %v", err)
+		}
+	}
+
+	// Clear rooms
+	s.hub.ClearRooms()
+
+	// Close all clients
+	for _, client := range s.hub.RemoveClients() {
+		if client.Conn != nil {
+			client.CloseWith(transport.CloseShutdown, "server shutting down")
+		}
+	}
+
+	// Last, so errors while shutting down are reported too
+	if closer, ok := s.errorReporter.(io.Closer); ok {
+		closer.Close()
+	}
+
+	s.logger.Println("✓ WebSocket graceful shutdown complete")
+	return nil
+}
+
+func (s *Server) originAllowed(origin string) bool {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+
+	for _, allowed := range s.allowedOrigins {
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func splitAndTrim(s string, sep string) []string {
+	parts := make([]string, 0)
+	for _, part := range strings.Split(s, sep) {
+		trimmed := strings.TrimSpace(part)
+		if trimmed != "" {
+			parts = append(parts, trimmed)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,1778 @@
+package pokerserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// Test helper to create a WebSocket connection
+func createTestWSConnection(t *testing.T, server *Server) (*httptest.Server, *websocket.Conn) {
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect websocket: %v", err)
+	}
+
+	return httpServer, ws
+}
+
+// Test helper to send a message and read response
+func sendMessage(t *testing.T, ws *websocket.Conn, msgType string, data interface{}) {
+	message := WebSocketMessage{
+		Type: msgType,
+		Data: data,
+	}
+	if err := ws.WriteJSON(message); err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+}
+
+// Test helper to read a message with timeout
+func readMessage(t *testing.T, ws *websocket.Conn, timeout time.Duration) *WebSocketMessage {
+	ws.SetReadDeadline(time.Now().Add(timeout))
+	var msg WebSocketMessage
+	if err := ws.ReadJSON(&msg); err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	return &msg
+}
+
+// Test helper to read until the server closes the connection, checking the code
+func expectClose(t *testing.T, ws *websocket.Conn, code int) {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := ws.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if !websocket.IsCloseError(err, code) {
+			t.Fatalf("Expected close code %d, got %v", code, err)
+		}
+		return
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	server := New()
+
+	if server == nil {
+		t.Fatal("NewServer returned nil")
+	}
+	if server.hub == nil {
+		t.Error("hub not initialized")
+	}
+	if server.ctx == nil {
+		t.Error("context not initialized")
+	}
+	if server.cancel == nil {
+		t.Error("cancel function not initialized")
+	}
+}
+
+func TestGetOrCreateRoom(t *testing.T) {
+	server := New()
+	roomID := "test-room-1"
+
+	// First call should create the room
+	room1 := server.getOrCreateRoom(roomID)
+	if room1 == nil {
+		t.Fatal("getOrCreateRoom returned nil")
+	}
+	if room1.ID != roomID {
+		t.Errorf("Expected room ID %s, got %s", roomID, room1.ID)
+	}
+
+	// Second call should return the same room
+	room2 := server.getOrCreateRoom(roomID)
+	if room1 != room2 {
+		t.Error("getOrCreateRoom should return the same room instance")
+	}
+
+	// Check initial state
+	if room1.Revealed {
+		t.Error("New room should not be revealed")
+	}
+	if len(room1.Participants) != 0 {
+		t.Error("New room should have no participants")
+	}
+	if room1.Story != nil {
+		t.Error("New room should have no story")
+	}
+	if room1.LastRound != nil {
+		t.Error("New room should have no last round")
+	}
+}
+
+func TestHandleJoinRoom(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	// Send join-room message
+	roomID := "test-room"
+	name := "Alice"
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   name,
+	})
+
+	// Read room-state response
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Errorf("Expected room-state message, got %s", msg.Type)
+	}
+
+	// Verify room state
+	data := msg.Data.(map[string]interface{})
+	participants := data["participants"].([]interface{})
+	if len(participants) != 1 {
+		t.Errorf("Expected 1 participant, got %d", len(participants))
+	}
+
+	// Verify room was created
+	room, exists := server.hub.Room(roomID)
+
+	if !exists {
+		t.Error("Room was not created")
+	}
+
+	room.Mu.RLock()
+	if len(room.Participants) != 1 {
+		t.Errorf("Expected 1 participant in room, got %d", len(room.Participants))
+	}
+
+	// Find the participant (we don't know the ID)
+	var participant *roompkg.Participant
+	for _, p := range room.Participants {
+		participant = p
+		break
+	}
+
+	if participant == nil {
+		t.Fatal("No participant found in room")
+	}
+	if participant.Name != name {
+		t.Errorf("Expected participant name %s, got %s", name, participant.Name)
+	}
+	if participant.Vote != nil {
+		t.Error("New participant should have no vote")
+	}
+	room.Mu.RUnlock()
+}
+
+func TestMultipleGuestsWithDuplicateNames(t *testing.T) {
+	server := New()
+	httpServer, ws1 := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws1.Close()
+
+	// Create second WebSocket connection
+	wsURL := "ws" + httpServer.URL[4:] + "/api/ws"
+	ws2, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create second WebSocket connection: %v", err)
+	}
+	defer ws2.Close()
+
+	roomID := "test-room"
+
+	// First guest joins
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Guest",
+	})
+	msg1 := readMessage(t, ws1, 2*time.Second)
+	if msg1.Type != "room-state" {
+		t.Errorf("Expected room-state message for ws1, got %s", msg1.Type)
+	}
+
+	// Verify first guest is named "Guest"
+	data1 := msg1.Data.(map[string]interface{})
+	participants1 := data1["participants"].([]interface{})
+	if len(participants1) != 1 {
+		t.Errorf("Expected 1 participant after first guest joins, got %d", len(participants1))
+	}
+	p1 := participants1[0].(map[string]interface{})
+	if p1["name"] != "Guest" {
+		t.Errorf("Expected first guest name to be 'Guest', got %s", p1["name"])
+	}
+
+	// Second guest joins with same name
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Guest",
+	})
+	msg2 := readMessage(t, ws2, 2*time.Second)
+	if msg2.Type != "room-state" {
+		t.Errorf("Expected room-state message for ws2, got %s", msg2.Type)
+	}
+	conflict := readMessage(t, ws2, 2*time.Second)
+	if conflict.Type != "name-conflict" {
+		t.Errorf("Expected name-conflict message for ws2, got %s", conflict.Type)
+	}
+
+	// Verify room has 2 participants with unique names
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	if len(room.Participants) != 2 {
+		t.Errorf("Expected 2 participants in room, got %d", len(room.Participants))
+	}
+
+	// Collect participant names
+	names := make([]string, 0, 2)
+	for _, p := range room.Participants {
+		names = append(names, p.Name)
+	}
+	room.Mu.RUnlock()
+
+	// Verify both "Guest" and "Guest 2" exist
+	hasGuest := false
+	hasGuest2 := false
+	for _, name := range names {
+		if name == "Guest" {
+			hasGuest = true
+		}
+		if name == "Guest 2" {
+			hasGuest2 = true
+		}
+	}
+	if !hasGuest || !hasGuest2 {
+		t.Errorf("Expected participants 'Guest' and 'Guest 2', got %v", names)
+	}
+
+	// Small delay to ensure all broadcasts are processed
+	time.Sleep(50 * time.Millisecond)
+
+	// First guest should be able to change name (become a player)
+	sendMessage(t, ws1, "update-name", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	msg3 := readMessage(t, ws1, 2*time.Second)
+	if msg3.Type != "room-state" {
+		t.Errorf("Expected room-state message after update-name, got %s", msg3.Type)
+	}
+
+	// Small delay to ensure update is processed
+	time.Sleep(50 * time.Millisecond)
+
+	// Verify first guest's name was updated
+	room.Mu.RLock()
+	updatedNames := make([]string, 0, 2)
+	for _, p := range room.Participants {
+		updatedNames = append(updatedNames, p.Name)
+	}
+	room.Mu.RUnlock()
+
+	hasAlice := false
+	hasGuest2AfterUpdate := false
+	for _, name := range updatedNames {
+		if name == "Alice" {
+			hasAlice = true
+		}
+		if name == "Guest 2" {
+			hasGuest2AfterUpdate = true
+		}
+	}
+	if !hasAlice || !hasGuest2AfterUpdate {
+		t.Errorf("Expected participants 'Alice' and 'Guest 2' after update, got %v", updatedNames)
+	}
+
+	// Second guest should also be able to change name
+	sendMessage(t, ws2, "update-name", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Bob",
+	})
+	msg4 := readMessage(t, ws2, 2*time.Second)
+	if msg4.Type != "room-state" {
+		t.Errorf("Expected room-state message after second update-name, got %s", msg4.Type)
+	}
+
+	// Small delay to ensure update is processed
+	time.Sleep(50 * time.Millisecond)
+
+	// Verify both names are updated
+	room.Mu.RLock()
+	finalNames := make([]string, 0, 2)
+	for _, p := range room.Participants {
+		finalNames = append(finalNames, p.Name)
+	}
+	room.Mu.RUnlock()
+
+	hasBob := false
+	hasAliceFinal := false
+	for _, name := range finalNames {
+		if name == "Alice" {
+			hasAliceFinal = true
+		}
+		if name == "Bob" {
+			hasBob = true
+		}
+	}
+	if !hasAliceFinal || !hasBob {
+		t.Errorf("Expected participants 'Alice' and 'Bob' after both updates, got %v", finalNames)
+	}
+}
+
+func TestHandleVote(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	// Join room first
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Send vote
+	vote := "5"
+	sendMessage(t, ws, "vote", map[string]interface{}{
+		"roomId": roomID,
+		"vote":   vote,
+	})
+
+	// Read participant-voted response
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "participant-voted" {
+		t.Errorf("Expected participant-voted message, got %s", msg.Type)
+	}
+
+	// Verify vote was recorded
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+
+	// Find the participant
+	var participant *roompkg.Participant
+	for _, p := range room.Participants {
+		participant = p
+		break
+	}
+
+	if participant.Vote == nil {
+		t.Fatal("Participant vote should not be nil")
+	}
+	if *participant.Vote != vote {
+		t.Errorf("Expected vote %s, got %s", vote, *participant.Vote)
+	}
+}
+
+func TestHandleReveal(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	// Join room and vote
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "vote", map[string]interface{}{
+		"roomId": roomID,
+		"vote":   "8",
+	})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+
+	// Reveal votes
+	sendMessage(t, ws, "reveal", map[string]interface{}{
+		"roomId": roomID,
+	})
+
+	// Read revealed response
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "revealed" {
+		t.Errorf("Expected revealed message, got %s", msg.Type)
+	}
+
+	// Verify room is revealed
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+
+	if !room.Revealed {
+		t.Error("Room should be revealed")
+	}
+	if room.LastRound == nil {
+		t.Error("LastRound should be set after reveal")
+	}
+	if len(room.LastRound.Participants) != 1 {
+		t.Errorf("Expected 1 participant in last round, got %d", len(room.LastRound.Participants))
+	}
+}
+
+func TestRevealIncludesConfidence(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Unknown confidence levels are dropped, keeping the vote
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "5", "confidence": "certain"})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "8", "confidence": "low"})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+
+	sendMessage(t, ws, "reveal", map[string]interface{}{"roomId": roomID})
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "revealed" {
+		t.Fatalf("Expected revealed message, got %s", msg.Type)
+	}
+
+	data := msg.Data.(map[string]interface{})
+	participant := data["participants"].([]interface{})[0].(map[string]interface{})
+	if participant["vote"] != "8" || participant["confidence"] != "low" {
+		t.Errorf("Expected vote 8 with low confidence, got %v", participant)
+	}
+	confidence := data["stats"].(map[string]interface{})["confidence"].(map[string]interface{})
+	if confidence["low"] != float64(1) {
+		t.Errorf("Expected one low-confidence vote in stats, got %v", confidence)
+	}
+}
+
+func TestRevealListsAbstentions(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "☕"})
+	msg := readMessage(t, ws, 2*time.Second)
+	if voted := msg.Data.(map[string]interface{}); voted["hasVote"] != true || voted["abstained"] != true {
+		t.Errorf("Expected the coffee card to be flagged as an abstention, got %v", voted)
+	}
+
+	sendMessage(t, ws, "reveal", map[string]interface{}{"roomId": roomID})
+	msg = readMessage(t, ws, 2*time.Second)
+	data := msg.Data.(map[string]interface{})
+	abstained := data["abstained"].([]interface{})
+	if len(abstained) != 1 || abstained[0].(map[string]interface{})["name"] != "Alice" {
+		t.Errorf("Expected Alice to have abstained, got %v", abstained)
+	}
+	if stats := data["stats"].(map[string]interface{}); stats["voteCount"] != float64(0) || stats["abstentions"] != float64(1) {
+		t.Errorf("Expected the abstention to be left out of the votes, got %v", stats)
+	}
+}
+
+func TestHandleReestimate(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	// Join room, vote, and reveal
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "vote", map[string]interface{}{
+		"roomId": roomID,
+		"vote":   "8",
+	})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+
+	sendMessage(t, ws, "reveal", map[string]interface{}{
+		"roomId": roomID,
+	})
+	readMessage(t, ws, 2*time.Second) // revealed
+	readMessage(t, ws, 2*time.Second) // consensus
+
+	// Reestimate
+	sendMessage(t, ws, "reestimate", map[string]interface{}{
+		"roomId": roomID,
+	})
+
+	// Read room-state response
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Errorf("Expected room-state message, got %s", msg.Type)
+	}
+
+	// Verify votes are cleared
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+
+	if room.Revealed {
+		t.Error("Room should not be revealed after reestimate")
+	}
+
+	for _, p := range room.Participants {
+		if p.Vote != nil {
+			t.Error("Votes should be cleared after reestimate")
+		}
+	}
+}
+
+func TestHandleReopenRound(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+	sendMessage(t, ws, "reveal", map[string]interface{}{"roomId": roomID})
+	readMessage(t, ws, 2*time.Second) // revealed
+	readMessage(t, ws, 2*time.Second) // consensus
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	firstID := room.LastRound.ID
+	room.Mu.RUnlock()
+
+	sendMessage(t, ws, "reopen-round", map[string]interface{}{"roomId": roomID})
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Fatalf("Expected room-state message, got %s", msg.Type)
+	}
+
+	room.Mu.RLock()
+	if room.Revealed {
+		t.Error("Room should not be revealed after reopen-round")
+	}
+	for _, p := range room.Participants {
+		if p.Vote == nil || *p.Vote != "8" {
+			t.Errorf("Vote should be kept after reopen-round, got %v", p.Vote)
+		}
+	}
+	if len(room.History) != 0 {
+		t.Errorf("Expected the reopened round to leave the history, got %d rounds", len(room.History))
+	}
+	room.Mu.RUnlock()
+
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+	sendMessage(t, ws, "reveal", map[string]interface{}{"roomId": roomID})
+	readMessage(t, ws, 2*time.Second) // revealed
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if len(room.History) != 1 {
+		t.Fatalf("Expected 1 round in history, got %d", len(room.History))
+	}
+	round := room.History[0]
+	if round.ID != firstID {
+		t.Errorf("Expected the round to keep ID %s, got %s", firstID, round.ID)
+	}
+	if len(round.Votes) != 1 || round.Votes[0].Vote != "5" {
+		t.Errorf("Expected the changed vote in history, got %+v", round.Votes)
+	}
+}
+
+func TestHandleReset(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	// Join room and vote
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "vote", map[string]interface{}{
+		"roomId": roomID,
+		"vote":   "8",
+	})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+
+	// Reset
+	sendMessage(t, ws, "reset", map[string]interface{}{
+		"roomId": roomID,
+	})
+
+	// Read room-reset response
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-reset" {
+		t.Errorf("Expected room-reset message, got %s", msg.Type)
+	}
+
+	// Verify votes are cleared
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+
+	if room.Revealed {
+		t.Error("Room should not be revealed after reset")
+	}
+
+	for _, p := range room.Participants {
+		if p.Vote != nil {
+			t.Error("Votes should be cleared after reset")
+		}
+	}
+}
+
+func TestHandleUpdateStory(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	// Join room first
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Update story
+	title := "User Authentication"
+	link := "https://example.com/story/123"
+	sendMessage(t, ws, "update-story", map[string]interface{}{
+		"roomId": roomID,
+		"story": map[string]interface{}{
+			"title": title,
+			"link":  link,
+		},
+	})
+
+	// Read story-updated response
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "story-updated" {
+		t.Errorf("Expected story-updated message, got %s", msg.Type)
+	}
+
+	// Verify story was updated
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+
+	if room.Story == nil {
+		t.Fatal("Story should not be nil")
+	}
+	if room.Story.Title != title {
+		t.Errorf("Expected story title %s, got %s", title, room.Story.Title)
+	}
+	if room.Story.Link != link {
+		t.Errorf("Expected story link %s, got %s", link, room.Story.Link)
+	}
+}
+
+func TestSetFinalEstimateRequiresFacilitator(t *testing.T) {
+	server := New()
+	roomID := "test-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	alice.Send("update-story", map[string]interface{}{"roomId": roomID, "story": map[string]interface{}{"title": "Login"}})
+
+	bob.Send("set-final-estimate", map[string]interface{}{"roomId": roomID, "estimate": "13"})
+	alice.Send("set-final-estimate", map[string]interface{}{"roomId": roomID, "estimate": "5"})
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if room.Story.FinalEstimate != "5" {
+		t.Errorf("Expected only the facilitator's estimate, got %q", room.Story.FinalEstimate)
+	}
+	for _, entry := range room.Audit {
+		if entry.Action == roompkg.AuditFinalEstimate && entry.ActorID != alice.ID {
+			t.Errorf("Expected no estimate from Bob in the audit log, got %+v", entry)
+		}
+	}
+}
+
+func TestHandleSuspendAndResumeVoting(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	// Join room first
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Suspend voting
+	sendMessage(t, ws, "suspend-voting", map[string]interface{}{
+		"roomId": roomID,
+	})
+
+	// Read room-state response
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Errorf("Expected room-state message, got %s", msg.Type)
+	}
+
+	// Verify participant is paused
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	var participant *roompkg.Participant
+	for _, p := range room.Participants {
+		participant = p
+		break
+	}
+	if !participant.Paused {
+		t.Error("Participant should be paused")
+	}
+	room.Mu.RUnlock()
+
+	// Resume voting
+	sendMessage(t, ws, "resume-voting", map[string]interface{}{
+		"roomId": roomID,
+	})
+
+	// Read room-state response
+	msg = readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Errorf("Expected room-state message, got %s", msg.Type)
+	}
+
+	// Verify participant is not paused
+	room.Mu.RLock()
+	for _, p := range room.Participants {
+		participant = p
+		break
+	}
+	if participant.Paused {
+		t.Error("Participant should not be paused after resume")
+	}
+	room.Mu.RUnlock()
+}
+
+func TestHandleRaiseAndLowerHand(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	handRaised := func(msg *WebSocketMessage) bool {
+		participants := msg.Data.(map[string]interface{})["participants"].([]interface{})
+		return participants[0].(map[string]interface{})["handRaised"] == true
+	}
+
+	sendMessage(t, ws, "raise-hand", map[string]interface{}{"roomId": roomID})
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-state" || !handRaised(msg) {
+		t.Fatalf("Expected room-state with the hand raised, got %s %v", msg.Type, msg.Data)
+	}
+
+	sendMessage(t, ws, "lower-hand", map[string]interface{}{"roomId": roomID})
+	msg = readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-state" || handRaised(msg) {
+		t.Fatalf("Expected room-state with the hand lowered, got %s %v", msg.Type, msg.Data)
+	}
+
+	// Revealing lowers raised hands
+	sendMessage(t, ws, "raise-hand", map[string]interface{}{"roomId": roomID})
+	readMessage(t, ws, 2*time.Second) // room-state
+	sendMessage(t, ws, "vote", map[string]interface{}{"roomId": roomID, "vote": "3"})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+	sendMessage(t, ws, "reveal", map[string]interface{}{"roomId": roomID})
+	msg = readMessage(t, ws, 2*time.Second)
+	if msg.Type != "revealed" || handRaised(msg) {
+		t.Errorf("Expected revealed with the hand lowered, got %s %v", msg.Type, msg.Data)
+	}
+}
+
+func TestHandleUpdateName(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	// Join room first
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Update name
+	newName := "Bob"
+	sendMessage(t, ws, "update-name", map[string]interface{}{
+		"roomId": roomID,
+		"name":   newName,
+	})
+
+	// Read room-state response
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Errorf("Expected room-state message, got %s", msg.Type)
+	}
+
+	// Verify name was updated
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+
+	var participant *roompkg.Participant
+	for _, p := range room.Participants {
+		participant = p
+		break
+	}
+
+	if participant.Name != newName {
+		t.Errorf("Expected participant name %s, got %s", newName, participant.Name)
+	}
+}
+
+func TestMultipleClientsInSameRoom(t *testing.T) {
+	server := New()
+
+	// Create two WebSocket connections
+	httpServer1, ws1 := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer ws1.Close()
+
+	httpServer2, ws2 := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer ws2.Close()
+
+	roomID := "test-room"
+
+	// Both clients join the same room
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1
+
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Bob",
+	})
+
+	// ws1 should receive a room-state update about Bob joining
+	msg1 := readMessage(t, ws1, 2*time.Second)
+	if msg1.Type != "room-state" {
+		t.Errorf("Expected room-state message, got %s", msg1.Type)
+	}
+
+	// ws2 should receive its own room-state
+	msg2 := readMessage(t, ws2, 2*time.Second)
+	if msg2.Type != "room-state" {
+		t.Errorf("Expected room-state message, got %s", msg2.Type)
+	}
+
+	// Verify room has 2 participants
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+
+	if len(room.Participants) != 2 {
+		t.Errorf("Expected 2 participants, got %d", len(room.Participants))
+	}
+}
+
+func TestClientDisconnect(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+
+	roomID := "test-room"
+
+	// Join room
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Get client ID before closing
+	clientCount := len(server.hub.Clients())
+
+	if clientCount != 1 {
+		t.Errorf("Expected 1 client, got %d", clientCount)
+	}
+
+	// Close connection
+	ws.Close()
+
+	// Give some time for disconnect handler to run
+	time.Sleep(100 * time.Millisecond)
+
+	// Verify client was removed
+	clientCount = len(server.hub.Clients())
+
+	if clientCount != 0 {
+		t.Errorf("Expected 0 clients after disconnect, got %d", clientCount)
+	}
+
+	// Verify participant data is kept for potential reconnection
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+
+	// Participant should still be in room for reconnection support
+	if len(room.Participants) != 1 {
+		t.Errorf("Expected 1 participant (kept for reconnection) after disconnect, got %d", len(room.Participants))
+	}
+}
+
+func TestConcurrentRoomOperations(t *testing.T) {
+	server := New()
+	roomID := "test-room"
+
+	var wg sync.WaitGroup
+	numGoroutines := 10
+
+	// Concurrently access the same room
+	for i := 0; i < numGoroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			room := server.getOrCreateRoom(roomID)
+			if room == nil {
+				t.Error("getOrCreateRoom returned nil")
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// Verify only one room was created
+	roomCount := len(server.hub.Rooms())
+
+	if roomCount != 1 {
+		t.Errorf("Expected 1 room, got %d", roomCount)
+	}
+}
+
+func TestServerInitializeAndShutdown(t *testing.T) {
+	server := New()
+
+	// Initialize server
+	if err := server.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+
+	// Verify heartbeat started
+	if server.heartbeat == nil {
+		t.Error("Heartbeat should be started after initialization")
+	}
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": "test-room", "name": "Alice"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Shutdown server
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatalf("Failed to shutdown server: %v", err)
+	}
+	// Clients are told to reconnect elsewhere
+	expectClose(t, ws, transport.CloseShutdown)
+
+	// Verify resources are cleaned up
+	roomCount := len(server.hub.Rooms())
+
+	clientCount := len(server.hub.Clients())
+
+	if roomCount != 0 {
+		t.Errorf("Expected 0 rooms after shutdown, got %d", roomCount)
+	}
+	if clientCount != 0 {
+		t.Errorf("Expected 0 clients after shutdown, got %d", clientCount)
+	}
+}
+
+func TestGetParticipantsArray(t *testing.T) {
+	server := New()
+	room := &roompkg.State{
+		ID:           "test-room",
+		Participants: make(map[string]*roompkg.Participant),
+	}
+
+	// Add participants
+	room.Participants["1"] = &roompkg.Participant{ID: "1", Name: "Alice", Vote: nil}
+	room.Participants["2"] = &roompkg.Participant{ID: "2", Name: "Bob", Vote: nil}
+
+	participants := server.getParticipantsArray(room)
+
+	if len(participants) != 2 {
+		t.Errorf("Expected 2 participants, got %d", len(participants))
+	}
+}
+
+func TestBroadcastToRoomWithExclude(t *testing.T) {
+	server := New()
+
+	// Create two WebSocket connections
+	httpServer1, ws1 := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer ws1.Close()
+
+	httpServer2, ws2 := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer ws2.Close()
+
+	roomID := "test-room"
+
+	// Both clients join the same room
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1
+
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Bob",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1 (Bob joined)
+	readMessage(t, ws2, 2*time.Second) // room-state for ws2
+
+	// Get Alice's client ID
+	room, _ := server.hub.Room(roomID)
+
+	var client1ID string
+	room.Mu.RLock()
+	for id, p := range room.Participants {
+		if p.Name == "Alice" {
+			client1ID = id
+		}
+	}
+	room.Mu.RUnlock()
+
+	// Broadcast a message excluding client 1
+	testData := map[string]interface{}{"test": "data"}
+	server.broadcastToRoom(context.Background(), roomID, "test-message", testData, client1ID)
+
+	// ws2 should receive the message
+	ws2.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg2 WebSocketMessage
+	err := ws2.ReadJSON(&msg2)
+	if err != nil {
+		t.Fatalf("ws2 should receive message: %v", err)
+	}
+	if msg2.Type != "test-message" {
+		t.Errorf("Expected test-message, got %s", msg2.Type)
+	}
+
+	// ws1 should not receive the message (it's excluded)
+	ws1.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	var msg1 WebSocketMessage
+	err = ws1.ReadJSON(&msg1)
+	if err == nil {
+		t.Error("ws1 should not receive message (excluded)")
+	}
+}
+
+func TestHandleLeaveRoom(t *testing.T) {
+	server := New()
+
+	httpServer1, ws1 := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer ws1.Close()
+
+	httpServer2, ws2 := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer ws2.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1
+
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Bob",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1 (Bob joined)
+	readMessage(t, ws2, 2*time.Second) // room-state for ws2
+
+	// Bob leaves explicitly
+	sendMessage(t, ws2, "leave-room", map[string]interface{}{
+		"roomId": roomID,
+	})
+
+	// Alice should receive a room-state without Bob
+	msg := readMessage(t, ws1, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Errorf("Expected room-state message, got %s", msg.Type)
+	}
+	data := msg.Data.(map[string]interface{})
+	participants := data["participants"].([]interface{})
+	if len(participants) != 1 {
+		t.Fatalf("Expected 1 participant after leave-room, got %d", len(participants))
+	}
+	if name := participants[0].(map[string]interface{})["name"]; name != "Alice" {
+		t.Errorf("Expected remaining participant to be Alice, got %v", name)
+	}
+}
+
+func TestDisconnectGracePeriodPurgesParticipant(t *testing.T) {
+	server := New()
+	server.gracePeriod = 50 * time.Millisecond
+
+	httpServer1, ws1 := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer ws1.Close()
+
+	httpServer2, ws2 := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1
+
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Bob",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1 (Bob joined)
+	readMessage(t, ws2, 2*time.Second) // room-state for ws2
+
+	// Bob drops without leaving. Alice sees him offline, unless that state
+	// was coalesced with the purge, then without him after the grace period
+	ws2.Close()
+	readRoomStateUntil(t, ws1, func(participants []interface{}) bool {
+		return len(participants) == 1
+	})
+}
+
+func TestRemoveParticipantDeletesEmptyRoom(t *testing.T) {
+	server := New()
+	room := server.getOrCreateRoom("test-room")
+	room.Participants["1"] = &roompkg.Participant{ID: "1", Name: "Alice"}
+
+	if !server.removeParticipant("test-room", "1") {
+		t.Fatal("Expected participant to be removed")
+	}
+	if server.removeParticipant("test-room", "1") {
+		t.Error("Removing a missing participant should return false")
+	}
+
+	_, exists := server.hub.Room("test-room")
+	if exists {
+		t.Error("Empty room should be deleted")
+	}
+}
+
+func TestPresenceStatusOnDisconnect(t *testing.T) {
+	server := New()
+
+	httpServer1, ws1 := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer ws1.Close()
+
+	httpServer2, ws2 := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1
+
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Bob",
+	})
+	msg := readMessage(t, ws1, 2*time.Second) // room-state for ws1 (Bob joined)
+	readMessage(t, ws2, 2*time.Second)        // room-state for ws2
+
+	for _, p := range msg.Data.(map[string]interface{})["participants"].([]interface{}) {
+		if online := p.(map[string]interface{})["online"]; online != true {
+			t.Errorf("Expected joined participant to be online, got %v", online)
+		}
+	}
+
+	ws2.Close()
+
+	// Alice should be told that Bob went offline while his entry is kept
+	msg = readMessage(t, ws1, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Fatalf("Expected room-state message, got %s", msg.Type)
+	}
+	participants := msg.Data.(map[string]interface{})["participants"].([]interface{})
+	if len(participants) != 2 {
+		t.Fatalf("Expected 2 participants after disconnect, got %d", len(participants))
+	}
+	for _, raw := range participants {
+		p := raw.(map[string]interface{})
+		expected := p["name"] == "Alice"
+		if p["online"] != expected {
+			t.Errorf("Expected %v online=%v, got %v", p["name"], expected, p["online"])
+		}
+	}
+}
+
+func TestDuplicateNamePolicyReject(t *testing.T) {
+	server := New()
+	server.namePolicy = DuplicateNameReject
+
+	httpServer1, ws1 := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer ws1.Close()
+
+	httpServer2, ws2 := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer ws2.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1
+
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	msg := readMessage(t, ws2, 2*time.Second)
+	if msg.Type != "join-rejected" {
+		t.Fatalf("Expected join-rejected message, got %s", msg.Type)
+	}
+	if reason := msg.Data.(map[string]interface{})["reason"]; reason != "name-taken" {
+		t.Errorf("Expected reason name-taken, got %v", reason)
+	}
+
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if len(room.Participants) != 1 {
+		t.Errorf("Expected 1 participant after rejected join, got %d", len(room.Participants))
+	}
+}
+
+func TestDuplicateNamePolicyRejectChecksOtherNames(t *testing.T) {
+	server := New()
+	server.namePolicy = DuplicateNameReject
+	roomID := "test-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice", "participantId": "p-alice"})
+	awaitMessage(t, alice, "room-state")
+
+	// The same participant in a second tab, under another name
+	tab := server.ConnectMemory()
+	defer tab.Close()
+	tab.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice (laptop)", "participantId": "p-alice"})
+	msg := <-tab.Messages()
+	if msg.Type != "room-state" {
+		t.Fatalf("Expected the second tab to join, got %s %v", msg.Type, msg.Data)
+	}
+	for msg := range alice.Messages() {
+		if msg.Type == "name-conflict" {
+			t.Errorf("Expected no name conflict, got %v", msg.Data)
+		}
+		if msg.Type == "room-state" {
+			break
+		}
+	}
+
+	// Someone else still can't take a name in use
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice (laptop)", "participantId": "p-bob"})
+	if msg := awaitMessage(t, bob, "join-rejected"); msg.Data.(map[string]interface{})["reason"] != "name-taken" {
+		t.Errorf("Expected reason name-taken, got %v", msg.Data)
+	}
+}
+
+func TestDuplicateNamePolicyToken(t *testing.T) {
+	server := New()
+	server.namePolicy = DuplicateNameToken
+
+	httpServer, ws1 := createTestWSConnection(t, server)
+	defer httpServer.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	session := readMessage(t, ws1, 2*time.Second)
+	if session.Type != "session" {
+		t.Fatalf("Expected session message, got %s", session.Type)
+	}
+	token := session.Data.(map[string]interface{})["sessionToken"].(string)
+	readMessage(t, ws1, 2*time.Second) // room-state
+
+	sendMessage(t, ws1, "vote", map[string]interface{}{
+		"roomId": roomID,
+		"vote":   "5",
+	})
+	readMessage(t, ws1, 2*time.Second) // participant-voted
+	ws1.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	// Without the token, the name cannot be reclaimed
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	ws2, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect websocket: %v", err)
+	}
+	defer ws2.Close()
+
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws2, 2*time.Second) // session
+	msg := readMessage(t, ws2, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Fatalf("Expected room-state message, got %s", msg.Type)
+	}
+	msg = readMessage(t, ws2, 2*time.Second)
+	if msg.Type != "name-conflict" {
+		t.Fatalf("Expected name-conflict message, got %s", msg.Type)
+	}
+	if assigned := msg.Data.(map[string]interface{})["assignedName"]; assigned != "Alice 2" {
+		t.Errorf("Expected assigned name 'Alice 2', got %v", assigned)
+	}
+
+	// With the token, the original participant and vote are restored
+	ws3, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect websocket: %v", err)
+	}
+	defer ws3.Close()
+
+	sendMessage(t, ws3, "join-room", map[string]interface{}{
+		"roomId":       roomID,
+		"name":         "Alice",
+		"sessionToken": token,
+	})
+	readMessage(t, ws3, 2*time.Second) // session
+	msg = readMessage(t, ws3, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Fatalf("Expected room-state message, got %s", msg.Type)
+	}
+
+	participants := msg.Data.(map[string]interface{})["participants"].([]interface{})
+	if len(participants) != 2 {
+		t.Fatalf("Expected 2 participants, got %d", len(participants))
+	}
+	for _, raw := range participants {
+		p := raw.(map[string]interface{})
+		if p["name"] == "Alice" && p["vote"] != "5" {
+			t.Errorf("Expected reclaimed participant to keep vote 5, got %v", p["vote"])
+		}
+	}
+}
+
+func TestHandleClearVote(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "vote", map[string]interface{}{
+		"roomId": roomID,
+		"vote":   "5",
+	})
+	readMessage(t, ws, 2*time.Second) // participant-voted
+
+	sendMessage(t, ws, "clear-vote", map[string]interface{}{
+		"roomId": roomID,
+	})
+
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "participant-voted" {
+		t.Fatalf("Expected participant-voted message, got %s", msg.Type)
+	}
+	if hasVote := msg.Data.(map[string]interface{})["hasVote"]; hasVote != false {
+		t.Errorf("Expected hasVote false, got %v", hasVote)
+	}
+
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	for _, p := range room.Participants {
+		if p.Vote != nil {
+			t.Errorf("Expected vote to be cleared, got %s", *p.Vote)
+		}
+	}
+}
+
+func TestHandleReaction(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Unsupported reactions are refused
+	sendMessage(t, ws, "reaction", map[string]interface{}{
+		"roomId": roomID,
+		"emoji":  "<script>",
+	})
+	if msg := readMessage(t, ws, 2*time.Second); msg.Type != "error" || msg.Data.(map[string]interface{})["code"] != errCodeInvalidInput {
+		t.Fatalf("Expected an unsupported reaction to be refused, got %s %v", msg.Type, msg.Data)
+	}
+
+	sendMessage(t, ws, "reaction", map[string]interface{}{
+		"roomId": roomID,
+		"emoji":  "🎉",
+	})
+
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "reaction" {
+		t.Fatalf("Expected reaction message, got %s", msg.Type)
+	}
+	data := msg.Data.(map[string]interface{})
+	if data["emoji"] != "🎉" {
+		t.Errorf("Expected emoji 🎉, got %v", data["emoji"])
+	}
+	if data["id"] == "" {
+		t.Error("Expected sender ID in reaction")
+	}
+
+	// Exceeding the rate limit drops the extra reactions
+	for i := 0; i < reactionLimit+3; i++ {
+		sendMessage(t, ws, "reaction", map[string]interface{}{
+			"roomId": roomID,
+			"emoji":  "👍",
+		})
+	}
+	for i := 0; i < reactionLimit-1; i++ {
+		readMessage(t, ws, 2*time.Second)
+	}
+	ws.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	var extra WebSocketMessage
+	if err := ws.ReadJSON(&extra); err == nil {
+		t.Errorf("Expected rate-limited reaction to be dropped, got %s", extra.Type)
+	}
+}
+
+func TestHandleSelecting(t *testing.T) {
+	server := New()
+	httpServer1, ws1 := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer ws1.Close()
+	httpServer2, ws2 := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer ws2.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	readMessage(t, ws1, 2*time.Second) // room-state
+	sendMessage(t, ws2, "join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	readMessage(t, ws2, 2*time.Second) // room-state
+	readMessage(t, ws1, 2*time.Second) // room-state (Bob joined)
+
+	// Only the first of a burst goes out, and not back to the sender
+	for i := 0; i < 5; i++ {
+		sendMessage(t, ws2, "selecting", map[string]interface{}{"roomId": roomID})
+	}
+	msg := readMessage(t, ws1, 2*time.Second)
+	if msg.Type != "selecting" {
+		t.Fatalf("Expected selecting message, got %s", msg.Type)
+	}
+	if msg.Data.(map[string]interface{})["id"] == "" {
+		t.Error("Expected sender ID in selecting")
+	}
+
+	ws1.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	var extra WebSocketMessage
+	if err := ws1.ReadJSON(&extra); err == nil {
+		t.Errorf("Expected throttled selecting to be dropped, got %s", extra.Type)
+	}
+	ws2.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if err := ws2.ReadJSON(&extra); err == nil {
+		t.Errorf("Expected no selecting echo to the sender, got %s", extra.Type)
+	}
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	for _, p := range room.Participants {
+		if p.Vote != nil {
+			t.Errorf("Expected selecting not to change room state, got vote %v", *p.Vote)
+		}
+	}
+}
+
+func TestHandleChatMessage(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	sendMessage(t, ws, "chat-message", map[string]interface{}{
+		"roomId": roomID,
+		"text":   "<b>hi</b>",
+	})
+
+	msg := readMessage(t, ws, 2*time.Second)
+	if msg.Type != "chat-message" {
+		t.Fatalf("Expected chat-message, got %s", msg.Type)
+	}
+	data := msg.Data.(map[string]interface{})
+	if data["text"] != "&lt;b&gt;hi&lt;/b&gt;" {
+		t.Errorf("Expected HTML-escaped text, got %v", data["text"])
+	}
+	if data["senderName"] != "Alice" {
+		t.Errorf("Expected sender Alice, got %v", data["senderName"])
+	}
+	if data["timestamp"] == nil {
+		t.Error("Expected server timestamp on chat message")
+	}
+
+	// Late joiners get recent history in room-state
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	ws2, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect websocket: %v", err)
+	}
+	defer ws2.Close()
+
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Bob",
+	})
+	msg = readMessage(t, ws2, 2*time.Second)
+	chat := msg.Data.(map[string]interface{})["chat"].([]interface{})
+	if len(chat) != 1 {
+		t.Errorf("Expected 1 chat message in room-state, got %d", len(chat))
+	}
+}
+
+func TestChatHistoryIsBounded(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	for i := 0; i < roompkg.MaxChatHistory+10; i++ {
+		sendMessage(t, ws, "chat-message", map[string]interface{}{
+			"roomId": roomID,
+			"text":   strings.Repeat("x", roompkg.MaxChatLength+10),
+		})
+		readMessage(t, ws, 2*time.Second) // chat-message
+	}
+
+	room, _ := server.hub.Room(roomID)
+
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if len(room.Chat) != roompkg.MaxChatHistory {
+		t.Errorf("Expected %d chat messages, got %d", roompkg.MaxChatHistory, len(room.Chat))
+	}
+	if len(room.Chat[0].Text) != roompkg.MaxChatLength {
+		t.Errorf("Expected chat text truncated to %d, got %d", roompkg.MaxChatLength, len(room.Chat[0].Text))
+	}
+}
+
+func TestHandleSync(t *testing.T) {
+	server := New()
+
+	httpServer1, ws1 := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer ws1.Close()
+
+	httpServer2, ws2 := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer ws2.Close()
+
+	roomID := "test-room"
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Alice",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1
+
+	sendMessage(t, ws2, "join-room", map[string]interface{}{
+		"roomId": roomID,
+		"name":   "Bob",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state for ws1 (Bob joined)
+	readMessage(t, ws2, 2*time.Second) // room-state for ws2
+
+	sendMessage(t, ws2, "sync", map[string]interface{}{
+		"roomId": roomID,
+	})
+
+	msg := readMessage(t, ws2, 2*time.Second)
+	if msg.Type != "room-state" {
+		t.Fatalf("Expected room-state message, got %s", msg.Type)
+	}
+	participants := msg.Data.(map[string]interface{})["participants"].([]interface{})
+	if len(participants) != 2 {
+		t.Errorf("Expected 2 participants in synced state, got %d", len(participants))
+	}
+
+	// Only the requesting client gets the state
+	var other WebSocketMessage
+	ws1.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	if err := ws1.ReadJSON(&other); err == nil {
+		t.Errorf("Expected no message for other participants, got %s", other.Type)
+	}
+}
+
+func TestHandleSyncRequiresMembership(t *testing.T) {
+	server := New()
+
+	httpServer1, ws1 := createTestWSConnection(t, server)
+	defer httpServer1.Close()
+	defer ws1.Close()
+
+	httpServer2, outsider := createTestWSConnection(t, server)
+	defer httpServer2.Close()
+	defer outsider.Close()
+
+	sendMessage(t, ws1, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, ws1, 2*time.Second) // room-state
+
+	sendMessage(t, outsider, "sync", map[string]interface{}{
+		"roomId": "test-room",
+	})
+
+	msg := readMessage(t, outsider, 2*time.Second)
+	if msg.Type != "error" || msg.Data.(map[string]interface{})["code"] != errCodeNotMember {
+		t.Errorf("Expected a not-member error for a non-participant, got %s %v", msg.Type, msg.Data)
+	}
+}
+
+func TestRoomStateShowsLatency(t *testing.T) {
+	server := New()
+	server.showLatency = true
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": "test-room", "name": "Alice"})
+	readUntilType(t, ws, "room-state")
+
+	// The client answers the ping while reading the first sync
+	for _, client := range server.hub.Clients() {
+		client.Ping(time.Now().Add(-50 * time.Millisecond))
+	}
+	sendMessage(t, ws, "sync", map[string]interface{}{"roomId": "test-room"})
+	readUntilType(t, ws, "room-state")
+	sendMessage(t, ws, "sync", map[string]interface{}{"roomId": "test-room"})
+	msg := readUntilType(t, ws, "room-state")
+	participant := msg.Data.(map[string]interface{})["participants"].([]interface{})[0].(map[string]interface{})
+	if latency, _ := participant["latencyMs"].(float64); latency < 50 {
+		t.Errorf("Expected Alice's latency in the room state, got %v", participant)
+	}
+}
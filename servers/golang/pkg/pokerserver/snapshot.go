@@ -0,0 +1,488 @@
+package pokerserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/hub"
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/redis/go-redis/v9"
+)
+
+// SnapshotStore keeps the latest snapshot of all rooms so they survive a
+// restart. LoadSnapshot returns nil when there is nothing to restore.
+type SnapshotStore interface {
+	SaveSnapshot(ctx context.Context, snapshot []byte) error
+	LoadSnapshot(ctx context.Context) ([]byte, error)
+}
+
+// roomSnapshotStore is implemented by snapshot stores that instances share.
+// They keep each room's snapshot under its ID, so an instance saving its rooms
+// leaves those of the others alone, and LoadSnapshot returns all of them.
+type roomSnapshotStore interface {
+	SaveRoomSnapshot(ctx context.Context, roomID string, snapshot []byte) error
+	DeleteRoomSnapshot(ctx context.Context, roomID string) error
+}
+
+// roomSnapshot is the persisted form of a room, including the fields the
+// client-facing JSON leaves out.
+type roomSnapshot struct {
+	ID            string                `json:"id"`
+	Info          roompkg.Info          `json:"info"`
+	Participants  []participantSnapshot `json:"participants"`
+	Revealed      bool                  `json:"revealed"`
+	LastRound     *roompkg.LastRound    `json:"lastRound,omitempty"`
+	Story         *roompkg.Story        `json:"story,omitempty"`
+	Chat          []roompkg.ChatMessage `json:"chat,omitempty"`
+	History       []RoundRecord         `json:"history,omitempty"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	LastActivity  time.Time             `json:"lastActivity"`
+	FacilitatorID string                `json:"facilitatorId,omitempty"`
+	Passcode      []byte                `json:"passcode,omitempty"`
+	Bans          *bansSnapshot         `json:"bans,omitempty"`
+	// Rooms saved before modes existed have no settings and vote in points
+	Settings         roompkg.Settings      `json:"settings"`
+	SecondRevealed   bool                  `json:"secondRevealed,omitempty"`
+	RevealPolicy     roompkg.RevealPolicy  `json:"revealPolicy"`
+	AllowVoteChanges bool                  `json:"allowVoteChanges,omitempty"`
+	Delphi           *roompkg.Delphi       `json:"delphi,omitempty"`
+	Deadline         *time.Time            `json:"deadline,omitempty"`
+	Batch            []roompkg.BatchStory  `json:"batch,omitempty"`
+	RoundStartedAt   time.Time             `json:"roundStartedAt"`
+	TeamID           string                `json:"teamId,omitempty"`
+	TeamScoped       bool                  `json:"teamScoped,omitempty"`
+	Backlog          []roompkg.Story       `json:"backlog,omitempty"`
+	Audit            []roompkg.AuditEntry  `json:"audit,omitempty"`
+	Events           []roompkg.EventRecord `json:"events,omitempty"`
+	Recurrence       *roompkg.Recurrence   `json:"recurrence,omitempty"`
+	// BroadcastSeq carries the numbering of broadcasts on to the room's next
+	// owner, so its clients don't see it start over
+	BroadcastSeq int64 `json:"broadcastSeq,omitempty"`
+}
+
+type participantSnapshot struct {
+	roompkg.Participant
+	SessionToken string `json:"sessionToken,omitempty"`
+	// VotedAt is kept so a restored round is still timed
+	VotedAt time.Time `json:"votedAt"`
+}
+
+// bansSnapshot leaves out address bans: their hashes are keyed per process and
+// wouldn't match after a restart.
+type bansSnapshot struct {
+	SessionTokens  []string `json:"sessionTokens,omitempty"`
+	ParticipantIDs []string `json:"participantIds,omitempty"`
+}
+
+// snapshotRooms serializes every room.
+func (s *Server) snapshotRooms() ([]byte, error) {
+	rooms := s.hub.Rooms()
+
+	snapshots := make([]json.RawMessage, 0, len(rooms))
+	for _, room := range rooms {
+		// Encode under the lock, as the snapshot shares the room's stories and rounds
+		room.Mu.RLock()
+		data, err := json.Marshal(newRoomSnapshot(room))
+		room.Mu.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, data)
+	}
+	return json.Marshal(snapshots)
+}
+
+// newRoomSnapshot captures a room. The caller must hold room.Mu.
+func newRoomSnapshot(room *roompkg.State) roomSnapshot {
+	snapshot := roomSnapshot{
+		ID:            room.ID,
+		Info:          room.Info,
+		Participants:  make([]participantSnapshot, 0, len(room.Participants)),
+		Revealed:      room.Revealed,
+		LastRound:     room.LastRound,
+		Story:         room.Story,
+		Chat:          room.Chat,
+		History:       room.History,
+		CreatedAt:     room.CreatedAt,
+		LastActivity:  room.LastActivity,
+		FacilitatorID: room.FacilitatorID,
+		Passcode:      room.Passcode,
+
+		Settings:         room.Settings,
+		SecondRevealed:   room.SecondRevealed,
+		RevealPolicy:     room.RevealPolicy,
+		AllowVoteChanges: room.AllowVoteChanges,
+		Delphi:           room.Delphi,
+		Batch:            room.Batch,
+		RoundStartedAt:   room.RoundStartedAt,
+		TeamID:           room.TeamID,
+		TeamScoped:       room.TeamScoped,
+		Backlog:          room.Backlog,
+		Audit:            room.Audit,
+		Events:           room.Events,
+		Recurrence:       room.Recurrence,
+		BroadcastSeq:     room.BroadcastSeq.Load(),
+	}
+	if roompkg.AsyncRoundOpen(room) {
+		deadline := room.Deadline
+		snapshot.Deadline = &deadline
+	}
+	for _, p := range room.Participants {
+		snapshot.Participants = append(snapshot.Participants, participantSnapshot{Participant: *p, SessionToken: p.SessionToken, VotedAt: p.VotedAt})
+	}
+	if bans := room.Bans; len(bans.SessionTokens)+len(bans.ParticipantIDs) > 0 {
+		snapshot.Bans = &bansSnapshot{
+			SessionTokens:  mapKeys(bans.SessionTokens),
+			ParticipantIDs: mapKeys(bans.ParticipantIDs),
+		}
+	}
+	return snapshot
+}
+
+// restoreRooms recreates the rooms in a snapshot. Everyone starts offline and
+// reclaims their seat, vote included, by rejoining within the grace period.
+// Rooms that already exist are left alone.
+func (s *Server) restoreRooms(data []byte) (int, error) {
+	var snapshots []roomSnapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return 0, fmt.Errorf("decoding snapshot: %w", err)
+	}
+
+	restored := 0
+	for _, snapshot := range snapshots {
+		if s.restoreRoom(snapshot) {
+			restored++
+		}
+	}
+	return restored, nil
+}
+
+// restoreRoom recreates one room unless it is empty, already exists or, when
+// rooms are routed to their owners, is held by another instance.
+func (s *Server) restoreRoom(snapshot roomSnapshot) bool {
+	if len(snapshot.Participants) == 0 && snapshot.Recurrence == nil {
+		return false
+	}
+	if s.ownershipEnabled() {
+		owner, err := s.claimRoom(s.ctx, snapshot.ID)
+		if err != nil {
+			s.logger.Printf("Error claiming room %s, restoring it anyway: %v", snapshot.ID, err)
+		} else if owner != s.instanceID {
+			return false
+		}
+	}
+
+	room := &roompkg.State{
+		ID:            snapshot.ID,
+		Info:          snapshot.Info,
+		Participants:  make(map[string]*roompkg.Participant, len(snapshot.Participants)),
+		Revealed:      snapshot.Revealed,
+		LastRound:     snapshot.LastRound,
+		Story:         snapshot.Story,
+		Chat:          snapshot.Chat,
+		History:       snapshot.History,
+		CreatedAt:     snapshot.CreatedAt,
+		LastActivity:  snapshot.LastActivity,
+		FacilitatorID: snapshot.FacilitatorID,
+		Passcode:      snapshot.Passcode,
+
+		Settings:         snapshot.Settings,
+		SecondRevealed:   snapshot.SecondRevealed,
+		RevealPolicy:     snapshot.RevealPolicy,
+		AllowVoteChanges: snapshot.AllowVoteChanges,
+		Delphi:           snapshot.Delphi,
+		Batch:            snapshot.Batch,
+		RoundStartedAt:   snapshot.RoundStartedAt,
+		TeamID:           snapshot.TeamID,
+		TeamScoped:       snapshot.TeamScoped,
+		Backlog:          snapshot.Backlog,
+		Audit:            snapshot.Audit,
+		Events:           snapshot.Events,
+		Recurrence:       snapshot.Recurrence,
+		Clock:            s.clock,
+		Firehose:         s.firehose,
+		Spectators:       roompkg.NewFirehose(),
+	}
+	if snapshot.Deadline != nil {
+		room.Deadline = *snapshot.Deadline
+	}
+	if room.Settings.Mode == "" {
+		room.Settings.Mode = roompkg.ModePoints
+	}
+	if room.RevealPolicy.Mode == "" {
+		room.RevealPolicy.Mode = roompkg.RevealAnyone
+	}
+	if n := len(room.Events); n > 0 {
+		room.EventSeq = room.Events[n-1].Seq
+	}
+	room.BroadcastSeq.Store(snapshot.BroadcastSeq)
+	for _, p := range snapshot.Participants {
+		participant := p.Participant
+		participant.SessionToken = p.SessionToken
+		participant.VotedAt = p.VotedAt
+		room.Participants[participant.ID] = &participant
+	}
+	// Everyone is offline until they rejoin
+	room.Mu.Lock()
+	for id, p := range room.Participants {
+		if p.Online {
+			room.Apply(&roompkg.PresenceEvent{ClientID: id, Online: false})
+		}
+	}
+	room.Mu.Unlock()
+	if snapshot.Bans != nil {
+		room.Bans = roompkg.RoomBans{
+			SessionTokens:  setOf(snapshot.Bans.SessionTokens),
+			ParticipantIDs: setOf(snapshot.Bans.ParticipantIDs),
+			IPHashes:       make(map[string]bool),
+		}
+	}
+
+	if !s.hub.AddRoom(room) {
+		return false
+	}
+
+	// An async round that closed while the server was down is revealed now
+	room.Mu.Lock()
+	if snapshot.Deadline != nil {
+		s.scheduleDeadline(room, *snapshot.Deadline)
+	}
+	s.scheduleStart(room)
+	s.scheduleRecurrence(room, room.Recurrence)
+	room.Mu.Unlock()
+	for id := range room.Participants {
+		s.scheduleParticipantCleanup(room.ID, id)
+	}
+	return true
+}
+
+// restoreSnapshot loads the rooms saved by the previous run, if any.
+func (s *Server) restoreSnapshot() {
+	data, err := s.snapshots.LoadSnapshot(s.ctx)
+	if err != nil {
+		s.logger.Printf("Error loading snapshot: %v", err)
+		return
+	}
+	if data == nil {
+		return
+	}
+
+	restored, err := s.restoreRooms(data)
+	if err != nil {
+		s.logger.Printf("Error restoring snapshot: %v", err)
+		return
+	}
+	s.logger.Printf("✓ Restored %d rooms from snapshot", restored)
+}
+
+func (s *Server) saveSnapshot(ctx context.Context) {
+	if store, ok := s.snapshots.(roomSnapshotStore); ok {
+		s.saveRoomSnapshots(ctx, store)
+		return
+	}
+
+	data, err := s.snapshotRooms()
+	if err != nil {
+		s.logger.Printf("Error creating snapshot: %v", err)
+		return
+	}
+	if err := s.snapshots.SaveSnapshot(ctx, data); err != nil {
+		s.logger.Printf("Error saving snapshot: %v", err)
+	}
+}
+
+// saveRoomSnapshots saves each room of this instance on its own, and deletes
+// those it saved before and no longer holds, unless they are open elsewhere.
+func (s *Server) saveRoomSnapshots(ctx context.Context, store roomSnapshotStore) {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+
+	rooms := s.hub.Rooms()
+	saved := make(map[string]bool, len(rooms))
+	for _, room := range rooms {
+		saved[room.ID] = true
+		room.Mu.RLock()
+		data, err := json.Marshal(newRoomSnapshot(room))
+		room.Mu.RUnlock()
+		if err == nil {
+			err = store.SaveRoomSnapshot(ctx, room.ID, data)
+		}
+		if err != nil {
+			s.logger.Printf("Error saving snapshot of room %s: %v", room.ID, err)
+		}
+	}
+
+	for roomID := range s.snapshotted {
+		if saved[roomID] {
+			continue
+		}
+		// The room may have been handed over rather than closed
+		if exists, err := s.roomExists(ctx, roomID); err != nil || exists {
+			continue
+		}
+		if err := store.DeleteRoomSnapshot(ctx, roomID); err != nil {
+			s.logger.Printf("Error deleting snapshot of room %s: %v", roomID, err)
+			saved[roomID] = true
+		}
+	}
+	s.snapshotted = saved
+}
+
+// startSnapshots saves a snapshot every interval until shutdown, which saves
+// a final one. Without an interval only the final snapshot is taken.
+func (s *Server) startSnapshots(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.saveSnapshot(s.ctx)
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// newSnapshotStoreFromConfig returns nil when snapshots aren't configured.
+func newSnapshotStoreFromConfig(cfg Config) (SnapshotStore, error) {
+	switch {
+	case cfg.SnapshotFile != "":
+		return &fileSnapshotStore{path: cfg.SnapshotFile}, nil
+	case cfg.SnapshotRedisKey != "":
+		opt, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+		}
+		cipher, err := hub.NewRedisCipher(cfg.RedisEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("redis_encryption_key: %w", err)
+		}
+		return &redisSnapshotStore{client: redis.NewClient(opt), key: cfg.SnapshotRedisKey, cipher: cipher}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// fileSnapshotStore writes snapshots to a file, replacing it atomically so a
+// crash mid-write leaves the previous snapshot intact.
+type fileSnapshotStore struct {
+	path string
+}
+
+func (f *fileSnapshotStore) SaveSnapshot(ctx context.Context, snapshot []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(snapshot); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (f *fileSnapshotStore) LoadSnapshot(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// redisSnapshotStore keeps snapshots in a Redis hash, a field per room, so
+// the instances sharing it each save their own rooms.
+type redisSnapshotStore struct {
+	client *redis.Client
+	key    string
+	cipher *hub.RedisCipher
+}
+
+// SaveSnapshot replaces every room's snapshot with those in snapshot.
+func (r *redisSnapshotStore) SaveSnapshot(ctx context.Context, snapshot []byte) error {
+	var rooms []json.RawMessage
+	if err := json.Unmarshal(snapshot, &rooms); err != nil {
+		return err
+	}
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, r.key)
+	for _, data := range rooms {
+		var room struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(data, &room); err != nil {
+			return err
+		}
+		pipe.HSet(ctx, r.key, room.ID, r.cipher.Seal(data, r.fieldKey(room.ID)))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *redisSnapshotStore) SaveRoomSnapshot(ctx context.Context, roomID string, snapshot []byte) error {
+	return r.client.HSet(ctx, r.key, roomID, r.cipher.Seal(snapshot, r.fieldKey(roomID))).Err()
+}
+
+func (r *redisSnapshotStore) DeleteRoomSnapshot(ctx context.Context, roomID string) error {
+	return r.client.HDel(ctx, r.key, roomID).Err()
+}
+
+func (r *redisSnapshotStore) Close() error {
+	return r.client.Close()
+}
+
+func (r *redisSnapshotStore) LoadSnapshot(ctx context.Context) ([]byte, error) {
+	fields, err := r.client.HGetAll(ctx, r.key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	rooms := make([]json.RawMessage, 0, len(fields))
+	for roomID, sealed := range fields {
+		data, err := r.cipher.Open([]byte(sealed), r.fieldKey(roomID))
+		if err != nil {
+			return nil, fmt.Errorf("room %s: %w", roomID, err)
+		}
+		rooms = append(rooms, data)
+	}
+	return json.Marshal(rooms)
+}
+
+// fieldKey binds a room's sealed snapshot to the room, so it can't be passed
+// off as another's.
+func (r *redisSnapshotStore) fieldKey(roomID string) string {
+	return r.key + ":" + roomID
+}
+
+func mapKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func setOf(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
@@ -0,0 +1,213 @@
+package pokerserver
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestSnapshotRestoresRooms(t *testing.T) {
+	store := &fileSnapshotStore{path: filepath.Join(t.TempDir(), "rooms.json")}
+
+	before := New(WithSnapshotStore(store))
+	room := before.getOrCreateRoom("test-room")
+	vote := "5"
+	room.Participants["old-client"] = &roompkg.Participant{
+		ID:            "old-client",
+		Name:          "Alice",
+		Vote:          &vote,
+		ParticipantId: "alice",
+		Online:        true,
+		SessionToken:  "token",
+	}
+	room.Story = &roompkg.Story{Title: "Login page"}
+	room.FacilitatorID = "old-client"
+	room.Bans.Add(&roompkg.Participant{ParticipantId: "mallory"}, "")
+	room.BroadcastSeq.Store(41)
+	if err := before.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	after := New(WithSnapshotStore(store))
+	if err := after.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer after.Shutdown(context.Background())
+
+	restored, ok := after.hub.Room("test-room")
+	if !ok {
+		t.Fatal("Expected room to be restored")
+	}
+	if p := restored.Participants["old-client"]; p == nil || p.Online || p.SessionToken != "token" {
+		t.Errorf("Expected Alice restored offline with her session, got %+v", p)
+	}
+	if restored.Story == nil || restored.Story.Title != "Login page" {
+		t.Errorf("Expected story to be restored, got %+v", restored.Story)
+	}
+	if !restored.Bans.Matches("", "mallory", "") {
+		t.Error("Expected bans to be restored")
+	}
+	if seq := restored.BroadcastSeq.Load(); seq != 41 {
+		t.Errorf("Expected broadcasts to go on from 41, got %d", seq)
+	}
+
+	// Alice reconnects and gets her vote back
+	httpServer, ws := createTestWSConnection(t, after)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId":        "test-room",
+		"name":          "Alice",
+		"participantId": "alice",
+	})
+	msg := readMessage(t, ws, 2*time.Second)
+	participants := msg.Data.(map[string]interface{})["participants"].([]interface{})
+	if len(participants) != 1 {
+		t.Fatalf("Expected Alice to reclaim her seat, got %v", participants)
+	}
+	if p := participants[0].(map[string]interface{}); p["vote"] != "5" || p["online"] != true {
+		t.Errorf("Expected Alice online with her vote, got %v", p)
+	}
+}
+
+func TestRestoreKeepsExistingRooms(t *testing.T) {
+	server := New()
+	server.getOrCreateRoom("test-room").Participants["live"] = &roompkg.Participant{ID: "live", Name: "Bob", Online: true}
+
+	data := []byte(`[{"id":"test-room","participants":[{"id":"old","name":"Alice"}]},{"id":"empty","participants":[]}]`)
+	restored, err := server.restoreRooms(data)
+	if err != nil {
+		t.Fatalf("restoreRooms failed: %v", err)
+	}
+	if restored != 0 {
+		t.Errorf("Expected nothing restored, got %d", restored)
+	}
+	if room, _ := server.hub.Room("test-room"); room.Participants["live"] == nil {
+		t.Error("Expected the live room to be kept")
+	}
+	if _, ok := server.hub.Room("empty"); ok {
+		t.Error("Expected empty rooms to be skipped")
+	}
+
+	if _, err := server.restoreRooms([]byte("not json")); err == nil {
+		t.Error("Expected an invalid snapshot to fail")
+	}
+}
+
+func TestFileSnapshotStoreMissingFile(t *testing.T) {
+	store := &fileSnapshotStore{path: filepath.Join(t.TempDir(), "rooms.json")}
+	data, err := store.LoadSnapshot(context.Background())
+	if err != nil || data != nil {
+		t.Errorf("Expected no snapshot, got %q, %v", data, err)
+	}
+}
+
+// memorySnapshotStore keeps snapshots per room, like the Redis store.
+type memorySnapshotStore struct {
+	mu    sync.Mutex
+	rooms map[string][]byte
+}
+
+func newMemorySnapshotStore() *memorySnapshotStore {
+	return &memorySnapshotStore{rooms: make(map[string][]byte)}
+}
+
+func (m *memorySnapshotStore) SaveSnapshot(ctx context.Context, snapshot []byte) error {
+	var rooms []roomSnapshot
+	if err := json.Unmarshal(snapshot, &rooms); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rooms = make(map[string][]byte)
+	for _, room := range rooms {
+		data, _ := json.Marshal(room)
+		m.rooms[room.ID] = data
+	}
+	return nil
+}
+
+func (m *memorySnapshotStore) SaveRoomSnapshot(ctx context.Context, roomID string, snapshot []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rooms[roomID] = snapshot
+	return nil
+}
+
+func (m *memorySnapshotStore) DeleteRoomSnapshot(ctx context.Context, roomID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rooms, roomID)
+	return nil
+}
+
+func (m *memorySnapshotStore) LoadSnapshot(ctx context.Context) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rooms := make([]json.RawMessage, 0, len(m.rooms))
+	for _, data := range m.rooms {
+		rooms = append(rooms, data)
+	}
+	return json.Marshal(rooms)
+}
+
+func TestSnapshotsOfSeveralInstances(t *testing.T) {
+	broker := NewMemoryBroker()
+	ownership := newMemoryOwnership()
+	store := newMemorySnapshotStore()
+	first := New(WithBroker(broker), WithRoomOwnership(ownership), WithSnapshotStore(store))
+	second := New(WithBroker(broker), WithRoomOwnership(ownership), WithSnapshotStore(store))
+	for _, server := range []*Server{first, second} {
+		if err := server.Initialize(); err != nil {
+			t.Fatalf("Failed to initialize server: %v", err)
+		}
+		defer server.Shutdown(context.Background())
+	}
+
+	httpServer1, alice := createTestWSConnection(t, first)
+	defer httpServer1.Close()
+	defer alice.Close()
+	httpServer2, bob := createTestWSConnection(t, second)
+	defer httpServer2.Close()
+	defer bob.Close()
+
+	sendMessage(t, alice, "join-room", map[string]interface{}{"roomId": "first-room", "name": "Alice"})
+	readRoomStateUntil(t, alice, func(p []interface{}) bool { return len(p) == 1 })
+	sendMessage(t, bob, "join-room", map[string]interface{}{"roomId": "second-room", "name": "Bob"})
+	readRoomStateUntil(t, bob, func(p []interface{}) bool { return len(p) == 1 })
+
+	// Each instance saving its rooms leaves those of the other in place
+	first.saveSnapshot(context.Background())
+	second.saveSnapshot(context.Background())
+	first.saveSnapshot(context.Background())
+	store.mu.Lock()
+	if len(store.rooms) != 2 || store.rooms["first-room"] == nil || store.rooms["second-room"] == nil {
+		t.Errorf("Expected both rooms saved, got %d", len(store.rooms))
+	}
+	store.mu.Unlock()
+
+	// A restarted instance leaves the rooms others hold to them
+	third := New(WithBroker(broker), WithRoomOwnership(ownership), WithSnapshotStore(store))
+	if err := third.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	defer third.Shutdown(context.Background())
+	if rooms := third.hub.Rooms(); len(rooms) != 0 {
+		t.Errorf("Expected no room restored while leased elsewhere, got %d", len(rooms))
+	}
+
+	ownership.Release(context.Background(), "second-room", second.instanceID)
+	third.restoreSnapshot()
+	if _, ok := third.hub.Room("second-room"); !ok {
+		t.Error("Expected the released room to be restored")
+	}
+	if _, ok := third.hub.Room("first-room"); ok {
+		t.Error("Expected the room still leased to stay with its owner")
+	}
+}
@@ -0,0 +1,151 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// Socket.IO compatibility: the endpoint speaks Engine.IO v4 over the
+// websocket transport only, carrying Socket.IO v5 packets on the default
+// namespace. Clients must connect with transports: ["websocket"].
+const socketIOPath = "/socket.io/"
+
+func (s *Server) handleSocketIO(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("EIO") != "4" {
+		writeEngineIOError(w, 5, "Unsupported protocol version")
+		return
+	}
+	if query.Get("transport") != "websocket" {
+		writeEngineIOError(w, 0, "Transport unknown")
+		return
+	}
+
+	ip, release, ok := s.acceptConnection(w, r)
+	if !ok {
+		return
+	}
+	defer release()
+	identity, ok := s.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Printf("Error upgrading to websocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	ws := s.registerClient(conn, ip, identity, transport.SocketIOCodec{})
+
+	handshake, _ := json.Marshal(transport.EngineIOHandshake{
+		SID:          generateToken(),
+		Upgrades:     []string{},
+		PingInterval: heartbeatInterval.Milliseconds(),
+		PingTimeout:  transport.EngineIOPingTimeout,
+		MaxPayload:   transport.EngineIOMaxPayload,
+	})
+	if err := ws.WriteMessage(websocket.TextMessage, append([]byte{transport.EngineOpen}, handshake...)); err != nil {
+		s.logger.Printf("Error sending Engine.IO handshake to %s: %v", ws.ID, err)
+	}
+
+	connected := false
+	for {
+		messageType, payload, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				s.logger.Printf("WebSocket error: %v", err)
+			}
+			ws.ReadErr = err
+			break
+		}
+		// Binary attachments are not supported
+		if messageType != websocket.TextMessage || len(payload) == 0 {
+			continue
+		}
+
+		if !s.handleEngineIOPacket(ws, string(payload), &connected) {
+			break
+		}
+	}
+
+	s.handleClientDisconnect(ws)
+}
+
+// handleEngineIOPacket processes one Engine.IO packet and reports whether the
+// connection should stay open.
+func (s *Server) handleEngineIOPacket(ws *ExtendedWebSocket, packet string, connected *bool) bool {
+	switch packet[0] {
+	case transport.EnginePing:
+		ws.WriteMessage(websocket.TextMessage, []byte{transport.EnginePong})
+	case transport.EnginePong:
+		ws.IsAlive.Store(true)
+		ws.RecordPong(nil, s.clock.Now())
+	case transport.EngineClose:
+		return false
+	case transport.EngineMessage:
+		return s.handleSocketIOPacket(ws, packet[1:], connected)
+	}
+	return true
+}
+
+func (s *Server) handleSocketIOPacket(ws *ExtendedWebSocket, packet string, connected *bool) bool {
+	if packet == "" {
+		return true
+	}
+	packetType, namespace, ackID, payload := transport.ParseSocketIOPacket(packet)
+
+	if namespace != "/" {
+		data, _ := json.Marshal(map[string]string{"message": "Invalid namespace"})
+		transport.SendSocketIOPacket(ws, transport.SocketConnectError, namespace+","+string(data))
+		return true
+	}
+
+	switch packetType {
+	case transport.SocketConnect:
+		data, _ := json.Marshal(map[string]string{"sid": ws.ID})
+		transport.SendSocketIOPacket(ws, transport.SocketConnect, string(data))
+		*connected = true
+	case transport.SocketDisconnect:
+		return false
+	case transport.SocketEvent:
+		if !*connected {
+			return true
+		}
+
+		var args []json.RawMessage
+		var event string
+		if err := json.Unmarshal([]byte(payload), &args); err != nil || len(args) == 0 || json.Unmarshal(args[0], &event) != nil {
+			s.logger.Printf("❌ Invalid Socket.IO event from %s", ws.ID)
+			return true
+		}
+
+		var data interface{}
+		if len(args) > 1 {
+			json.Unmarshal(args[1], &data)
+		}
+		message := WebSocketMessage{Type: event, Data: data}
+		if err := transport.CheckMessage(message); err != nil {
+			s.logger.Printf("⚠️ Dropping Socket.IO event from %s: %v", ws.ID, err)
+		} else {
+			s.handleMessage(ws, message)
+		}
+
+		// Acknowledge after the messages the event produced
+		if ackID != "" {
+			s.enqueueFrame(ws, transport.SocketIOPacket(transport.SocketAck, ackID+"[]"))
+		}
+	default:
+		s.logger.Printf("Unsupported Socket.IO packet type %q from %s", packetType, ws.ID)
+	}
+	return true
+}
+
+func writeEngineIOError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{"code": code, "message": message})
+}
@@ -0,0 +1,174 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+func dialSocketIO(t *testing.T, server *Server) (*httptest.Server, *websocket.Conn) {
+	httpServer := httptest.NewServer(server.Handler())
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/socket.io/?EIO=4&transport=websocket"
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		httpServer.Close()
+		t.Fatalf("Failed to connect to Socket.IO endpoint: %v", err)
+	}
+	return httpServer, conn
+}
+
+func readPacket(t *testing.T, conn *websocket.Conn) string {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, payload, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Failed to read packet: %v", err)
+	}
+	return string(payload)
+}
+
+func writePacket(t *testing.T, conn *websocket.Conn, packet string) {
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(packet)); err != nil {
+		t.Fatalf("Failed to write packet: %v", err)
+	}
+}
+
+func TestSocketIOHandshakeAndEvents(t *testing.T) {
+	server := New(WithSocketIO(true))
+	httpServer, conn := dialSocketIO(t, server)
+	defer httpServer.Close()
+	defer conn.Close()
+
+	open := readPacket(t, conn)
+	var handshake transport.EngineIOHandshake
+	if !strings.HasPrefix(open, "0") || json.Unmarshal([]byte(open[1:]), &handshake) != nil {
+		t.Fatalf("Expected Engine.IO open packet, got %q", open)
+	}
+	if handshake.SID == "" || handshake.PingInterval != heartbeatInterval.Milliseconds() {
+		t.Errorf("Unexpected handshake: %+v", handshake)
+	}
+
+	writePacket(t, conn, "40")
+	if connect := readPacket(t, conn); !strings.HasPrefix(connect, `40{"sid":`) {
+		t.Fatalf("Expected Socket.IO connect ack, got %q", connect)
+	}
+
+	writePacket(t, conn, `421["join-room",{"roomId":"test-room","name":"Alice"}]`)
+
+	event := readPacket(t, conn)
+	if !strings.HasPrefix(event, "42") {
+		t.Fatalf("Expected Socket.IO event, got %q", event)
+	}
+	var args []json.RawMessage
+	if err := json.Unmarshal([]byte(event[2:]), &args); err != nil || len(args) != 2 {
+		t.Fatalf("Failed to decode event %q: %v", event, err)
+	}
+	if string(args[0]) != `"room-state"` {
+		t.Errorf("Expected room-state event, got %s", args[0])
+	}
+	var state struct {
+		Participants []roompkg.Participant `json:"participants"`
+	}
+	json.Unmarshal(args[1], &state)
+	if len(state.Participants) != 1 || state.Participants[0].Name != "Alice" {
+		t.Errorf("Unexpected room state: %s", args[1])
+	}
+
+	if ack := readPacket(t, conn); ack != "431[]" {
+		t.Errorf("Expected ack for event 1, got %q", ack)
+	}
+
+	// Engine.IO pings from the client are answered with a pong
+	writePacket(t, conn, "2")
+	if pong := readPacket(t, conn); pong != "3" {
+		t.Errorf("Expected pong, got %q", pong)
+	}
+}
+
+func TestSocketIOInvalidNamespace(t *testing.T) {
+	server := New(WithSocketIO(true))
+	httpServer, conn := dialSocketIO(t, server)
+	defer httpServer.Close()
+	defer conn.Close()
+
+	readPacket(t, conn) // open
+	writePacket(t, conn, "40/admin,")
+	if reply := readPacket(t, conn); !strings.HasPrefix(reply, "44/admin,") {
+		t.Errorf("Expected connect error for unknown namespace, got %q", reply)
+	}
+}
+
+func TestSocketIOClientsReceiveBroadcasts(t *testing.T) {
+	server := New(WithSocketIO(true))
+
+	httpServer, conn := dialSocketIO(t, server)
+	defer httpServer.Close()
+	defer conn.Close()
+	readPacket(t, conn) // open
+	writePacket(t, conn, "40")
+	readPacket(t, conn) // connect ack
+	writePacket(t, conn, `42["join-room",{"roomId":"test-room","name":"Alice"}]`)
+	readPacket(t, conn) // room-state
+
+	// A plain WebSocket client in the same room
+	wsServer, ws := createTestWSConnection(t, server)
+	defer wsServer.Close()
+	defer ws.Close()
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Bob",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+	readPacket(t, conn)               // room-state with Bob
+
+	sendMessage(t, ws, "vote", map[string]interface{}{
+		"roomId": "test-room",
+		"vote":   "5",
+	})
+	if event := readPacket(t, conn); !strings.HasPrefix(event, `42["participant-voted",`) {
+		t.Errorf("Expected participant-voted event, got %q", event)
+	}
+}
+
+func TestSocketIORejectsUnsupportedTransports(t *testing.T) {
+	handler := New(WithSocketIO(true)).Handler()
+
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"EIO=4&transport=polling", 0},
+		{"EIO=3&transport=websocket", 5},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/socket.io/?"+tt.query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", tt.query, rec.Code)
+		}
+		var body struct {
+			Code int `json:"code"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		if body.Code != tt.code {
+			t.Errorf("%s: expected Engine.IO error code %d, got %d", tt.query, tt.code, body.Code)
+		}
+	}
+}
+
+func TestSocketIODisabledByDefault(t *testing.T) {
+	handler := New(WithSocketIO(false)).Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/socket.io/?EIO=4&transport=websocket", nil))
+	if rec.Code == http.StatusBadRequest || rec.Code == http.StatusSwitchingProtocols {
+		t.Errorf("Expected Socket.IO endpoint to be unmounted, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,151 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// Spectator links let stakeholders watch a room's estimation live without
+// joining it. Their token is signed like an invite, so it ends with the room
+// session, and it only opens the read-only stream at /api/rooms/{id}/watch.
+
+// SpectatorLink is returned when a spectator link is created.
+type SpectatorLink struct {
+	RoomID    string    `json:"roomId"`
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// handleCreateSpectatorLink issues a token for watching a room. Private rooms
+// ask for their passcode or the admin token, as invites do.
+func (s *Server) handleCreateSpectatorLink(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("id")
+	room, ok := s.hub.Room(roomID)
+	if !ok {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	if !s.isAdminRequest(r) && !s.authorizeRoomRead(w, r, room.PasscodeHash()) {
+		return
+	}
+	ttl, ok := readInviteTTL(w, r)
+	if !ok {
+		return
+	}
+
+	room.Mu.RLock()
+	createdAt := room.CreatedAt
+	room.Mu.RUnlock()
+
+	// Tokens carry whole seconds
+	expiresAt := s.clock.Now().Add(ttl).Truncate(time.Second)
+	token := s.signRoomToken(invitePayload{RoomID: roomID, ExpiresAt: expiresAt.Unix(), Watch: true}, createdAt)
+	link := SpectatorLink{
+		RoomID:    roomID,
+		Token:     token,
+		URL:       "/api/rooms/" + roomID + "/watch?token=" + token,
+		ExpiresAt: expiresAt,
+	}
+	s.logger.Printf("👀 Spectator link created for room %s, expires %s", roomID, expiresAt.Format(time.RFC3339))
+
+	writeJSON(w, http.StatusCreated, link)
+}
+
+// handleWatchRoom streams a room to a spectator: its state when they connect,
+// then the broadcasts in spectatorMessages. Spectators aren't participants,
+// so nobody sees them, and every message they send is refused.
+func (s *Server) handleWatchRoom(w http.ResponseWriter, r *http.Request) {
+	roomID := r.PathValue("id")
+	room, ok := s.hub.Room(roomID)
+	if !ok {
+		http.Error(w, "room not found", http.StatusNotFound)
+		return
+	}
+	room.Mu.RLock()
+	createdAt := room.CreatedAt
+	room.Mu.RUnlock()
+	if err := s.verifyRoomToken(r.URL.Query().Get("token"), roomID, createdAt, true); err != nil {
+		http.Error(w, "invalid spectator link", http.StatusForbidden)
+		return
+	}
+
+	ip, release, ok := s.acceptConnection(w, r)
+	if !ok {
+		return
+	}
+	defer release()
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Printf("Error upgrading spectator stream: %v", err)
+		return
+	}
+	defer conn.Close()
+	ws := &ExtendedWebSocket{Conn: conn, RemoteIP: ip}
+	conn.SetReadLimit(maxMessageSize)
+
+	// Subscribing while no room-state goes out means the first state the
+	// spectator sees is never newer than the next one
+	room.StateMu.Lock()
+	sub := room.Spectators.Subscribe("")
+	room.Mu.RLock()
+	state := s.roomStatePayload(room)
+	room.Mu.RUnlock()
+	room.StateMu.Unlock()
+	defer room.Spectators.Unsubscribe(sub)
+	s.logger.Printf("👀 Spectator watching room %s from %s", roomID, ip)
+
+	if err := ws.WriteJSON(WebSocketMessage{Type: "room-state", Data: roompkg.SpectatorState(state)}); err != nil {
+		return
+	}
+
+	hungUp := make(chan struct{})
+	go func() {
+		defer close(hungUp)
+		for {
+			_, payload, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var message WebSocketMessage
+			json.Unmarshal(payload, &message)
+			ws.WriteJSON(WebSocketMessage{Type: "error", Data: map[string]interface{}{
+				"messageType": message.Type,
+				"code":        errCodeNotAllowed,
+				"message":     "Spectators can only watch the room",
+			}})
+		}
+	}()
+
+	heartbeat := s.clock.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case message, ok := <-sub.Events:
+			if !ok {
+				// The stream also ends when the room goes
+				if current, open := s.hub.Room(roomID); !open || current != room {
+					ws.CloseWith(transport.CloseRoomClosed, "room closed")
+				} else {
+					ws.CloseWith(transport.CloseSlowClient, "too slow to keep up")
+				}
+				return
+			}
+			if err := ws.WriteJSON(message); err != nil {
+				return
+			}
+		case <-heartbeat.C():
+			ws.Ping(s.clock.Now())
+		case <-hungUp:
+			return
+		case <-s.ctx.Done():
+			ws.CloseWith(transport.CloseShutdown, "server shutting down")
+			return
+		}
+	}
+}
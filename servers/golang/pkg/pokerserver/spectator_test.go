@@ -0,0 +1,86 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+func TestSpectatorLink(t *testing.T) {
+	server := New()
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	roomID := "watched-room"
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	awaitMessage(t, alice, "room-state")
+	alice.Send("chat-message", map[string]interface{}{"roomId": roomID, "text": "Team only"})
+	awaitMessage(t, alice, "chat-message")
+
+	resp, err := http.Post(httpServer.URL+"/api/rooms/"+roomID+"/spectator-links", "application/json", strings.NewReader(`{"ttl":"1h"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", resp.StatusCode)
+	}
+	var link SpectatorLink
+	json.NewDecoder(resp.Body).Decode(&link)
+
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	room, _ := server.hub.Room(roomID)
+	invite := server.signInvite(roomID, room.CreatedAt, time.Now().Add(time.Hour))
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL+"/api/rooms/"+roomID+"/watch?token="+invite, nil); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected an invite not to open the stream, got %v", err)
+	}
+
+	spectator, _, err := websocket.DefaultDialer.Dial(wsURL+link.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to watch the room: %v", err)
+	}
+	defer spectator.Close()
+
+	state := readMessage(t, spectator, 2*time.Second)
+	data := state.Data.(map[string]interface{})
+	if state.Type != "room-state" || len(data["participants"].([]interface{})) != 1 {
+		t.Fatalf("Expected the room's state, got %s %v", state.Type, state.Data)
+	}
+	if _, ok := data["chat"]; ok {
+		t.Error("Expected spectators not to see the chat")
+	}
+
+	sendMessage(t, spectator, "vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	msg := readMessage(t, spectator, 2*time.Second)
+	if msg.Type != "error" || msg.Data.(map[string]interface{})["code"] != errCodeNotAllowed {
+		t.Errorf("Expected the vote to be refused, got %s %v", msg.Type, msg.Data)
+	}
+
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	for msg.Type != "revealed" {
+		msg = readMessage(t, spectator, 2*time.Second)
+		if msg.Type == "room-state" && len(msg.Data.(map[string]interface{})["participants"].([]interface{})) != 1 {
+			t.Errorf("Expected the spectator not to appear as a participant, got %v", msg.Data)
+		}
+	}
+
+	server.closeRoom(roomID)
+	for {
+		spectator.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, _, err := spectator.ReadMessage(); err != nil {
+			if !websocket.IsCloseError(err, transport.CloseRoomClosed) {
+				t.Errorf("Expected the stream to end with the room, got %v", err)
+			}
+			break
+		}
+	}
+}
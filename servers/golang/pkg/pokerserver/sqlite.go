@@ -0,0 +1,29 @@
+//go:build cgo
+
+package pokerserver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// NewSQLiteStorage opens, or creates, the database file at path and creates
+// the schema if needed.
+func NewSQLiteStorage(ctx context.Context, path string) (*SQLStorage, error) {
+	// Foreign keys are off by default in SQLite, and WAL with a busy timeout
+	// lets reads carry on while a round is being saved
+	params := url.Values{
+		"_foreign_keys": {"on"},
+		"_journal_mode": {"WAL"},
+		"_busy_timeout": {"5000"},
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("opening SQLite: %w", err)
+	}
+	return newSQLStorage(ctx, db, sqliteTypes, "SQLite")
+}
@@ -0,0 +1,13 @@
+//go:build !cgo
+
+package pokerserver
+
+import (
+	"context"
+	"errors"
+)
+
+// NewSQLiteStorage is unavailable without cgo, which the SQLite driver needs.
+func NewSQLiteStorage(ctx context.Context, path string) (*SQLStorage, error) {
+	return nil, errors.New("SQLite storage requires a build with CGO_ENABLED=1")
+}
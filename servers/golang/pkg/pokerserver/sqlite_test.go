@@ -0,0 +1,30 @@
+//go:build cgo
+
+package pokerserver
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteStorage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "poker.db")
+	storage, err := NewSQLiteStorage(context.Background(), path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage failed: %v", err)
+	}
+	defer storage.Close()
+
+	testSQLStorage(t, storage)
+
+	// Reopening keeps the data and doesn't trip over the existing schema
+	reopened, err := NewSQLiteStorage(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Reopening failed: %v", err)
+	}
+	defer reopened.Close()
+	if _, rounds, err := reopened.LoadSession(context.Background(), "missing"); err != ErrSessionNotFound || rounds != nil {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+}
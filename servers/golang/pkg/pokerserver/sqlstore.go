@@ -0,0 +1,399 @@
+package pokerserver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// storageSchema is written for Postgres; sqliteTypes adapts it for SQLite so
+// both backends keep the same tables.
+const storageSchema = `
+CREATE TABLE IF NOT EXISTS poker_sessions (
+	room_id       TEXT NOT NULL,
+	started_at    TIMESTAMPTZ NOT NULL,
+	passcode_hash BYTEA,
+	PRIMARY KEY (room_id, started_at)
+);
+
+CREATE TABLE IF NOT EXISTS poker_rounds (
+	room_id        TEXT NOT NULL,
+	started_at     TIMESTAMPTZ NOT NULL,
+	round_id       TEXT NOT NULL,
+	story          JSONB,
+	stats          JSONB NOT NULL,
+	final_estimate TEXT NOT NULL DEFAULT '',
+	revealed_at    TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (room_id, started_at, round_id),
+	FOREIGN KEY (room_id, started_at) REFERENCES poker_sessions ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS poker_votes (
+	room_id     TEXT NOT NULL,
+	started_at  TIMESTAMPTZ NOT NULL,
+	round_id    TEXT NOT NULL,
+	position    INTEGER NOT NULL,
+	participant TEXT NOT NULL,
+	vote        TEXT NOT NULL,
+	PRIMARY KEY (room_id, started_at, round_id, position),
+	FOREIGN KEY (room_id, started_at, round_id) REFERENCES poker_rounds ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS poker_rounds_revealed_at ON poker_rounds (revealed_at);
+
+CREATE TABLE IF NOT EXISTS poker_schema_version (
+	version INTEGER NOT NULL
+);
+`
+
+// storageMigrations bring tables created from an earlier storageSchema up to
+// date. They run once each, in order, and poker_schema_version counts how
+// many have.
+var storageMigrations = []string{
+	// details keep the whole round or vote as JSON, so fields without a
+	// column of their own survive a reload
+	`ALTER TABLE poker_rounds ADD COLUMN details JSONB`,
+	`ALTER TABLE poker_votes ADD COLUMN details JSONB`,
+	`ALTER TABLE poker_sessions ADD COLUMN summary JSONB`,
+	`ALTER TABLE poker_sessions ADD COLUMN team_id TEXT`,
+	`CREATE INDEX IF NOT EXISTS poker_sessions_team_id ON poker_sessions (team_id, started_at)`,
+}
+
+// SQLStorage records sessions, rounds and votes in PostgreSQL or SQLite. It
+// creates its tables on first use.
+type SQLStorage struct {
+	db *sql.DB
+}
+
+// newSQLStorage creates or migrates the schema on an open database, closing
+// it on failure. types adapts the Postgres column types when not nil.
+func newSQLStorage(ctx context.Context, db *sql.DB, types *strings.Replacer, backend string) (*SQLStorage, error) {
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connecting to %s: %w", backend, err)
+	}
+	if err := migrateSchema(ctx, db, types); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating %s schema: %w", backend, err)
+	}
+	return &SQLStorage{db: db}, nil
+}
+
+func migrateSchema(ctx context.Context, db *sql.DB, types *strings.Replacer) error {
+	adapt := func(statement string) string {
+		if types == nil {
+			return statement
+		}
+		return types.Replace(statement)
+	}
+
+	if _, err := db.ExecContext(ctx, adapt(storageSchema)); err != nil {
+		return err
+	}
+	var version int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM poker_schema_version`).Scan(&version); err != nil {
+		return err
+	}
+
+	for ; version < len(storageMigrations); version++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, adapt(storageMigrations[version])); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO poker_schema_version (version) VALUES ($1)`, version+1); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+	}
+	return nil
+}
+
+// newStorageFromConfig returns nil when storage isn't configured, along with
+// the backend's name for logging.
+func newStorageFromConfig(ctx context.Context, cfg Config) (Storage, string, error) {
+	var (
+		storage *SQLStorage
+		backend string
+		err     error
+	)
+	switch cfg.storageBackend() {
+	case StoragePostgres:
+		backend = "Postgres"
+		storage, err = NewPostgresStorage(ctx, cfg.DatabaseURL)
+	case StorageSQLite:
+		backend = "SQLite"
+		storage, err = NewSQLiteStorage(ctx, cfg.SQLitePath)
+	default:
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, backend, err
+	}
+	return storage, backend, nil
+}
+
+// SaveRound upserts the session, the round and its votes in one transaction.
+func (p *SQLStorage) SaveRound(ctx context.Context, session Session, round RoundRecord) error {
+	story, err := json.Marshal(round.Story)
+	if err != nil {
+		return err
+	}
+	stats, err := json.Marshal(round.Stats)
+	if err != nil {
+		return err
+	}
+	// The votes have their own rows
+	detailed := round
+	detailed.Votes = nil
+	details, err := json.Marshal(detailed)
+	if err != nil {
+		return err
+	}
+
+	// SQLite stores timestamps as text, which only sorts and matches in one zone
+	startedAt := session.StartedAt.UTC()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO poker_sessions (room_id, started_at, passcode_hash, team_id) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (room_id, started_at) DO UPDATE SET
+			passcode_hash = EXCLUDED.passcode_hash, team_id = EXCLUDED.team_id`,
+		session.RoomID, startedAt, session.PasscodeHash, nullString(session.TeamID),
+	); err != nil {
+		return fmt.Errorf("saving session: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO poker_rounds (room_id, started_at, round_id, story, stats, final_estimate, revealed_at, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (room_id, started_at, round_id) DO UPDATE SET
+			story = EXCLUDED.story, stats = EXCLUDED.stats, final_estimate = EXCLUDED.final_estimate,
+			details = EXCLUDED.details`,
+		session.RoomID, startedAt, round.ID, story, stats, round.FinalEstimate, time.UnixMilli(round.RevealedAt).UTC(), details,
+	); err != nil {
+		return fmt.Errorf("saving round: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM poker_votes WHERE room_id = $1 AND started_at = $2 AND round_id = $3`,
+		session.RoomID, startedAt, round.ID,
+	); err != nil {
+		return fmt.Errorf("saving votes: %w", err)
+	}
+	for i, vote := range round.Votes {
+		details, err := json.Marshal(vote)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO poker_votes (room_id, started_at, round_id, position, participant, vote, details)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			session.RoomID, startedAt, round.ID, i, vote.Name, vote.Vote, details,
+		); err != nil {
+			return fmt.Errorf("saving votes: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SaveSummary records the summary of an ended session.
+func (p *SQLStorage) SaveSummary(ctx context.Context, session Session, summary SessionSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	if _, err := p.db.ExecContext(ctx, `
+		INSERT INTO poker_sessions (room_id, started_at, passcode_hash, team_id, summary) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (room_id, started_at) DO UPDATE SET summary = EXCLUDED.summary`,
+		session.RoomID, session.StartedAt.UTC(), session.PasscodeHash, nullString(session.TeamID), data,
+	); err != nil {
+		return fmt.Errorf("saving summary: %w", err)
+	}
+	return nil
+}
+
+// sessionColumns are read by scanSession
+const sessionColumns = `room_id, started_at, passcode_hash, COALESCE(team_id, ''), summary`
+
+// LoadSession returns the room's most recent session with its rounds.
+func (p *SQLStorage) LoadSession(ctx context.Context, roomID string) (Session, []RoundRecord, error) {
+	session, err := scanSession(p.db.QueryRowContext(ctx, `
+		SELECT `+sessionColumns+` FROM poker_sessions
+		WHERE room_id = $1 ORDER BY started_at DESC LIMIT 1`,
+		roomID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Session{}, nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return Session{}, nil, err
+	}
+
+	rounds, err := p.loadRounds(ctx, session)
+	if err != nil {
+		return Session{}, nil, err
+	}
+	return session, rounds, nil
+}
+
+// LoadTeamSessions returns the team's most recent sessions, oldest first.
+func (p *SQLStorage) LoadTeamSessions(ctx context.Context, teamID string, limit int) ([]Session, [][]RoundRecord, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT `+sessionColumns+` FROM poker_sessions
+		WHERE team_id = $1 ORDER BY started_at DESC LIMIT $2`,
+		teamID, limit,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sessions []Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, nil, ErrSessionNotFound
+	}
+
+	slices.Reverse(sessions)
+	rounds := make([][]RoundRecord, len(sessions))
+	for i, session := range sessions {
+		if rounds[i], err = p.loadRounds(ctx, session); err != nil {
+			return nil, nil, err
+		}
+	}
+	return sessions, rounds, nil
+}
+
+// scanSession reads a row of sessionColumns.
+func scanSession(row interface{ Scan(...any) error }) (Session, error) {
+	var session Session
+	var summary []byte
+	if err := row.Scan(&session.RoomID, &session.StartedAt, &session.PasscodeHash, &session.TeamID, &summary); err != nil {
+		return Session{}, err
+	}
+	if summary != nil {
+		session.Summary = &SessionSummary{}
+		if err := json.Unmarshal(summary, session.Summary); err != nil {
+			return Session{}, err
+		}
+	}
+	return session, nil
+}
+
+// loadRounds returns the rounds of a session with their votes.
+func (p *SQLStorage) loadRounds(ctx context.Context, session Session) ([]RoundRecord, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT round_id, story, stats, final_estimate, revealed_at, details FROM poker_rounds
+		WHERE room_id = $1 AND started_at = $2 ORDER BY revealed_at`,
+		session.RoomID, session.StartedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rounds []RoundRecord
+	index := make(map[string]int)
+	for rows.Next() {
+		var round RoundRecord
+		var story, stats, details []byte
+		var revealedAt time.Time
+		if err := rows.Scan(&round.ID, &story, &stats, &round.FinalEstimate, &revealedAt, &details); err != nil {
+			return nil, err
+		}
+		// Rounds saved before details were recorded only have the columns
+		if details != nil {
+			if err := json.Unmarshal(details, &round); err != nil {
+				return nil, err
+			}
+		}
+		if err := json.Unmarshal(story, &round.Story); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(stats, &round.Stats); err != nil {
+			return nil, err
+		}
+		round.RevealedAt = revealedAt.UnixMilli()
+		round.Votes = []roompkg.RoundVote{}
+		index[round.ID] = len(rounds)
+		rounds = append(rounds, round)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	votes, err := p.db.QueryContext(ctx, `
+		SELECT round_id, participant, vote, details FROM poker_votes
+		WHERE room_id = $1 AND started_at = $2 ORDER BY round_id, position`,
+		session.RoomID, session.StartedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer votes.Close()
+
+	for votes.Next() {
+		var roundID string
+		var vote roompkg.RoundVote
+		var details []byte
+		if err := votes.Scan(&roundID, &vote.Name, &vote.Vote, &details); err != nil {
+			return nil, err
+		}
+		if details != nil {
+			if err := json.Unmarshal(details, &vote); err != nil {
+				return nil, err
+			}
+		}
+		if i, ok := index[roundID]; ok {
+			rounds[i].Votes = append(rounds[i].Votes, vote)
+		}
+	}
+	return rounds, votes.Err()
+}
+
+// nullString stores empty strings as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// Close closes the database connection pool.
+func (p *SQLStorage) Close() error {
+	return p.db.Close()
+}
+
+// sqliteTypes maps the Postgres column types in storageSchema and
+// storageMigrations to ones SQLite understands. TIMESTAMP makes the driver hand back time.Time values.
+var sqliteTypes = strings.NewReplacer(
+	"TIMESTAMPTZ", "TIMESTAMP",
+	"JSONB", "BLOB",
+	"BYTEA", "BLOB",
+)
@@ -0,0 +1,101 @@
+package pokerserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// testSQLStorage runs the same checks against each backend.
+func testSQLStorage(t *testing.T, storage *SQLStorage) {
+	t.Helper()
+	ctx := context.Background()
+
+	roomID := "sql-test-" + generateToken()
+	teamID := "team-" + generateToken()
+	session := Session{RoomID: roomID, StartedAt: time.Now().Truncate(time.Millisecond), TeamID: teamID}
+	round := RoundRecord{
+		ID:          "1",
+		Story:       &roompkg.Story{Title: "Checkout"},
+		Votes:       []roompkg.RoundVote{{Name: "Alice", Vote: "5", SecondVote: "3", Confidence: "high"}, {Name: "Bob", Vote: "8"}},
+		Stats:       roompkg.VoteStats{VoteCount: 2},
+		RevealedAt:  time.Now().UnixMilli(),
+		Mode:        roompkg.ModeTwoDimensional,
+		Dimensions:  []string{"effort", "uncertainty"},
+		SecondStats: &roompkg.VoteStats{VoteCount: 1},
+	}
+	if err := storage.SaveRound(ctx, session, round); err != nil {
+		t.Fatalf("SaveRound failed: %v", err)
+	}
+
+	// Setting the final estimate saves the round again
+	round.FinalEstimate = "8"
+	if err := storage.SaveRound(ctx, session, round); err != nil {
+		t.Fatalf("SaveRound update failed: %v", err)
+	}
+
+	loaded, rounds, err := storage.LoadSession(ctx, roomID)
+	if err != nil {
+		t.Fatalf("LoadSession failed: %v", err)
+	}
+	if !loaded.StartedAt.Equal(session.StartedAt) {
+		t.Errorf("Expected session start %v, got %v", session.StartedAt, loaded.StartedAt)
+	}
+	if len(rounds) != 1 || rounds[0].FinalEstimate != "8" || len(rounds[0].Votes) != 2 || rounds[0].Story.Title != "Checkout" {
+		t.Errorf("Unexpected rounds: %+v", rounds)
+	} else if vote := rounds[0].Votes[0]; vote.SecondVote != "3" || vote.Confidence != "high" || rounds[0].SecondStats == nil {
+		t.Errorf("Expected the fields without a column to be kept, got %+v", rounds[0])
+	}
+
+	if loaded.Summary != nil {
+		t.Errorf("Expected no summary before the session ended, got %+v", loaded.Summary)
+	}
+	if err := storage.SaveSummary(ctx, session, SessionSummary{RoomID: roomID, Rounds: 1, TotalPoints: 8}); err != nil {
+		t.Fatalf("SaveSummary failed: %v", err)
+	}
+	if loaded, _, err := storage.LoadSession(ctx, roomID); err != nil || loaded.Summary == nil || loaded.Summary.TotalPoints != 8 {
+		t.Errorf("Expected the summary to be stored, got %+v, %v", loaded.Summary, err)
+	}
+
+	// An earlier session of the team, in another room
+	earlier := Session{RoomID: "other-" + roomID, StartedAt: session.StartedAt.Add(-time.Hour), TeamID: teamID}
+	if err := storage.SaveRound(ctx, earlier, RoundRecord{ID: "1", Stats: roompkg.VoteStats{}, RevealedAt: earlier.StartedAt.UnixMilli()}); err != nil {
+		t.Fatalf("SaveRound failed: %v", err)
+	}
+	sessions, teamRounds, err := storage.LoadTeamSessions(ctx, teamID, 10)
+	if err != nil {
+		t.Fatalf("LoadTeamSessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].RoomID != earlier.RoomID || sessions[1].RoomID != roomID {
+		t.Errorf("Expected the team's sessions oldest first, got %+v", sessions)
+	} else if len(teamRounds[1]) != 1 || sessions[1].Summary == nil {
+		t.Errorf("Expected the sessions' rounds and summary, got %+v", teamRounds)
+	}
+	if sessions, _, err := storage.LoadTeamSessions(ctx, teamID, 1); err != nil || len(sessions) != 1 || sessions[0].RoomID != roomID {
+		t.Errorf("Expected only the latest session, got %+v, %v", sessions, err)
+	}
+	if _, _, err := storage.LoadTeamSessions(ctx, "missing-"+teamID, 10); err != ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound for an unknown team, got %v", err)
+	}
+
+	if _, _, err := storage.LoadSession(ctx, "missing-"+roomID); err != ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+}
+
+func TestNewStorageFromConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	storage, _, err := newStorageFromConfig(context.Background(), cfg)
+	if storage != nil || err != nil {
+		t.Errorf("Expected no storage by default, got %v %v", storage, err)
+	}
+
+	cfg.Storage = StoragePostgres
+	cfg.DatabaseURL = "postgres://localhost:1/poker?connect_timeout=1"
+	storage, backend, err := newStorageFromConfig(context.Background(), cfg)
+	if storage != nil || err == nil || backend != "Postgres" {
+		t.Errorf("Expected an unreachable database to fail, got %v %q %v", storage, backend, err)
+	}
+}
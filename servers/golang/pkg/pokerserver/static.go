@@ -0,0 +1,49 @@
+package pokerserver
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// staticHandler serves the frontend from fsys. Paths that don't match a file
+// fall back to the page Next.js exported for them, then to index.html, so
+// client-side routes survive a reload.
+func staticHandler(fsys fs.FS) http.Handler {
+	fileServer := http.FileServerFS(fsys)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" {
+			name = "."
+		}
+
+		if info, err := fs.Stat(fsys, name); err == nil && (!info.IsDir() || fileExists(fsys, path.Join(name, "index.html"))) {
+			// Next.js fingerprints everything under _next/static
+			if strings.HasPrefix(name, "_next/static/") {
+				w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			}
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+
+		// Missing assets are a 404; only page navigations get the fallback
+		if path.Ext(name) != "" && path.Ext(name) != ".html" {
+			http.NotFound(w, r)
+			return
+		}
+
+		page := "index.html"
+		if fileExists(fsys, name+".html") {
+			page = name + ".html"
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeFileFS(w, r, fsys, page)
+	})
+}
+
+func fileExists(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,59 @@
+package pokerserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticFiles(t *testing.T) {
+	frontend := fstest.MapFS{
+		"index.html":              {Data: []byte("home")},
+		"about.html":              {Data: []byte("about")},
+		"_next/static/app-123.js": {Data: []byte("console.log(1)")},
+		"favicon.ico":             {Data: []byte("icon")},
+		"docs/index.html":         {Data: []byte("docs")},
+		"game/placeholder/x.txt":  {Data: []byte("x")},
+	}
+	handler := New(WithStaticFiles(frontend), WithAdminToken("secret")).Handler()
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/", http.StatusOK, "home"},
+		{"/about", http.StatusOK, "about"},
+		{"/docs/", http.StatusOK, "docs"},
+		{"/favicon.ico", http.StatusOK, "icon"},
+		{"/_next/static/app-123.js", http.StatusOK, "console.log(1)"},
+		{"/game/ABC123", http.StatusOK, "home"},
+		{"/game", http.StatusOK, "home"},
+		{"/_next/static/missing.js", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("GET %s: expected %d, got %d", tt.path, tt.status, rec.Code)
+			continue
+		}
+		if tt.body != "" && strings.TrimSpace(rec.Body.String()) != tt.body {
+			t.Errorf("GET %s: expected %q, got %q", tt.path, tt.body, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_next/static/app-123.js", nil))
+	if !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Errorf("Expected fingerprinted assets to be cached, got %q", rec.Header().Get("Cache-Control"))
+	}
+
+	// API routes still take precedence
+	if rec := adminRequest(handler, http.MethodGet, "/admin/rooms", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected admin API to answer, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,5 @@
+package pokerserver
+
+func strPtr(s string) *string {
+	return &s
+}
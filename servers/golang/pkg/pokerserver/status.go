@@ -0,0 +1,36 @@
+package pokerserver
+
+import (
+	"unicode/utf8"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// handleSetStatus sets a note on the sender shown to the room, such as "in
+// another meeting", or clears it when empty. Voting clears it too.
+func (s *Server) handleSetStatus(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	status, _ := data["status"].(string)
+
+	status = roompkg.CleanText(status, false)
+	if utf8.RuneCountInString(status) > roompkg.MaxStatusLength {
+		s.sendInputError(ws, "set-status", &inputError{Field: "status", Reason: reasonTooLong, Limit: roompkg.MaxStatusLength})
+		return
+	}
+
+	room, exists := s.hub.Room(roomID)
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	participant, ok := room.Participants[ws.ID]
+	if !ok || participant.Status == status {
+		room.Mu.Unlock()
+		return
+	}
+	room.Apply(&roompkg.StatusEvent{ClientID: ws.ID, Status: status})
+	room.Mu.Unlock()
+
+	s.broadcastRoomState(ws.Context(), roomID)
+}
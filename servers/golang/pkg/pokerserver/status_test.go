@@ -0,0 +1,38 @@
+package pokerserver
+
+import (
+	"strings"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestSetStatus(t *testing.T) {
+	server := New()
+	roomID := "status-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	room, _ := server.hub.Room(roomID)
+
+	alice.Send("set-status", map[string]interface{}{"roomId": roomID, "status": strings.Repeat("x", roompkg.MaxStatusLength+1)})
+	if data := awaitMessage(t, alice, "error").Data.(map[string]interface{}); data["reason"] != reasonTooLong {
+		t.Errorf("Expected a long status to be refused, got %v", data)
+	}
+
+	alice.Send("set-status", map[string]interface{}{"roomId": roomID, "status": " in another\nmeeting "})
+	room.Mu.RLock()
+	status := room.Participants[alice.ID].Status
+	room.Mu.RUnlock()
+	if status != "in another meeting" {
+		t.Errorf("Expected the cleaned status, got %q", status)
+	}
+
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if status := room.Participants[alice.ID].Status; status != "" {
+		t.Errorf("Expected voting to clear the status, got %q", status)
+	}
+}
@@ -0,0 +1,132 @@
+package pokerserver
+
+import (
+	"context"
+	"errors"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// Storage persists completed rounds beyond the lifetime of the in-memory room.
+// SaveRound is called again for a round when it changes (e.g. once a final
+// estimate is set), so implementations should upsert by session and round ID.
+type Storage interface {
+	SaveRound(ctx context.Context, session Session, round RoundRecord) error
+}
+
+// SessionLoader is implemented by storage that can read rounds back, so
+// exports and reports keep working after the room is gone from memory.
+type SessionLoader interface {
+	// LoadSession returns the room's most recent session and its rounds in
+	// reveal order, or ErrSessionNotFound.
+	LoadSession(ctx context.Context, roomID string) (Session, []RoundRecord, error)
+}
+
+// SummaryStorage is implemented by storage that keeps the summary of a
+// session once the facilitator ends it.
+type SummaryStorage interface {
+	SaveSummary(ctx context.Context, session Session, summary SessionSummary) error
+}
+
+// TeamLoader is implemented by storage that can find the sessions of a team,
+// for its velocity.
+type TeamLoader interface {
+	// LoadTeamSessions returns up to limit of the team's most recent sessions,
+	// oldest first, each with its rounds in reveal order, or
+	// ErrSessionNotFound.
+	LoadTeamSessions(ctx context.Context, teamID string, limit int) ([]Session, [][]RoundRecord, error)
+}
+
+// ErrSessionNotFound is returned by SessionLoader for rooms without stored rounds.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is one lifetime of a room, from its creation until everyone left.
+type Session = roompkg.Session
+
+// RoundRecord is a completed (revealed) round kept for session exports.
+type RoundRecord = roompkg.RoundRecord
+
+// SessionSummary sums up a session when the facilitator ends it.
+type SessionSummary = roompkg.SessionSummary
+
+// pendingRound is a round to save, or the session's summary when summary is
+// set. Both share the queue so a summary is never written ahead of a round.
+type pendingRound struct {
+	session Session
+	round   RoundRecord
+	summary *SessionSummary
+}
+
+const storageQueueSize = 256
+
+// saveRound hands the round to a single background writer so handlers never
+// block on storage and successive saves of the same round stay in order.
+func (s *Server) saveRound(session Session, round RoundRecord) {
+	if s.storage == nil {
+		return
+	}
+	s.enqueueStorage(pendingRound{session: session, round: round})
+}
+
+// saveSummary queues the summary of an ended session, for storage that keeps
+// them.
+func (s *Server) saveSummary(session Session, summary SessionSummary) {
+	if _, ok := s.storage.(SummaryStorage); !ok {
+		return
+	}
+	s.enqueueStorage(pendingRound{session: session, summary: &summary})
+}
+
+func (s *Server) enqueueStorage(pending pendingRound) {
+	s.storageOnce.Do(func() {
+		s.storageQueue = make(chan pendingRound, storageQueueSize)
+		go s.runStorageWriter()
+	})
+
+	select {
+	case s.storageQueue <- pending:
+	default:
+		if pending.summary != nil {
+			s.logger.Printf("Storage queue full, dropping the session summary for room %s", pending.session.RoomID)
+			return
+		}
+		s.logger.Printf("Storage queue full, dropping round %s for room %s", pending.round.ID, pending.session.RoomID)
+	}
+}
+
+func (s *Server) runStorageWriter() {
+	for {
+		select {
+		case pending := <-s.storageQueue:
+			if pending.summary != nil {
+				if err := s.storage.(SummaryStorage).SaveSummary(s.ctx, pending.session, *pending.summary); err != nil {
+					s.logger.Printf("Error saving the session summary for room %s: %v", pending.session.RoomID, err)
+				}
+				continue
+			}
+			if err := s.storage.SaveRound(s.ctx, pending.session, pending.round); err != nil {
+				s.logger.Printf("Error saving round %s for room %s: %v", pending.round.ID, pending.session.RoomID, err)
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// loadStoredSession reads a room's last session from storage, for rooms no
+// longer in memory.
+func (s *Server) loadStoredSession(ctx context.Context, roomID string) (Session, []RoundRecord, bool) {
+	loader, ok := s.storage.(SessionLoader)
+	if !ok {
+		return Session{}, nil, false
+	}
+
+	session, rounds, err := loader.LoadSession(ctx, roomID)
+	if err != nil {
+		if !errors.Is(err, ErrSessionNotFound) {
+			s.logger.Printf("Error loading stored session for room %s: %v", roomID, err)
+		}
+		return Session{}, nil, false
+	}
+	return session, rounds, true
+}
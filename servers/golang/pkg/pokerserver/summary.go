@@ -0,0 +1,36 @@
+package pokerserver
+
+import roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+
+// handleEndSession lets the facilitator close the room: everyone is sent the
+// session-summary, then room-closed, and the summary is stored and posted to
+// the webhooks. Joining the room again starts a new session.
+func (s *Server) handleEndSession(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring end-session from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "end-session", errCodeNotAllowed, "Only the facilitator can end the session", nil)
+		return
+	}
+	room.Apply(&roompkg.CloseEvent{})
+	session := room.Session()
+	summary := roompkg.SummarizeSession(session, room.History, s.clock.Now())
+	room.Mu.Unlock()
+
+	s.broadcastToRoom(ws.Context(), roomID, "session-summary", summary)
+	s.broadcastToRoom(ws.Context(), roomID, "room-closed", map[string]interface{}{"roomId": roomID})
+	s.closeRoom(roomID)
+
+	s.saveSummary(session, summary)
+	s.notify(roomID, EventSessionEnded, summary)
+	s.logf(ws.Context(), "🏁 %s ended the session in room %s after %d round(s)", ws.ID, roomID, summary.Rounds)
+}
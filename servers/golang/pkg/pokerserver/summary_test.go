@@ -0,0 +1,55 @@
+package pokerserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+func TestHandleEndSession(t *testing.T) {
+	server := New()
+	roomID := playRound(t, server)
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	facilitatorID := room.FacilitatorID
+	room.Mu.RUnlock()
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	// Only the facilitator can end the session
+	sendMessage(t, ws, "end-session", map[string]interface{}{"roomId": roomID})
+	if msg := readUntilType(t, ws, "error"); msg.Data.(map[string]interface{})["code"] != errCodeNotAllowed {
+		t.Fatalf("Expected end-session from a participant to be refused, got %v", msg.Data)
+	}
+	if _, ok := server.hub.Room(roomID); !ok {
+		t.Fatal("Expected the room to survive end-session from a participant")
+	}
+
+	facilitator := server.hub.Client(facilitatorID)
+	server.handleEndSession(facilitator, map[string]interface{}{"roomId": roomID})
+
+	msg := readMessage(t, ws, 2*time.Second)
+	for msg.Type == "room-state" || msg.Type == "replay" {
+		msg = readMessage(t, ws, 2*time.Second)
+	}
+	if msg.Type != "session-summary" {
+		t.Fatalf("Expected session-summary, got %s", msg.Type)
+	}
+	data, _ := msg.Data.(map[string]interface{})
+	if data["storiesEstimated"] != float64(1) || data["totalPoints"] != float64(8) {
+		t.Errorf("Unexpected summary: %v", data)
+	}
+	if msg := readMessage(t, ws, 2*time.Second); msg.Type != "room-closed" {
+		t.Errorf("Expected room-closed, got %s", msg.Type)
+	}
+	expectClose(t, ws, transport.CloseRoomClosed)
+	if _, ok := server.hub.Room(roomID); ok {
+		t.Error("Expected the room to be closed")
+	}
+}
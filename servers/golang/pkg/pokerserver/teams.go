@@ -0,0 +1,88 @@
+package pokerserver
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// teamsEvents are the events posted to Microsoft Teams as adaptive cards.
+var teamsEvents = map[string]bool{
+	EventRoundRevealed: true,
+	EventFinalEstimate: true,
+}
+
+// adaptiveCard is the subset of the Adaptive Card schema the cards use.
+type adaptiveCard struct {
+	Schema  string                   `json:"$schema"`
+	Type    string                   `json:"type"`
+	Version string                   `json:"version"`
+	Body    []map[string]interface{} `json:"body"`
+	Actions []map[string]interface{} `json:"actions,omitempty"`
+}
+
+// teamsMessage wraps an event's adaptive card in the message an incoming
+// webhook accepts.
+func teamsMessage(event Event) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     teamsCard(event),
+		}},
+	}
+}
+
+// teamsCard summarizes a revealed round, or the final estimate given to a
+// story, as a title, a set of facts and a link to the story.
+func teamsCard(event Event) adaptiveCard {
+	round, _ := event.Data.(RoundRecord)
+	title := storyTitle(round.Story)
+
+	var heading string
+	var facts []map[string]string
+	fact := func(name, value string) {
+		if value != "" {
+			facts = append(facts, map[string]string{"title": name, "value": value})
+		}
+	}
+	fact("Room", event.RoomID)
+	switch event.Type {
+	case EventFinalEstimate:
+		heading = fmt.Sprintf("✅ %s estimated at %s", title, round.FinalEstimate)
+		fact("Final estimate", round.FinalEstimate)
+		if round.ID != "" {
+			fact("Votes", strconv.Itoa(round.Stats.VoteCount))
+			fact("Average", formatStat(round.Stats.Average))
+		}
+	default:
+		heading = fmt.Sprintf("🃏 Votes on %s revealed", title)
+		fact("Votes", strconv.Itoa(round.Stats.VoteCount))
+		fact("Average", formatStat(round.Stats.Average))
+		fact("Median", formatStat(round.Stats.Median))
+		if round.Stats.Min != nil {
+			fact("Range", formatStat(round.Stats.Min)+"–"+formatStat(round.Stats.Max))
+		}
+		if round.Stats.Agreement != nil {
+			fact("Agreement", formatStat(round.Stats.Agreement)+"%")
+		}
+		if round.DurationMs > 0 {
+			fact("Time to reveal", formatDuration(round.DurationMs))
+		}
+	}
+
+	card := adaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []map[string]interface{}{
+			{"type": "TextBlock", "text": heading, "weight": "Bolder", "size": "Medium", "wrap": true},
+			{"type": "FactSet", "facts": facts},
+		},
+	}
+	if round.Story != nil && round.Story.Link != "" {
+		card.Actions = []map[string]interface{}{
+			{"type": "Action.OpenUrl", "title": "Open story", "url": round.Story.Link},
+		}
+	}
+	return card
+}
@@ -0,0 +1,81 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestTeamsAdaptiveCards(t *testing.T) {
+	var mu sync.Mutex
+	var teams []map[string]interface{}
+	teamsWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&message)
+		mu.Lock()
+		teams = append(teams, message)
+		mu.Unlock()
+	}))
+	defer teamsWebhook.Close()
+	slackPosts := 0
+	slackWebhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		slackPosts++
+		mu.Unlock()
+	}))
+	defer slackWebhook.Close()
+
+	notifier := newWebhookNotifier(WebhooksConfig{TeamsURL: teamsWebhook.URL, SlackURL: slackWebhook.URL}, loopbackOutbound())
+
+	average, median, low, high := 4.0, 5.0, 3.0, 5.0
+	story := &roompkg.Story{Title: "Checkout", Link: "https://example.com/PROJ-1"}
+	round := RoundRecord{ID: "1", Story: story, Stats: roompkg.VoteStats{VoteCount: 3, Average: &average, Median: &median, Min: &low, Max: &high}}
+	if err := notifier.Notify(t.Context(), Event{Type: EventRoundRevealed, RoomID: "room", Data: round}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	round.FinalEstimate = "5"
+	if err := notifier.Notify(t.Context(), Event{Type: EventFinalEstimate, RoomID: "room", Data: round}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if err := notifier.Notify(t.Context(), Event{Type: EventSessionEnded, RoomID: "room", Data: SessionSummary{}}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if slackPosts != 1 {
+		t.Errorf("Expected Slack to only hear about the session ending, got %d posts", slackPosts)
+	}
+	if len(teams) != 2 {
+		t.Fatalf("Expected cards for the reveal and the final estimate only, got %d", len(teams))
+	}
+
+	attachment := teams[0]["attachments"].([]interface{})[0].(map[string]interface{})
+	if teams[0]["type"] != "message" || attachment["contentType"] != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("Unexpected Teams message: %v", teams[0])
+	}
+	card := attachment["content"].(map[string]interface{})
+	body := card["body"].([]interface{})
+	if card["type"] != "AdaptiveCard" || body[0].(map[string]interface{})["text"] != "🃏 Votes on “Checkout” revealed" {
+		t.Errorf("Unexpected card: %v", card)
+	}
+	facts := make(map[string]interface{})
+	for _, fact := range body[1].(map[string]interface{})["facts"].([]interface{}) {
+		facts[fact.(map[string]interface{})["title"].(string)] = fact.(map[string]interface{})["value"]
+	}
+	if facts["Votes"] != "3" || facts["Average"] != "4" || facts["Range"] != "3–5" {
+		t.Errorf("Unexpected facts: %v", facts)
+	}
+	if action := card["actions"].([]interface{})[0].(map[string]interface{}); action["url"] != story.Link {
+		t.Errorf("Expected a link to the story, got %v", action)
+	}
+
+	card = teams[1]["attachments"].([]interface{})[0].(map[string]interface{})["content"].(map[string]interface{})
+	if text := card["body"].([]interface{})[0].(map[string]interface{})["text"]; text != "✅ “Checkout” estimated at 5" {
+		t.Errorf("Unexpected final estimate card: %v", text)
+	}
+}
@@ -0,0 +1,72 @@
+package pokerserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// Team tokens scope rooms to teams when one server hosts several. A client
+// that connects with its team's token creates rooms belonging to the team,
+// which nobody outside it can join, and the token opens the admin API on the
+// team's rooms alone. Clients without a token keep using unscoped rooms.
+
+// requestToken returns the bearer token a request carries. Browsers can't set
+// headers on WebSocket requests, so it may come as the token query parameter.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
+
+// teamForToken returns the team a token belongs to.
+func (s *Server) teamForToken(token string) (string, bool) {
+	for team, teamToken := range s.teamTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(teamToken)) == 1 {
+			return team, true
+		}
+	}
+	return "", false
+}
+
+// adminScope returns the team an admin request is limited to: none for the
+// admin token, or the team whose token it carries.
+func (s *Server) adminScope(r *http.Request) (string, bool) {
+	if s.isAdminRequest(r) {
+		return "", true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", false
+	}
+	return s.teamForToken(token)
+}
+
+// adminTeam returns the team requireAdmin scoped the request to.
+func adminTeam(r *http.Request) string {
+	team, _ := r.Context().Value(adminTeamKey).(string)
+	return team
+}
+
+func withAdminTeam(r *http.Request, team string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), adminTeamKey, team))
+}
+
+// adminRoom looks up the room an admin request names, writing a 404 when it
+// doesn't exist or is outside the request's team.
+func (s *Server) adminRoom(w http.ResponseWriter, r *http.Request) (*roompkg.State, bool) {
+	room, ok := s.hub.Room(r.PathValue("id"))
+	if ok {
+		room.Mu.RLock()
+		ok = room.VisibleTo(adminTeam(r))
+		room.Mu.RUnlock()
+	}
+	if !ok {
+		http.Error(w, "room not found", http.StatusNotFound)
+	}
+	return room, ok
+}
@@ -0,0 +1,137 @@
+package pokerserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newTeamServer() *Server {
+	return New(WithAdminToken("admin-secret"), WithTeamTokens(map[string]string{
+		"payments": "payments-secret",
+		"search":   "search-secret",
+	}))
+}
+
+func TestTeamTokenConnections(t *testing.T) {
+	server := newTeamServer()
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL+"?token=wrong", nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected an unknown token to be refused with 401, got %v", err)
+	}
+
+	alice, _, err := websocket.DefaultDialer.Dial(wsURL+"?token=payments-secret", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect with a team token: %v", err)
+	}
+	defer alice.Close()
+	sendMessage(t, alice, "join-room", map[string]interface{}{"roomId": "payments-room", "name": "Alice"})
+	readUntilType(t, alice, "room-state")
+
+	room, _ := server.hub.Room("payments-room")
+	room.Mu.RLock()
+	team, scoped := room.TeamID, room.TeamScoped
+	room.Mu.RUnlock()
+	if team != "payments" || !scoped {
+		t.Errorf("Expected the room to belong to payments, got %q (scoped %v)", team, scoped)
+	}
+
+	header := http.Header{"Authorization": {"Bearer search-secret"}}
+	bob, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		t.Fatalf("Failed to connect with a team token header: %v", err)
+	}
+	defer bob.Close()
+	sendMessage(t, bob, "join-room", map[string]interface{}{"roomId": "payments-room", "name": "Bob"})
+	msg := readMessage(t, bob, 2*time.Second)
+	if data, _ := msg.Data.(map[string]interface{}); msg.Type != "join-rejected" || data["reason"] != "wrong-team" {
+		t.Errorf("Expected a cross-team join to be rejected, got %s %v", msg.Type, msg.Data)
+	}
+}
+
+func TestTeamScopedRooms(t *testing.T) {
+	server := newTeamServer()
+	join := func(team, roomID, name string) *MemoryClient {
+		client := server.ConnectMemory()
+		client.ws.TeamID = team
+		client.Send("join-room", map[string]interface{}{"roomId": roomID, "name": name})
+		return client
+	}
+
+	alice := join("payments", "payments-room", "Alice")
+	defer alice.Close()
+	carol := join("payments", "payments-room", "Carol")
+	defer carol.Close()
+	dave := join("", "payments-room", "Dave")
+	defer dave.Close()
+	if data := awaitMessage(t, dave, "join-rejected").Data.(map[string]interface{}); data["reason"] != "wrong-team" {
+		t.Errorf("Expected a client without the team's token to be rejected, got %v", data)
+	}
+	room, _ := server.hub.Room("payments-room")
+	room.Mu.RLock()
+	members := len(room.Participants)
+	room.Mu.RUnlock()
+	if members != 2 {
+		t.Errorf("Expected the team's two members in the room, got %d", members)
+	}
+
+	erin := join("search", "search-room", "Erin")
+	defer erin.Close()
+	// Team members can still use rooms nobody's token created
+	frank := join("", "open-room", "Frank")
+	defer frank.Close()
+	grace := join("search", "open-room", "Grace")
+	defer grace.Close()
+	open, _ := server.hub.Room("open-room")
+	open.Mu.RLock()
+	members = len(open.Participants)
+	open.Mu.RUnlock()
+	if members != 2 {
+		t.Errorf("Expected anyone to join an unscoped room, got %d participants", members)
+	}
+
+	handler := server.Handler()
+	rec := adminRequest(handler, http.MethodGet, "/admin/rooms", "payments-secret")
+	var list struct {
+		Rooms []AdminRoomSummary `json:"rooms"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || len(list.Rooms) != 1 || list.Rooms[0].ID != "payments-room" {
+		t.Errorf("Expected the team to list its room alone, got %d %+v", rec.Code, list.Rooms)
+	}
+	if rec := adminRequest(handler, http.MethodGet, "/admin/rooms/search-room", "payments-secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected another team's room to be hidden, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodDelete, "/admin/rooms/open-room", "payments-secret"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected an unscoped room to be out of a team's reach, got %d", rec.Code)
+	}
+	if rec := adminRequest(handler, http.MethodDelete, "/admin/rooms/payments-room", "payments-secret"); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the team to delete its own room, got %d", rec.Code)
+	}
+	rec = adminRequest(handler, http.MethodGet, "/admin/rooms", "admin-secret")
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if len(list.Rooms) != 2 {
+		t.Errorf("Expected the admin token to see every room, got %+v", list.Rooms)
+	}
+}
+
+func TestTeamTokensConfig(t *testing.T) {
+	t.Setenv("TEAM_TOKENS", "payments=one, search=one,bad team=two")
+	_, err := LoadConfig("")
+	if err == nil {
+		t.Fatal("Expected invalid team tokens to fail")
+	}
+	for _, want := range []string{"team_tokens.search: token is already in use", `invalid team ID "bad team"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got: %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package pokerserver
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/kjaniec-dev/planning-poker/servers/golang/pkg/pokerserver"
+
+// Span attributes shared by the message, broadcast and broker spans
+var (
+	attrMessageType = attribute.Key("poker.message.type")
+	attrRoomID      = attribute.Key("poker.room.id")
+	attrClientID    = attribute.Key("poker.client.id")
+	attrRecipients  = attribute.Key("poker.broadcast.recipients")
+)
+
+// brokerPropagator carries trace context inside broker messages so a
+// broadcast relayed by another instance continues the same trace.
+var brokerPropagator = propagation.TraceContext{}
+
+// defaultTracer follows the global provider, so tracing set up by the
+// embedding program after New is still picked up.
+func defaultTracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// defaultMeter follows the global provider like defaultTracer.
+func defaultMeter() metric.Meter {
+	return otel.Meter(tracerName)
+}
+
+// logf logs with the trace ID of ctx, if any, so log lines can be matched to
+// the span that produced them.
+func (s *Server) logf(ctx context.Context, format string, args ...interface{}) {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		format = fmt.Sprintf("[trace=%s] %s", sc.TraceID(), format)
+	}
+	s.logger.Printf(format, args...)
+}
@@ -0,0 +1,119 @@
+package pokerserver
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracedServer(opts ...Option) (*Server, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return New(append([]Option{WithTracerProvider(provider)}, opts...)...), recorder
+}
+
+func findSpan(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestMessageHandlingIsTraced(t *testing.T) {
+	server, recorder := newTracedServer()
+
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{
+		"roomId": "test-room",
+		"name":   "Alice",
+	})
+	readMessage(t, ws, 2*time.Second) // room-state
+
+	spans := recorder.Ended()
+	message := findSpan(spans, "ws.message join-room")
+	if message == nil {
+		t.Fatalf("Expected a span for join-room, got %d spans", len(spans))
+	}
+	broadcast := findSpan(spans, "ws.broadcast")
+	if broadcast == nil {
+		t.Fatal("Expected a span for the room-state broadcast")
+	}
+	if broadcast.Parent().SpanID() != message.SpanContext().SpanID() {
+		t.Error("Expected broadcast span to be a child of the message span")
+	}
+
+	var roomID string
+	for _, attr := range message.Attributes() {
+		if attr.Key == attrRoomID {
+			roomID = attr.Value.AsString()
+		}
+	}
+	if roomID != "test-room" {
+		t.Errorf("Expected room ID attribute, got %q", roomID)
+	}
+}
+
+func TestBrokerPropagatesTraceContext(t *testing.T) {
+	broker := &fakeBroker{}
+	server, recorder := newTracedServer(WithBroker(broker))
+	if err := server.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize server: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	ctx, span := server.tracer.Start(context.Background(), "test")
+	server.publishToBroker(ctx, "test-room", "emitted", nil, "")
+	span.End()
+
+	broker.mu.Lock()
+	published := broker.published[0]
+	handle := broker.handle
+	broker.mu.Unlock()
+
+	if published.TraceContext["traceparent"] == "" {
+		t.Fatalf("Expected traceparent in broker message, got %v", published.TraceContext)
+	}
+
+	// Delivered as if by another instance
+	published.Origin = "other-instance"
+	handle(published)
+
+	receive := findSpan(recorder.Ended(), "broker.receive")
+	if receive == nil {
+		t.Fatal("Expected a span for the broker delivery")
+	}
+	if receive.SpanContext().TraceID() != span.SpanContext().TraceID() {
+		t.Error("Expected delivery to continue the publisher's trace")
+	}
+}
+
+func TestLogfIncludesTraceID(t *testing.T) {
+	var buf bytes.Buffer
+	server, _ := newTracedServer(WithLogger(log.New(&buf, "", 0)))
+
+	server.logf(context.Background(), "no span")
+	ctx, span := server.tracer.Start(context.Background(), "test")
+	defer span.End()
+	server.logf(ctx, "with span")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "no span" {
+		t.Errorf("Expected untraced line without ID, got %q", lines[0])
+	}
+	want := "[trace=" + trace.SpanContextFromContext(ctx).TraceID().String() + "] with span"
+	if lines[1] != want {
+		t.Errorf("Expected %q, got %q", want, lines[1])
+	}
+}
@@ -0,0 +1,182 @@
+package pokerserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjaniec-dev/planning-poker/servers/golang/internal/transport"
+)
+
+// WebSocketMessage is a protocol message. Broadcasts to a room carry its
+// next Seq, so clients can tell when they missed one and drop repeats.
+type WebSocketMessage = transport.Message
+
+// ExtendedWebSocket is a client connection, as handed to a HandlerFunc.
+type ExtendedWebSocket = transport.Conn
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	ip, release, ok := s.acceptConnection(w, r)
+	if !ok {
+		return
+	}
+	defer release()
+	identity, ok := s.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Printf("Error upgrading to websocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	ws := s.registerClient(conn, ip, identity, transport.NegotiateCodec(conn, r))
+
+	for {
+		message, err := ws.ReadProtocolMessage()
+		if errors.Is(err, transport.ErrMalformedMessage) {
+			s.logger.Printf("⚠️ Dropping message from %s: %v", ws.ID, err)
+			continue
+		}
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				s.logger.Printf("WebSocket error: %v", err)
+			}
+			ws.ReadErr = err
+			break
+		}
+
+		s.handleMessage(ws, message)
+	}
+
+	s.handleClientDisconnect(ws)
+}
+
+// maxMessageSize limits client messages, leaving room for an import-stories
+// CSV of maxImportBytes.
+const maxMessageSize = 2 * maxImportBytes
+
+// registerClient tracks a freshly upgraded connection until it disconnects.
+func (s *Server) registerClient(conn *websocket.Conn, ip string, identity clientIdentity, codec transport.WireCodec) *ExtendedWebSocket {
+	ws := &ExtendedWebSocket{
+		Conn:     conn,
+		ID:       generateID(),
+		RemoteIP: ip,
+		TeamID:   identity.TeamID,
+		UserID:   identity.UserID,
+		UserName: identity.UserName,
+		Codec:    codec,
+
+		ConnectedAt: s.clock.Now(),
+	}
+	ws.IsAlive.Store(true)
+	conn.SetReadLimit(maxMessageSize)
+
+	s.hub.AddClient(ws)
+
+	s.logger.Printf("✅ Client connected: %s from %s", ws.ID, ws.RemoteIP)
+	s.connMetrics.opened.Add(s.ctx, 1)
+
+	// Setup pong handler for heartbeat
+	ws.SetPongHandler(func(payload string) error {
+		ws.IsAlive.Store(true)
+		ws.RecordPong([]byte(payload), s.clock.Now())
+		return nil
+	})
+	return ws
+}
+
+// Handler returns the HTTP handler serving the WebSocket endpoint and the
+// REST API, wrapped in CORS handling for the allowed origins.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/ws", s.handleWebSocket)
+	mux.HandleFunc("POST /api/rooms", s.requireAPIKey(ScopeRoomsCreate, s.handleCreateRoom))
+	mux.HandleFunc("HEAD /api/rooms/{id}", s.handleRoomExistsHTTP)
+	mux.HandleFunc("GET /api/rooms/{id}", s.handleGetRoom)
+	mux.HandleFunc("GET /api/rooms/{id}/export", s.requireAPIKey(ScopeExport, s.handleExport))
+	mux.HandleFunc("GET /api/rooms/{id}/report", s.requireAPIKey(ScopeExport, s.handleReport))
+	mux.HandleFunc("POST /api/rooms/{id}/invites", s.handleCreateInvite)
+	mux.HandleFunc("POST /api/rooms/{id}/spectator-links", s.handleCreateSpectatorLink)
+	mux.HandleFunc("GET /api/rooms/{id}/watch", s.handleWatchRoom)
+	mux.HandleFunc("POST /api/rooms/{id}/stories/import", s.handleImportStoriesHTTP)
+	mux.HandleFunc("GET /api/teams/{id}/velocity", s.handleTeamVelocity)
+	mux.HandleFunc("GET /api/teams/{id}/participants/stats", s.requireAPIKey(ScopeExport, s.handleParticipantStats))
+	mux.HandleFunc("GET /admin/rooms", s.requireAdmin(s.handleAdminListRooms))
+	mux.HandleFunc("GET /admin/rooms/{id}", s.requireAdmin(s.handleAdminGetRoom))
+	mux.HandleFunc("GET /admin/rooms/{id}/audit", s.requireAdmin(s.handleAdminRoomAudit))
+	mux.HandleFunc("GET /admin/rooms/{id}/events", s.requireAdmin(s.handleAdminRoomEvents))
+	mux.HandleFunc("DELETE /admin/rooms/{id}", s.requireAdmin(s.handleAdminDeleteRoom))
+	mux.HandleFunc("POST /admin/rooms/{id}/reset", s.requireAdmin(s.handleAdminResetRoom))
+	mux.HandleFunc("POST /admin/broadcast", s.requireAdmin(s.handleAdminBroadcast))
+	mux.HandleFunc("GET /admin/ws", s.requireAdmin(s.handleAdminFirehose))
+	mux.HandleFunc("GET /admin/api-keys", s.requireAdmin(requireFullAdmin(s.handleAdminListAPIKeys)))
+	mux.HandleFunc("POST /admin/api-keys", s.requireAdmin(requireFullAdmin(s.handleAdminCreateAPIKey)))
+	mux.HandleFunc("DELETE /admin/api-keys/{id}", s.requireAdmin(requireFullAdmin(s.handleAdminRevokeAPIKey)))
+	if s.oidc != nil {
+		mux.HandleFunc("GET /auth/login", s.handleLogin)
+		mux.HandleFunc("GET /auth/callback", s.handleAuthCallback)
+	}
+	if s.socketIO {
+		mux.HandleFunc(socketIOPath, s.handleSocketIO)
+	}
+	if s.static != nil {
+		mux.Handle("/", staticHandler(s.static))
+	} else {
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("WebSocket server running"))
+		})
+	}
+
+	return s.requestLogMiddleware(s.corsMiddleware(mux))
+}
+
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+
+		// Check if the origin is allowed
+		originAllowed := origin != "" && s.originAllowed(origin)
+		if originAllowed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if !originAllowed && origin != "" {
+			s.logger.Printf("CORS: Rejected request from origin: %s", origin)
+			http.Error(w, "CORS origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Room-Passcode")
+		w.Header().Set("Access-Control-Max-Age", "86400") // Cache preflight for 24 hours
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func generateID() string {
+	return time.Now().Format("20060102150405.000000") + "-" + os.Getenv("HOSTNAME")
+}
+
+func generateToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("Error generating token: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,141 @@
+package pokerserver
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// Team IDs are chosen by the teams themselves, so they are kept to
+// characters that are safe in a URL path
+var teamIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+const (
+	defaultVelocitySessions = 20
+	maxVelocitySessions     = 100
+)
+
+// TeamVelocity is the estimated points of a team's recent sessions, oldest
+// first, for charting throughput.
+type TeamVelocity struct {
+	TeamID        string           `json:"teamId"`
+	Sessions      []SessionSummary `json:"sessions"`
+	AveragePoints *float64         `json:"averagePoints,omitempty"`
+}
+
+// handleSetTeam lets the facilitator file the room's sessions under a team,
+// or take them out of it with an empty "teamId". Rounds already stored keep
+// the team they were saved with until they are saved again.
+func (s *Server) handleSetTeam(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	teamID, _ := data["teamId"].(string)
+
+	room, exists := s.hub.Room(roomID)
+
+	if !exists {
+		return
+	}
+
+	if teamID != "" && !teamIDPattern.MatchString(teamID) {
+		s.logf(ws.Context(), "⚠️ Ignoring set-team from %s in room %s: invalid team ID %q", ws.ID, roomID, teamID)
+		s.sendError(ws, "set-team", errCodeInvalidInput, "teamId is invalid", nil)
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring set-team from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "set-team", errCodeNotAllowed, "Only the facilitator can set the team", nil)
+		return
+	}
+	if room.TeamScoped {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring set-team from %s in room %s, which belongs to team %s", ws.ID, roomID, room.TeamID)
+		s.sendError(ws, "set-team", errCodeNotAllowed, "The room belongs to its team", nil)
+		return
+	}
+	room.Apply(&roompkg.TeamEvent{TeamID: teamID})
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "👥 Room %s now belongs to team %q", roomID, teamID)
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+// handleTeamVelocity serves the points estimated in each of the team's
+// stored sessions. Only totals are shared, no stories or votes, so private
+// rooms are included without their passcode.
+func (s *Server) handleTeamVelocity(w http.ResponseWriter, r *http.Request) {
+	teamID := r.PathValue("id")
+	if !teamIDPattern.MatchString(teamID) {
+		http.Error(w, "invalid team ID", http.StatusBadRequest)
+		return
+	}
+
+	sessions, rounds, ok := s.loadTeamSessions(w, r, teamID)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, teamVelocity(teamID, sessions, rounds))
+}
+
+// loadTeamSessions loads the team's latest sessions, as many as the
+// "sessions" query parameter asks for, answering the request itself when it
+// can't.
+func (s *Server) loadTeamSessions(w http.ResponseWriter, r *http.Request, teamID string) ([]Session, [][]RoundRecord, bool) {
+	limit := defaultVelocitySessions
+	if raw := r.URL.Query().Get("sessions"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxVelocitySessions {
+			http.Error(w, "sessions must be between 1 and "+strconv.Itoa(maxVelocitySessions), http.StatusBadRequest)
+			return nil, nil, false
+		}
+		limit = n
+	}
+
+	loader, ok := s.storage.(TeamLoader)
+	if !ok {
+		http.Error(w, "team not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	sessions, rounds, err := loader.LoadTeamSessions(r.Context(), teamID, limit)
+	if errors.Is(err, ErrSessionNotFound) {
+		http.Error(w, "team not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	if err != nil {
+		s.logger.Printf("Error loading sessions of team %s: %v", teamID, err)
+		http.Error(w, "failed to load team sessions", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return sessions, rounds, true
+}
+
+// teamVelocity summarizes each session, ending it at its last reveal unless
+// it was ended by the facilitator.
+func teamVelocity(teamID string, sessions []Session, rounds [][]RoundRecord) TeamVelocity {
+	velocity := TeamVelocity{TeamID: teamID, Sessions: make([]SessionSummary, 0, len(sessions))}
+	total := 0.0
+	for i, session := range sessions {
+		endedAt := session.StartedAt
+		if n := len(rounds[i]); n > 0 {
+			endedAt = time.UnixMilli(rounds[i][n-1].RevealedAt)
+		}
+		if session.Summary != nil {
+			endedAt = time.UnixMilli(session.Summary.EndedAt)
+		}
+		summary := roompkg.SummarizeSession(session, rounds[i], endedAt)
+		velocity.Sessions = append(velocity.Sessions, summary)
+		total += summary.TotalPoints
+	}
+	if len(sessions) > 0 {
+		average := math.Round(total*10/float64(len(sessions))) / 10
+		velocity.AveragePoints = &average
+	}
+	return velocity
+}
@@ -0,0 +1,124 @@
+package pokerserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// fakeTeamStorage serves the stored sessions of one team.
+type fakeTeamStorage struct {
+	fakeSessionStorage
+	teamID   string
+	sessions []Session
+	rounds   [][]RoundRecord
+}
+
+func (f *fakeTeamStorage) LoadTeamSessions(ctx context.Context, teamID string, limit int) ([]Session, [][]RoundRecord, error) {
+	if teamID != f.teamID {
+		return nil, nil, ErrSessionNotFound
+	}
+	start := max(len(f.sessions)-limit, 0)
+	return f.sessions[start:], f.rounds[start:], nil
+}
+
+func velocityRequest(server *Server, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestTeamVelocity(t *testing.T) {
+	start := time.Now().Add(-48 * time.Hour)
+	storage := &fakeTeamStorage{
+		teamID: "platform",
+		sessions: []Session{
+			{RoomID: "sprint-1", StartedAt: start, TeamID: "platform"},
+			{RoomID: "sprint-2", StartedAt: start.Add(24 * time.Hour), TeamID: "platform"},
+		},
+		rounds: [][]RoundRecord{
+			{
+				{ID: "1", Story: &roompkg.Story{Title: "A"}, FinalEstimate: "5", RevealedAt: start.Add(time.Hour).UnixMilli()},
+				{ID: "2", Story: &roompkg.Story{Title: "B"}, FinalEstimate: "8", RevealedAt: start.Add(2 * time.Hour).UnixMilli()},
+			},
+			{
+				{ID: "1", Story: &roompkg.Story{Title: "C"}, FinalEstimate: "?", RevealedAt: start.Add(25 * time.Hour).UnixMilli()},
+				{ID: "2", Story: &roompkg.Story{Title: "D"}, FinalEstimate: "3", RevealedAt: start.Add(26 * time.Hour).UnixMilli()},
+			},
+		},
+	}
+	server := New(WithStorage(storage))
+
+	rec := velocityRequest(server, "/api/teams/platform/velocity")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var velocity TeamVelocity
+	if err := json.Unmarshal(rec.Body.Bytes(), &velocity); err != nil {
+		t.Fatalf("Failed to decode velocity: %v", err)
+	}
+	if len(velocity.Sessions) != 2 || velocity.Sessions[0].TotalPoints != 13 || velocity.Sessions[1].TotalPoints != 3 {
+		t.Errorf("Unexpected sessions: %+v", velocity.Sessions)
+	}
+	if velocity.Sessions[0].EndedAt != start.Add(2*time.Hour).UnixMilli() {
+		t.Errorf("Expected the session to end at its last reveal, got %d", velocity.Sessions[0].EndedAt)
+	}
+	if velocity.AveragePoints == nil || *velocity.AveragePoints != 8 {
+		t.Errorf("Expected 8 points on average, got %v", velocity.AveragePoints)
+	}
+
+	rec = velocityRequest(server, "/api/teams/platform/velocity?sessions=1")
+	if err := json.Unmarshal(rec.Body.Bytes(), &velocity); err != nil || len(velocity.Sessions) != 1 || velocity.Sessions[0].RoomID != "sprint-2" {
+		t.Errorf("Expected only the latest session, got %+v", velocity.Sessions)
+	}
+
+	for path, want := range map[string]int{
+		"/api/teams/unknown/velocity":              http.StatusNotFound,
+		"/api/teams/platform/velocity?sessions=0":  http.StatusBadRequest,
+		"/api/teams/bad%20team/velocity":           http.StatusBadRequest,
+		"/api/teams/platform/velocity?sessions=no": http.StatusBadRequest,
+	} {
+		if rec := velocityRequest(server, path); rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, rec.Code)
+		}
+	}
+
+	if rec := velocityRequest(New(), "/api/teams/platform/velocity"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without storage, got %d", rec.Code)
+	}
+}
+
+func TestHandleSetTeam(t *testing.T) {
+	server := New()
+	httpServer, ws := createTestWSConnection(t, server)
+	defer httpServer.Close()
+	defer ws.Close()
+
+	sendMessage(t, ws, "join-room", map[string]interface{}{"roomId": "team-room", "name": "Alice", "teamId": "platform"})
+	msg := readMessage(t, ws, 2*time.Second)
+	if data, _ := msg.Data.(map[string]interface{}); data["teamId"] != "platform" {
+		t.Errorf("Expected the room to be filed under the team it was created with, got %v", data["teamId"])
+	}
+
+	sendMessage(t, ws, "set-team", map[string]interface{}{"roomId": "team-room", "teamId": "not a team"})
+	if msg := readMessage(t, ws, 2*time.Second); msg.Type != "error" || msg.Data.(map[string]interface{})["code"] != errCodeInvalidInput {
+		t.Fatalf("Expected an invalid team ID to be refused, got %s %v", msg.Type, msg.Data)
+	}
+	sendMessage(t, ws, "set-team", map[string]interface{}{"roomId": "team-room", "teamId": "mobile"})
+	msg = readMessage(t, ws, 2*time.Second)
+	if data, _ := msg.Data.(map[string]interface{}); data["teamId"] != "mobile" {
+		t.Errorf("Expected team mobile, got %v", data["teamId"])
+	}
+
+	room, _ := server.hub.Room("team-room")
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if session := room.Session(); session.TeamID != "mobile" {
+		t.Errorf("Expected rounds to be stored under the team, got %q", session.TeamID)
+	}
+}
@@ -0,0 +1,72 @@
+package pokerserver
+
+import (
+	"slices"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// handleSetVoteChanges lets the facilitator allow votes to change after the
+// reveal, for teams that converge by nudging their votes as they discuss.
+func (s *Server) handleSetVoteChanges(ws *ExtendedWebSocket, data map[string]interface{}) {
+	roomID, _ := data["roomId"].(string)
+	allowed, _ := data["allowed"].(bool)
+
+	room, exists := s.hub.Room(roomID)
+	if !exists {
+		return
+	}
+
+	room.Mu.Lock()
+	if room.FacilitatorID != ws.ID {
+		room.Mu.Unlock()
+		s.logf(ws.Context(), "⚠️ Ignoring set-vote-changes from non-facilitator %s in room %s", ws.ID, roomID)
+		s.sendError(ws, "set-vote-changes", errCodeNotAllowed, "Only the facilitator can let votes change", nil)
+		return
+	}
+	room.Apply(&roompkg.VoteChangesEvent{Allowed: allowed})
+	room.Mu.Unlock()
+
+	s.logf(ws.Context(), "✏️ Room %s vote changes after reveal allowed: %t", roomID, allowed)
+	s.broadcastRoomState(ws.Context(), roomID)
+}
+
+// changeRevealedVote changes the sender's vote in the revealed round, keeping
+// the one first revealed, and marks the round adjusted in the history.
+func (s *Server) changeRevealedVote(ws *ExtendedWebSocket, room *roompkg.State, vote string) {
+	room.Mu.Lock()
+	if vote == "" || !room.Settings.AcceptsVote(vote) {
+		room.Mu.Unlock()
+		s.sendInputError(ws, "vote", &inputError{Field: "vote", Reason: reasonNotInDeck})
+		return
+	}
+	i := slices.IndexFunc(room.LastRound.Participants, func(p roompkg.Participant) bool { return p.ID == ws.ID })
+	if i < 0 || !room.LastRound.Participants[i].HasVoted() {
+		room.Mu.Unlock()
+		s.sendError(ws, "vote", errCodeNotAllowed, "Only votes of the revealed round can change", nil)
+		return
+	}
+	if *room.LastRound.Participants[i].Vote == vote {
+		room.Mu.Unlock()
+		return
+	}
+	room.Apply(&roompkg.VoteChangeEvent{ClientID: ws.ID, Vote: vote})
+	changed := room.LastRound.Participants[i]
+	round, _ := room.LastRoundRecord()
+	room.RecordAudit(roompkg.AuditEntry{Action: roompkg.AuditVoteChanged, ActorID: ws.ID, RoundID: round.ID, Votes: map[string]string{changed.Name: vote}})
+	session := room.Session()
+	room.Mu.Unlock()
+
+	s.saveRound(session, round)
+
+	s.logf(ws.Context(), "✏️ %s changed their vote from %s to %s in room %s", ws.ID, changed.OriginalVote, vote, room.ID)
+	s.broadcastToRoom(ws.Context(), room.ID, "vote-changed", map[string]interface{}{
+		"id":           ws.ID,
+		"name":         changed.Name,
+		"roundId":      round.ID,
+		"originalVote": changed.OriginalVote,
+		"vote":         vote,
+		"stats":        round.Stats,
+	})
+	s.broadcastRoomState(ws.Context(), room.ID)
+}
@@ -0,0 +1,62 @@
+package pokerserver
+
+import (
+	"slices"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestVoteChangesAfterReveal(t *testing.T) {
+	server := New()
+	roomID := "adjusted-room"
+
+	alice := server.ConnectMemory()
+	defer alice.Close()
+	alice.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Alice"})
+	bob := server.ConnectMemory()
+	defer bob.Close()
+	bob.Send("join-room", map[string]interface{}{"roomId": roomID, "name": "Bob"})
+
+	alice.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "3"})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	alice.Send("reveal", map[string]interface{}{"roomId": roomID})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	if data := awaitMessage(t, bob, "error").Data.(map[string]interface{}); data["code"] != errCodeInvalidPhase {
+		t.Errorf("Expected revealed votes to be fixed by default, got %v", data)
+	}
+
+	alice.Send("set-vote-changes", map[string]interface{}{"roomId": roomID, "allowed": true})
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "5"})
+	changed := awaitMessage(t, alice, "vote-changed").Data.(map[string]interface{})
+	stats := changed["stats"].(map[string]interface{})
+	if changed["originalVote"] != "8" || changed["vote"] != "5" || stats["average"] != float64(4) {
+		t.Errorf("Expected Bob's change from 8 to 5 with new stats, got %v", changed)
+	}
+
+	room, _ := server.hub.Room(roomID)
+	room.Mu.RLock()
+	round := room.History[len(room.History)-1]
+	events := append([]roompkg.EventRecord(nil), room.Events...)
+	room.Mu.RUnlock()
+	bobsVote := round.Votes[slices.IndexFunc(round.Votes, func(v roompkg.RoundVote) bool { return v.Name == "Bob" })]
+	if !round.Adjusted || bobsVote.OriginalVote != "8" || bobsVote.Vote != "5" {
+		t.Errorf("Expected the round to be marked adjusted, got %+v", round)
+	}
+
+	replayed, err := roompkg.Replay(roomID, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed := replayed.History[len(replayed.History)-1]; !replayed.Adjusted || *replayed.Stats.Average != 4 {
+		t.Errorf("Expected the replayed round to be adjusted, got %+v", replayed)
+	}
+
+	// Going back to the revealed vote undoes the adjustment
+	bob.Send("vote", map[string]interface{}{"roomId": roomID, "vote": "8"})
+	room.Mu.RLock()
+	defer room.Mu.RUnlock()
+	if round := room.History[len(room.History)-1]; round.Adjusted || room.Participants[bob.ID].OriginalVote != "" {
+		t.Errorf("Expected the round no longer adjusted, got %+v", round)
+	}
+}
@@ -0,0 +1,250 @@
+package pokerserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+// Event is something that happened in a room, as posted to webhooks.
+type Event struct {
+	Type   string      `json:"type"`
+	RoomID string      `json:"roomId"`
+	Time   int64       `json:"time"`
+	Data   interface{} `json:"data"`
+}
+
+// Event types posted to webhooks
+const (
+	EventAsyncRoundClosed = "async-round-closed"
+	EventSessionEnded     = "session-ended"
+	EventRoundRevealed    = "round-revealed"
+	EventFinalEstimate    = "final-estimate"
+	EventSessionStarted   = "session-started"
+)
+
+// slackEvents are the events posted to Slack, leaving out the ones that come
+// with every round so the channel isn't flooded.
+var slackEvents = map[string]bool{
+	EventAsyncRoundClosed: true,
+	EventSessionEnded:     true,
+	EventSessionStarted:   true,
+}
+
+// webhookNotifier posts events to the configured webhooks: the event as JSON
+// to each URL, a one-line description to Slack, an adaptive card to Teams,
+// and whatever their template renders to the templated destinations.
+type webhookNotifier struct {
+	urls       []string
+	slackURL   string
+	teamsURL   string
+	templates  []webhookDestination
+	httpClient *http.Client
+}
+
+// webhookDestination is a parsed WebhookTemplate.
+type webhookDestination struct {
+	url         string
+	contentType string
+	events      map[string]bool
+	body        *template.Template
+}
+
+// webhookTemplateFuncs are available in webhook templates: json encodes a
+// value, text describes the event as the Slack message does, and stat formats
+// a statistic such as .Data.Stats.Average.
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"text": slackText,
+	"stat": formatStat,
+}
+
+func parseWebhookTemplate(body string) (*template.Template, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, errors.New("template is empty")
+	}
+	return template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(body)
+}
+
+// newWebhookNotifier returns nil when no webhook is configured. Templates that
+// don't parse are left out; Config.Validate reports them.
+func newWebhookNotifier(cfg WebhooksConfig, outbound outboundPolicy) *webhookNotifier {
+	if len(cfg.URLs) == 0 && cfg.SlackURL == "" && cfg.TeamsURL == "" && len(cfg.Templates) == 0 {
+		return nil
+	}
+	n := &webhookNotifier{
+		urls:       cfg.URLs,
+		slackURL:   cfg.SlackURL,
+		teamsURL:   cfg.TeamsURL,
+		httpClient: outbound.client(),
+	}
+	for _, webhook := range cfg.Templates {
+		body, err := parseWebhookTemplate(webhook.Body)
+		if err != nil {
+			continue
+		}
+		destination := webhookDestination{url: webhook.URL, contentType: webhook.ContentType, body: body}
+		if destination.contentType == "" {
+			destination.contentType = "application/json"
+		}
+		if len(webhook.Events) > 0 {
+			destination.events = make(map[string]bool, len(webhook.Events))
+			for _, eventType := range webhook.Events {
+				destination.events[eventType] = true
+			}
+		}
+		n.templates = append(n.templates, destination)
+	}
+	return n
+}
+
+// Notify delivers the event to every destination, returning the failures.
+func (n *webhookNotifier) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, url := range n.urls {
+		if err := n.post(ctx, url, event); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if n.slackURL != "" && slackEvents[event.Type] {
+		if err := n.post(ctx, n.slackURL, map[string]string{"text": slackText(event)}); err != nil {
+			errs = append(errs, fmt.Errorf("slack: %w", err))
+		}
+	}
+	if n.teamsURL != "" && teamsEvents[event.Type] {
+		if err := n.post(ctx, n.teamsURL, teamsMessage(event)); err != nil {
+			errs = append(errs, fmt.Errorf("teams: %w", err))
+		}
+	}
+	for _, destination := range n.templates {
+		if destination.events != nil && !destination.events[event.Type] {
+			continue
+		}
+		var body bytes.Buffer
+		if err := destination.body.Execute(&body, event); err != nil {
+			errs = append(errs, fmt.Errorf("template: %w", err))
+			continue
+		}
+		if err := n.send(ctx, destination.url, destination.contentType, body.Bytes()); err != nil {
+			errs = append(errs, fmt.Errorf("template: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *webhookNotifier) post(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, url, "application/json", body)
+}
+
+func (n *webhookNotifier) send(ctx context.Context, url, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// slackText describes an event for a Slack channel.
+func slackText(event Event) string {
+	switch event.Type {
+	case EventAsyncRoundClosed:
+		round, _ := event.Data.(RoundRecord)
+		text := fmt.Sprintf("⏰ Voting on %s closed in room %s with %d vote(s)", storyTitle(round.Story), event.RoomID, round.Stats.VoteCount)
+		if round.Stats.Average != nil {
+			text += fmt.Sprintf(", average %s, median %s", formatStat(round.Stats.Average), formatStat(round.Stats.Median))
+		}
+		return text
+	case EventRoundRevealed:
+		round, _ := event.Data.(RoundRecord)
+		text := fmt.Sprintf("🃏 Votes on %s revealed in room %s: %d vote(s)", storyTitle(round.Story), event.RoomID, round.Stats.VoteCount)
+		if round.Stats.Average != nil {
+			text += fmt.Sprintf(", average %s, median %s", formatStat(round.Stats.Average), formatStat(round.Stats.Median))
+		}
+		return text
+	case EventFinalEstimate:
+		round, _ := event.Data.(RoundRecord)
+		return fmt.Sprintf("✅ %s estimated at %s in room %s", storyTitle(round.Story), round.FinalEstimate, event.RoomID)
+	case EventSessionStarted:
+		info, _ := event.Data.(roompkg.Info)
+		name := "The session"
+		if info.Name != "" {
+			name = "“" + info.Name + "”"
+		}
+		return fmt.Sprintf("🔔 %s is starting in room %s", name, event.RoomID)
+	case EventSessionEnded:
+		summary, _ := event.Data.(SessionSummary)
+		text := fmt.Sprintf("🏁 Session in room %s ended: %d stories estimated, %s points in total", event.RoomID, summary.StoriesEstimated, strconv.FormatFloat(summary.TotalPoints, 'f', -1, 64))
+		if summary.ParticipationRate != nil {
+			text += fmt.Sprintf(", %s%% participation", formatStat(summary.ParticipationRate))
+		}
+		return text
+	default:
+		return fmt.Sprintf("Planning Poker: %s in room %s", event.Type, event.RoomID)
+	}
+}
+
+// storyTitle quotes a story's title for a message, falling back to "the story".
+func storyTitle(story *roompkg.Story) string {
+	if story == nil || story.Title == "" {
+		return "the story"
+	}
+	return "“" + story.Title + "”"
+}
+
+// notify posts an event to the webhooks in the background, when any are
+// configured. Failures are only logged, as the room carries on either way.
+func (s *Server) notify(roomID, eventType string, data interface{}) {
+	if s.webhooks == nil {
+		return
+	}
+	event := Event{Type: eventType, RoomID: roomID, Time: s.clock.Now().UnixMilli(), Data: data}
+	s.webhookWG.Add(1)
+	go func() {
+		defer s.webhookWG.Done()
+		// Not tied to the server's context, so shutdown doesn't cut deliveries short
+		ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+		defer cancel()
+		if err := s.webhooks.Notify(ctx, event); err != nil {
+			s.logger.Printf("❌ Notifying %s for room %s failed: %v", eventType, roomID, err)
+			s.reportError(ctx, ErrorEvent{Err: fmt.Errorf("notifying %s: %w", eventType, err), Source: ErrorSourceWebhook, RoomID: roomID})
+		}
+	}()
+}
+
+// waitForWebhooks lets deliveries in progress finish, until ctx is done.
+func (s *Server) waitForWebhooks(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.webhookWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,68 @@
+package pokerserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	roompkg "github.com/kjaniec-dev/planning-poker/servers/golang/internal/room"
+)
+
+func TestWebhookTemplates(t *testing.T) {
+	var mu sync.Mutex
+	var bodies, contentTypes []string
+	destination := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		mu.Unlock()
+	}))
+	defer destination.Close()
+
+	notifier := newWebhookNotifier(WebhooksConfig{Templates: []WebhookTemplate{
+		{URL: destination.URL, Body: `{"content": {{text . | json}}, "room": {{json .RoomID}}}`},
+		{URL: destination.URL, Body: `votes={{.Data.Stats.VoteCount}}`, ContentType: "text/plain", Events: []string{EventAsyncRoundClosed}},
+	}}, loopbackOutbound())
+
+	round := RoundRecord{Story: &roompkg.Story{Title: `Say "hi"`}, Stats: roompkg.VoteStats{VoteCount: 3}}
+	if err := notifier.Notify(t.Context(), Event{Type: EventAsyncRoundClosed, RoomID: "room", Data: round}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if err := notifier.Notify(t.Context(), Event{Type: EventSessionEnded, RoomID: "room", Data: SessionSummary{}}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 posts, the second template only for async rounds, got %v", bodies)
+	}
+	if !strings.HasPrefix(bodies[0], `{"content": "⏰ Voting on “Say \"hi\"”`) || !strings.HasSuffix(bodies[0], `"room": "room"}`) {
+		t.Errorf("Unexpected templated body: %s", bodies[0])
+	}
+	if bodies[1] != "votes=3" || contentTypes[1] != "text/plain" || contentTypes[0] != "application/json" {
+		t.Errorf("Unexpected plain body %q with content types %v", bodies[1], contentTypes)
+	}
+}
+
+func TestWebhookTemplateValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Webhooks.Templates = []WebhookTemplate{
+		{URL: "hooks.example.com", Body: "{}"},
+		{URL: "https://hooks.example.com", Body: "{{.Type"},
+		{URL: "https://hooks.example.com"},
+	}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected invalid templates to fail validation")
+	}
+	for _, want := range []string{"webhooks.templates[0].url", "webhooks.templates[1].body", "webhooks.templates[2].body"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got: %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/kjaniec-dev/planning-poker/servers/golang/pkg/pokerserver"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// configureTLS prepares httpServer for HTTPS. With autocert it returns the
+// plain HTTP server that answers ACME HTTP-01 challenges and redirects
+// everything else to HTTPS; the caller runs it alongside httpServer.
+func configureTLS(httpServer *http.Server, cfg pokerserver.TLSConfig) *http.Server {
+	if len(cfg.AutocertHosts) == 0 {
+		return nil
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.AutocertHosts...),
+		Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+		Email:      cfg.AutocertEmail,
+	}
+	httpServer.TLSConfig = manager.TLSConfig()
+
+	return &http.Server{
+		Addr:    ":" + cfg.HTTPPort,
+		Handler: manager.HTTPHandler(nil),
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:web
+var webFiles embed.FS
+
+// embeddedFrontend returns the frontend copied into web/ at build time, if any.
+func embeddedFrontend() (fs.FS, bool) {
+	frontend, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		return nil, false
+	}
+	if _, err := fs.Stat(frontend, "index.html"); err != nil {
+		return nil, false
+	}
+	return frontend, true
+}
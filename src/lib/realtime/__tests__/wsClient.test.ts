@@ -28,6 +28,7 @@ describe("wsClient", () => {
   afterEach(() => {
     mockServer.close();
     jest.clearAllTimers();
+    jest.restoreAllMocks();
   });
 
   // Import after setup to avoid connection attempts during module load
@@ -35,6 +36,39 @@ describe("wsClient", () => {
     return require("../wsClient");
   };
 
+  // The reconnect wsClient schedules, found by its back-off delay
+  const scheduledReconnect = (setTimeoutSpy: jest.SpyInstance, delay: number) =>
+    setTimeoutSpy.mock.calls.find((call) => call[1] === delay)?.[0] as
+      | (() => void)
+      | undefined;
+
+  type ServerSocket = {
+    send: (data: string) => void;
+    close: (options?: {
+      code: number;
+      reason: string;
+      wasClean: boolean;
+    }) => void;
+  };
+  type SentMessage = { type: string; data: Record<string, unknown> };
+
+  // Keeps the server side of each connection, in order, with the messages
+  // the client sent on it
+  const acceptConnections = () => {
+    const connections: { socket: ServerSocket; messages: SentMessage[] }[] =
+      [];
+    mockServer.on("connection", (socket) => {
+      const connection = { socket, messages: [] as SentMessage[] };
+      connections.push(connection);
+      socket.on("message", (data) => {
+        connection.messages.push(JSON.parse(data.toString()));
+      });
+    });
+    return connections;
+  };
+
+  const wait = (ms = 100) => new Promise((resolve) => setTimeout(resolve, ms));
+
   describe("connectIfNeeded", () => {
     it("should establish WebSocket connection", (done) => {
       const { connectIfNeeded } = getWsClient();
@@ -212,6 +246,257 @@ describe("wsClient", () => {
     });
   });
 
+  describe("close codes", () => {
+    it.each([4002, 4003, 4004, 4005])(
+      "should not reconnect after close code %i",
+      async (code) => {
+        const { joinRoom, subscribeToMessages } = getWsClient();
+        const setTimeoutSpy = jest.spyOn(window, "setTimeout");
+        jest.spyOn(console, "log").mockImplementation();
+
+        mockServer.on("connection", (socket) => {
+          socket.on("message", () => {
+            socket.close({ code, reason: "Goodbye", wasClean: true });
+          });
+        });
+
+        const disconnected = new Promise((resolve) => {
+          subscribeToMessages((message: { type: string; data: unknown }) => {
+            if (message.type === "disconnected") resolve(message.data);
+          });
+        });
+        joinRoom("test-room", "Alice");
+
+        expect(await disconnected).toEqual({ code, reason: "Goodbye" });
+        expect(scheduledReconnect(setTimeoutSpy, 2000)).toBeUndefined();
+      },
+    );
+
+    it.each([1000, 4000, 4001])(
+      "should reconnect with back-off after close code %i",
+      async (code) => {
+        const { joinRoom, subscribeToMessages } = getWsClient();
+        const setTimeoutSpy = jest.spyOn(window, "setTimeout");
+        jest.spyOn(console, "log").mockImplementation();
+        const received: string[] = [];
+        subscribeToMessages((message: { type: string }) => {
+          received.push(message.type);
+        });
+
+        const joins: string[] = [];
+        let closed = false;
+        mockServer.on("connection", (socket) => {
+          socket.on("message", (data) => {
+            const message = JSON.parse(data.toString());
+            joins.push(message.data.roomId);
+            if (!closed) {
+              closed = true;
+              socket.close({ code, reason: "Going away", wasClean: true });
+            }
+          });
+        });
+
+        joinRoom("test-room", "Alice");
+        await new Promise((resolve) => setTimeout(resolve, 100));
+
+        const reconnect = scheduledReconnect(setTimeoutSpy, 2000);
+        expect(reconnect).toBeDefined();
+        expect(received).not.toContain("disconnected");
+
+        // The room is joined again on the new connection
+        reconnect?.();
+        await new Promise((resolve) => setTimeout(resolve, 100));
+        expect(joins).toEqual(["test-room", "test-room"]);
+      },
+    );
+  });
+
+  describe("resume", () => {
+    // Joins test-room and gets a session for it on a first connection
+    const joinWithSession = async () => {
+      const wsClient = getWsClient();
+      const setTimeoutSpy = jest.spyOn(window, "setTimeout");
+      jest.spyOn(console, "log").mockImplementation();
+      const connections = acceptConnections();
+
+      wsClient.joinRoom("test-room", "Alice");
+      await wait();
+      const [first] = connections;
+      first.socket.send(
+        JSON.stringify({
+          type: "session",
+          data: { roomId: "test-room", sessionToken: "token-1" },
+        }),
+      );
+      first.socket.send(
+        JSON.stringify({
+          type: "room-state",
+          seq: 7,
+          data: { seq: 7, participants: [] },
+        }),
+      );
+      await wait();
+      return { wsClient, setTimeoutSpy, connections };
+    };
+
+    // Drops the first connection and lets the client reconnect
+    const reconnect = async (
+      setTimeoutSpy: jest.SpyInstance,
+      connections: { socket: ServerSocket }[],
+    ) => {
+      connections[0].socket.close({
+        code: 4000,
+        reason: "Going away",
+        wasClean: true,
+      });
+      await wait();
+      scheduledReconnect(setTimeoutSpy, 2000)?.();
+      await wait();
+    };
+
+    it("should resume the session after reconnecting", async () => {
+      const { setTimeoutSpy, connections } = await joinWithSession();
+      await reconnect(setTimeoutSpy, connections);
+
+      expect(connections).toHaveLength(2);
+      expect(connections[1].messages).toEqual([
+        {
+          type: "resume",
+          data: { roomId: "test-room", sessionToken: "token-1", lastSeq: 7 },
+        },
+      ]);
+    });
+
+    it("should join again when the resume is refused", async () => {
+      const { setTimeoutSpy, connections } = await joinWithSession();
+      await reconnect(setTimeoutSpy, connections);
+
+      connections[1].socket.send(
+        JSON.stringify({
+          type: "resume-failed",
+          data: { roomId: "test-room", reason: "Session expired" },
+        }),
+      );
+      await wait();
+
+      const [resume, join] = connections[1].messages;
+      expect(resume.type).toBe("resume");
+      expect(join).toEqual({
+        type: "join-room",
+        data: expect.objectContaining({
+          roomId: "test-room",
+          name: "Alice",
+          resumable: true,
+        }),
+      });
+      expect(connections[1].messages).toHaveLength(2);
+    });
+
+    it("should not resume a room after joining another", async () => {
+      const { wsClient, setTimeoutSpy, connections } = await joinWithSession();
+      wsClient.joinRoom("other-room", "Alice");
+      await wait();
+      await reconnect(setTimeoutSpy, connections);
+
+      expect(connections[1].messages).toEqual([
+        {
+          type: "join-room",
+          data: expect.objectContaining({ roomId: "other-room" }),
+        },
+      ]);
+    });
+
+    it("should not resume a room after being removed from it", async () => {
+      const { wsClient, connections } = await joinWithSession();
+      connections[0].socket.close({
+        code: 4002,
+        reason: "Kicked",
+        wasClean: true,
+      });
+      await wait();
+
+      wsClient.joinRoom("test-room", "Alice");
+      await wait();
+      expect(connections[1].messages).toEqual([
+        {
+          type: "join-room",
+          data: expect.objectContaining({ roomId: "test-room" }),
+        },
+      ]);
+    });
+  });
+
+  describe("sequence numbers", () => {
+    // Joins test-room and sends the client the given messages, returning the
+    // messages the client passed on and those it sent after joining
+    const receive = async (messages: Record<string, unknown>[]) => {
+      const { joinRoom, subscribeToMessages } = getWsClient();
+      const connections = acceptConnections();
+      const received: { type: string; seq?: number }[] = [];
+      subscribeToMessages((message: { type: string; seq?: number }) => {
+        received.push(message);
+      });
+
+      joinRoom("test-room", "Alice");
+      await wait();
+      for (const message of messages) {
+        connections[0].socket.send(JSON.stringify(message));
+      }
+      await wait();
+      return { received, sent: connections[0].messages.slice(1) };
+    };
+
+    it("should pass on broadcasts received in order", async () => {
+      const { received, sent } = await receive([
+        { type: "room-state", seq: 1, data: {} },
+        { type: "participant-voted", seq: 2, data: {} },
+        { type: "revealed", seq: 3, data: {} },
+      ]);
+
+      expect(received.map((message) => message.seq)).toEqual([1, 2, 3]);
+      expect(sent).toEqual([]);
+    });
+
+    it("should ask to sync once when broadcasts were missed", async () => {
+      const { received, sent } = await receive([
+        { type: "room-state", seq: 1, data: {} },
+        { type: "participant-voted", seq: 4, data: {} },
+        { type: "participant-voted", seq: 5, data: {} },
+      ]);
+
+      expect(received.map((message) => message.seq)).toEqual([1, 4, 5]);
+      expect(sent).toEqual([{ type: "sync", data: { roomId: "test-room" } }]);
+    });
+
+    it("should drop broadcasts received twice", async () => {
+      const { received, sent } = await receive([
+        { type: "room-state", seq: 1, data: {} },
+        { type: "participant-voted", seq: 2, data: {} },
+        { type: "participant-voted", seq: 2, data: {} },
+      ]);
+
+      expect(received.map((message) => message.seq)).toEqual([1, 2]);
+      expect(sent).toEqual([]);
+    });
+
+    it("should not check messages without a seq", async () => {
+      const { received, sent } = await receive([
+        { type: "room-state", seq: 1, data: {} },
+        { type: "session", data: {} },
+        { type: "reaction", data: {} },
+        { type: "participant-voted", seq: 2, data: {} },
+      ]);
+
+      expect(received.map((message) => message.type)).toEqual([
+        "room-state",
+        "session",
+        "reaction",
+        "participant-voted",
+      ]);
+      expect(sent).toEqual([]);
+    });
+  });
+
   describe("error handling", () => {
     it("should handle malformed messages", (done) => {
       const { connectIfNeeded } = getWsClient();
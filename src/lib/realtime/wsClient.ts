@@ -1,6 +1,7 @@
 type WSMessage = {
   type: string;
   data: unknown;
+  seq?: number;
 };
 
 type MessageListener = (msg: WSMessage) => void;
@@ -16,6 +17,21 @@ let reconnectTimeout: number | null = null;
 let lastJoin: { roomId: string; name: string; participantId: string } | null =
   null;
 
+// Close codes the server uses when reconnecting wouldn't help: the
+// participant was kicked or banned, the room was closed, or the session
+// resumed on another connection
+const finalCloseCodes = new Set([4002, 4003, 4004, 4005]);
+
+// What a reconnect needs to resume the session instead of joining again
+let resumeState: {
+  roomId: string;
+  sessionToken: string;
+  lastSeq: number;
+} | null = null;
+
+// The seq of the last room broadcast received on this connection
+let lastBroadcastSeq: number | null = null;
+
 function getOrCreateParticipantId(): string {
   const stored = sessionStorage.getItem("planning-poker-participant-id");
   if (stored) {
@@ -54,12 +70,83 @@ function doJoinIfNeeded() {
         roomId: lastJoin.roomId,
         name: lastJoin.name,
         participantId: lastJoin.participantId,
+        resumable: true,
       },
     };
     socket.send(JSON.stringify(joinMessage));
   }
 }
 
+function doResumeOrJoin() {
+  if (
+    socket &&
+    socket.readyState === WebSocket.OPEN &&
+    lastJoin &&
+    resumeState?.roomId === lastJoin.roomId
+  ) {
+    socket.send(
+      JSON.stringify({
+        type: "resume",
+        data: {
+          roomId: resumeState.roomId,
+          sessionToken: resumeState.sessionToken,
+          lastSeq: resumeState.lastSeq,
+        },
+      }),
+    );
+    return;
+  }
+  doJoinIfNeeded();
+}
+
+// checkSeq drops room broadcasts received twice and asks for the room state
+// when some were missed. A room state is always kept: it replaces everything.
+function checkSeq(msg: WSMessage): boolean {
+  if (typeof msg.seq !== "number") return true;
+  if (lastBroadcastSeq !== null && msg.seq <= lastBroadcastSeq) {
+    return msg.type === "room-state" && msg.seq === lastBroadcastSeq;
+  }
+  if (
+    lastBroadcastSeq !== null &&
+    msg.seq > lastBroadcastSeq + 1 &&
+    msg.type !== "room-state" &&
+    lastJoin
+  ) {
+    sendMessage("sync", { roomId: lastJoin.roomId });
+  }
+  lastBroadcastSeq = msg.seq;
+  return true;
+}
+
+// trackSession keeps the session token and the last room state seen, and
+// falls back to joining when the server can't resume the session.
+function trackSession(msg: WSMessage) {
+  const data = msg.data as Record<string, unknown> | null;
+  switch (msg.type) {
+    case "session":
+      if (
+        typeof data?.roomId === "string" &&
+        typeof data.sessionToken === "string"
+      ) {
+        resumeState = {
+          roomId: data.roomId,
+          sessionToken: data.sessionToken,
+          lastSeq: 0,
+        };
+      }
+      break;
+    case "room-state":
+      if (resumeState && typeof data?.seq === "number") {
+        resumeState.lastSeq = data.seq;
+      }
+      break;
+    case "resume-failed":
+      resumeState = null;
+      doJoinIfNeeded();
+      break;
+  }
+}
+
 export function connectIfNeeded() {
   if (
     socket &&
@@ -79,13 +166,16 @@ export function connectIfNeeded() {
   ws.onopen = () => {
     isConnecting = false;
     reconnectAttempts = 0;
+    lastBroadcastSeq = null;
 
-    doJoinIfNeeded();
+    doResumeOrJoin();
   };
 
   ws.onmessage = (event) => {
     try {
       const msg: WSMessage = JSON.parse(event.data);
+      if (!checkSeq(msg)) return;
+      trackSession(msg);
       notifyListeners(msg);
     } catch (err) {
       console.error("❌ [wsClient] Failed to parse message:", err);
@@ -101,6 +191,15 @@ export function connectIfNeeded() {
     isConnecting = false;
     socket = null;
 
+    if (finalCloseCodes.has(event.code)) {
+      lastJoin = null;
+      resumeState = null;
+      notifyListeners({
+        type: "disconnected",
+        data: { code: event.code, reason: event.reason },
+      });
+      return;
+    }
     if (reconnectAttempts < maxReconnectAttempts) {
       reconnectAttempts++;
       const delay = Math.min(1000 * 2 ** reconnectAttempts, 5000);
@@ -122,6 +221,7 @@ export function connectIfNeeded() {
 export function joinRoom(roomId: string, name: string) {
   const participantId = getOrCreateParticipantId();
   lastJoin = { roomId, name, participantId };
+  lastBroadcastSeq = null;
   connectIfNeeded();
   if (socket && socket.readyState === WebSocket.OPEN) {
     doJoinIfNeeded();